@@ -64,7 +64,9 @@ generating_crds_v1() {
   "$CONTROLLER_GEN_BIN_PATH" "$CRD_OPTIONS" paths="./pkg/apis/nfs.rook.io/v1alpha1" output:crd:artifacts:config="$NFS_CRDS_DIR"
   # Format with yq for consistent whitespace
   $YQ_BIN_PATH read $NFS_CRDS_DIR/nfs.rook.io_nfsservers.yaml > $NFS_CRDS_DIR/crds.yaml
-  rm -f $NFS_CRDS_DIR/nfs.rook.io_nfsservers.yaml
+  echo "---" >> $NFS_CRDS_DIR/crds.yaml
+  $YQ_BIN_PATH read $NFS_CRDS_DIR/nfs.rook.io_nfsvolumesnapshots.yaml >> $NFS_CRDS_DIR/crds.yaml
+  rm -f $NFS_CRDS_DIR/nfs.rook.io_nfsservers.yaml $NFS_CRDS_DIR/nfs.rook.io_nfsvolumesnapshots.yaml
 }
 
 generating_crds_v1alpha2() {
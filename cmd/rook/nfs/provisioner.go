@@ -19,8 +19,10 @@ package nfs
 import (
 	"context"
 	"errors"
+	"os"
 
 	"github.com/rook/rook/cmd/rook/rook"
+	"github.com/rook/rook/pkg/operator/k8sutil"
 	"github.com/rook/rook/pkg/operator/nfs"
 	"github.com/rook/rook/pkg/util/flags"
 	"github.com/spf13/cobra"
@@ -35,6 +37,7 @@ var provisonerCmd = &cobra.Command{
 
 var (
 	provisioner *string
+	leaseName   *string
 )
 
 func init() {
@@ -42,6 +45,7 @@ func init() {
 	flags.SetLoggingFlags(provisonerCmd.Flags())
 
 	provisioner = provisonerCmd.Flags().String("provisioner", "", "Name of the provisioner. The provisioner will only provision volumes for claims that request a StorageClass with a provisioner field set equal to this name.")
+	leaseName = provisonerCmd.Flags().String("leaseName", "", "Name of the lease held by the replica of the NFS server serving the exports. Only the active replica provisions, deletes and syncs the volumes.")
 	provisonerCmd.RunE = startProvisioner
 }
 
@@ -61,13 +65,21 @@ func startProvisioner(cmd *cobra.Command, args []string) error {
 		logger.Fatalf("Error getting server version: %v", err)
 	}
 
-	clientNFSProvisioner, err := nfs.NewNFSProvisioner(clientset, rookClientset)
+	clientNFSProvisioner, err := nfs.NewNFSProvisioner(clientset, rookClientset, *provisioner)
 	if err != nil {
 		return err
 	}
 
-	pc := controller.NewProvisionController(clientset, *provisioner, clientNFSProvisioner, serverVersion.GitVersion)
+	options := []func(*controller.ProvisionController) error{}
+	if len(*leaseName) > 0 {
+		clientNFSProvisioner.SetActiveLease(os.Getenv(k8sutil.PodNamespaceEnvVar), *leaseName, os.Getenv(k8sutil.PodNameEnvVar))
+		// the replica holding the lease of the nfs server handles the volumes, not the leader of the provisioners
+		options = append(options, controller.LeaderElection(false))
+	}
+
+	pc := controller.NewProvisionController(clientset, *provisioner, clientNFSProvisioner, serverVersion.GitVersion, options...)
 	neverStopCtx := context.Background()
+	go clientNFSProvisioner.SyncVolumes(neverStopCtx)
 	pc.Run(neverStopCtx)
 	return nil
 }
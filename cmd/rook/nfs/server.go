@@ -17,9 +17,12 @@ limitations under the License.
 package nfs
 
 import (
+	"context"
 	"errors"
+	"os"
 
 	"github.com/rook/rook/cmd/rook/rook"
+	"github.com/rook/rook/pkg/operator/k8sutil"
 	"github.com/rook/rook/pkg/operator/nfs"
 	"github.com/rook/rook/pkg/util/flags"
 	"github.com/spf13/cobra"
@@ -34,6 +37,7 @@ https://github.com/rook/rook`,
 
 var (
 	ganeshaConfigPath *string
+	serverLeaseName   *string
 )
 
 func init() {
@@ -41,6 +45,7 @@ func init() {
 	flags.SetLoggingFlags(serverCmd.Flags())
 
 	ganeshaConfigPath = serverCmd.Flags().String("ganeshaConfigPath", "", "ConfigPath of nfs ganesha")
+	serverLeaseName = serverCmd.Flags().String("leaseName", "", "Name of the lease held by the replica serving the exports. The other replicas wait as standby.")
 
 	serverCmd.RunE = startServer
 }
@@ -59,12 +64,23 @@ func startServer(cmd *cobra.Command, args []string) error {
 		logger.Fatalf("Error setting up NFS server: %v", err)
 	}
 
+	if len(*serverLeaseName) == 0 {
+		runServer()
+		return nil
+	}
+
+	rookContext := rook.NewContext()
+	nfs.RunActive(context.Background(), rookContext.Clientset, os.Getenv(k8sutil.PodNamespaceEnvVar), *serverLeaseName, os.Getenv(k8sutil.PodNameEnvVar), func(ctx context.Context) {
+		runServer()
+	})
+	return nil
+}
+
+func runServer() {
 	logger.Infof("starting NFS server")
 	// This blocks until server exits (presumably due to an error)
-	err = nfs.Run(*ganeshaConfigPath)
+	err := nfs.Run(*ganeshaConfigPath)
 	if err != nil {
 		logger.Errorf("NFS server Exited Unexpectedly with err: %v", err)
 	}
-
-	return nil
 }
@@ -0,0 +1,55 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+// IsAutoscalingEnabled returns whether the number of active metadata servers is adjusted to the load
+func (s *MetadataServerSpec) IsAutoscalingEnabled() bool {
+	return s.Autoscaling != nil && s.Autoscaling.Enabled
+}
+
+// MinActiveCount returns the lowest number of active metadata servers
+func (s *MetadataServerSpec) MinActiveCount() int32 {
+	if s.IsAutoscalingEnabled() && s.Autoscaling.MinActiveCount > 0 {
+		return s.Autoscaling.MinActiveCount
+	}
+	return s.ActiveCount
+}
+
+// MaxActiveCount returns the highest number of active metadata servers, the number of mds daemons is based on it
+func (s *MetadataServerSpec) MaxActiveCount() int32 {
+	if s.IsAutoscalingEnabled() && s.Autoscaling.MaxActiveCount > s.MinActiveCount() {
+		return s.Autoscaling.MaxActiveCount
+	}
+	return s.MinActiveCount()
+}
+
+// ActiveMDSCount returns the number of active metadata servers of the filesystem. With autoscaling, it is the
+// last number set by the autoscaler within the autoscaling bounds.
+func (f *CephFilesystem) ActiveMDSCount() int32 {
+	mds := &f.Spec.MetadataServer
+	if !mds.IsAutoscalingEnabled() {
+		return mds.ActiveCount
+	}
+	count := mds.MinActiveCount()
+	if f.Status != nil && f.Status.MDSAutoscaling != nil && f.Status.MDSAutoscaling.ActiveCount > count {
+		count = f.Status.MDSAutoscaling.ActiveCount
+	}
+	if count > mds.MaxActiveCount() {
+		count = mds.MaxActiveCount()
+	}
+	return count
+}
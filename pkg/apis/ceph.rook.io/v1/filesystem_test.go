@@ -0,0 +1,50 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestActiveMDSCount(t *testing.T) {
+	fs := &CephFilesystem{Spec: FilesystemSpec{MetadataServer: MetadataServerSpec{ActiveCount: 2}}}
+	assert.False(t, fs.Spec.MetadataServer.IsAutoscalingEnabled())
+	assert.Equal(t, int32(2), fs.ActiveMDSCount())
+	assert.Equal(t, int32(2), fs.Spec.MetadataServer.MaxActiveCount())
+
+	// the min defaults to the activeCount
+	fs.Spec.MetadataServer.Autoscaling = &MDSAutoscalingSpec{Enabled: true, MaxActiveCount: 4}
+	assert.Equal(t, int32(2), fs.Spec.MetadataServer.MinActiveCount())
+	assert.Equal(t, int32(4), fs.Spec.MetadataServer.MaxActiveCount())
+	assert.Equal(t, int32(2), fs.ActiveMDSCount())
+
+	// the autoscaled count is kept within the bounds
+	fs.Status = &CephFilesystemStatus{MDSAutoscaling: &MDSAutoscalingStatus{ActiveCount: 3}}
+	assert.Equal(t, int32(3), fs.ActiveMDSCount())
+	fs.Status.MDSAutoscaling.ActiveCount = 6
+	assert.Equal(t, int32(4), fs.ActiveMDSCount())
+	fs.Spec.MetadataServer.Autoscaling.MinActiveCount = 1
+	fs.Status.MDSAutoscaling.ActiveCount = 1
+	assert.Equal(t, int32(1), fs.ActiveMDSCount())
+
+	// disabled autoscaling goes back to the activeCount
+	fs.Spec.MetadataServer.Autoscaling.Enabled = false
+	assert.Equal(t, int32(2), fs.ActiveMDSCount())
+	assert.Equal(t, int32(2), fs.Spec.MetadataServer.MaxActiveCount())
+}
@@ -16,6 +16,10 @@ limitations under the License.
 
 package v1
 
+import (
+	v1 "k8s.io/api/core/v1"
+)
+
 // IsMultus get whether to use multus network provider
 func (n *NetworkSpec) IsMultus() bool {
 	return n.Provider == "multus"
@@ -26,3 +30,31 @@ func (n *NetworkSpec) IsMultus() bool {
 func (n *NetworkSpec) IsHost() bool {
 	return (n.HostNetwork && n.Provider == "") || n.Provider == "host"
 }
+
+// IsEncryptionEnabled get whether the msgr2 secure mode is required on all the connections
+func (n *NetworkSpec) IsEncryptionEnabled() bool {
+	return n.Connections != nil && n.Connections.Encryption != nil && n.Connections.Encryption.Enabled
+}
+
+// IsCompressionEnabled get whether the connections between the OSDs are compressed
+func (n *NetworkSpec) IsCompressionEnabled() bool {
+	return n.Connections != nil && n.Connections.Compression != nil && n.Connections.Compression.Enabled
+}
+
+// IsIPFamilySet returns whether the cluster requests the IPv6 family or dual-stack rather than the default
+// single-stack IPv4
+func (n *NetworkSpec) IsIPFamilySet() bool {
+	return n.IPFamily == IPv6 || n.DualStack
+}
+
+// IPFamilies returns the IP families of the pods and services of the cluster, the primary family first
+func (n *NetworkSpec) IPFamilies() []v1.IPFamily {
+	primary, secondary := v1.IPv4Protocol, v1.IPv6Protocol
+	if n.IPFamily == IPv6 {
+		primary, secondary = v1.IPv6Protocol, v1.IPv4Protocol
+	}
+	if n.DualStack {
+		return []v1.IPFamily{primary, secondary}
+	}
+	return []v1.IPFamily{primary}
+}
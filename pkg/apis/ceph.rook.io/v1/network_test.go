@@ -22,6 +22,7 @@ import (
 
 	"github.com/ghodss/yaml"
 	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
 )
 
 func TestNetworkCephSpecLegacy(t *testing.T) {
@@ -71,3 +72,38 @@ selectors:
 
 	assert.Equal(t, expected, net)
 }
+
+func TestNetworkSpecConnections(t *testing.T) {
+	netSpecYAML := []byte(`
+connections:
+  encryption:
+    enabled: true
+  compression:
+    enabled: false`)
+
+	rawJSON, err := yaml.YAMLToJSON(netSpecYAML)
+	assert.Nil(t, err)
+
+	var net NetworkSpec
+
+	err = json.Unmarshal(rawJSON, &net)
+	assert.Nil(t, err)
+
+	assert.True(t, net.IsEncryptionEnabled())
+	assert.False(t, net.IsCompressionEnabled())
+	assert.False(t, (&NetworkSpec{}).IsEncryptionEnabled())
+}
+
+func TestNetworkSpecIPFamilies(t *testing.T) {
+	assert.Equal(t, []v1.IPFamily{v1.IPv4Protocol}, (&NetworkSpec{}).IPFamilies())
+	assert.Equal(t, []v1.IPFamily{v1.IPv6Protocol}, (&NetworkSpec{IPFamily: IPv6}).IPFamilies())
+	assert.Equal(t, []v1.IPFamily{v1.IPv4Protocol, v1.IPv6Protocol}, (&NetworkSpec{IPFamily: IPv4, DualStack: true}).IPFamilies())
+	assert.Equal(t, []v1.IPFamily{v1.IPv6Protocol, v1.IPv4Protocol}, (&NetworkSpec{IPFamily: IPv6, DualStack: true}).IPFamilies())
+}
+
+func TestNetworkSpecIsIPFamilySet(t *testing.T) {
+	assert.False(t, (&NetworkSpec{}).IsIPFamilySet())
+	assert.False(t, (&NetworkSpec{IPFamily: IPv4}).IsIPFamilySet())
+	assert.True(t, (&NetworkSpec{IPFamily: IPv6}).IsIPFamilySet())
+	assert.True(t, (&NetworkSpec{IPFamily: IPv4, DualStack: true}).IsIPFamilySet())
+}
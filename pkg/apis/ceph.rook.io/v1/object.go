@@ -85,6 +85,10 @@ func (o *CephObjectStore) ValidateUpdate(old runtime.Object) error {
 	if err != nil {
 		return err
 	}
+	oos := old.(*CephObjectStore)
+	if err := validateErasureCodedUpdate(o.Spec.DataPool, oos.Spec.DataPool); err != nil {
+		return errors.Wrap(err, "invalid data pool")
+	}
 	return nil
 }
 
@@ -60,6 +60,25 @@ func TestValidateObjectStoreSpec(t *testing.T) {
 	err = ValidateObjectSpec(o)
 	assert.Error(t, err)
 }
+func TestCephObjectStoreValidateUpdate(t *testing.T) {
+	o := &CephObjectStore{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-store", Namespace: "rook-ceph"},
+		Spec: ObjectStoreSpec{
+			Gateway:  GatewaySpec{Port: 80},
+			DataPool: PoolSpec{ErasureCoded: ErasureCodedSpec{DataChunks: 2, CodingChunks: 1}},
+		},
+	}
+	up := o.DeepCopy()
+	up.Spec.Gateway.Port = 8080
+	assert.NoError(t, up.ValidateUpdate(o))
+
+	// the chunks of the data pool can only change with allowMigration
+	up.Spec.DataPool.ErasureCoded.DataChunks = 4
+	assert.Error(t, up.ValidateUpdate(o))
+	up.Spec.DataPool.ErasureCoded.AllowMigration = true
+	assert.NoError(t, up.ValidateUpdate(o))
+}
+
 func TestIsTLSEnabled(t *testing.T) {
 	objStore := &CephObjectStore{
 		ObjectMeta: metav1.ObjectMeta{
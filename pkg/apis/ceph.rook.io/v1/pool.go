@@ -46,6 +46,12 @@ func (p *PoolSpec) IsCompressionEnabled() bool {
 	return p.CompressionMode != ""
 }
 
+// IsEmpty returns whether none of the QoS limits is set
+func (q *QoSSpec) IsEmpty() bool {
+	return q.IOPSLimit == nil && q.ReadIOPSLimit == nil && q.WriteIOPSLimit == nil &&
+		q.BPSLimit == nil && q.ReadBPSLimit == nil && q.WriteBPSLimit == nil
+}
+
 func (p *ReplicatedSpec) IsTargetRatioEnabled() bool {
 	return p.TargetSizeRatio != 0
 }
@@ -104,13 +110,39 @@ func (p *CephBlockPool) ValidateUpdate(old runtime.Object) error {
 			return errors.New("invalid update: erasurecoded field is set already in previous object. cannot be changed to use replicated")
 		}
 	}
+	return validateErasureCodedUpdate(p.Spec, ocbp.Spec)
+}
+
+// validateErasureCodedUpdate checks that the chunks of an erasure coded pool only change if the pool can be migrated
+func validateErasureCodedUpdate(ps, old PoolSpec) error {
+	if !erasureCodedChunksChanged(ps, old) {
+		return nil
+	}
+	if !ps.ErasureCoded.AllowMigration {
+		return errors.New("invalid update: the erasurecoded chunks of an existing pool can only be changed with allowMigration to migrate the data to a new pool")
+	}
 	return nil
 }
 
+// erasureCodedChunksChanged returns whether the chunks of an erasure coded pool changed
+func erasureCodedChunksChanged(ps, old PoolSpec) bool {
+	if !old.IsErasureCoded() || !ps.IsErasureCoded() {
+		return false
+	}
+	return ps.ErasureCoded.DataChunks != old.ErasureCoded.DataChunks || ps.ErasureCoded.CodingChunks != old.ErasureCoded.CodingChunks
+}
+
 func (p *CephBlockPool) ValidateDelete() error {
 	return nil
 }
 
+func (p *CephBlockPool) GetStatusConditions() *[]Condition {
+	if p.Status == nil {
+		p.Status = &CephBlockPoolStatus{}
+	}
+	return &p.Status.Conditions
+}
+
 // SnapshotSchedulesEnabled returns whether snapshot schedules are desired
 func (p *MirroringSpec) SnapshotSchedulesEnabled() bool {
 	return len(p.SnapshotSchedules) > 0
@@ -57,6 +57,18 @@ func TestCephBlockPoolValidateUpdate(t *testing.T) {
 	up.Spec.ErasureCoded.CodingChunks = 1
 	err := up.ValidateUpdate(p)
 	assert.Error(t, err)
+
+	// the chunks of an erasure coded pool can only change with a migration
+	p = up.DeepCopy()
+	p.Spec.Replicated = ReplicatedSpec{}
+	up = p.DeepCopy()
+	up.Spec.ErasureCoded.DataChunks = 4
+	up.Spec.ErasureCoded.CodingChunks = 2
+	err = up.ValidateUpdate(p)
+	assert.Error(t, err)
+	up.Spec.ErasureCoded.AllowMigration = true
+	err = up.ValidateUpdate(p)
+	assert.NoError(t, err)
 }
 
 func TestMirroringSpec_SnapshotSchedulesEnabled(t *testing.T) {
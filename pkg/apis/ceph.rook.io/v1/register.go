@@ -60,10 +60,20 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&CephClusterList{},
 		&CephBlockPool{},
 		&CephBlockPoolList{},
+		&CephBlockPoolRadosNamespace{},
+		&CephBlockPoolRadosNamespaceList{},
+		&CephBlockPoolImageMirror{},
+		&CephBlockPoolImageMirrorList{},
+		&CephCrushRule{},
+		&CephCrushRuleList{},
 		&CephFilesystem{},
 		&CephFilesystemList{},
+		&CephFilesystemSubVolumeGroup{},
+		&CephFilesystemSubVolumeGroupList{},
 		&CephNFS{},
 		&CephNFSList{},
+		&CephNFSExport{},
+		&CephNFSExportList{},
 		&CephObjectStore{},
 		&CephObjectStoreList{},
 		&CephObjectStoreUser{},
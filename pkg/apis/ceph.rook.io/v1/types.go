@@ -78,6 +78,11 @@ type DaemonHealthSpec struct {
 	// +optional
 	// +nullable
 	ObjectStorageDaemon HealthCheckSpec `json:"osd,omitempty"`
+	// Manager represents the health check settings for the Ceph manager, the timeout is how long the
+	// active mgr may be unhealthy before failing over to a standby. The mgr is not failed over without a timeout.
+	// +optional
+	// +nullable
+	Manager HealthCheckSpec `json:"mgr,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -217,8 +222,72 @@ type ClusterSpec struct {
 	// +optional
 	// +nullable
 	LogCollector LogCollectorSpec `json:"logCollector,omitempty"`
+
+	// CephConfig is the Ceph configuration applied to the centralized mon configuration database, keyed
+	// by the section (e.g. "global", "osd", "osd.3", "client.rgw") and then by the option name. Values
+	// changed out of band are restored and reported in the status.
+	// +optional
+	// +nullable
+	CephConfig map[string]CephConfigOptions `json:"cephConfig,omitempty"`
+
+	// CapacityForecast represents the settings of the usage history and capacity forecast of the pools
+	// +optional
+	// +nullable
+	CapacityForecast CapacityForecastSpec `json:"capacityForecast,omitempty"`
+
+	// Scrub represents the time window in which the OSDs are allowed to scrub the pools
+	// +optional
+	// +nullable
+	Scrub ScrubWindowSpec `json:"scrub,omitempty"`
 }
 
+// ScrubWindowSpec represents the hours and the days of the week in which the OSDs are allowed to start scrubs.
+// The end of a window is exclusive and a window may wrap around, e.g. from 22 to 6. The scrubs are allowed
+// at any time when the window is not set.
+type ScrubWindowSpec struct {
+	// BeginHour is the hour of the day (0-23) from which scrubs are allowed
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=23
+	// +optional
+	// +nullable
+	BeginHour *int `json:"beginHour,omitempty"`
+	// EndHour is the hour of the day (0-23) from which scrubs are no longer allowed
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=23
+	// +optional
+	// +nullable
+	EndHour *int `json:"endHour,omitempty"`
+	// BeginWeekDay is the day of the week (0-6, 0 is Sunday) from which scrubs are allowed
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=6
+	// +optional
+	// +nullable
+	BeginWeekDay *int `json:"beginWeekDay,omitempty"`
+	// EndWeekDay is the day of the week (0-6, 0 is Sunday) from which scrubs are no longer allowed
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=6
+	// +optional
+	// +nullable
+	EndWeekDay *int `json:"endWeekDay,omitempty"`
+}
+
+// CapacityForecastSpec represents the settings of the capacity forecast of the pools and device classes
+type CapacityForecastSpec struct {
+	// Enabled enables the collection of the usage history and the capacity forecast
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+	// Window is how far back the usage history is kept to compute the growth rate, 7 days by default
+	// +optional
+	Window *metav1.Duration `json:"window,omitempty"`
+	// AutoTargetSizeRatio sets the target_size_ratio of the CephBlockPools that do not set one in their
+	// spec from their forecast usage, so that the PG autoscaler sizes them ahead of their growth
+	// +optional
+	AutoTargetSizeRatio bool `json:"autoTargetSizeRatio,omitempty"`
+}
+
+// CephConfigOptions are the Ceph configuration options of a section keyed by the option name
+type CephConfigOptions map[string]string
+
 // LogCollectorSpec is the logging spec
 type LogCollectorSpec struct {
 	// Enabled represents whether the log collector is enabled
@@ -277,6 +346,90 @@ type DashboardSpec struct {
 	// SSL determines whether SSL should be used
 	// +optional
 	SSL bool `json:"ssl,omitempty"`
+	// CertificateSecretName is the name of a kubernetes.io/tls secret with the certificate the dashboard serves
+	// when SSL is enabled. If not specified, a self-signed certificate is generated.
+	// +optional
+	CertificateSecretName string `json:"certificateSecretName,omitempty"`
+	// Ingress exposes the dashboard outside the cluster with an ingress
+	// +optional
+	// +nullable
+	Ingress *DashboardIngressSpec `json:"ingress,omitempty"`
+	// SSO configures single sign-on for the dashboard with a SAML2 identity provider
+	// +optional
+	// +nullable
+	SSO *DashboardSSOSpec `json:"sso,omitempty"`
+	// Roles are the custom dashboard roles managed by the operator
+	// +optional
+	// +nullable
+	Roles []DashboardRoleSpec `json:"roles,omitempty"`
+	// Users are the dashboard user accounts managed by the operator
+	// +optional
+	// +nullable
+	Users []DashboardUserSpec `json:"users,omitempty"`
+}
+
+// DashboardIngressSpec represents the ingress created for the dashboard service
+type DashboardIngressSpec struct {
+	// Host is the fully qualified domain name the dashboard is served at
+	Host string `json:"host"`
+	// IngressClassName is the name of the ingress class, the cluster default class is used if not specified
+	// +optional
+	IngressClassName *string `json:"ingressClassName,omitempty"`
+	// Annotations are added to the ingress, e.g. to configure the ingress controller
+	// +optional
+	// +nullable
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// TLSSecretName is the name of the secret with the certificate the ingress terminates TLS with
+	// +optional
+	TLSSecretName string `json:"tlsSecretName,omitempty"`
+}
+
+// DashboardSSOSpec represents the SAML2 single sign-on settings of the dashboard
+type DashboardSSOSpec struct {
+	// BaseURL is the URL where the users access the dashboard, the identity provider redirects to it after login
+	BaseURL string `json:"baseURL"`
+	// IdPMetadataSecretName is the name of the secret with the identity provider metadata XML in the "metadata" key
+	IdPMetadataSecretName string `json:"idpMetadataSecretName"`
+	// UsernameAttribute is the SAML attribute holding the username, "uid" if not specified
+	// +optional
+	UsernameAttribute string `json:"usernameAttribute,omitempty"`
+	// EntityID is the identity provider entity ID, required when the metadata describes more than one entity
+	// +optional
+	EntityID string `json:"entityID,omitempty"`
+}
+
+// DashboardRoleSpec represents a custom dashboard role
+type DashboardRoleSpec struct {
+	// Name is the name of the role
+	Name string `json:"name"`
+	// Description is the description of the role
+	// +optional
+	Description string `json:"description,omitempty"`
+	// Scopes maps a dashboard security scope (e.g. pool, rbd-image, cephfs) to the permissions granted on it (read, create, update, delete)
+	// +optional
+	// +nullable
+	Scopes map[string][]string `json:"scopes,omitempty"`
+}
+
+// DashboardUserSpec represents a dashboard user account
+type DashboardUserSpec struct {
+	// Name is the username, which must match the SAML username attribute when SSO is enabled
+	Name string `json:"name"`
+	// Roles are the system or custom roles granted to the user
+	// +optional
+	// +nullable
+	Roles []string `json:"roles,omitempty"`
+	// DisplayName is the full name of the user
+	// +optional
+	DisplayName string `json:"displayName,omitempty"`
+	// Email is the email address of the user
+	// +optional
+	Email string `json:"email,omitempty"`
+	// PasswordSecretName is the name of the secret with the password of the user in the "password" key.
+	// The password is updated when the secret changes. If not specified, a random password is generated
+	// when the user is created, which is only useful for users logging in with SSO.
+	// +optional
+	PasswordSecretName string `json:"passwordSecretName,omitempty"`
 }
 
 // MonitoringSpec represents the settings for Prometheus based Ceph monitoring
@@ -352,6 +505,104 @@ type CephStatus struct {
 	Capacity       Capacity                     `json:"capacity,omitempty"`
 	// +optional
 	Versions *CephDaemonsVersions `json:"versions,omitempty"`
+	// +optional
+	Mgr *MgrStatus `json:"mgr,omitempty"`
+	// ConfigDrift lists the options of the cephConfig spec that were found changed out of band
+	// +optional
+	ConfigDrift []CephConfigDriftStatus `json:"configDrift,omitempty"`
+	// CapacityForecast is the growth rate and forecast of the raw capacity of each device class
+	// +optional
+	CapacityForecast *CapacityForecastStatus `json:"capacityForecast,omitempty"`
+	// Network is the network observed on the pods and connections of the cluster
+	// +optional
+	Network *NetworkStatus `json:"network,omitempty"`
+}
+
+// NetworkStatus represents the network observed on the pods and connections of the cluster
+type NetworkStatus struct {
+	// PodsMissingPublicNetwork lists the running pods connecting to the Ceph daemons that Multus did not attach
+	// to the public network, in the <namespace>/<name> form
+	// +optional
+	PodsMissingPublicNetwork []string `json:"podsMissingPublicNetwork,omitempty"`
+	// Connections are the msgr2 modes of the connections in the mon configuration database
+	// +optional
+	Connections *ConnectionsStatus `json:"connections,omitempty"`
+}
+
+// ConnectionsStatus represents the msgr2 modes of the connections in the mon configuration database
+type ConnectionsStatus struct {
+	// ClusterMode is the mode of the connections between the daemons
+	// +optional
+	ClusterMode string `json:"clusterMode,omitempty"`
+	// ServiceMode is the mode accepted by the daemons from the clients
+	// +optional
+	ServiceMode string `json:"serviceMode,omitempty"`
+	// ClientMode is the mode of the connections of the clients to the daemons
+	// +optional
+	ClientMode string `json:"clientMode,omitempty"`
+	// Encrypted is true when all the connections require the secure mode
+	// +optional
+	Encrypted bool `json:"encrypted,omitempty"`
+	// OSDCompressMode is the compression mode of the connections between the OSDs
+	// +optional
+	OSDCompressMode string `json:"osdCompressMode,omitempty"`
+	// Message is the reason the connections are not all encrypted when encryption is enabled
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// CapacityForecastStatus represents the capacity forecast of the device classes of the cluster
+type CapacityForecastStatus struct {
+	// DeviceClasses is the forecast of each device class
+	// +optional
+	DeviceClasses []DeviceClassCapacityStatus `json:"deviceClasses,omitempty"`
+	// LastUpdated is the last time the forecast changed
+	// +optional
+	LastUpdated string `json:"lastUpdated,omitempty"`
+}
+
+// DeviceClassCapacityStatus represents the raw capacity and forecast of a device class
+type DeviceClassCapacityStatus struct {
+	// Name is the device class
+	Name string `json:"name"`
+	// TotalBytes is the raw capacity of the device class
+	// +optional
+	TotalBytes uint64 `json:"bytesTotal,omitempty"`
+	// UsedBytes is the raw capacity used in the device class
+	// +optional
+	UsedBytes uint64 `json:"bytesUsed,omitempty"`
+	// AvailableBytes is the raw capacity available in the device class
+	// +optional
+	AvailableBytes uint64 `json:"bytesAvailable,omitempty"`
+	// GrowthBytesPerDay is the growth rate of the used capacity over the forecast window
+	// +optional
+	GrowthBytesPerDay int64 `json:"growthBytesPerDay,omitempty"`
+	// DaysUntilFull is the number of days until the available capacity is exhausted at the current growth
+	// rate, unset when the usage is not growing
+	// +optional
+	DaysUntilFull *int64 `json:"daysUntilFull,omitempty"`
+}
+
+// CephConfigDriftStatus represents an option of the cephConfig spec changed out of band
+type CephConfigDriftStatus struct {
+	// Section is the section of the option
+	Section string `json:"section"`
+	// Option is the name of the option
+	Option string `json:"option"`
+	// Expected is the value in the spec
+	Expected string `json:"expected"`
+	// Actual is the value found in the mon configuration database, empty if the option was removed
+	// +optional
+	Actual string `json:"actual,omitempty"`
+	// LastDetected is the last time the drift was detected and the expected value restored
+	LastDetected string `json:"lastDetected"`
+}
+
+// MgrStatus represents the status of the Ceph managers
+type MgrStatus struct {
+	// Modules is the list of mgr modules currently enabled, including the always-on modules
+	// +optional
+	Modules []string `json:"modules,omitempty"`
 }
 
 // Capacity is the capacity information of a Ceph Cluster
@@ -408,6 +659,8 @@ const (
 	ClusterDeletingReason ConditionReason = "ClusterDeleting"
 	// ClusterConnectingReason is cluster connecting reason
 	ClusterConnectingReason ConditionReason = "ClusterConnecting"
+	// ClusterNetworkValidationFailedReason is the reason when the kubernetes networks do not support the cluster network
+	ClusterNetworkValidationFailedReason ConditionReason = "ClusterNetworkValidationFailed"
 
 	// ReconcileSucceeded represents when a resource reconciliation was successful.
 	ReconcileSucceeded ConditionReason = "ReconcileSucceeded"
@@ -533,6 +786,10 @@ type Module struct {
 	// Enabled determines whether a module should be enabled or not
 	// +optional
 	Enabled bool `json:"enabled,omitempty"`
+	// Settings are the module configuration options, each applied as "mgr/<module>/<key>" in the centralized mon configuration database
+	// +optional
+	// +nullable
+	Settings map[string]string `json:"settings,omitempty"`
 }
 
 // ExternalSpec represents the options supported by an external cluster
@@ -600,6 +857,11 @@ type PoolSpec struct {
 	// +nullable
 	DeviceClass string `json:"deviceClass,omitempty"`
 
+	// The name of a CephCrushRule placing the data of the pool. It takes precedence over the
+	// failure domain, crush root and device class.
+	// +optional
+	CrushRule string `json:"crushRule,omitempty"`
+
 	// The inline compression mode in Bluestore OSD to set to (options are: none, passive, aggressive, force)
 	// +kubebuilder:validation:Enum=none;passive;aggressive;force;""
 	// +kubebuilder:default=none
@@ -607,6 +869,29 @@ type PoolSpec struct {
 	// +nullable
 	CompressionMode string `json:"compressionMode,omitempty"`
 
+	// The inline compression algorithm in Bluestore OSD (options are: snappy, zlib, zstd, lz4), the OSD default when empty
+	// +kubebuilder:validation:Enum=snappy;zlib;zstd;lz4;""
+	// +optional
+	CompressionAlgorithm string `json:"compressionAlgorithm,omitempty"`
+
+	// The ratio of the compressed size to the original size (0-1) below which the data is stored compressed, the OSD
+	// default when unset
+	// +optional
+	CompressionRequiredRatio float64 `json:"compressionRequiredRatio,omitempty"`
+
+	// The scrub settings of the pool
+	// +optional
+	// +nullable
+	Scrub PoolScrubSpec `json:"scrub,omitempty"`
+
+	// The recovery priority of the pool relative to the other pools, from -10 to 10. The pools with the higher
+	// priority are recovered first.
+	// +kubebuilder:validation:Minimum=-10
+	// +kubebuilder:validation:Maximum=10
+	// +optional
+	// +nullable
+	RecoveryPriority *int `json:"recoveryPriority,omitempty"`
+
 	// The replication settings
 	// +optional
 	Replicated ReplicatedSpec `json:"replicated,omitempty"`
@@ -635,6 +920,67 @@ type PoolSpec struct {
 	// +optional
 	// +nullable
 	Quotas QuotaSpec `json:"quotas,omitempty"`
+
+	// PurgeTrashOnDeletion purges the rbd trash of the pool when the CephBlockPool is deleted. Otherwise the
+	// images in the trash block the deletion like the other images of the pool.
+	// +optional
+	PurgeTrashOnDeletion bool `json:"purgeTrashOnDeletion,omitempty"`
+
+	// QoS represents the rbd QoS limits of the images of the pool
+	// +optional
+	// +nullable
+	QoS QoSSpec `json:"qos,omitempty"`
+}
+
+// QoSSpec represents the rbd QoS limits of images. The limits that are not set are inherited, a limit of 0
+// means unlimited.
+type QoSSpec struct {
+	// IOPSLimit is the limit of IO operations per second
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	// +nullable
+	IOPSLimit *int64 `json:"iopsLimit,omitempty"`
+	// ReadIOPSLimit is the limit of read operations per second
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	// +nullable
+	ReadIOPSLimit *int64 `json:"readIOPSLimit,omitempty"`
+	// WriteIOPSLimit is the limit of write operations per second
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	// +nullable
+	WriteIOPSLimit *int64 `json:"writeIOPSLimit,omitempty"`
+	// BPSLimit is the limit of bytes per second
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	// +nullable
+	BPSLimit *int64 `json:"bpsLimit,omitempty"`
+	// ReadBPSLimit is the limit of bytes read per second
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	// +nullable
+	ReadBPSLimit *int64 `json:"readBPSLimit,omitempty"`
+	// WriteBPSLimit is the limit of bytes written per second
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	// +nullable
+	WriteBPSLimit *int64 `json:"writeBPSLimit,omitempty"`
+}
+
+// PoolScrubSpec represents the scrub intervals of a pool. The OSD defaults apply to the intervals that are not set.
+type PoolScrubSpec struct {
+	// MinInterval is the interval after which the pool is scrubbed when the load of the cluster is low
+	// +optional
+	// +nullable
+	MinInterval *metav1.Duration `json:"minInterval,omitempty"`
+	// MaxInterval is the interval after which the pool is scrubbed regardless of the load of the cluster
+	// +optional
+	// +nullable
+	MaxInterval *metav1.Duration `json:"maxInterval,omitempty"`
+	// DeepInterval is the interval after which the pool is deep scrubbed
+	// +optional
+	// +nullable
+	DeepInterval *metav1.Duration `json:"deepInterval,omitempty"`
 }
 
 // MirrorHealthCheckSpec represents the health specification of a Ceph Storage Pool mirror
@@ -657,6 +1003,297 @@ type CephBlockPoolStatus struct {
 	// +optional
 	// +nullable
 	Info map[string]string `json:"info,omitempty"`
+	// +optional
+	Capacity *PoolCapacityStatus `json:"capacity,omitempty"`
+	// +optional
+	Migration *PoolMigrationStatus `json:"migration,omitempty"`
+	// QoS is the qos limits rook set on the pool, only these limits are removed when they are removed from the spec
+	// +optional
+	QoS *QoSSpec `json:"qos,omitempty"`
+	// +optional
+	Conditions []Condition `json:"conditions,omitempty"`
+}
+
+// PoolCapacityStatus represents the usage and capacity forecast of a pool
+type PoolCapacityStatus struct {
+	// UsedBytes is the data stored in the pool, before replication or erasure coding
+	// +optional
+	UsedBytes uint64 `json:"bytesUsed,omitempty"`
+	// AvailableBytes is the data that can still be stored in the pool before the fullest OSD of its
+	// crush rule is full
+	// +optional
+	AvailableBytes uint64 `json:"bytesAvailable,omitempty"`
+	// GrowthBytesPerDay is the growth rate of the stored data over the forecast window
+	// +optional
+	GrowthBytesPerDay int64 `json:"growthBytesPerDay,omitempty"`
+	// DaysUntilFull is the number of days until the pool is full at the current growth rate, unset
+	// when the usage is not growing
+	// +optional
+	DaysUntilFull *int64 `json:"daysUntilFull,omitempty"`
+	// TargetSizeRatio is the target_size_ratio set automatically from the forecast
+	// +optional
+	TargetSizeRatio float64 `json:"targetSizeRatio,omitempty"`
+	// LastUpdated is the last time the forecast changed
+	// +optional
+	LastUpdated string `json:"lastUpdated,omitempty"`
+}
+
+// +genclient
+// +genclient:noStatus
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CephBlockPoolRadosNamespace represents a Ceph BlockPool Rados Namespace
+// +kubebuilder:subresource:status
+type CephBlockPoolRadosNamespace struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	// Spec represents the specification of a Ceph BlockPool Rados Namespace
+	Spec CephBlockPoolRadosNamespaceSpec `json:"spec"`
+	// Status represents the status of a CephBlockPool Rados Namespace
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	Status *CephBlockPoolRadosNamespaceStatus `json:"status,omitempty"`
+}
+
+// CephBlockPoolRadosNamespaceSpec represents the specification of a CephBlockPool Rados Namespace
+type CephBlockPoolRadosNamespaceSpec struct {
+	// BlockPoolName is the name of Ceph BlockPool. Typically it's the name of
+	// the CephBlockPool CR.
+	BlockPoolName string `json:"blockPoolName"`
+	// QoS represents the rbd QoS limits of the images of the rados namespace. They take precedence over
+	// the limits of the pool.
+	// +optional
+	// +nullable
+	QoS QoSSpec `json:"qos,omitempty"`
+}
+
+// CephBlockPoolRadosNamespaceStatus represents the Status of Ceph BlockPool
+// Rados Namespace
+type CephBlockPoolRadosNamespaceStatus struct {
+	// +optional
+	Phase ConditionType `json:"phase,omitempty"`
+	// +optional
+	// +nullable
+	Info map[string]string `json:"info,omitempty"`
+	// QoS is the qos limits rook set on the images of the rados namespace, only these limits are removed when
+	// they are removed from the spec
+	// +optional
+	QoS *QoSSpec `json:"qos,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CephBlockPoolRadosNamespaceList represents a list of Ceph BlockPool Rados Namespace
+type CephBlockPoolRadosNamespaceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+	Items           []CephBlockPoolRadosNamespace `json:"items"`
+}
+
+// +genclient
+// +genclient:noStatus
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CephCrushRule represents a Ceph CRUSH rule and the CRUSH buckets it places data on
+// +kubebuilder:subresource:status
+type CephCrushRule struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	// Spec represents the specification of a Ceph CRUSH rule
+	Spec CrushRuleSpec `json:"spec"`
+	// Status represents the status of a Ceph CRUSH rule
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	Status *CephCrushRuleStatus `json:"status,omitempty"`
+}
+
+// CrushRuleSpec represents the specification of a Ceph CRUSH rule
+type CrushRuleSpec struct {
+	// Type is the type of the pools using the rule
+	// +kubebuilder:validation:Enum=replicated;erasure
+	// +optional
+	Type string `json:"type,omitempty"`
+
+	// BucketTypes are custom bucket types to add to the CRUSH map, such as row or pdu
+	// +optional
+	BucketTypes []string `json:"bucketTypes,omitempty"`
+
+	// Buckets are CRUSH buckets to create or move in the CRUSH hierarchy
+	// +optional
+	Buckets []CrushBucketSpec `json:"buckets,omitempty"`
+
+	// Steps are the steps of the rule
+	// +kubebuilder:validation:MinItems=1
+	Steps []CrushRuleStepSpec `json:"steps"`
+}
+
+// CrushBucketSpec represents a CRUSH bucket
+type CrushBucketSpec struct {
+	// Name of the bucket
+	Name string `json:"name"`
+	// Type of the bucket
+	Type string `json:"type"`
+	// Parent is the bucket to move the bucket under, the bucket is not moved if empty
+	// +optional
+	Parent string `json:"parent,omitempty"`
+}
+
+// CrushRuleStepSpec represents a step of a CRUSH rule
+type CrushRuleStepSpec struct {
+	// Op is the operation of the step
+	// +kubebuilder:validation:Enum=take;choose;chooseleaf;emit
+	Op string `json:"op"`
+	// Item is the bucket to start from in a take step
+	// +optional
+	Item string `json:"item,omitempty"`
+	// DeviceClass restricts a take step to the devices of the class
+	// +optional
+	DeviceClass string `json:"deviceClass,omitempty"`
+	// Num is the number of buckets to choose, 0 chooses as many buckets as the pool size
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Num int `json:"num,omitempty"`
+	// Type is the type of the buckets to choose
+	// +optional
+	Type string `json:"type,omitempty"`
+	// Mode is how to choose the buckets. It defaults to firstn for replicated rules and indep for
+	// erasure rules.
+	// +kubebuilder:validation:Enum=firstn;indep;""
+	// +optional
+	Mode string `json:"mode,omitempty"`
+}
+
+// CephCrushRuleStatus represents the status of a Ceph CRUSH rule
+type CephCrushRuleStatus struct {
+	// +optional
+	Phase ConditionType `json:"phase,omitempty"`
+	// RuleID is the ID of the rule in the CRUSH map
+	// +optional
+	RuleID *int `json:"ruleID,omitempty"`
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CephCrushRuleList represents a list of Ceph CRUSH rules
+type CephCrushRuleList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+	Items           []CephCrushRule `json:"items"`
+}
+
+const (
+	// ImageMirrorStatePrimary is the state of images that are mirrored to the peers
+	ImageMirrorStatePrimary = "primary"
+	// ImageMirrorStateSecondary is the state of images that are mirrored from a peer
+	ImageMirrorStateSecondary = "secondary"
+)
+
+// +genclient
+// +genclient:noStatus
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CephBlockPoolImageMirror represents the mirroring of a set of images of a Ceph BlockPool
+// +kubebuilder:subresource:status
+type CephBlockPoolImageMirror struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	// Spec represents the specification of the mirroring of the images
+	Spec CephBlockPoolImageMirrorSpec `json:"spec"`
+	// Status represents the mirroring status of the images
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	Status *CephBlockPoolImageMirrorStatus `json:"status,omitempty"`
+}
+
+// CephBlockPoolImageMirrorSpec represents the specification of the mirroring of a set of images
+type CephBlockPoolImageMirrorSpec struct {
+	// BlockPoolName is the name of the CephBlockPool of the images
+	BlockPoolName string `json:"blockPoolName"`
+
+	// RadosNamespace is the rados namespace of the images in the pool
+	// +optional
+	RadosNamespace string `json:"radosNamespace,omitempty"`
+
+	// Images are the names of the images to mirror. Mirroring is enabled for these images in a pool with the
+	// "image" mirroring mode. If empty, the state applies to all the mirrored images of the pool.
+	// +optional
+	Images []string `json:"images,omitempty"`
+
+	// Mode is the mirroring mode of the images: either snapshot or journal
+	// +kubebuilder:validation:Enum=snapshot;journal;""
+	// +optional
+	Mode string `json:"mode,omitempty"`
+
+	// State is the desired state of the images on this cluster: primary to promote them, or secondary to
+	// demote them so they are mirrored from the peer
+	// +kubebuilder:validation:Enum=primary;secondary;""
+	// +optional
+	State string `json:"state,omitempty"`
+
+	// Force promotes the images even if the peer cluster is unreachable
+	// +optional
+	Force bool `json:"force,omitempty"`
+
+	// Resync flags the secondary images to be resynchronized from the primary images each time it is set to
+	// a new value, e.g. after a forced promotion caused a split-brain
+	// +optional
+	Resync string `json:"resync,omitempty"`
+
+	// StatusCheck is the interval to refresh the mirroring status of the images
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	StatusCheck MirrorHealthCheckSpec `json:"statusCheck,omitempty"`
+}
+
+// CephBlockPoolImageMirrorStatus represents the mirroring status of a set of images
+type CephBlockPoolImageMirrorStatus struct {
+	// +optional
+	Phase ConditionType `json:"phase,omitempty"`
+	// Message explains why the images are not in the desired state
+	// +optional
+	Message string `json:"message,omitempty"`
+	// Images is the mirroring status of each image
+	// +optional
+	// +nullable
+	Images []ImageMirrorStatus `json:"images,omitempty"`
+	// LastResync is the last value of the resync field that was applied
+	// +optional
+	LastResync string `json:"lastResync,omitempty"`
+	// LastChecked is the last time the status of the images changed
+	// +optional
+	LastChecked string `json:"lastChecked,omitempty"`
+}
+
+// ImageMirrorStatus represents the mirroring status of an image
+type ImageMirrorStatus struct {
+	// Name is the name of the image
+	Name string `json:"name"`
+	// Primary is whether the image is primary on this cluster
+	// +optional
+	Primary bool `json:"primary,omitempty"`
+	// State is the replay state of the image, e.g. up+replaying
+	// +optional
+	State string `json:"state,omitempty"`
+	// Description is the description of the replay state
+	// +optional
+	Description string `json:"description,omitempty"`
+	// LastUpdate is the last time the replay state was updated by rbd-mirror
+	// +optional
+	LastUpdate string `json:"lastUpdate,omitempty"`
+	// LastSnapshotTime is the time of the last mirror snapshot of the image
+	// +optional
+	LastSnapshotTime string `json:"lastSnapshotTime,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CephBlockPoolImageMirrorList represents a list of Ceph BlockPool image mirrors
+type CephBlockPoolImageMirrorList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+	Items           []CephBlockPoolImageMirror `json:"items"`
 }
 
 // MirroringStatusSpec is the status of the pool mirroring
@@ -926,7 +1563,52 @@ type ErasureCodedSpec struct {
 
 	// The algorithm for erasure coding
 	// +optional
-	Algorithm string `json:"algorithm,omitempty"`
+	Algorithm string `json:"algorithm,omitempty"`
+
+	// AllowMigration allows changing the data and coding chunks of an existing pool. The data is migrated
+	// to a new pool created with the new erasure code profile, which then replaces the pool.
+	// +optional
+	AllowMigration bool `json:"allowMigration,omitempty"`
+}
+
+const (
+	// PoolMigrationMigrating is the phase of a pool migration while the data is moved to the new pool
+	PoolMigrationMigrating = "Migrating"
+	// PoolMigrationSwapping is the phase of a pool migration while the new pool replaces the old pool
+	PoolMigrationSwapping = "Swapping"
+	// PoolMigrationCompleted is the phase of a completed pool migration
+	PoolMigrationCompleted = "Completed"
+)
+
+// PoolMigrationStatus represents the progress of the migration of a pool to a new erasure code profile
+type PoolMigrationStatus struct {
+	// Phase is the step of the migration: Migrating, Swapping or Completed
+	// +optional
+	Phase string `json:"phase,omitempty"`
+	// SourceProfile is the erasure code profile of the pool before the migration
+	// +optional
+	SourceProfile string `json:"sourceProfile,omitempty"`
+	// TargetProfile is the erasure code profile the pool is migrated to
+	// +optional
+	TargetProfile string `json:"targetProfile,omitempty"`
+	// TargetPool is the pool the data is migrated to before it replaces the pool
+	// +optional
+	TargetPool string `json:"targetPool,omitempty"`
+	// Total is the number of images or objects to migrate
+	// +optional
+	Total int `json:"total,omitempty"`
+	// Migrated is the number of images or objects migrated
+	// +optional
+	Migrated int `json:"migrated,omitempty"`
+	// Pending are the images that cannot be migrated until their clients stop using them
+	// +optional
+	Pending []string `json:"pending,omitempty"`
+	// +optional
+	Message string `json:"message,omitempty"`
+	// +optional
+	StartTime string `json:"startTime,omitempty"`
+	// +optional
+	CompletionTime string `json:"completionTime,omitempty"`
 }
 
 // +genclient
@@ -955,6 +1637,81 @@ type CephFilesystemList struct {
 	Items           []CephFilesystem `json:"items"`
 }
 
+// +genclient
+// +genclient:noStatus
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CephFilesystemSubVolumeGroup represents a Ceph Filesystem SubVolumeGroup
+// +kubebuilder:subresource:status
+type CephFilesystemSubVolumeGroup struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	// Spec represents the specification of a Ceph Filesystem SubVolumeGroup
+	Spec CephFilesystemSubVolumeGroupSpec `json:"spec"`
+	// Status represents the status of a CephFilesystem SubVolumeGroup
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	Status *CephFilesystemSubVolumeGroupStatus `json:"status,omitempty"`
+}
+
+// CephFilesystemSubVolumeGroupSpec represents the specification of a Ceph Filesystem SubVolumeGroup
+type CephFilesystemSubVolumeGroupSpec struct {
+	// FilesystemName is the name of Ceph Filesystem the group is created in. Typically it's the name of
+	// the CephFilesystem CR.
+	FilesystemName string `json:"filesystemName"`
+	// Quota is the size limit of the group, unlimited if not set
+	// +kubebuilder:validation:Pattern=`^[0-9]+[\.]?[0-9]*([KMGTPE]i|[kMGTPE])?$`
+	// +optional
+	// +nullable
+	Quota *string `json:"quota,omitempty"`
+	// DataPoolName is the name of the data pool of the filesystem the files of the group are stored in,
+	// the default data pool of the filesystem if not set. It cannot be changed once the group is created.
+	// +optional
+	DataPoolName string `json:"dataPoolName,omitempty"`
+	// Pinning pins the group to the MDS ranks of the filesystem. At most one of the pinning types can be set.
+	// +optional
+	// +nullable
+	Pinning SubVolumeGroupPinningSpec `json:"pinning,omitempty"`
+}
+
+// SubVolumeGroupPinningSpec represents the MDS export pinning of a subvolume group
+type SubVolumeGroupPinningSpec struct {
+	// Export pins the group to an MDS rank
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=255
+	// +optional
+	// +nullable
+	Export *int `json:"export,omitempty"`
+	// Distributed pins the subvolumes of the group to the MDS ranks, distributed by the hash of their name
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=1
+	// +optional
+	// +nullable
+	Distributed *int `json:"distributed,omitempty"`
+	// Random pins this proportion (between 0 and 1) of the directories of the group to a random MDS rank
+	// +optional
+	// +nullable
+	Random *float64 `json:"random,omitempty"`
+}
+
+// CephFilesystemSubVolumeGroupStatus represents the Status of Ceph Filesystem SubVolumeGroup
+type CephFilesystemSubVolumeGroupStatus struct {
+	// +optional
+	Phase ConditionType `json:"phase,omitempty"`
+	// +optional
+	// +nullable
+	Info map[string]string `json:"info,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CephFilesystemSubVolumeGroupList represents a list of Ceph Filesystem SubVolumeGroups
+type CephFilesystemSubVolumeGroupList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+	Items           []CephFilesystemSubVolumeGroup `json:"items"`
+}
+
 // FilesystemSpec represents the spec of a file system
 type FilesystemSpec struct {
 	// The metadata pool settings
@@ -1025,6 +1782,59 @@ type MetadataServerSpec struct {
 	// PriorityClassName sets priority classes on components
 	// +optional
 	PriorityClassName string `json:"priorityClassName,omitempty"`
+
+	// Autoscaling adjusts the number of active metadata servers to the load of the filesystem
+	// +optional
+	// +nullable
+	Autoscaling *MDSAutoscalingSpec `json:"autoscaling,omitempty"`
+}
+
+// MDSAutoscalingSpec represents the settings of the autoscaling of the active metadata servers
+type MDSAutoscalingSpec struct {
+	// Enabled whether the number of active metadata servers is adjusted to the load. The activeCount is ignored
+	// when enabled.
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+
+	// MinActiveCount is the lowest number of active metadata servers, the activeCount if not set
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=10
+	// +optional
+	MinActiveCount int32 `json:"minActiveCount,omitempty"`
+
+	// MaxActiveCount is the highest number of active metadata servers. A standby daemon is deployed for each
+	// of them.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=10
+	MaxActiveCount int32 `json:"maxActiveCount"`
+
+	// ScaleUpRequestRate is the client request rate per second of an active metadata server above which
+	// a metadata server is activated, 1000 if not set
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	ScaleUpRequestRate int64 `json:"scaleUpRequestRate,omitempty"`
+
+	// ScaleDownRequestRate is the client request rate per second of an active metadata server below which
+	// a metadata server is deactivated, half the scaleUpRequestRate if not set
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	ScaleDownRequestRate int64 `json:"scaleDownRequestRate,omitempty"`
+
+	// CachePressureThreshold is the usage of the cache of an active metadata server in percent of its
+	// mds_cache_memory_limit above which a metadata server is activated, 80 if not set
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	CachePressureThreshold int `json:"cachePressureThreshold,omitempty"`
+
+	// Interval is the interval at which the load is measured, 1m if not set
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+
+	// ScaleDownStabilizationWindow is how long the load must stay low before a metadata server is
+	// deactivated, 10m if not set
+	// +optional
+	ScaleDownStabilizationWindow *metav1.Duration `json:"scaleDownStabilizationWindow,omitempty"`
 }
 
 // FSMirroringSpec represents the setting for a mirrored filesystem
@@ -1073,6 +1883,32 @@ type CephFilesystemStatus struct {
 	// MirroringStatus is the filesystem mirroring status
 	// +optional
 	MirroringStatus *FilesystemMirroringInfoSpec `json:"mirroringStatus,omitempty"`
+	// MDSAutoscaling is the status of the autoscaling of the active metadata servers
+	// +optional
+	// +nullable
+	MDSAutoscaling *MDSAutoscalingStatus `json:"mdsAutoscaling,omitempty"`
+}
+
+// MDSAutoscalingStatus is the status of the autoscaling of the active metadata servers
+type MDSAutoscalingStatus struct {
+	// ActiveCount is the number of active metadata servers set by the autoscaler
+	// +optional
+	ActiveCount int32 `json:"activeCount,omitempty"`
+	// RequestRate is the client request rate per second of all the active metadata servers when the status last changed
+	// +optional
+	RequestRate int64 `json:"requestRate,omitempty"`
+	// CacheUsage is the highest usage of the cache of the active metadata servers in percent when the status last changed
+	// +optional
+	CacheUsage int `json:"cacheUsage,omitempty"`
+	// LastChecked is the time the load was measured when the status last changed
+	// +optional
+	LastChecked string `json:"lastChecked,omitempty"`
+	// LastScaled is the last time the number of active metadata servers changed
+	// +optional
+	LastScaled string `json:"lastScaled,omitempty"`
+	// Reason is the reason of the last change of the number of active metadata servers
+	// +optional
+	Reason string `json:"reason,omitempty"`
 }
 
 // FilesystemMirroringInfo is the status of the pool mirroring
@@ -1381,6 +2217,8 @@ type ObjectStoreStatus struct {
 	// +nullable
 	Info       map[string]string `json:"info,omitempty"`
 	Conditions []Condition       `json:"conditions,omitempty"`
+	// +optional
+	DataPoolMigration *PoolMigrationStatus `json:"dataPoolMigration,omitempty"`
 }
 
 // BucketStatus represents the status of a bucket
@@ -1557,7 +2395,61 @@ type CephNFS struct {
 	Spec              NFSGaneshaSpec `json:"spec"`
 	// +kubebuilder:pruning:PreserveUnknownFields
 	// +optional
-	Status *Status `json:"status,omitempty"`
+	Status *NFSStatus `json:"status,omitempty"`
+}
+
+// NFSStatus represents the status of a CephNFS
+type NFSStatus struct {
+	// +optional
+	Phase string `json:"phase,omitempty"`
+
+	// Servers are the clients and the usage of the exports of each Ganesha server
+	// +optional
+	Servers []NFSServerStatus `json:"servers,omitempty"`
+}
+
+// NFSServerStatus represents the clients and the usage of the exports of a Ganesha server
+type NFSServerStatus struct {
+	// Name is the ID of the server
+	Name string `json:"name"`
+
+	// Clients are the addresses of the clients connected to the server
+	// +optional
+	Clients []string `json:"clients,omitempty"`
+
+	// Exports are the usage statistics of the exports served since the server started
+	// +optional
+	Exports []NFSExportUsage `json:"exports,omitempty"`
+
+	// LastChecked is the time the statistics were collected when they last changed
+	// +optional
+	LastChecked string `json:"lastChecked,omitempty"`
+
+	// Message explains why the statistics could not be collected
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// NFSExportUsage represents the usage statistics of an export of a Ganesha server
+type NFSExportUsage struct {
+	// ExportID is the ID of the export
+	ExportID int `json:"exportID"`
+
+	// Path is the path exported
+	// +optional
+	Path string `json:"path,omitempty"`
+
+	// ReadOps is the number of read operations
+	ReadOps int64 `json:"readOps"`
+
+	// WriteOps is the number of write operations
+	WriteOps int64 `json:"writeOps"`
+
+	// ReadBytes is the number of bytes read
+	ReadBytes int64 `json:"readBytes"`
+
+	// WrittenBytes is the number of bytes written
+	WrittenBytes int64 `json:"writtenBytes"`
 }
 
 // CephNFSList represents a list Ceph NFSes
@@ -1568,6 +2460,134 @@ type CephNFSList struct {
 	Items           []CephNFS `json:"items"`
 }
 
+// +genclient
+// +genclient:noStatus
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CephNFSExport represents an export of a CephNFS
+// +kubebuilder:subresource:status
+type CephNFSExport struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	// Spec represents the specification of an export of a CephNFS
+	Spec CephNFSExportSpec `json:"spec"`
+	// Status represents the status of an export of a CephNFS
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	Status *CephNFSExportStatus `json:"status,omitempty"`
+}
+
+// CephNFSExportSpec represents the specification of an export of a CephNFS
+type CephNFSExportSpec struct {
+	// NFSName is the metadata name of the CephNFS CR serving the export
+	NFSName string `json:"nfsName"`
+
+	// ExportID is the ID of the export in the ganesha servers, the operator assigns one if not set
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	ExportID int `json:"exportID,omitempty"`
+
+	// PseudoPath is the path of the export in the NFSv4 pseudo filesystem
+	// +kubebuilder:validation:Pattern=`^/`
+	PseudoPath string `json:"pseudoPath"`
+
+	// AccessType is the access of the clients to the export, RW if not set
+	// +kubebuilder:validation:Enum=RW;RO;None
+	// +optional
+	AccessType string `json:"accessType,omitempty"`
+
+	// Squash is the squashing of the user ids of the clients, None if not set
+	// +kubebuilder:validation:Enum=None;Root;All
+	// +optional
+	Squash string `json:"squash,omitempty"`
+
+	// Clients restrict the access and squashing of some clients. The clients not listed get the access and
+	// squashing of the export.
+	// +optional
+	Clients []NFSExportClientSpec `json:"clients,omitempty"`
+
+	// CephFS exports a path of a CephFilesystem
+	// +optional
+	// +nullable
+	CephFS *NFSExportCephFSSpec `json:"cephfs,omitempty"`
+
+	// RGW exports a bucket of a CephObjectStore
+	// +optional
+	// +nullable
+	RGW *NFSExportRGWSpec `json:"rgw,omitempty"`
+}
+
+// NFSExportClientSpec represents the access of some clients to an export
+type NFSExportClientSpec struct {
+	// Addresses are the IP addresses, CIDRs or host names of the clients
+	// +kubebuilder:validation:MinItems=1
+	Addresses []string `json:"addresses"`
+
+	// AccessType is the access of the clients to the export, the access type of the export if not set
+	// +kubebuilder:validation:Enum=RW;RO;None
+	// +optional
+	AccessType string `json:"accessType,omitempty"`
+
+	// Squash is the squashing of the user ids of the clients, the squashing of the export if not set
+	// +kubebuilder:validation:Enum=None;Root;All
+	// +optional
+	Squash string `json:"squash,omitempty"`
+}
+
+// NFSExportCephFSSpec represents an export of a path of a CephFilesystem
+type NFSExportCephFSSpec struct {
+	// FilesystemName is the metadata name of the CephFilesystem CR
+	FilesystemName string `json:"filesystemName"`
+
+	// Path is the exported path in the filesystem, / if not set
+	// +kubebuilder:validation:Pattern=`^/`
+	// +optional
+	Path string `json:"path,omitempty"`
+}
+
+// NFSExportRGWSpec represents an export of a bucket of a CephObjectStore
+type NFSExportRGWSpec struct {
+	// ObjectStoreName is the metadata name of the CephObjectStore CR, the CephNFS must serve this object store
+	ObjectStoreName string `json:"objectStoreName"`
+
+	// Bucket is the exported bucket
+	Bucket string `json:"bucket"`
+
+	// UserID is the object store user accessing the bucket, the owner of the bucket if not set
+	// +optional
+	UserID string `json:"userID,omitempty"`
+
+	// SecretName is the name of the secret with the AccessKey and SecretKey of the user. The keys are read from
+	// the object store if not set.
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
+}
+
+// CephNFSExportStatus represents the status of an export of a CephNFS
+type CephNFSExportStatus struct {
+	// +optional
+	Phase ConditionType `json:"phase,omitempty"`
+	// ExportID is the ID of the export in the ganesha servers
+	// +optional
+	ExportID int `json:"exportID,omitempty"`
+	// ReloadedServers is the number of ganesha servers that reloaded their exports since the last change
+	// +optional
+	ReloadedServers int `json:"reloadedServers,omitempty"`
+	// Message explains the phase, typically an error
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CephNFSExportList represents a list of exports of CephNFSes
+type CephNFSExportList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+	Items           []CephNFSExport `json:"items"`
+}
+
 // NFSGaneshaSpec represents the spec of an nfs ganesha server
 type NFSGaneshaSpec struct {
 	// RADOS is the Ganesha RADOS specification
@@ -1575,6 +2595,59 @@ type NFSGaneshaSpec struct {
 
 	// Server is the Ganesha Server specification
 	Server GaneshaServerSpec `json:"server"`
+
+	// RGW configures the Ganesha servers to export the buckets of an object store
+	// +optional
+	// +nullable
+	RGW *GaneshaRGWSpec `json:"rgw,omitempty"`
+
+	// Ingress fronts all the active Ganesha servers with a single stable endpoint
+	// +optional
+	// +nullable
+	Ingress *GaneshaIngressSpec `json:"ingress,omitempty"`
+
+	// Statistics reports the clients and the usage of the exports of the Ganesha servers
+	// +optional
+	Statistics GaneshaStatisticsSpec `json:"statistics,omitempty"`
+}
+
+// GaneshaStatisticsSpec represents the collection of the statistics of the Ganesha servers
+type GaneshaStatisticsSpec struct {
+	// Enabled enables the collection of the clients and the usage of the exports in the status and the metrics
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+}
+
+// GaneshaIngressSpec represents the service load balancing the clients across the active Ganesha servers
+type GaneshaIngressSpec struct {
+	// ServiceType is the type of the service, ClusterIP by default. A LoadBalancer service gets a floating
+	// virtual IP from the load balancer of the Kubernetes cluster.
+	// +kubebuilder:validation:Enum=ClusterIP;NodePort;LoadBalancer
+	// +optional
+	ServiceType v1.ServiceType `json:"serviceType,omitempty"`
+
+	// LoadBalancerIP is the virtual IP requested from the load balancer when the service type is LoadBalancer
+	// +optional
+	LoadBalancerIP string `json:"loadBalancerIP,omitempty"`
+
+	// Annotations to add to the service, e.g. to configure the load balancer
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +nullable
+	// +optional
+	Annotations rook.Annotations `json:"annotations,omitempty"`
+
+	// SessionAffinityTimeoutSeconds is how long a client stays bound to the same server after its last
+	// connection, 3 hours by default
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=86400
+	// +optional
+	SessionAffinityTimeoutSeconds *int32 `json:"sessionAffinityTimeoutSeconds,omitempty"`
+}
+
+// GaneshaRGWSpec represents the object store whose buckets a Ganesha server exports
+type GaneshaRGWSpec struct {
+	// ObjectStoreName is the metadata name of the CephObjectStore CR
+	ObjectStoreName string `json:"objectStoreName"`
 }
 
 // GaneshaRADOSSpec represents the specification of a Ganesha RADOS object
@@ -1622,6 +2695,63 @@ type GaneshaServerSpec struct {
 	// LogLevel set logging level
 	// +optional
 	LogLevel string `json:"logLevel,omitempty"`
+
+	// Protocols are the NFS protocol versions served, 3 and 4. Only NFSv4 is served by default.
+	// +optional
+	Protocols []int `json:"protocols,omitempty"`
+
+	// Security configures the authentication of the clients and the mapping of their user and group names
+	// +optional
+	// +nullable
+	Security *GaneshaSecuritySpec `json:"security,omitempty"`
+}
+
+// GaneshaSecuritySpec represents the security settings of the Ganesha servers
+type GaneshaSecuritySpec struct {
+	// Flavors are the RPC security flavors accepted by the exports: sys, krb5, krb5i and krb5p.
+	// The krb5 flavors require kerberos to be configured.
+	// +optional
+	Flavors []string `json:"flavors,omitempty"`
+
+	// Kerberos configures the servers to authenticate the clients with Kerberos
+	// +optional
+	// +nullable
+	Kerberos *GaneshaKerberosSpec `json:"kerberos,omitempty"`
+
+	// IDMapping configures the mapping of the NFSv4 user and group names to ids
+	// +optional
+	// +nullable
+	IDMapping *GaneshaIDMappingSpec `json:"idMapping,omitempty"`
+}
+
+// GaneshaKerberosSpec represents the Kerberos service of the Ganesha servers
+type GaneshaKerberosSpec struct {
+	// PrincipalName is the service name of the Kerberos principal of the servers, "nfs" by default
+	// +optional
+	PrincipalName string `json:"principalName,omitempty"`
+
+	// KeytabSecretName is the name of the Secret holding the keytab of the servers in the "krb5.keytab" key
+	KeytabSecretName string `json:"keytabSecretName"`
+
+	// ConfigConfigMapName is the name of the ConfigMap holding the krb5.conf of the realm in the "krb5.conf" key
+	// +optional
+	ConfigConfigMapName string `json:"configConfigMapName,omitempty"`
+}
+
+// GaneshaIDMappingSpec represents the mapping of the NFSv4 user and group names to ids
+type GaneshaIDMappingSpec struct {
+	// Domain is the NFSv4 domain of the user and group names, the DNS domain of the servers by default
+	// +optional
+	Domain string `json:"domain,omitempty"`
+
+	// ConfigConfigMapName is the name of the ConfigMap holding an idmapd.conf in the "idmapd.conf" key,
+	// e.g. to map the names with LDAP
+	// +optional
+	ConfigConfigMapName string `json:"configConfigMapName,omitempty"`
+
+	// OnlyNumericOwners maps the user and group names to ids only when they are numeric
+	// +optional
+	OnlyNumericOwners bool `json:"onlyNumericOwners,omitempty"`
 }
 
 // NetworkSpec for Ceph includes backward compatibility code
@@ -1652,6 +2782,37 @@ type NetworkSpec struct {
 	// DualStack determines whether Ceph daemons should listen on both IPv4 and IPv6
 	// +optional
 	DualStack bool `json:"dualStack,omitempty"`
+
+	// Connections are the settings of the msgr2 protocol connections of the daemons and clients
+	// +nullable
+	// +optional
+	Connections *ConnectionsSpec `json:"connections,omitempty"`
+}
+
+// ConnectionsSpec represents the settings of the msgr2 protocol connections
+type ConnectionsSpec struct {
+	// Encryption settings of the connections
+	// +optional
+	Encryption *EncryptionSpec `json:"encryption,omitempty"`
+
+	// Compression settings of the connections between the OSDs
+	// +optional
+	Compression *CompressionSpec `json:"compression,omitempty"`
+}
+
+// EncryptionSpec represents the encryption of the msgr2 protocol connections
+type EncryptionSpec struct {
+	// Enabled requires the secure mode on all the connections of the daemons and the clients. The kernel
+	// clients of the CSI driver need a kernel of at least 5.11.
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+}
+
+// CompressionSpec represents the on-wire compression of the msgr2 protocol connections
+type CompressionSpec struct {
+	// Enabled compresses the messages between the OSDs. Requires Ceph Quincy.
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
 }
 
 // DisruptionManagementSpec configures management of daemon disruptions
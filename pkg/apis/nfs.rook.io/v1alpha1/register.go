@@ -55,6 +55,8 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 	scheme.AddKnownTypes(SchemeGroupVersion,
 		&NFSServer{},
 		&NFSServerList{},
+		&NFSVolumeSnapshot{},
+		&NFSVolumeSnapshotList{},
 	)
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
 	return nil
@@ -17,6 +17,7 @@ package v1alpha1
 
 import (
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -50,6 +51,12 @@ type NFSServerStatus struct {
 	State   NFSServerState `json:"state,omitempty"`
 	Message string         `json:"message,omitempty"`
 	Reason  string         `json:"reason,omitempty"`
+
+	// The replica serving the exports
+	ActiveReplica string `json:"activeReplica,omitempty"`
+
+	// The number of replicas running as standby to take over the exports
+	StandbyReplicas int `json:"standbyReplicas,omitempty"`
 }
 
 // +genclient
@@ -59,6 +66,7 @@ type NFSServerStatus struct {
 // +kubebuilder:subresource:status
 // +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
 // +kubebuilder:printcolumn:name="State",type="string",JSONPath=".status.state",description="NFS Server instance state"
+// +kubebuilder:printcolumn:name="Active",type="string",JSONPath=".status.activeReplica",description="The replica serving the exports"
 
 // NFSServer is the Schema for the nfsservers API
 type NFSServer struct {
@@ -84,7 +92,8 @@ type NFSServerSpec struct {
 	// The annotations-related configuration to add/set on each Pod related object.
 	Annotations map[string]string `json:"annotations,omitempty"`
 
-	// Replicas of the NFS daemon
+	// Replicas of the NFS daemon. A single replica serves the exports, the other replicas are standbys
+	// that take over when it fails. More than one replica requires ReadWriteMany claims for the exports.
 	Replicas int `json:"replicas,omitempty"`
 
 	// The parameters to configure the NFS export
@@ -142,3 +151,65 @@ type AllowedClientsSpec struct {
 	// +kubebuilder:validation:Enum=none;rootid;root;all
 	Squash string `json:"squash,omitempty"`
 }
+
+const (
+	// SnapshotFinalizer keeps a snapshot until the provisioner removed its copy of the volume
+	SnapshotFinalizer = "nfsvolumesnapshot.nfs.rook.io"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:printcolumn:name="Source",type="string",JSONPath=".spec.persistentVolumeClaimName",description="The claim of the snapshotted volume"
+// +kubebuilder:printcolumn:name="ReadyToUse",type="boolean",JSONPath=".status.readyToUse",description="Whether the snapshot can be restored"
+
+// NFSVolumeSnapshot is a point in time copy of a volume provisioned from an NFSServer export
+type NFSVolumeSnapshot struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   NFSVolumeSnapshotSpec   `json:"spec,omitempty"`
+	Status NFSVolumeSnapshotStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+
+// NFSVolumeSnapshotList contains a list of NFSVolumeSnapshot
+type NFSVolumeSnapshotList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+	Items           []NFSVolumeSnapshot `json:"items"`
+}
+
+// NFSVolumeSnapshotSpec represents the volume to snapshot
+type NFSVolumeSnapshotSpec struct {
+	// Name of the claim of the volume to snapshot, in the namespace of the snapshot
+	// +kubebuilder:validation:MinLength=1
+	PersistentVolumeClaimName string `json:"persistentVolumeClaimName"`
+}
+
+// NFSVolumeSnapshotStatus defines the observed state of NFSVolumeSnapshot
+type NFSVolumeSnapshotStatus struct {
+	// Whether the copy of the volume is complete and can be restored to a new claim
+	ReadyToUse bool `json:"readyToUse,omitempty"`
+
+	// The time the copy of the volume was taken
+	// +optional
+	// +nullable
+	CreationTime *metav1.Time `json:"creationTime,omitempty"`
+
+	// The minimum size of a claim to restore the snapshot to
+	// +optional
+	RestoreSize *resource.Quantity `json:"restoreSize,omitempty"`
+
+	// The provisioner that took the snapshot
+	Provisioner string `json:"provisioner,omitempty"`
+
+	// The directory of the snapshot in the export of the NFS server
+	Path string `json:"path,omitempty"`
+
+	Message string `json:"message,omitempty"`
+}
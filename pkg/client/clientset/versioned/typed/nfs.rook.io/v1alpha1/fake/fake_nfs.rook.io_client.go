@@ -32,6 +32,10 @@ func (c *FakeNfsV1alpha1) NFSServers(namespace string) v1alpha1.NFSServerInterfa
 	return &FakeNFSServers{c, namespace}
 }
 
+func (c *FakeNfsV1alpha1) NFSVolumeSnapshots(namespace string) v1alpha1.NFSVolumeSnapshotInterface {
+	return &FakeNFSVolumeSnapshots{c, namespace}
+}
+
 // RESTClient returns a RESTClient that is used to communicate
 // with API server by this client implementation.
 func (c *FakeNfsV1alpha1) RESTClient() rest.Interface {
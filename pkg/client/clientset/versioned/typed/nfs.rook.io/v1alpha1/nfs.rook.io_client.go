@@ -27,6 +27,7 @@ import (
 type NfsV1alpha1Interface interface {
 	RESTClient() rest.Interface
 	NFSServersGetter
+	NFSVolumeSnapshotsGetter
 }
 
 // NfsV1alpha1Client is used to interact with features provided by the nfs.rook.io group.
@@ -38,6 +39,10 @@ func (c *NfsV1alpha1Client) NFSServers(namespace string) NFSServerInterface {
 	return newNFSServers(c, namespace)
 }
 
+func (c *NfsV1alpha1Client) NFSVolumeSnapshots(namespace string) NFSVolumeSnapshotInterface {
+	return newNFSVolumeSnapshots(c, namespace)
+}
+
 // NewForConfig creates a new NfsV1alpha1Client for the given config.
 func NewForConfig(c *rest.Config) (*NfsV1alpha1Client, error) {
 	config := *c
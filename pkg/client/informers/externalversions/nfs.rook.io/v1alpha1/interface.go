@@ -26,6 +26,8 @@ import (
 type Interface interface {
 	// NFSServers returns a NFSServerInformer.
 	NFSServers() NFSServerInformer
+	// NFSVolumeSnapshots returns a NFSVolumeSnapshotInformer.
+	NFSVolumeSnapshots() NFSVolumeSnapshotInformer
 }
 
 type version struct {
@@ -43,3 +45,8 @@ func New(f internalinterfaces.SharedInformerFactory, namespace string, tweakList
 func (v *version) NFSServers() NFSServerInformer {
 	return &nFSServerInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
+
+// NFSVolumeSnapshots returns a NFSVolumeSnapshotInformer.
+func (v *version) NFSVolumeSnapshots() NFSVolumeSnapshotInformer {
+	return &nFSVolumeSnapshotInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
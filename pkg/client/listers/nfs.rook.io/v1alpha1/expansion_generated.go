@@ -25,3 +25,11 @@ type NFSServerListerExpansion interface{}
 // NFSServerNamespaceListerExpansion allows custom methods to be added to
 // NFSServerNamespaceLister.
 type NFSServerNamespaceListerExpansion interface{}
+
+// NFSVolumeSnapshotListerExpansion allows custom methods to be added to
+// NFSVolumeSnapshotLister.
+type NFSVolumeSnapshotListerExpansion interface{}
+
+// NFSVolumeSnapshotNamespaceListerExpansion allows custom methods to be added to
+// NFSVolumeSnapshotNamespaceLister.
+type NFSVolumeSnapshotNamespaceListerExpansion interface{}
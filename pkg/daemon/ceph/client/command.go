@@ -39,6 +39,8 @@ const (
 	CephTool = "ceph"
 	// RBDTool is the name of the CLI tool for 'rbd'
 	RBDTool = "rbd"
+	// RadosTool is the name of the CLI tool for 'rados'
+	RadosTool = "rados"
 	// Kubectl is the name of the CLI tool for 'kubectl'
 	Kubectl = "kubectl"
 	// CrushTool is the name of the CLI tool for 'crushtool'
@@ -67,7 +69,7 @@ func FinalizeCephCommandArgs(command string, clusterInfo *ClusterInfo, args []st
 	// we could use a slice and iterate over it but since we have only 3 elements
 	// I don't think this is worth a loop
 	timeout := strconv.Itoa(int(exec.CephCommandsTimeout.Seconds()))
-	if command != "rbd" && command != "crushtool" && command != "radosgw-admin" {
+	if command != "rbd" && command != "rados" && command != "crushtool" && command != "radosgw-admin" {
 		args = append(args, "--connect-timeout="+timeout)
 	}
 
@@ -125,13 +127,20 @@ func NewRBDCommand(context *clusterd.Context, clusterInfo *ClusterInfo, args []s
 	return cmd
 }
 
+// NewRadosCommand returns a command for the 'rados' tool with a plain output
+func NewRadosCommand(context *clusterd.Context, clusterInfo *ClusterInfo, args []string) *CephToolCommand {
+	cmd := newCephToolCommand(RadosTool, context, clusterInfo, args)
+	cmd.JsonOutput = false
+	return cmd
+}
+
 func (c *CephToolCommand) run() ([]byte, error) {
 	command, args := FinalizeCephCommandArgs(c.tool, c.clusterInfo, c.args, c.context.ConfigDir)
 	if c.JsonOutput {
 		args = append(args, "--format", "json")
 	} else {
-		// the `rbd` tool doesn't use special flag for plain format
-		if c.tool != RBDTool {
+		// the `rbd` and `rados` tools don't use special flag for plain format
+		if c.tool != RBDTool && c.tool != RadosTool {
 			args = append(args, "--format", "plain")
 		}
 	}
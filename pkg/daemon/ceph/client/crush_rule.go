@@ -18,14 +18,28 @@ package client
 
 import (
 	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 
+	"github.com/pkg/errors"
 	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
 )
 
 const (
 	crushReplicatedType      = 1
+	crushErasureType         = 3
 	ruleMinSizeDefault       = 1
 	ruleMaxSizeDefault       = 10
+	ruleMaxSizeErasure       = 20
+	crushRuleTypeErasure     = "erasure"
+	crushRuleTypeReplicated  = "replicated"
+	crushRuleModeFirstn      = "firstn"
+	crushRuleModeIndep       = "indep"
+	chooseleafTriesErasure   = 5
+	chooseTriesErasure       = 100
 	twoStepCRUSHRuleTemplate = `
 rule %s {
         id %d
@@ -180,3 +194,309 @@ func checkIfRuleIDExists(rules []ruleSpec, ID int) bool {
 
 	return false
 }
+
+// CreateOrUpdateCrushRule creates a CRUSH rule with the given steps, or replaces the steps of the rule if they
+// changed. The rule keeps its ID so the pools using it are not affected, except for the placement of their
+// data. It returns the ID of the rule.
+func CreateOrUpdateCrushRule(context *clusterd.Context, clusterInfo *ClusterInfo, ruleName string, spec cephv1.CrushRuleSpec) (int, error) {
+	crushMap, err := getCurrentCrushMap(context, clusterInfo)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to get current crush map")
+	}
+
+	var existing *ruleSpec
+	for i := range crushMap.Rules {
+		if crushMap.Rules[i].Name == ruleName {
+			existing = &crushMap.Rules[i]
+			break
+		}
+	}
+	if existing != nil && crushRuleMatches(*existing, spec) {
+		logger.Debugf("CRUSH rule %q is up to date", ruleName)
+		return existing.ID, nil
+	}
+
+	ruleID := generateRuleID(crushMap.Rules)
+	if existing != nil {
+		ruleID = existing.ID
+		logger.Infof("updating the steps of CRUSH rule %q", ruleName)
+	} else {
+		logger.Infof("creating CRUSH rule %q", ruleName)
+	}
+	rule := buildCustomCrushRule(ruleID, ruleName, spec)
+	err = editCrushMap(context, clusterInfo, func(crushMap string) (string, error) {
+		return removeCrushRule(crushMap, ruleName) + rule, nil
+	})
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to set crush rule %q", ruleName)
+	}
+
+	return ruleID, nil
+}
+
+// DeleteCrushRule deletes a CRUSH rule. It fails if the rule is used by a pool.
+func DeleteCrushRule(context *clusterd.Context, clusterInfo *ClusterInfo, ruleName string) error {
+	args := []string{"osd", "crush", "rule", "rm", ruleName}
+	output, err := NewCephCommand(context, clusterInfo, args).Run()
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete crush rule %q. %s", ruleName, string(output))
+	}
+
+	logger.Infof("deleted crush rule %q", ruleName)
+	return nil
+}
+
+// EnsureCrushBucketTypes adds the bucket types missing from the CRUSH map
+func EnsureCrushBucketTypes(context *clusterd.Context, clusterInfo *ClusterInfo, bucketTypes []string) error {
+	crushMap, err := getCurrentCrushMap(context, clusterInfo)
+	if err != nil {
+		return errors.Wrap(err, "failed to get current crush map")
+	}
+
+	missing := []string{}
+	for _, bucketType := range bucketTypes {
+		if !crushTypeExists(crushMap, bucketType) {
+			missing = append(missing, bucketType)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	logger.Infof("adding CRUSH bucket types %v", missing)
+	err = editCrushMap(context, clusterInfo, func(crushMap string) (string, error) {
+		return addCrushTypes(crushMap, missing)
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to add crush bucket types %v", missing)
+	}
+	return nil
+}
+
+// EnsureCrushBuckets creates the buckets missing from the CRUSH map and moves the buckets under their parent
+func EnsureCrushBuckets(context *clusterd.Context, clusterInfo *ClusterInfo, buckets []cephv1.CrushBucketSpec) error {
+	crushMap, err := getCurrentCrushMap(context, clusterInfo)
+	if err != nil {
+		return errors.Wrap(err, "failed to get current crush map")
+	}
+
+	// create the buckets first so they can be the parents of each other
+	bucketTypes := map[string]string{}
+	for _, bucket := range crushMap.Buckets {
+		bucketTypes[bucket.Name] = bucket.TypeName
+	}
+	for _, bucket := range buckets {
+		if _, ok := bucketTypes[bucket.Name]; ok {
+			continue
+		}
+		args := []string{"osd", "crush", "add-bucket", bucket.Name, bucket.Type}
+		output, err := NewCephCommand(context, clusterInfo, args).Run()
+		if err != nil {
+			return errors.Wrapf(err, "failed to add crush bucket %q. %s", bucket.Name, string(output))
+		}
+		logger.Infof("added crush bucket %q of type %q", bucket.Name, bucket.Type)
+		bucketTypes[bucket.Name] = bucket.Type
+	}
+
+	parents := crushBucketParents(crushMap)
+	for _, bucket := range buckets {
+		if bucket.Parent == "" || parents[bucket.Name] == bucket.Parent {
+			continue
+		}
+		parentType, ok := bucketTypes[bucket.Parent]
+		if !ok {
+			return errors.Errorf("parent %q of crush bucket %q not found", bucket.Parent, bucket.Name)
+		}
+		args := []string{"osd", "crush", "move", bucket.Name, fmt.Sprintf("%s=%s", parentType, bucket.Parent)}
+		output, err := NewCephCommand(context, clusterInfo, args).Run()
+		if err != nil {
+			return errors.Wrapf(err, "failed to move crush bucket %q under %q. %s", bucket.Name, bucket.Parent, string(output))
+		}
+		logger.Infof("moved crush bucket %q under %q", bucket.Name, bucket.Parent)
+	}
+
+	return nil
+}
+
+// crushBucketParents returns the parent of each bucket of the CRUSH map
+func crushBucketParents(crushMap CrushMap) map[string]string {
+	names := map[int]string{}
+	for _, bucket := range crushMap.Buckets {
+		names[bucket.ID] = bucket.Name
+	}
+	parents := map[string]string{}
+	for _, bucket := range crushMap.Buckets {
+		for _, item := range bucket.Items {
+			if name, ok := names[item.ID]; ok {
+				parents[name] = bucket.Name
+			}
+		}
+	}
+	return parents
+}
+
+func crushTypeExists(crushMap CrushMap, typeName string) bool {
+	for _, t := range crushMap.Types {
+		if t.Name == typeName {
+			return true
+		}
+	}
+	return false
+}
+
+var crushTypeLine = regexp.MustCompile(`(?m)^type (\d+) \S+$`)
+
+// addCrushTypes adds bucket types after the last type of the plain text CRUSH map
+func addCrushTypes(crushMap string, types []string) (string, error) {
+	matches := crushTypeLine.FindAllStringSubmatchIndex(crushMap, -1)
+	if len(matches) == 0 {
+		return "", errors.New("no bucket type found in the crush map")
+	}
+	maxID := 0
+	for _, match := range matches {
+		id, err := strconv.Atoi(crushMap[match[2]:match[3]])
+		if err != nil {
+			return "", errors.Wrapf(err, "invalid bucket type id %q", crushMap[match[2]:match[3]])
+		}
+		if id > maxID {
+			maxID = id
+		}
+	}
+
+	end := matches[len(matches)-1][1]
+	lines := ""
+	for i, t := range types {
+		lines += fmt.Sprintf("\ntype %d %s", maxID+i+1, t)
+	}
+	return crushMap[:end] + lines + crushMap[end:], nil
+}
+
+// removeCrushRule removes a rule from the plain text CRUSH map
+func removeCrushRule(crushMap, ruleName string) string {
+	rule := regexp.MustCompile(`(?ms)^rule ` + regexp.QuoteMeta(ruleName) + ` \{.*?^\}\n?`)
+	return rule.ReplaceAllString(crushMap, "")
+}
+
+func crushRuleType(spec cephv1.CrushRuleSpec) string {
+	if spec.Type == crushRuleTypeErasure {
+		return crushRuleTypeErasure
+	}
+	return crushRuleTypeReplicated
+}
+
+func crushRuleStepMode(spec cephv1.CrushRuleSpec, step cephv1.CrushRuleStepSpec) string {
+	if step.Mode != "" {
+		return step.Mode
+	}
+	if crushRuleType(spec) == crushRuleTypeErasure {
+		return crushRuleModeIndep
+	}
+	return crushRuleModeFirstn
+}
+
+// buildCustomCrushRule returns the plain text of a CRUSH rule with the given steps
+func buildCustomCrushRule(ruleID int, ruleName string, spec cephv1.CrushRuleSpec) string {
+	ruleType := crushRuleType(spec)
+	maxSize := ruleMaxSizeDefault
+	if ruleType == crushRuleTypeErasure {
+		maxSize = ruleMaxSizeErasure
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "\nrule %s {\n", ruleName)
+	fmt.Fprintf(&b, "        id %d\n", ruleID)
+	fmt.Fprintf(&b, "        type %s\n", ruleType)
+	fmt.Fprintf(&b, "        min_size %d\n", ruleMinSizeDefault)
+	fmt.Fprintf(&b, "        max_size %d\n", maxSize)
+	if ruleType == crushRuleTypeErasure {
+		// the tries of the default erasure code rules of ceph
+		fmt.Fprintf(&b, "        step set_chooseleaf_tries %d\n", chooseleafTriesErasure)
+		fmt.Fprintf(&b, "        step set_choose_tries %d\n", chooseTriesErasure)
+	}
+	for _, step := range spec.Steps {
+		switch step.Op {
+		case "take":
+			if step.DeviceClass != "" {
+				fmt.Fprintf(&b, "        step take %s class %s\n", step.Item, step.DeviceClass)
+			} else {
+				fmt.Fprintf(&b, "        step take %s\n", step.Item)
+			}
+		case "choose", "chooseleaf":
+			fmt.Fprintf(&b, "        step %s %s %d type %s\n", step.Op, crushRuleStepMode(spec, step), step.Num, step.Type)
+		case "emit":
+			b.WriteString("        step emit\n")
+		}
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// buildCustomCrushSteps returns the steps of a CRUSH rule as they are reported in the CRUSH map
+func buildCustomCrushSteps(spec cephv1.CrushRuleSpec) []stepSpec {
+	steps := []stepSpec{}
+	if crushRuleType(spec) == crushRuleTypeErasure {
+		steps = append(steps,
+			stepSpec{Operation: "set_chooseleaf_tries", Number: chooseleafTriesErasure},
+			stepSpec{Operation: "set_choose_tries", Number: chooseTriesErasure})
+	}
+	for _, step := range spec.Steps {
+		switch step.Op {
+		case "take":
+			// the device class of a take step is a shadow bucket of the class
+			itemName := step.Item
+			if step.DeviceClass != "" {
+				itemName = fmt.Sprintf("%s~%s", step.Item, step.DeviceClass)
+			}
+			steps = append(steps, stepSpec{Operation: "take", ItemName: itemName})
+		case "choose", "chooseleaf":
+			steps = append(steps, stepSpec{
+				Operation: fmt.Sprintf("%s_%s", step.Op, crushRuleStepMode(spec, step)),
+				Number:    uint(step.Num),
+				Type:      step.Type,
+			})
+		case "emit":
+			steps = append(steps, *stepEmit)
+		}
+	}
+	return steps
+}
+
+// crushRuleMatches returns whether a rule of the CRUSH map has the type and steps of the spec
+func crushRuleMatches(rule ruleSpec, spec cephv1.CrushRuleSpec) bool {
+	ruleType := crushReplicatedType
+	if crushRuleType(spec) == crushRuleTypeErasure {
+		ruleType = crushErasureType
+	}
+	if rule.Type != ruleType {
+		return false
+	}
+
+	expected := buildCustomCrushSteps(spec)
+	if len(rule.Steps) != len(expected) {
+		return false
+	}
+	for i, step := range rule.Steps {
+		// the ID of the item is not known before the rule is created
+		step.Item = 0
+		if step != expected[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// CrushDeviceClasses returns the device classes of the OSDs in the CRUSH map
+func CrushDeviceClasses(crushMap CrushMap) []string {
+	classes := map[string]bool{}
+	for _, device := range crushMap.Devices {
+		if device.Class != "" {
+			classes[device.Class] = true
+		}
+	}
+	result := []string{}
+	for class := range classes {
+		result = append(result, class)
+	}
+	sort.Strings(result)
+	return result
+}
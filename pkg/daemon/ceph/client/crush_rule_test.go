@@ -135,3 +135,174 @@ func Test_generateRuleID(t *testing.T) {
 		})
 	}
 }
+
+func TestBuildCustomCrushRule(t *testing.T) {
+	spec := cephv1.CrushRuleSpec{
+		Steps: []cephv1.CrushRuleStepSpec{
+			{Op: "take", Item: "default", DeviceClass: "ssd"},
+			{Op: "choose", Num: 2, Type: "row"},
+			{Op: "chooseleaf", Num: 0, Type: "pdu"},
+			{Op: "emit"},
+		},
+	}
+	rule := buildCustomCrushRule(3, "pdu_rule", spec)
+	assert.Equal(t, `
+rule pdu_rule {
+        id 3
+        type replicated
+        min_size 1
+        max_size 10
+        step take default class ssd
+        step choose firstn 2 type row
+        step chooseleaf firstn 0 type pdu
+        step emit
+}
+`, rule)
+
+	spec.Type = "erasure"
+	spec.Steps[1].Mode = "firstn"
+	rule = buildCustomCrushRule(4, "ec_rule", spec)
+	assert.Equal(t, `
+rule ec_rule {
+        id 4
+        type erasure
+        min_size 1
+        max_size 20
+        step set_chooseleaf_tries 5
+        step set_choose_tries 100
+        step take default class ssd
+        step choose firstn 2 type row
+        step chooseleaf indep 0 type pdu
+        step emit
+}
+`, rule)
+}
+
+func TestCrushRuleMatches(t *testing.T) {
+	spec := cephv1.CrushRuleSpec{
+		Steps: []cephv1.CrushRuleStepSpec{
+			{Op: "take", Item: "default", DeviceClass: "hdd"},
+			{Op: "chooseleaf", Num: 0, Type: "host"},
+			{Op: "emit"},
+		},
+	}
+	rule := ruleSpec{
+		ID:   1,
+		Name: "host_rule",
+		Type: crushReplicatedType,
+		Steps: []stepSpec{
+			{Operation: "take", Item: -2, ItemName: "default~hdd"},
+			{Operation: "chooseleaf_firstn", Number: 0, Type: "host"},
+			{Operation: "emit"},
+		},
+	}
+	assert.True(t, crushRuleMatches(rule, spec))
+
+	// the failure domain changed
+	spec.Steps[1].Type = "rack"
+	assert.False(t, crushRuleMatches(rule, spec))
+	spec.Steps[1].Type = "host"
+
+	// the type changed
+	spec.Type = "erasure"
+	assert.False(t, crushRuleMatches(rule, spec))
+}
+
+func TestAddCrushTypes(t *testing.T) {
+	crushMap := `# types
+type 0 osd
+type 1 host
+type 11 root
+
+# buckets
+`
+	result, err := addCrushTypes(crushMap, []string{"pdu", "row"})
+	assert.NoError(t, err)
+	assert.Equal(t, `# types
+type 0 osd
+type 1 host
+type 11 root
+type 12 pdu
+type 13 row
+
+# buckets
+`, result)
+
+	_, err = addCrushTypes("# buckets\n", []string{"pdu"})
+	assert.Error(t, err)
+}
+
+func TestRemoveCrushRule(t *testing.T) {
+	crushMap := `# rules
+rule replicated_rule {
+	id 0
+	type replicated
+	step take default
+	step chooseleaf firstn 0 type host
+	step emit
+}
+rule pdu_rule {
+	id 1
+	type replicated
+	step take default
+	step chooseleaf firstn 0 type pdu
+	step emit
+}
+rule pdu_rule_2 {
+	id 2
+	type replicated
+	step take default
+	step emit
+}
+`
+	result := removeCrushRule(crushMap, "pdu_rule")
+	assert.NotContains(t, result, "rule pdu_rule {")
+	assert.Contains(t, result, "rule replicated_rule {")
+	assert.Contains(t, result, "rule pdu_rule_2 {")
+	assert.Equal(t, crushMap, removeCrushRule(crushMap, "other_rule"))
+}
+
+func TestEnsureCrushBuckets(t *testing.T) {
+	commands := [][]string{}
+	executor := &exectest.MockExecutor{}
+	executor.MockExecuteCommandWithOutput = func(command string, args ...string) (string, error) {
+		logger.Infof("Command: %s %v", command, args)
+		if args[0] == "osd" && args[1] == "crush" && args[2] == "dump" {
+			return testCrushMap, nil
+		}
+		commands = append(commands, args[:5])
+		return "", nil
+	}
+	context := &clusterd.Context{Executor: executor}
+
+	buckets := []cephv1.CrushBucketSpec{
+		{Name: "row1", Type: "row", Parent: "default"},
+		{Name: "pdu1", Type: "pdu", Parent: "row1"},
+		// the host is already under the root
+		{Name: "minikube", Type: "host", Parent: "default"},
+	}
+	err := EnsureCrushBuckets(context, AdminClusterInfo("mycluster"), buckets)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"osd", "crush", "add-bucket", "row1", "row"},
+		{"osd", "crush", "add-bucket", "pdu1", "pdu"},
+		{"osd", "crush", "move", "row1", "root=default"},
+		{"osd", "crush", "move", "pdu1", "row=row1"},
+	}, commands)
+
+	commands = [][]string{}
+	err = EnsureCrushBuckets(context, AdminClusterInfo("mycluster"), []cephv1.CrushBucketSpec{{Name: "pdu1", Type: "pdu", Parent: "missing"}})
+	assert.Error(t, err)
+}
+
+func TestCrushDeviceClasses(t *testing.T) {
+	var crushMap CrushMap
+	err := json.Unmarshal([]byte(testCrushMap), &crushMap)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"hdd"}, CrushDeviceClasses(crushMap))
+
+	crushMap.Devices = append(crushMap.Devices, crushMap.Devices[0], crushMap.Devices[0])
+	crushMap.Devices[1].Class = "ssd"
+	crushMap.Devices[2].Class = ""
+	assert.Equal(t, []string{"hdd", "ssd"}, CrushDeviceClasses(crushMap))
+}
@@ -18,10 +18,12 @@ package client
 import (
 	"encoding/json"
 	"fmt"
+	"syscall"
 
 	"github.com/pkg/errors"
 	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
 	"github.com/rook/rook/pkg/clusterd"
+	"github.com/rook/rook/pkg/util/exec"
 )
 
 type CephErasureCodeProfile struct {
@@ -33,6 +35,21 @@ type CephErasureCodeProfile struct {
 	CrushRoot        string `json:"crush-root"`
 }
 
+// String returns the chunks of the profile
+func (p CephErasureCodeProfile) String() string {
+	return fmt.Sprintf("k=%d,m=%d", p.DataChunkCount, p.CodingChunkCount)
+}
+
+// Matches returns whether the profile has the chunks of the pool spec
+func (p CephErasureCodeProfile) Matches(pool cephv1.PoolSpec) bool {
+	return p.DataChunkCount == pool.ErasureCoded.DataChunks && p.CodingChunkCount == pool.ErasureCoded.CodingChunks
+}
+
+// ErasureCodedSpecString returns the chunks of the erasure coded pool spec in the format of a profile
+func ErasureCodedSpecString(pool cephv1.PoolSpec) string {
+	return CephErasureCodeProfile{DataChunkCount: pool.ErasureCoded.DataChunks, CodingChunkCount: pool.ErasureCoded.CodingChunks}.String()
+}
+
 func ListErasureCodeProfiles(context *clusterd.Context, clusterInfo *ClusterInfo) ([]string, error) {
 	args := []string{"osd", "erasure-code-profile", "ls"}
 	buf, err := NewCephCommand(context, clusterInfo, args).Run()
@@ -65,6 +82,28 @@ func GetErasureCodeProfileDetails(context *clusterd.Context, clusterInfo *Cluste
 	return ecProfileDetails, nil
 }
 
+// GetPoolErasureCodeProfile returns the name and details of the erasure code profile of a pool. The
+// profile is nil if the pool does not exist or is not erasure coded.
+func GetPoolErasureCodeProfile(context *clusterd.Context, clusterInfo *ClusterInfo, poolName string) (string, *CephErasureCodeProfile, error) {
+	details, err := GetPoolDetails(context, clusterInfo, poolName)
+	if err != nil {
+		if code, ok := exec.ExitStatus(errors.Cause(err)); ok && code == int(syscall.ENOENT) {
+			logger.Debugf("pool %q not found", poolName)
+			return "", nil, nil
+		}
+		return "", nil, err
+	}
+	if details.ErasureCodeProfile == "" {
+		return "", nil, nil
+	}
+
+	profile, err := GetErasureCodeProfileDetails(context, clusterInfo, details.ErasureCodeProfile)
+	if err != nil {
+		return "", nil, err
+	}
+	return details.ErasureCodeProfile, &profile, nil
+}
+
 func CreateErasureCodeProfile(context *clusterd.Context, clusterInfo *ClusterInfo, profileName string, pool cephv1.PoolSpec) error {
 	// look up the default profile so we can use the default plugin/technique
 	defaultProfile, err := GetErasureCodeProfileDetails(context, clusterInfo, "default")
@@ -17,6 +17,7 @@ package client
 
 import (
 	"fmt"
+	"os/exec"
 	"testing"
 
 	"github.com/pkg/errors"
@@ -87,3 +88,51 @@ func testCreateProfile(t *testing.T, failureDomain, crushRoot, deviceClass strin
 	err := CreateErasureCodeProfile(context, AdminClusterInfo("mycluster"), "myapp", spec)
 	assert.Nil(t, err)
 }
+
+func TestGetPoolErasureCodeProfile(t *testing.T) {
+	executor := &exectest.MockExecutor{}
+	context := &clusterd.Context{Executor: executor}
+	executor.MockExecuteCommandWithOutput = func(command string, args ...string) (string, error) {
+		logger.Infof("Command: %s %v", command, args)
+		if args[1] == "pool" && args[2] == "get" {
+			switch args[3] {
+			case "ecpool":
+				return `{"pool":"ecpool","erasure_code_profile":"ecpool_ecprofile"}`, nil
+			case "replicapool":
+				return `{"pool":"replicapool","size":3}`, nil
+			}
+			if args[3] == "missing" {
+				return "", exec.Command("sh", "-c", "exit 2").Run()
+			}
+			return "", errors.New("connection timed out")
+		}
+		if args[1] == "erasure-code-profile" && args[2] == "get" {
+			assert.Equal(t, "ecpool_ecprofile", args[3])
+			return `{"k":"2","m":"1","plugin":"jerasure"}`, nil
+		}
+		return "", errors.Errorf("unexpected ceph command %q", args)
+	}
+	clusterInfo := AdminClusterInfo("mycluster")
+
+	name, profile, err := GetPoolErasureCodeProfile(context, clusterInfo, "ecpool")
+	assert.NoError(t, err)
+	assert.Equal(t, "ecpool_ecprofile", name)
+	assert.Equal(t, "k=2,m=1", profile.String())
+	assert.True(t, profile.Matches(cephv1.PoolSpec{ErasureCoded: cephv1.ErasureCodedSpec{DataChunks: 2, CodingChunks: 1}}))
+	assert.False(t, profile.Matches(cephv1.PoolSpec{ErasureCoded: cephv1.ErasureCodedSpec{DataChunks: 4, CodingChunks: 2}}))
+
+	// a replicated pool has no profile
+	_, profile, err = GetPoolErasureCodeProfile(context, clusterInfo, "replicapool")
+	assert.NoError(t, err)
+	assert.Nil(t, profile)
+
+	// neither a missing pool
+	_, profile, err = GetPoolErasureCodeProfile(context, clusterInfo, "missing")
+	assert.NoError(t, err)
+	assert.Nil(t, profile)
+
+	// the other failures are not taken for a missing pool
+	_, profile, err = GetPoolErasureCodeProfile(context, clusterInfo, "unreachable")
+	assert.Error(t, err)
+	assert.Nil(t, profile)
+}
@@ -381,3 +381,40 @@ func GetMDSDump(context *clusterd.Context, clusterInfo *ClusterInfo) (*MDSDump,
 	}
 	return &dump, nil
 }
+
+// MDSPerfCounters are the perf counters of an mds daemon that measure its load
+type MDSPerfCounters struct {
+	MDSServer struct {
+		// HandleClientRequest is the number of client requests handled since the daemon started
+		HandleClientRequest int64 `json:"handle_client_request"`
+	} `json:"mds_server"`
+	Mempool struct {
+		// CacheBytes is the size of the metadata cache
+		CacheBytes int64 `json:"mds_co_bytes"`
+	} `json:"mempool"`
+}
+
+// GetMDSPerfCounters returns the perf counters of an mds daemon
+func GetMDSPerfCounters(context *clusterd.Context, clusterInfo *ClusterInfo, mdsName string) (*MDSPerfCounters, error) {
+	args := []string{"tell", fmt.Sprintf("mds.%s", mdsName), "perf", "dump"}
+	buf, err := NewCephCommand(context, clusterInfo, args).Run()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get perf counters of mds %q", mdsName)
+	}
+	var counters MDSPerfCounters
+	if err := json.Unmarshal(buf, &counters); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal perf counters of mds %q. %s", mdsName, string(buf))
+	}
+	return &counters, nil
+}
+
+// ActiveDaemons returns the mds daemons that are active in the filesystem
+func (m *MDSMap) ActiveDaemons() []MDSInfo {
+	active := []MDSInfo{}
+	for _, info := range m.Info {
+		if info.State == "up:active" {
+			active = append(active, info)
+		}
+	}
+	return active
+}
@@ -587,3 +587,32 @@ func TestWaitForNoStandbys(t *testing.T) {
 	assert.NoError(t, err)
 
 }
+
+func TestGetMDSPerfCounters(t *testing.T) {
+	executor := &exectest.MockExecutor{}
+	context := &clusterd.Context{Executor: executor}
+	executor.MockExecuteCommandWithOutput = func(command string, args ...string) (string, error) {
+		logger.Infof("Command: %s %v", command, args)
+		if args[0] == "tell" && args[1] == "mds.myfs-a" && args[2] == "perf" && args[3] == "dump" {
+			return `{"mds_server":{"dispatch_client_request":12,"handle_client_request":2500,"handle_client_session":10},
+			"mempool":{"mds_co_items":340,"mds_co_bytes":1048576},"mds_mem":{"rss":104857600}}`, nil
+		}
+		return "", errors.Errorf("unexpected ceph command %q", args)
+	}
+
+	counters, err := GetMDSPerfCounters(context, AdminClusterInfo("mycluster"), "myfs-a")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2500), counters.MDSServer.HandleClientRequest)
+	assert.Equal(t, int64(1048576), counters.Mempool.CacheBytes)
+
+	_, err = GetMDSPerfCounters(context, AdminClusterInfo("mycluster"), "myfs-b")
+	assert.Error(t, err)
+
+	// the active daemons of the filesystem
+	var fs CephFilesystemDetails
+	err = json.Unmarshal([]byte(cephFilesystemGetResponseRaw), &fs)
+	assert.NoError(t, err)
+	active := fs.MDSMap.ActiveDaemons()
+	assert.Equal(t, 1, len(active))
+	assert.Equal(t, "1", active[0].Name)
+}
@@ -0,0 +1,173 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/rook/rook/pkg/clusterd"
+)
+
+// PoolImageInventory lists the rbd images of a pool and of its rados namespaces
+type PoolImageInventory struct {
+	// Images are the specs of the images
+	Images []string
+	// TrashImages are the specs of the images in the trash, with the id of the image in the trash
+	TrashImages []string
+	// Snapshots are the specs of the snapshots of the images
+	Snapshots []string
+}
+
+// Empty returns whether the pool has no image, image in the trash or snapshot
+func (i *PoolImageInventory) Empty() bool {
+	return len(i.Images) == 0 && len(i.TrashImages) == 0 && len(i.Snapshots) == 0
+}
+
+type trashImage struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// imageOrSnapshot is an image, or a snapshot if the snapshot is set, in the long listing of the images
+type imageOrSnapshot struct {
+	Image    string `json:"image"`
+	Snapshot string `json:"snapshot"`
+}
+
+// GetPoolImageInventory lists the images, the images in the trash and the snapshots of the images of a pool
+// and of its rados namespaces. The images of the other pools that store their data in the pool are listed too.
+func GetPoolImageInventory(context *clusterd.Context, clusterInfo *ClusterInfo, poolName string) (*PoolImageInventory, error) {
+	namespaces, err := listPoolNamespaces(context, clusterInfo, poolName)
+	if err != nil {
+		return nil, err
+	}
+
+	inventory := &PoolImageInventory{Images: []string{}, TrashImages: []string{}, Snapshots: []string{}}
+	for _, namespace := range namespaces {
+		// the snapshots are listed with the images to avoid listing the snapshots of each image
+		images, err := listImagesAndSnapshots(context, clusterInfo, poolName, namespace)
+		if err != nil {
+			return nil, err
+		}
+		for _, image := range images {
+			imageSpec := GetImageSpec(poolName, namespace, image.Image)
+			if image.Snapshot == "" {
+				inventory.Images = append(inventory.Images, imageSpec)
+			} else {
+				inventory.Snapshots = append(inventory.Snapshots, fmt.Sprintf("%s@%s", imageSpec, image.Snapshot))
+			}
+		}
+
+		trashImages, err := listTrashImages(context, clusterInfo, poolName, namespace)
+		if err != nil {
+			return nil, err
+		}
+		for _, image := range trashImages {
+			inventory.TrashImages = append(inventory.TrashImages, fmt.Sprintf("%s (%s)", GetImageSpec(poolName, namespace, image.Name), image.ID))
+		}
+	}
+
+	dataPoolImages, err := ListImagesInDataPools(context, clusterInfo, poolName)
+	if err != nil {
+		return nil, err
+	}
+	for _, image := range dataPoolImages {
+		if image.TrashID != "" {
+			inventory.TrashImages = append(inventory.TrashImages, fmt.Sprintf("%s (%s)", image.ImageSpec, image.TrashID))
+		} else {
+			inventory.Images = append(inventory.Images, image.ImageSpec)
+		}
+	}
+
+	return inventory, nil
+}
+
+// PurgeTrash removes the images of the trash of a pool and of its rados namespaces. The images whose
+// deferment time has not expired are kept in the trash.
+func PurgeTrash(context *clusterd.Context, clusterInfo *ClusterInfo, poolName string) error {
+	namespaces, err := listPoolNamespaces(context, clusterInfo, poolName)
+	if err != nil {
+		return err
+	}
+
+	for _, namespace := range namespaces {
+		args := []string{"trash", "purge", poolName}
+		if namespace != "" {
+			args = append(args, "--namespace", namespace)
+		}
+		output, err := NewRBDCommand(context, clusterInfo, args).Run()
+		if err != nil {
+			return errors.Wrapf(err, "failed to purge the trash of pool %q namespace %q. %s", poolName, namespace, string(output))
+		}
+	}
+
+	logger.Infof("purged the trash of pool %q", poolName)
+	return nil
+}
+
+// listPoolNamespaces returns the rados namespaces of a pool, starting with the default namespace
+func listPoolNamespaces(context *clusterd.Context, clusterInfo *ClusterInfo, poolName string) ([]string, error) {
+	radosNamespaces, err := ListRadosNamespacesInPool(context, clusterInfo, poolName)
+	if err != nil {
+		return nil, err
+	}
+	namespaces := []string{""}
+	for _, namespace := range radosNamespaces {
+		namespaces = append(namespaces, namespace.Name)
+	}
+	return namespaces, nil
+}
+
+func listTrashImages(context *clusterd.Context, clusterInfo *ClusterInfo, poolName, namespace string) ([]trashImage, error) {
+	args := []string{"trash", "ls", poolName}
+	if namespace != "" {
+		args = append(args, "--namespace", namespace)
+	}
+	cmd := NewRBDCommand(context, clusterInfo, args)
+	cmd.JsonOutput = true
+	buf, err := cmd.Run()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list the trash of pool %q namespace %q. %s", poolName, namespace, string(buf))
+	}
+
+	var images []trashImage
+	if err := json.Unmarshal(buf, &images); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal trash images. %s", string(buf))
+	}
+	return images, nil
+}
+
+func listImagesAndSnapshots(context *clusterd.Context, clusterInfo *ClusterInfo, poolName, namespace string) ([]imageOrSnapshot, error) {
+	args := []string{"ls", "--long", "--pool", poolName}
+	if namespace != "" {
+		args = append(args, "--namespace", namespace)
+	}
+	cmd := NewRBDCommand(context, clusterInfo, args)
+	cmd.JsonOutput = true
+	buf, err := cmd.Run()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list the images and snapshots in pool %q namespace %q. %s", poolName, namespace, string(buf))
+	}
+
+	var images []imageOrSnapshot
+	if err := json.Unmarshal(buf, &images); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal images and snapshots. %s", string(buf))
+	}
+	return images, nil
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/rook/rook/pkg/clusterd"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetPoolImageInventory(t *testing.T) {
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutput: func(command string, args ...string) (string, error) {
+			logger.Infof("Command: %s %v", command, args)
+			if command == "ceph" && args[0] == "osd" && args[1] == "pool" && args[2] == "ls" {
+				return `[{"pool_name":"replicapool","type":1,"application_metadata":{"rbd":{}}},
+					{"pool_name":"otherpool","type":1,"application_metadata":{"rbd":{}}}]`, nil
+			}
+			assert.Equal(t, "rbd", command)
+			switch args[0] {
+			case "namespace":
+				if args[3] == "otherpool" {
+					return `[]`, nil
+				}
+				return `[{"name":"ns1"}]`, nil
+			case "ls":
+				if args[1] == "--long" {
+					// the snapshots are listed with the images
+					if len(args) > 4 && args[4] == "--namespace" {
+						return `[{"image":"image3","size":1073741824,"format":2}]`, nil
+					}
+					return `[{"image":"image1","size":1073741824,"format":2},
+						{"image":"image1","snapshot":"snap1","snapshot_id":4,"size":1073741824,"format":2},
+						{"image":"image2","size":1073741824,"format":2}]`, nil
+				}
+				if args[2] == "otherpool" {
+					return `["image4","image5"]`, nil
+				}
+				if len(args) > 3 && args[3] == "--namespace" {
+					return `["image3"]`, nil
+				}
+				return `["image1","image2"]`, nil
+			case "info":
+				// the data of image4 is stored in replicapool
+				if args[1] == "otherpool/image4" {
+					return `{"name":"image4","data_pool":"replicapool"}`, nil
+				}
+				return `{}`, nil
+			case "trash":
+				if args[2] == "otherpool" || (len(args) > 3 && args[3] == "--namespace") {
+					return `[]`, nil
+				}
+				return `[{"id":"10f5a3b6e2a4","name":"csi-vol-1"}]`, nil
+			}
+			return "", errors.Errorf("unexpected command %s %v", command, args)
+		},
+	}
+	context := &clusterd.Context{Executor: executor}
+
+	inventory, err := GetPoolImageInventory(context, AdminClusterInfo("mycluster"), "replicapool")
+	assert.NoError(t, err)
+	assert.False(t, inventory.Empty())
+	assert.Equal(t, []string{"replicapool/image1", "replicapool/image2", "replicapool/ns1/image3", "otherpool/image4"}, inventory.Images)
+	assert.Equal(t, []string{"replicapool/image1@snap1"}, inventory.Snapshots)
+	assert.Equal(t, []string{"replicapool/csi-vol-1 (10f5a3b6e2a4)"}, inventory.TrashImages)
+}
+
+func TestPurgeTrash(t *testing.T) {
+	purged := [][]string{}
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutput: func(command string, args ...string) (string, error) {
+			logger.Infof("Command: %s %v", command, args)
+			if args[0] == "namespace" {
+				return `[{"name":"ns1"}]`, nil
+			}
+			purged = append(purged, args)
+			return "", nil
+		},
+	}
+	context := &clusterd.Context{Executor: executor}
+
+	err := PurgeTrash(context, AdminClusterInfo("mycluster"), "replicapool")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(purged))
+	assert.Equal(t, []string{"trash", "purge", "replicapool"}, purged[0][:3])
+	assert.Equal(t, []string{"trash", "purge", "replicapool", "--namespace", "ns1"}, purged[1][:5])
+}
@@ -0,0 +1,217 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	"github.com/rook/rook/pkg/clusterd"
+)
+
+const (
+	replicatedPoolType = 1
+	// ImageMigrationExecuted is the state of an image migration ready to be committed
+	ImageMigrationExecuted = "executed"
+)
+
+// ImageDataPool is an rbd image and the pool its data is stored in
+type ImageDataPool struct {
+	ImageSpec string
+	DataPool  string
+	// TrashID is the id of the image if it is in the trash
+	TrashID string
+}
+
+type poolListDetails struct {
+	Name                string                     `json:"pool_name"`
+	Type                int                        `json:"type"`
+	ApplicationMetadata map[string]json.RawMessage `json:"application_metadata"`
+}
+
+// ListImagesInDataPools lists the rbd images of all the replicated rbd pools and their rados namespaces,
+// including the images in the trash, that store their data in one of the given data pools
+func ListImagesInDataPools(context *clusterd.Context, clusterInfo *ClusterInfo, dataPools ...string) ([]ImageDataPool, error) {
+	args := []string{"osd", "pool", "ls", "detail"}
+	buf, err := NewCephCommand(context, clusterInfo, args).Run()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list pools")
+	}
+	var pools []poolListDetails
+	if err := json.Unmarshal(buf, &pools); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal pools. %s", string(buf))
+	}
+
+	images := []ImageDataPool{}
+	for _, pool := range pools {
+		// the images are in replicated pools, an erasure coded pool only stores their data
+		if _, ok := pool.ApplicationMetadata["rbd"]; !ok || pool.Type != replicatedPoolType {
+			continue
+		}
+		namespaces, err := ListRadosNamespacesInPool(context, clusterInfo, pool.Name)
+		if err != nil {
+			return nil, err
+		}
+		namespaces = append([]RadosNamespace{{Name: ""}}, namespaces...)
+		for _, namespace := range namespaces {
+			names, err := listImageNames(context, clusterInfo, pool.Name, namespace.Name)
+			if err != nil {
+				return nil, err
+			}
+			for _, name := range names {
+				imageSpec := GetImageSpec(pool.Name, namespace.Name, name)
+				dataPool, err := GetImageDataPool(context, clusterInfo, imageSpec)
+				if err != nil {
+					return nil, err
+				}
+				if isDataPool(dataPools, dataPool) {
+					images = append(images, ImageDataPool{ImageSpec: imageSpec, DataPool: dataPool})
+				}
+			}
+
+			// the images in the trash still store their data in their data pool
+			trashImages, err := listTrashImages(context, clusterInfo, pool.Name, namespace.Name)
+			if err != nil {
+				return nil, err
+			}
+			for _, image := range trashImages {
+				dataPool, err := getTrashImageDataPool(context, clusterInfo, pool.Name, namespace.Name, image.ID)
+				if err != nil {
+					return nil, err
+				}
+				if isDataPool(dataPools, dataPool) {
+					imageSpec := GetImageSpec(pool.Name, namespace.Name, image.Name)
+					images = append(images, ImageDataPool{ImageSpec: imageSpec, DataPool: dataPool, TrashID: image.ID})
+				}
+			}
+		}
+	}
+
+	return images, nil
+}
+
+func isDataPool(dataPools []string, dataPool string) bool {
+	for _, p := range dataPools {
+		if dataPool == p {
+			return true
+		}
+	}
+	return false
+}
+
+func listImageNames(context *clusterd.Context, clusterInfo *ClusterInfo, poolName, namespace string) ([]string, error) {
+	args := []string{"ls", "--pool", poolName}
+	if namespace != "" {
+		args = append(args, "--namespace", namespace)
+	}
+	cmd := NewRBDCommand(context, clusterInfo, args)
+	cmd.JsonOutput = true
+	buf, err := cmd.Run()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list images in pool %q namespace %q. %s", poolName, namespace, string(buf))
+	}
+
+	var names []string
+	if err := json.Unmarshal(buf, &names); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal images. %s", string(buf))
+	}
+	return names, nil
+}
+
+// GetImageDataPool returns the pool the data of an image is stored in, empty if the data is stored in
+// the pool of the image
+func GetImageDataPool(context *clusterd.Context, clusterInfo *ClusterInfo, imageSpec string) (string, error) {
+	return getImageDataPool(context, clusterInfo, imageSpec, []string{"info", imageSpec})
+}
+
+// getTrashImageDataPool returns the pool the data of an image in the trash is stored in
+func getTrashImageDataPool(context *clusterd.Context, clusterInfo *ClusterInfo, poolName, namespace, imageID string) (string, error) {
+	args := []string{"info", "--pool", poolName, "--image-id", imageID}
+	if namespace != "" {
+		args = append(args, "--namespace", namespace)
+	}
+	return getImageDataPool(context, clusterInfo, imageID, args)
+}
+
+func getImageDataPool(context *clusterd.Context, clusterInfo *ClusterInfo, image string, args []string) (string, error) {
+	cmd := NewRBDCommand(context, clusterInfo, args)
+	cmd.JsonOutput = true
+	buf, err := cmd.Run()
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get info of image %q. %s", image, string(buf))
+	}
+
+	var info struct {
+		DataPool string `json:"data_pool"`
+	}
+	if err := json.Unmarshal(buf, &info); err != nil {
+		return "", errors.Wrapf(err, "failed to unmarshal info of image %q", image)
+	}
+	return info.DataPool, nil
+}
+
+// GetImageMigrationState returns the state of the migration of an image, empty if the image is not migrating
+func GetImageMigrationState(context *clusterd.Context, clusterInfo *ClusterInfo, imageSpec string) (string, error) {
+	args := []string{"status", imageSpec}
+	cmd := NewRBDCommand(context, clusterInfo, args)
+	cmd.JsonOutput = true
+	buf, err := cmd.Run()
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get status of image %q. %s", imageSpec, string(buf))
+	}
+
+	var status struct {
+		Migration *struct {
+			State string `json:"state"`
+		} `json:"migration"`
+	}
+	if err := json.Unmarshal(buf, &status); err != nil {
+		return "", errors.Wrapf(err, "failed to unmarshal status of image %q", imageSpec)
+	}
+	if status.Migration == nil {
+		return "", nil
+	}
+	return status.Migration.State, nil
+}
+
+// PrepareImageMigration prepares the migration of the data of an image to another data pool. The image
+// keeps its name and pool. The preparation fails if the image is in use.
+func PrepareImageMigration(context *clusterd.Context, clusterInfo *ClusterInfo, imageSpec, dataPool string) error {
+	return runImageMigrationCommand(context, clusterInfo, imageSpec, "prepare", "--data-pool", dataPool)
+}
+
+// ExecuteImageMigration copies the data of a prepared image migration, the image can be used during the copy
+func ExecuteImageMigration(context *clusterd.Context, clusterInfo *ClusterInfo, imageSpec string) error {
+	return runImageMigrationCommand(context, clusterInfo, imageSpec, "execute")
+}
+
+// CommitImageMigration removes the source of an executed image migration
+func CommitImageMigration(context *clusterd.Context, clusterInfo *ClusterInfo, imageSpec string) error {
+	return runImageMigrationCommand(context, clusterInfo, imageSpec, "commit")
+}
+
+func runImageMigrationCommand(context *clusterd.Context, clusterInfo *ClusterInfo, imageSpec, action string, options ...string) error {
+	args := append([]string{"migration", action}, options...)
+	args = append(args, imageSpec)
+	output, err := NewRBDCommand(context, clusterInfo, args).Run()
+	if err != nil {
+		return errors.Wrapf(err, "failed to %s migration of image %q. %s", action, imageSpec, string(output))
+	}
+
+	logger.Infof("migration %s of image %q succeeded", action, imageSpec)
+	return nil
+}
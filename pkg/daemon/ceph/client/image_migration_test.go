@@ -0,0 +1,119 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/rook/rook/pkg/clusterd"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListImagesInDataPools(t *testing.T) {
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutput: func(command string, args ...string) (string, error) {
+			logger.Infof("Command: %s %v", command, args)
+			if command == "ceph" && args[0] == "osd" && args[1] == "pool" && args[2] == "ls" {
+				return `[{"pool_name":"replicapool","type":1,"application_metadata":{"rbd":{}}},
+					{"pool_name":"ecpool","type":3,"application_metadata":{"rbd":{}}},
+					{"pool_name":"myfs-metadata","type":1,"application_metadata":{"cephfs":{}}}]`, nil
+			}
+			assert.Equal(t, "rbd", command)
+			switch args[0] {
+			case "namespace":
+				assert.Equal(t, "replicapool", args[3])
+				return `[{"name":"ns1"}]`, nil
+			case "ls":
+				assert.Equal(t, "replicapool", args[2])
+				if len(args) > 3 && args[3] == "--namespace" {
+					return `["image3"]`, nil
+				}
+				return `["image1","image2"]`, nil
+			case "trash":
+				assert.Equal(t, "replicapool", args[2])
+				if len(args) > 3 && args[3] == "--namespace" {
+					return `[]`, nil
+				}
+				return `[{"id":"1234","name":"image4"}]`, nil
+			case "info":
+				if args[1] == "--pool" {
+					assert.Equal(t, []string{"replicapool", "--image-id", "1234"}, args[2:5])
+					return `{"name":"image4","data_pool":"ecpool"}`, nil
+				}
+				switch args[1] {
+				case "replicapool/image1":
+					return `{"name":"image1","data_pool":"ecpool"}`, nil
+				case "replicapool/image2":
+					return `{"name":"image2"}`, nil
+				case "replicapool/ns1/image3":
+					return `{"name":"image3","data_pool":"ecpool-migration"}`, nil
+				}
+			}
+			return "", errors.Errorf("unexpected command %s %v", command, args)
+		},
+	}
+	context := &clusterd.Context{Executor: executor}
+
+	images, err := ListImagesInDataPools(context, AdminClusterInfo("mycluster"), "ecpool", "ecpool-migration")
+	assert.NoError(t, err)
+	assert.Equal(t, []ImageDataPool{
+		{ImageSpec: "replicapool/image1", DataPool: "ecpool"},
+		{ImageSpec: "replicapool/image4", DataPool: "ecpool", TrashID: "1234"},
+		{ImageSpec: "replicapool/ns1/image3", DataPool: "ecpool-migration"},
+	}, images)
+
+	images, err = ListImagesInDataPools(context, AdminClusterInfo("mycluster"), "otherpool")
+	assert.NoError(t, err)
+	assert.Empty(t, images)
+}
+
+func TestImageMigration(t *testing.T) {
+	commands := [][]string{}
+	status := `{"watchers":[]}`
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutput: func(command string, args ...string) (string, error) {
+			logger.Infof("Command: %s %v", command, args)
+			assert.Equal(t, "rbd", command)
+			if args[0] == "status" {
+				assert.Equal(t, "replicapool/image1", args[1])
+				return status, nil
+			}
+			commands = append(commands, args)
+			return "", nil
+		},
+	}
+	context := &clusterd.Context{Executor: executor}
+	clusterInfo := AdminClusterInfo("mycluster")
+
+	state, err := GetImageMigrationState(context, clusterInfo, "replicapool/image1")
+	assert.NoError(t, err)
+	assert.Equal(t, "", state)
+	status = `{"watchers":[],"migration":{"source_pool_name":"replicapool","state":"executed"}}`
+	state, err = GetImageMigrationState(context, clusterInfo, "replicapool/image1")
+	assert.NoError(t, err)
+	assert.Equal(t, ImageMigrationExecuted, state)
+
+	assert.NoError(t, PrepareImageMigration(context, clusterInfo, "replicapool/image1", "ecpool-migration"))
+	assert.NoError(t, ExecuteImageMigration(context, clusterInfo, "replicapool/image1"))
+	assert.NoError(t, CommitImageMigration(context, clusterInfo, "replicapool/image1"))
+	assert.Equal(t, 3, len(commands))
+	assert.Equal(t, []string{"migration", "prepare", "--data-pool", "ecpool-migration", "replicapool/image1"}, commands[0][:5])
+	assert.Equal(t, []string{"migration", "execute", "replicapool/image1"}, commands[1][:3])
+	assert.Equal(t, []string{"migration", "commit", "replicapool/image1"}, commands[2][:3])
+}
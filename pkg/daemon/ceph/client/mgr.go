@@ -64,6 +64,28 @@ func CephMgrStat(context *clusterd.Context, clusterInfo *ClusterInfo) (*MgrStat,
 	return &mgrStat, nil
 }
 
+// MgrModuleList is the list of mgr modules reported by "ceph mgr module ls"
+type MgrModuleList struct {
+	AlwaysOnModules []string `json:"always_on_modules"`
+	EnabledModules  []string `json:"enabled_modules"`
+}
+
+// MgrListModules returns the mgr modules that are always on or enabled
+func MgrListModules(context *clusterd.Context, clusterInfo *ClusterInfo) (*MgrModuleList, error) {
+	args := []string{"mgr", "module", "ls"}
+	buf, err := NewCephCommand(context, clusterInfo, args).Run()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list mgr modules. %s", string(buf))
+	}
+
+	var moduleList MgrModuleList
+	if err := json.Unmarshal(buf, &moduleList); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal mgr module list")
+	}
+
+	return &moduleList, nil
+}
+
 // MgrEnableModule enables a mgr module
 func MgrEnableModule(context *clusterd.Context, clusterInfo *ClusterInfo, name string, force bool) error {
 	retryCount := 5
@@ -102,6 +124,16 @@ func MgrDisableModule(context *clusterd.Context, clusterInfo *ClusterInfo, name
 	return enableModule(context, clusterInfo, name, false, "disable")
 }
 
+// MgrFail marks the given mgr daemon as failed so that a standby takes over as the active mgr
+func MgrFail(context *clusterd.Context, clusterInfo *ClusterInfo, name string) error {
+	args := []string{"mgr", "fail", name}
+	_, err := NewCephCommand(context, clusterInfo, args).Run()
+	if err != nil {
+		return errors.Wrapf(err, "failed to fail mgr %q", name)
+	}
+	return nil
+}
+
 func enableModule(context *clusterd.Context, clusterInfo *ClusterInfo, name string, force bool, action string) error {
 	args := []string{"mgr", "module", action, name}
 	if force {
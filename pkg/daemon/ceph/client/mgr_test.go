@@ -143,3 +143,39 @@ func TestSetBalancerMode(t *testing.T) {
 	err := setBalancerMode(&clusterd.Context{Executor: executor}, AdminClusterInfo("mycluster"), "upmap")
 	assert.NoError(t, err)
 }
+
+func TestMgrListModules(t *testing.T) {
+	executor := &exectest.MockExecutor{}
+	executor.MockExecuteCommandWithOutput = func(command string, args ...string) (string, error) {
+		logger.Infof("Command: %s %v", command, args)
+		if args[0] == "mgr" && args[1] == "module" && args[2] == "ls" {
+			return `{"always_on_modules":["balancer","crash"],"enabled_modules":["pg_autoscaler","prometheus"],"disabled_modules":[{"name":"telemetry"}]}`, nil
+		}
+
+		return "", errors.Errorf("unexpected ceph command %q", args)
+	}
+
+	clusterInfo := AdminClusterInfo("mycluster")
+	modules, err := MgrListModules(&clusterd.Context{Executor: executor}, clusterInfo)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"balancer", "crash"}, modules.AlwaysOnModules)
+	assert.Equal(t, []string{"pg_autoscaler", "prometheus"}, modules.EnabledModules)
+}
+
+func TestMgrFail(t *testing.T) {
+	failed := ""
+	executor := &exectest.MockExecutor{}
+	executor.MockExecuteCommandWithOutput = func(command string, args ...string) (string, error) {
+		logger.Infof("Command: %s %v", command, args)
+		if args[0] == "mgr" && args[1] == "fail" {
+			failed = args[2]
+			return "", nil
+		}
+		return "", errors.Errorf("unexpected ceph command %q", args)
+	}
+
+	clusterInfo := AdminClusterInfo("mycluster")
+	err := MgrFail(&clusterd.Context{Executor: executor}, clusterInfo, "a")
+	assert.NoError(t, err)
+	assert.Equal(t, "a", failed)
+}
@@ -384,3 +384,183 @@ func CreateRBDMirrorBootstrapPeerWithoutPool(context *clusterd.Context, clusterI
 	// Return the base64 encoded token
 	return []byte(base64.StdEncoding.EncodeToString(decodedTokenBackToJSON)), nil
 }
+
+// ImageMirroringInfo is the mirroring section of the rbd image info
+type ImageMirroringInfo struct {
+	Mode     string `json:"mode"`
+	State    string `json:"state"`
+	GlobalID string `json:"global_id"`
+	Primary  bool   `json:"primary"`
+}
+
+// ImageMirroringStatus is the mirroring status of an rbd image
+type ImageMirroringStatus struct {
+	Name        string `json:"name"`
+	GlobalID    string `json:"global_id"`
+	State       string `json:"state"`
+	Description string `json:"description"`
+	LastUpdate  string `json:"last_update"`
+}
+
+type poolMirroringVerboseStatus struct {
+	Images []ImageMirroringStatus `json:"images"`
+}
+
+type mirrorSnapshot struct {
+	Name      string `json:"name"`
+	Timestamp string `json:"timestamp"`
+	Namespace struct {
+		Type string `json:"type"`
+	} `json:"namespace"`
+}
+
+// GetImageSpec returns the spec of an image in a pool and an optional rados namespace
+func GetImageSpec(poolName, radosNamespace, imageName string) string {
+	if radosNamespace == "" {
+		return getImageSpec(imageName, poolName)
+	}
+	return fmt.Sprintf("%s/%s/%s", poolName, radosNamespace, imageName)
+}
+
+// EnableImageMirroring enables mirroring of an image with the given mode, either snapshot or journal
+func EnableImageMirroring(context *clusterd.Context, clusterInfo *ClusterInfo, imageSpec, mode string) error {
+	logger.Infof("enabling mirroring type %q for image %q", mode, imageSpec)
+	args := []string{"mirror", "image", "enable", imageSpec, mode}
+	output, err := NewRBDCommand(context, clusterInfo, args).Run()
+	if err != nil {
+		return errors.Wrapf(err, "failed to enable mirroring type %q for image %q. %s", mode, imageSpec, output)
+	}
+	return nil
+}
+
+// DisableImageMirroring disables mirroring of an image
+func DisableImageMirroring(context *clusterd.Context, clusterInfo *ClusterInfo, imageSpec string) error {
+	logger.Infof("disabling mirroring for image %q", imageSpec)
+	args := []string{"mirror", "image", "disable", imageSpec}
+	output, err := NewRBDCommand(context, clusterInfo, args).Run()
+	if err != nil {
+		return errors.Wrapf(err, "failed to disable mirroring for image %q. %s", imageSpec, output)
+	}
+	return nil
+}
+
+// PromoteImage promotes a mirrored image to primary. Force promotes the image even if the peer
+// cluster is unreachable, which may cause a split-brain that must be resolved with a resync.
+func PromoteImage(context *clusterd.Context, clusterInfo *ClusterInfo, imageSpec string, force bool) error {
+	logger.Infof("promoting image %q to primary", imageSpec)
+	args := []string{"mirror", "image", "promote", imageSpec}
+	if force {
+		args = append(args, "--force")
+	}
+	output, err := NewRBDCommand(context, clusterInfo, args).Run()
+	if err != nil {
+		return errors.Wrapf(err, "failed to promote image %q. %s", imageSpec, output)
+	}
+	return nil
+}
+
+// DemoteImage demotes a mirrored image to non-primary
+func DemoteImage(context *clusterd.Context, clusterInfo *ClusterInfo, imageSpec string) error {
+	logger.Infof("demoting image %q to non-primary", imageSpec)
+	args := []string{"mirror", "image", "demote", imageSpec}
+	output, err := NewRBDCommand(context, clusterInfo, args).Run()
+	if err != nil {
+		return errors.Wrapf(err, "failed to demote image %q. %s", imageSpec, output)
+	}
+	return nil
+}
+
+// ResyncImage flags a non-primary image to be resynchronized from the primary image
+func ResyncImage(context *clusterd.Context, clusterInfo *ClusterInfo, imageSpec string) error {
+	logger.Infof("flagging image %q for resync", imageSpec)
+	args := []string{"mirror", "image", "resync", imageSpec}
+	output, err := NewRBDCommand(context, clusterInfo, args).Run()
+	if err != nil {
+		return errors.Wrapf(err, "failed to resync image %q. %s", imageSpec, output)
+	}
+	return nil
+}
+
+// GetImageMirroringInfo returns the mirroring info of an image, the mirroring state is "disabled" if
+// the mirroring of the image is not enabled
+func GetImageMirroringInfo(context *clusterd.Context, clusterInfo *ClusterInfo, imageSpec string) (*ImageMirroringInfo, error) {
+	args := []string{"info", imageSpec}
+	cmd := NewRBDCommand(context, clusterInfo, args)
+	cmd.JsonOutput = true
+	buf, err := cmd.Run()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get info of image %q. %s", imageSpec, string(buf))
+	}
+
+	var info struct {
+		Mirroring *ImageMirroringInfo `json:"mirroring"`
+	}
+	if err := json.Unmarshal(buf, &info); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal info of image %q", imageSpec)
+	}
+	if info.Mirroring == nil {
+		return &ImageMirroringInfo{State: "disabled"}, nil
+	}
+	return info.Mirroring, nil
+}
+
+// GetImageMirroringStatus returns the mirroring status of an image
+func GetImageMirroringStatus(context *clusterd.Context, clusterInfo *ClusterInfo, imageSpec string) (*ImageMirroringStatus, error) {
+	args := []string{"mirror", "image", "status", imageSpec}
+	cmd := NewRBDCommand(context, clusterInfo, args)
+	cmd.JsonOutput = true
+	buf, err := cmd.Run()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to retrieve mirroring status of image %q. %s", imageSpec, string(buf))
+	}
+
+	var status ImageMirroringStatus
+	if err := json.Unmarshal(buf, &status); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal mirroring status of image %q", imageSpec)
+	}
+	return &status, nil
+}
+
+// ListMirroredImages returns the mirroring status of all the mirrored images of a pool
+func ListMirroredImages(context *clusterd.Context, clusterInfo *ClusterInfo, poolName, radosNamespace string) ([]ImageMirroringStatus, error) {
+	args := []string{"mirror", "pool", "status", poolName, "--verbose"}
+	if radosNamespace != "" {
+		args = append(args, "--namespace", radosNamespace)
+	}
+	cmd := NewRBDCommand(context, clusterInfo, args)
+	cmd.JsonOutput = true
+	buf, err := cmd.Run()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list mirrored images of pool %q. %s", poolName, string(buf))
+	}
+
+	var status poolMirroringVerboseStatus
+	if err := json.Unmarshal(buf, &status); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal mirroring status of pool %q", poolName)
+	}
+	return status.Images, nil
+}
+
+// GetLastMirrorSnapshotTime returns the time of the most recent mirror snapshot of an image, or an
+// empty string if the image has no mirror snapshot, e.g. when it is mirrored with journaling
+func GetLastMirrorSnapshotTime(context *clusterd.Context, clusterInfo *ClusterInfo, imageSpec string) (string, error) {
+	args := []string{"snap", "ls", "--all", imageSpec}
+	cmd := NewRBDCommand(context, clusterInfo, args)
+	cmd.JsonOutput = true
+	buf, err := cmd.Run()
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to list snapshots of image %q. %s", imageSpec, string(buf))
+	}
+
+	var snapshots []mirrorSnapshot
+	if err := json.Unmarshal(buf, &snapshots); err != nil {
+		return "", errors.Wrapf(err, "failed to unmarshal snapshots of image %q", imageSpec)
+	}
+	// snapshots are listed from the oldest to the newest
+	for i := len(snapshots) - 1; i >= 0; i-- {
+		if snapshots[i].Namespace.Type == "mirror" {
+			return snapshots[i].Timestamp, nil
+		}
+	}
+	return "", nil
+}
@@ -340,3 +340,70 @@ func TestRemoveClusterPeer(t *testing.T) {
 	err := removeClusterPeer(context, AdminClusterInfo("mycluster"), pool, peerUUID)
 	assert.NoError(t, err)
 }
+
+func TestImageMirroring(t *testing.T) {
+	executor := &exectest.MockExecutor{}
+	executed := [][]string{}
+	executor.MockExecuteCommandWithOutput = func(command string, args ...string) (string, error) {
+		executed = append(executed, args)
+		if args[0] == "info" {
+			assert.Equal(t, "replicapool/ns/image1", args[1])
+			return `{"name":"image1","mirroring":{"mode":"snapshot","state":"enabled","global_id":"c1a3f2d8","primary":true}}`, nil
+		}
+		if args[0] == "snap" {
+			return `[{"id":4,"name":"snap1","timestamp":"Thu Jun  3 09:00:00 2021","namespace":{"type":"user"}},{"id":5,"name":".mirror.primary.c1a3f2d8.1","timestamp":"Thu Jun  3 09:15:10 2021","namespace":{"type":"mirror"}},{"id":6,"name":"snap2","timestamp":"Thu Jun  3 09:20:00 2021","namespace":{"type":"user"}}]`, nil
+		}
+		if args[0] == "mirror" && args[1] == "image" && args[2] == "status" {
+			return `{"name":"image1","global_id":"c1a3f2d8","state":"up+stopped","description":"local image is primary","last_update":"2021-06-03 09:15:30"}`, nil
+		}
+		if args[0] == "mirror" && args[1] == "pool" && args[2] == "status" {
+			assert.Equal(t, "--verbose", args[4])
+			return `{"summary":{"health":"OK"},"images":[{"name":"image1","global_id":"c1a3f2d8","state":"up+stopped","description":"local image is primary","last_update":"2021-06-03 09:15:30"}]}`, nil
+		}
+		return "", nil
+	}
+	context := &clusterd.Context{Executor: executor}
+	c := AdminClusterInfo("mycluster")
+
+	assert.Equal(t, "replicapool/image1", GetImageSpec("replicapool", "", "image1"))
+	imageSpec := GetImageSpec("replicapool", "ns", "image1")
+	assert.Equal(t, "replicapool/ns/image1", imageSpec)
+
+	info, err := GetImageMirroringInfo(context, c, imageSpec)
+	assert.NoError(t, err)
+	assert.Equal(t, "enabled", info.State)
+	assert.True(t, info.Primary)
+
+	status, err := GetImageMirroringStatus(context, c, imageSpec)
+	assert.NoError(t, err)
+	assert.Equal(t, "up+stopped", status.State)
+
+	images, err := ListMirroredImages(context, c, "replicapool", "")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(images))
+	assert.Equal(t, "image1", images[0].Name)
+
+	lastSnapshot, err := GetLastMirrorSnapshotTime(context, c, imageSpec)
+	assert.NoError(t, err)
+	assert.Equal(t, "Thu Jun  3 09:15:10 2021", lastSnapshot)
+
+	executed = [][]string{}
+	assert.NoError(t, EnableImageMirroring(context, c, imageSpec, "snapshot"))
+	assert.NoError(t, PromoteImage(context, c, imageSpec, true))
+	assert.NoError(t, DemoteImage(context, c, imageSpec))
+	assert.NoError(t, ResyncImage(context, c, imageSpec))
+	assert.NoError(t, DisableImageMirroring(context, c, imageSpec))
+	assert.Equal(t, []string{"mirror", "image", "enable", imageSpec, "snapshot"}, executed[0][:5])
+	assert.Equal(t, []string{"mirror", "image", "promote", imageSpec, "--force"}, executed[1][:5])
+	assert.Equal(t, []string{"mirror", "image", "demote", imageSpec}, executed[2][:4])
+	assert.Equal(t, []string{"mirror", "image", "resync", imageSpec}, executed[3][:4])
+	assert.Equal(t, []string{"mirror", "image", "disable", imageSpec}, executed[4][:4])
+
+	// an image without mirroring
+	executor.MockExecuteCommandWithOutput = func(command string, args ...string) (string, error) {
+		return `{"name":"image1"}`, nil
+	}
+	info, err = GetImageMirroringInfo(context, c, imageSpec)
+	assert.NoError(t, err)
+	assert.Equal(t, "disabled", info.State)
+}
@@ -19,6 +19,7 @@ package client
 import (
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -31,12 +32,19 @@ import (
 )
 
 const (
-	confirmFlag             = "--yes-i-really-mean-it"
-	reallyConfirmFlag       = "--yes-i-really-really-mean-it"
-	targetSizeRatioProperty = "target_size_ratio"
-	compressionModeProperty = "compression_mode"
-	PgAutoscaleModeProperty = "pg_autoscale_mode"
-	PgAutoscaleModeOn       = "on"
+	confirmFlag                      = "--yes-i-really-mean-it"
+	reallyConfirmFlag                = "--yes-i-really-really-mean-it"
+	targetSizeRatioProperty          = "target_size_ratio"
+	compressionModeProperty          = "compression_mode"
+	crushRuleProperty                = "crush_rule"
+	compressionAlgorithmProperty     = "compression_algorithm"
+	compressionRequiredRatioProperty = "compression_required_ratio"
+	scrubMinIntervalProperty         = "scrub_min_interval"
+	scrubMaxIntervalProperty         = "scrub_max_interval"
+	deepScrubIntervalProperty        = "deep_scrub_interval"
+	recoveryPriorityProperty         = "recovery_priority"
+	PgAutoscaleModeProperty          = "pg_autoscale_mode"
+	PgAutoscaleModeOn                = "on"
 )
 
 type CephStoragePoolSummary struct {
@@ -58,10 +66,13 @@ type CephStoragePoolDetails struct {
 }
 
 type CephStoragePoolStats struct {
-	Pools []struct {
+	StatsByClass map[string]DeviceClassStats `json:"stats_by_class"`
+	Pools        []struct {
 		Name  string `json:"name"`
 		ID    int    `json:"id"`
 		Stats struct {
+			Stored       float64 `json:"stored"`
+			PercentUsed  float64 `json:"percent_used"`
 			BytesUsed    float64 `json:"bytes_used"`
 			RawBytesUsed float64 `json:"raw_bytes_used"`
 			MaxAvail     float64 `json:"max_avail"`
@@ -75,6 +86,13 @@ type CephStoragePoolStats struct {
 	} `json:"pools"`
 }
 
+// DeviceClassStats is the raw capacity of a device class
+type DeviceClassStats struct {
+	TotalBytes        float64 `json:"total_bytes"`
+	TotalAvailBytes   float64 `json:"total_avail_bytes"`
+	TotalUsedRawBytes float64 `json:"total_used_raw_bytes"`
+}
+
 type PoolStatistics struct {
 	Images struct {
 		Count            int `json:"count"`
@@ -160,10 +178,19 @@ func CreatePoolWithProfile(context *clusterd.Context, clusterInfo *ClusterInfo,
 		return fmt.Errorf("pool %q type is not defined as replicated or erasure coded", poolName)
 	}
 
-	// create a new erasure code profile for the new pool
-	ecProfileName := GetErasureCodeProfileForPool(poolName)
-	if err := CreateErasureCodeProfile(context, clusterInfo, ecProfileName, pool); err != nil {
-		return errors.Wrapf(err, "failed to create erasure code profile for pool %q", poolName)
+	// the profile of an existing pool cannot change, the pool must be migrated to a pool with a new profile
+	ecProfileName, profile, err := GetPoolErasureCodeProfile(context, clusterInfo, poolName)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get erasure code profile of pool %q", poolName)
+	}
+	if profile == nil {
+		// create a new erasure code profile for the new pool
+		ecProfileName = GetErasureCodeProfileForPool(poolName)
+		if err := CreateErasureCodeProfile(context, clusterInfo, ecProfileName, pool); err != nil {
+			return errors.Wrapf(err, "failed to create erasure code profile for pool %q", poolName)
+		}
+	} else if !profile.Matches(pool) {
+		return errors.Errorf("erasure code profile %q of pool %q is %s and cannot be changed to %s without migrating the pool", ecProfileName, poolName, profile.String(), ErasureCodedSpecString(pool))
 	}
 
 	// If the pool is not a replicated pool, then the only other option is an erasure coded pool.
@@ -250,6 +277,34 @@ func setCommonPoolProperties(context *clusterd.Context, clusterInfo *ClusterInfo
 		pool.Parameters[compressionModeProperty] = pool.CompressionMode
 	}
 
+	if pool.CompressionAlgorithm != "" {
+		pool.Parameters[compressionAlgorithmProperty] = pool.CompressionAlgorithm
+	}
+
+	if pool.CompressionRequiredRatio != 0 {
+		pool.Parameters[compressionRequiredRatioProperty] = strconv.FormatFloat(pool.CompressionRequiredRatio, 'f', -1, 64)
+	}
+
+	// the scrub intervals are set in seconds
+	if pool.Scrub.MinInterval != nil {
+		pool.Parameters[scrubMinIntervalProperty] = strconv.FormatFloat(pool.Scrub.MinInterval.Seconds(), 'f', -1, 64)
+	}
+	if pool.Scrub.MaxInterval != nil {
+		pool.Parameters[scrubMaxIntervalProperty] = strconv.FormatFloat(pool.Scrub.MaxInterval.Seconds(), 'f', -1, 64)
+	}
+	if pool.Scrub.DeepInterval != nil {
+		pool.Parameters[deepScrubIntervalProperty] = strconv.FormatFloat(pool.Scrub.DeepInterval.Seconds(), 'f', -1, 64)
+	}
+
+	if pool.RecoveryPriority != nil {
+		pool.Parameters[recoveryPriorityProperty] = strconv.Itoa(*pool.RecoveryPriority)
+	}
+
+	// the rule of an existing pool is changed with the property, the rule is passed at creation otherwise
+	if pool.CrushRule != "" {
+		pool.Parameters[crushRuleProperty] = pool.CrushRule
+	}
+
 	// Apply properties
 	for propName, propValue := range pool.Parameters {
 		err := SetPoolProperty(context, clusterInfo, poolName, propName, propValue)
@@ -347,6 +402,9 @@ func GetErasureCodeProfileForPool(baseName string) string {
 
 func CreateECPoolForApp(context *clusterd.Context, clusterInfo *ClusterInfo, poolName, ecProfileName string, pool cephv1.PoolSpec, pgCount, appName string, enableECOverwrite bool) error {
 	args := []string{"osd", "pool", "create", poolName, pgCount, "erasure", ecProfileName}
+	if pool.CrushRule != "" {
+		args = append(args, pool.CrushRule)
+	}
 	output, err := NewCephCommand(context, clusterInfo, args).Run()
 	if err != nil {
 		return errors.Wrapf(err, "failed to create EC pool %s. %s", poolName, string(output))
@@ -374,6 +432,9 @@ func CreateReplicatedPoolForApp(context *clusterd.Context, clusterInfo *ClusterI
 		// The stretch cluster rule is created initially by the operator when the stretch cluster is configured
 		// so there is no need to create a new crush rule for the pools here.
 		crushRuleName = defaultStretchCrushRuleName
+	} else if pool.CrushRule != "" {
+		// the rule is created by its CephCrushRule
+		crushRuleName = pool.CrushRule
 	} else if pool.IsHybridStoragePool() {
 		// Create hybrid crush rule
 		err := createHybridCrushRule(context, clusterInfo, clusterSpec, crushRuleName, pool)
@@ -479,6 +540,14 @@ func createHybridCrushRule(context *clusterd.Context, clusterInfo *ClusterInfo,
 }
 
 func updateCrushMap(context *clusterd.Context, clusterInfo *ClusterInfo, ruleset string) error {
+	// Append the new crush rule into the crush map
+	return editCrushMap(context, clusterInfo, func(crushMap string) (string, error) {
+		return crushMap + ruleset, nil
+	})
+}
+
+// editCrushMap edits the plain text of the crush map and injects the edited crush map
+func editCrushMap(context *clusterd.Context, clusterInfo *ClusterInfo, edit func(crushMap string) (string, error)) error {
 
 	// Fetch the compiled crush map
 	compiledCRUSHMapFilePath, err := GetCompiledCrushMap(context, clusterInfo)
@@ -505,21 +574,17 @@ func updateCrushMap(context *clusterd.Context, clusterInfo *ClusterInfo, ruleset
 		}
 	}()
 
-	// Append plain rule to the decompiled crush map
-	f, err := os.OpenFile(filepath.Clean(decompiledCRUSHMapFilePath), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0400)
+	// the decompiled crush map is created if it is missing like when appending to it
+	decompiledCRUSHMap, err := ioutil.ReadFile(filepath.Clean(decompiledCRUSHMapFilePath))
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrapf(err, "failed to read decompiled crush map %q", decompiledCRUSHMapFilePath)
+	}
+	editedCRUSHMap, err := edit(string(decompiledCRUSHMap))
 	if err != nil {
-		return errors.Wrapf(err, "failed to open decompiled crush map %q", decompiledCRUSHMapFilePath)
+		return err
 	}
-	defer func() {
-		err := f.Close()
-		if err != nil {
-			logger.Errorf("failed to close file %q. %v", f.Name(), err)
-		}
-	}()
-
-	// Append the new crush rule into the crush map
-	if _, err := f.WriteString(ruleset); err != nil {
-		return errors.Wrapf(err, "failed to append replicated plain crush rule to decompiled crush map %q", decompiledCRUSHMapFilePath)
+	if err := ioutil.WriteFile(decompiledCRUSHMapFilePath, []byte(editedCRUSHMap), 0400); err != nil {
+		return errors.Wrapf(err, "failed to write decompiled crush map %q", decompiledCRUSHMapFilePath)
 	}
 
 	// Compile the plain text to CRUSH binary format
@@ -570,6 +635,29 @@ func createReplicationCrushRule(context *clusterd.Context, clusterInfo *ClusterI
 	return nil
 }
 
+// RenamePool renames a pool. The clients refer to the pool by its ID so they are not affected.
+func RenamePool(context *clusterd.Context, clusterInfo *ClusterInfo, name, newName string) error {
+	args := []string{"osd", "pool", "rename", name, newName}
+	output, err := NewCephCommand(context, clusterInfo, args).Run()
+	if err != nil {
+		return errors.Wrapf(err, "failed to rename pool %q to %q. %s", name, newName, string(output))
+	}
+
+	logger.Infof("renamed pool %q to %q", name, newName)
+	return nil
+}
+
+// RenameCrushRule renames a crush rule, the pools using the rule are not affected
+func RenameCrushRule(context *clusterd.Context, clusterInfo *ClusterInfo, name, newName string) error {
+	args := []string{"osd", "crush", "rule", "rename", name, newName}
+	output, err := NewCephCommand(context, clusterInfo, args).Run()
+	if err != nil {
+		return errors.Wrapf(err, "failed to rename crush rule %q to %q. %s", name, newName, string(output))
+	}
+
+	return nil
+}
+
 // SetPoolProperty sets a property to a given pool
 func SetPoolProperty(context *clusterd.Context, clusterInfo *ClusterInfo, name, propName, propVal string) error {
 	args := []string{"osd", "pool", "set", name, propName, propVal}
@@ -640,6 +728,15 @@ func GetPoolStatistics(context *clusterd.Context, clusterInfo *ClusterInfo, name
 	return &poolStats, nil
 }
 
+// CrushRuleExists returns whether a crush rule exists in the crush map
+func CrushRuleExists(context *clusterd.Context, clusterInfo *ClusterInfo, ruleName string) (bool, error) {
+	crushMap, err := getCurrentCrushMap(context, clusterInfo)
+	if err != nil {
+		return false, err
+	}
+	return crushRuleExists(crushMap, ruleName), nil
+}
+
 func crushRuleExists(crushMap CrushMap, ruleName string) bool {
 	// Check if the crush rule already exists
 	for _, rule := range crushMap.Rules {
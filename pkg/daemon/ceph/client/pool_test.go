@@ -20,12 +20,14 @@ import (
 	"reflect"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/pkg/errors"
 	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
 	"github.com/rook/rook/pkg/clusterd"
 	exectest "github.com/rook/rook/pkg/util/exec/test"
 	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestCreateECPoolWithOverwrites(t *testing.T) {
@@ -94,6 +96,41 @@ func testCreateECPool(t *testing.T, overwrite bool, compressionMode string) {
 	}
 }
 
+func TestCreatePoolWithProfileExistingProfile(t *testing.T) {
+	profileSet := false
+	executor := &exectest.MockExecutor{}
+	context := &clusterd.Context{Executor: executor}
+	executor.MockExecuteCommandWithOutput = func(command string, args ...string) (string, error) {
+		logger.Infof("Command: %s %v", command, args)
+		if args[1] == "pool" && args[2] == "get" {
+			return `{"pool":"mypool","erasure_code_profile":"mypool_ecprofile"}`, nil
+		}
+		if args[1] == "erasure-code-profile" {
+			if args[2] == "get" {
+				assert.Equal(t, "mypool_ecprofile", args[3])
+				return `{"k":"2","m":"1","plugin":"jerasure"}`, nil
+			}
+			if args[2] == "set" {
+				profileSet = true
+			}
+		}
+		return "", nil
+	}
+	spec := cephv1.PoolSpec{ErasureCoded: cephv1.ErasureCodedSpec{DataChunks: 2, CodingChunks: 1}}
+
+	// the profile of the pool is kept
+	err := CreatePoolWithProfile(context, AdminClusterInfo("mycluster"), &cephv1.ClusterSpec{}, "mypool", spec, "myapp")
+	assert.NoError(t, err)
+	assert.False(t, profileSet)
+
+	// the chunks of the pool cannot change
+	spec.ErasureCoded.DataChunks = 4
+	err = CreatePoolWithProfile(context, AdminClusterInfo("mycluster"), &cephv1.ClusterSpec{}, "mypool", spec, "myapp")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot be changed to k=4,m=1 without migrating the pool")
+	assert.False(t, profileSet)
+}
+
 func TestCreateReplicaPoolWithFailureDomain(t *testing.T) {
 	testCreateReplicaPool(t, "osd", "mycrushroot", "", "")
 }
@@ -192,6 +229,50 @@ func testIsStringInSlice(a string, list []string) bool {
 	return false
 }
 
+func TestSetCommonPoolProperties(t *testing.T) {
+	properties := map[string]string{}
+	executor := &exectest.MockExecutor{}
+	context := &clusterd.Context{Executor: executor}
+	executor.MockExecuteCommandWithOutput = func(command string, args ...string) (string, error) {
+		logger.Infof("Command: %s %v", command, args)
+		if args[0] == "osd" && args[1] == "pool" && args[2] == "set" {
+			assert.Equal(t, "mypool", args[3])
+			properties[args[4]] = args[5]
+			return "", nil
+		}
+		return "", errors.Errorf("unexpected ceph command %q", args)
+	}
+
+	// no property is set by default
+	err := setCommonPoolProperties(context, AdminClusterInfo("mycluster"), cephv1.PoolSpec{}, "mypool", "")
+	assert.NoError(t, err)
+	assert.Empty(t, properties)
+
+	recoveryPriority := 0
+	p := cephv1.PoolSpec{
+		CompressionMode:          "aggressive",
+		CompressionAlgorithm:     "zstd",
+		CompressionRequiredRatio: 0.7,
+		Scrub: cephv1.PoolScrubSpec{
+			MinInterval:  &metav1.Duration{Duration: 24 * time.Hour},
+			MaxInterval:  &metav1.Duration{Duration: 7 * 24 * time.Hour},
+			DeepInterval: &metav1.Duration{Duration: 90 * time.Minute},
+		},
+		RecoveryPriority: &recoveryPriority,
+	}
+	err = setCommonPoolProperties(context, AdminClusterInfo("mycluster"), p, "mypool", "")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"compression_mode":           "aggressive",
+		"compression_algorithm":      "zstd",
+		"compression_required_ratio": "0.7",
+		"scrub_min_interval":         "86400",
+		"scrub_max_interval":         "604800",
+		"deep_scrub_interval":        "5400",
+		"recovery_priority":          "0",
+	}, properties)
+}
+
 func TestGetPoolStatistics(t *testing.T) {
 	p := PoolStatistics{}
 	p.Images.Count = 1
@@ -0,0 +1,162 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"encoding/json"
+	"strconv"
+	"syscall"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	"github.com/rook/rook/pkg/util/exec"
+)
+
+const (
+	qosIOPSLimitOption      = "rbd_qos_iops_limit"
+	qosReadIOPSLimitOption  = "rbd_qos_read_iops_limit"
+	qosWriteIOPSLimitOption = "rbd_qos_write_iops_limit"
+	qosBPSLimitOption       = "rbd_qos_bps_limit"
+	qosReadBPSLimitOption   = "rbd_qos_read_bps_limit"
+	qosWriteBPSLimitOption  = "rbd_qos_write_bps_limit"
+
+	poolConfigLevel  = "pool"
+	imageConfigLevel = "image"
+)
+
+// qosOptionNames are the rbd options of the QoS limits, in a stable order
+var qosOptionNames = []string{
+	qosIOPSLimitOption,
+	qosReadIOPSLimitOption,
+	qosWriteIOPSLimitOption,
+	qosBPSLimitOption,
+	qosReadBPSLimitOption,
+	qosWriteBPSLimitOption,
+}
+
+// rbdConfigOption is an rbd option as listed by "rbd config pool|image list"
+type rbdConfigOption struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Source string `json:"source"`
+}
+
+// qosOptions returns the rbd options of the QoS limits that are set
+func qosOptions(qos cephv1.QoSSpec) map[string]string {
+	options := map[string]string{}
+	limits := map[string]*int64{
+		qosIOPSLimitOption:      qos.IOPSLimit,
+		qosReadIOPSLimitOption:  qos.ReadIOPSLimit,
+		qosWriteIOPSLimitOption: qos.WriteIOPSLimit,
+		qosBPSLimitOption:       qos.BPSLimit,
+		qosReadBPSLimitOption:   qos.ReadBPSLimit,
+		qosWriteBPSLimitOption:  qos.WriteBPSLimit,
+	}
+	for name, limit := range limits {
+		if limit != nil {
+			options[name] = strconv.FormatInt(*limit, 10)
+		}
+	}
+	return options
+}
+
+// SetPoolQoS sets the QoS limits of the images of a pool. The applied limits that are not set anymore are removed
+// from the pool so the images inherit the limits of the cluster, the limits set by others are kept.
+func SetPoolQoS(context *clusterd.Context, clusterInfo *ClusterInfo, poolName string, qos, applied cephv1.QoSSpec) error {
+	if err := applyQoS(context, clusterInfo, poolConfigLevel, poolName, qos, applied); err != nil {
+		return errors.Wrapf(err, "failed to set the qos limits of pool %q", poolName)
+	}
+	return nil
+}
+
+// SetImageQoS sets the QoS limits of an image. The applied limits that are not set anymore are removed from the
+// image so it inherits the limits of its pool, the limits set by others are kept.
+func SetImageQoS(context *clusterd.Context, clusterInfo *ClusterInfo, imageSpec string, qos, applied cephv1.QoSSpec) error {
+	if err := applyQoS(context, clusterInfo, imageConfigLevel, imageSpec, qos, applied); err != nil {
+		return errors.Wrapf(err, "failed to set the qos limits of image %q", imageSpec)
+	}
+	return nil
+}
+
+// ListImagesInRadosNamespace lists the names of the images of a rados namespace, or of the default
+// namespace of the pool if the namespace is empty
+func ListImagesInRadosNamespace(context *clusterd.Context, clusterInfo *ClusterInfo, poolName, namespace string) ([]string, error) {
+	return listImageNames(context, clusterInfo, poolName, namespace)
+}
+
+// applyQoS sets and removes the QoS options of a pool or an image so they match the limits. Only the options
+// that differ from the options set at the same level are changed. An option that is not in the limits is only
+// removed if it still has the value of the applied limits.
+func applyQoS(context *clusterd.Context, clusterInfo *ClusterInfo, level, target string, qos, applied cephv1.QoSSpec) error {
+	current, err := listConfigOptions(context, clusterInfo, level, target)
+	if err != nil {
+		return err
+	}
+
+	desired := qosOptions(qos)
+	previous := qosOptions(applied)
+	for _, name := range qosOptionNames {
+		value, ok := desired[name]
+		currentValue, isSet := current[name]
+		appliedValue, wasApplied := previous[name]
+		switch {
+		case ok && (!isSet || currentValue != value):
+			logger.Infof("setting %s %q option %q to %q", level, target, name, value)
+			args := []string{"config", level, "set", target, name, value}
+			if output, err := NewRBDCommand(context, clusterInfo, args).Run(); err != nil {
+				return errors.Wrapf(err, "failed to set option %q to %q. %s", name, value, string(output))
+			}
+		case !ok && isSet && wasApplied && appliedValue == currentValue:
+			logger.Infof("removing %s %q option %q", level, target, name)
+			args := []string{"config", level, "remove", target, name}
+			if output, err := NewRBDCommand(context, clusterInfo, args).Run(); err != nil {
+				if code, ok := exec.ExitStatus(errors.Cause(err)); ok && code == int(syscall.ENOENT) {
+					continue
+				}
+				return errors.Wrapf(err, "failed to remove option %q. %s", name, string(output))
+			}
+		}
+	}
+
+	return nil
+}
+
+// listConfigOptions returns the rbd options set at the level of the pool or the image, not the options
+// they inherit
+func listConfigOptions(context *clusterd.Context, clusterInfo *ClusterInfo, level, target string) (map[string]string, error) {
+	args := []string{"config", level, "list", target}
+	cmd := NewRBDCommand(context, clusterInfo, args)
+	cmd.JsonOutput = true
+	buf, err := cmd.Run()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list the options of %s %q. %s", level, target, string(buf))
+	}
+
+	var options []rbdConfigOption
+	if err := json.Unmarshal(buf, &options); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal the options of %s %q. %s", level, target, string(buf))
+	}
+
+	result := map[string]string{}
+	for _, option := range options {
+		if option.Source == level {
+			result[option.Name] = option.Value
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetPoolQoS(t *testing.T) {
+	changes := []string{}
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutput: func(command string, args ...string) (string, error) {
+			logger.Infof("Command: %s %v", command, args)
+			assert.Equal(t, "rbd", command)
+			assert.Equal(t, "pool", args[1])
+			switch args[2] {
+			case "list":
+				return `[{"name":"rbd_qos_bps_limit","value":"0","source":"config"},
+					{"name":"rbd_qos_iops_limit","value":"100","source":"pool"},
+					{"name":"rbd_qos_read_iops_limit","value":"50","source":"pool"},
+					{"name":"rbd_qos_write_bps_limit","value":"1024","source":"pool"}]`, nil
+			case "set":
+				changes = append(changes, "set "+args[4]+"="+args[5])
+				return "", nil
+			case "remove":
+				changes = append(changes, "remove "+args[4])
+				return "", nil
+			}
+			return "", errors.Errorf("unexpected command %s %v", command, args)
+		},
+	}
+	context := &clusterd.Context{Executor: executor}
+
+	iops := int64(100)
+	readIOPS := int64(80)
+	bps := int64(4096)
+	appliedReadIOPS := int64(50)
+	writeBPS := int64(1024)
+	qos := cephv1.QoSSpec{IOPSLimit: &iops, ReadIOPSLimit: &readIOPS, BPSLimit: &bps}
+	applied := cephv1.QoSSpec{IOPSLimit: &iops, ReadIOPSLimit: &appliedReadIOPS, WriteBPSLimit: &writeBPS}
+	err := SetPoolQoS(context, AdminClusterInfo("mycluster"), "replicapool", qos, applied)
+	assert.NoError(t, err)
+	// the unchanged iops limit is not set again and the write bps limit is not in the spec anymore
+	assert.Equal(t, []string{
+		"set rbd_qos_read_iops_limit=80",
+		"set rbd_qos_bps_limit=4096",
+		"remove rbd_qos_write_bps_limit",
+	}, changes)
+
+	// only the limits that still have the applied values are removed, the limits inherited from the
+	// cluster and the limits set by others are kept
+	changes = []string{}
+	appliedReadIOPS = int64(60)
+	applied = cephv1.QoSSpec{IOPSLimit: &iops, ReadIOPSLimit: &appliedReadIOPS}
+	err = SetPoolQoS(context, AdminClusterInfo("mycluster"), "replicapool", cephv1.QoSSpec{}, applied)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"remove rbd_qos_iops_limit"}, changes)
+
+	// nothing is removed if no limits were applied
+	changes = []string{}
+	err = SetPoolQoS(context, AdminClusterInfo("mycluster"), "replicapool", cephv1.QoSSpec{}, cephv1.QoSSpec{})
+	assert.NoError(t, err)
+	assert.Empty(t, changes)
+}
+
+func TestSetImageQoS(t *testing.T) {
+	changes := []string{}
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutput: func(command string, args ...string) (string, error) {
+			logger.Infof("Command: %s %v", command, args)
+			assert.Equal(t, "image", args[1])
+			assert.Equal(t, "replicapool/ns1/csi-vol-1", args[3])
+			switch args[2] {
+			case "list":
+				// the limit of the pool is inherited, not set on the image
+				return `[{"name":"rbd_qos_iops_limit","value":"100","source":"pool"}]`, nil
+			case "set":
+				changes = append(changes, "set "+args[4]+"="+args[5])
+				return "", nil
+			}
+			return "", errors.Errorf("unexpected command %s %v", command, args)
+		},
+	}
+	context := &clusterd.Context{Executor: executor}
+
+	iops := int64(100)
+	err := SetImageQoS(context, AdminClusterInfo("mycluster"), "replicapool/ns1/csi-vol-1", cephv1.QoSSpec{IOPSLimit: &iops}, cephv1.QoSSpec{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"set rbd_qos_iops_limit=100"}, changes)
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"encoding/json"
+	"syscall"
+
+	"github.com/pkg/errors"
+	"github.com/rook/rook/pkg/clusterd"
+	"github.com/rook/rook/pkg/util/exec"
+)
+
+// RadosNamespace is an rbd namespace in a pool
+type RadosNamespace struct {
+	Name string `json:"name"`
+}
+
+// CreateRadosNamespace creates an rbd namespace in the given pool. It succeeds if the namespace already exists.
+func CreateRadosNamespace(context *clusterd.Context, clusterInfo *ClusterInfo, poolName, namespace string) error {
+	logger.Infof("creating rados namespace %q in pool %q", namespace, poolName)
+	args := []string{"namespace", "create", "--pool", poolName, "--namespace", namespace}
+	buf, err := NewRBDCommand(context, clusterInfo, args).Run()
+	if err != nil {
+		if code, ok := exec.ExitStatus(err); ok && code == int(syscall.EEXIST) {
+			logger.Debugf("rados namespace %q already exists in pool %q", namespace, poolName)
+			return nil
+		}
+		return errors.Wrapf(err, "failed to create rados namespace %q in pool %q. %s", namespace, poolName, string(buf))
+	}
+
+	logger.Infof("successfully created rados namespace %q in pool %q", namespace, poolName)
+	return nil
+}
+
+// ListRadosNamespacesInPool lists the rbd namespaces of the given pool
+func ListRadosNamespacesInPool(context *clusterd.Context, clusterInfo *ClusterInfo, poolName string) ([]RadosNamespace, error) {
+	args := []string{"namespace", "ls", "--pool", poolName}
+	cmd := NewRBDCommand(context, clusterInfo, args)
+	cmd.JsonOutput = true
+	buf, err := cmd.Run()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list rados namespaces in pool %q. %s", poolName, string(buf))
+	}
+
+	var namespaces []RadosNamespace
+	if err := json.Unmarshal(buf, &namespaces); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal rados namespaces. %s", string(buf))
+	}
+	return namespaces, nil
+}
+
+// DeleteRadosNamespace deletes an rbd namespace from the given pool. It succeeds if the namespace does not
+// exist and fails if images still exist in the namespace.
+func DeleteRadosNamespace(context *clusterd.Context, clusterInfo *ClusterInfo, poolName, namespace string) error {
+	logger.Infof("deleting rados namespace %q from pool %q", namespace, poolName)
+	args := []string{"namespace", "remove", "--pool", poolName, "--namespace", namespace}
+	buf, err := NewRBDCommand(context, clusterInfo, args).Run()
+	if err != nil {
+		if code, ok := exec.ExitStatus(err); ok {
+			switch code {
+			case int(syscall.ENOENT):
+				logger.Debugf("rados namespace %q does not exist in pool %q", namespace, poolName)
+				return nil
+			case int(syscall.EBUSY):
+				return errors.Errorf("rados namespace %q in pool %q still contains images", namespace, poolName)
+			}
+		}
+		return errors.Wrapf(err, "failed to delete rados namespace %q from pool %q. %s", namespace, poolName, string(buf))
+	}
+
+	logger.Infof("successfully deleted rados namespace %q from pool %q", namespace, poolName)
+	return nil
+}
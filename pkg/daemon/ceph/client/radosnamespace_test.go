@@ -0,0 +1,77 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/rook/rook/pkg/clusterd"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRadosNamespace(t *testing.T) {
+	namespaces := map[string]bool{}
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutput: func(command string, args ...string) (string, error) {
+			logger.Infof("Command: %s %v", command, args)
+			assert.Equal(t, "rbd", command)
+			assert.Equal(t, "namespace", args[0])
+			assert.Equal(t, "--pool", args[2])
+			assert.Equal(t, "replicapool", args[3])
+			switch args[1] {
+			case "create":
+				assert.Equal(t, "--namespace", args[4])
+				namespaces[args[5]] = true
+				return "", nil
+			case "ls":
+				if len(namespaces) == 0 {
+					return "[]", nil
+				}
+				return `[{"name":"team-a"}]`, nil
+			case "remove":
+				delete(namespaces, args[5])
+				return "", nil
+			}
+			return "", errors.Errorf("unexpected rbd command %q", args)
+		},
+	}
+	context := &clusterd.Context{Executor: executor}
+	clusterInfo := AdminClusterInfo("mycluster")
+
+	assert.NoError(t, CreateRadosNamespace(context, clusterInfo, "replicapool", "team-a"))
+	assert.True(t, namespaces["team-a"])
+
+	list, err := ListRadosNamespacesInPool(context, clusterInfo, "replicapool")
+	assert.NoError(t, err)
+	assert.Equal(t, []RadosNamespace{{Name: "team-a"}}, list)
+
+	assert.NoError(t, DeleteRadosNamespace(context, clusterInfo, "replicapool", "team-a"))
+	assert.False(t, namespaces["team-a"])
+
+	list, err = ListRadosNamespacesInPool(context, clusterInfo, "replicapool")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(list))
+
+	// a failure that is not an exit status is returned
+	executor.MockExecuteCommandWithOutput = func(command string, args ...string) (string, error) {
+		return "", errors.New("failed")
+	}
+	assert.Error(t, CreateRadosNamespace(context, clusterInfo, "replicapool", "team-a"))
+	assert.Error(t, DeleteRadosNamespace(context, clusterInfo, "replicapool", "team-a"))
+}
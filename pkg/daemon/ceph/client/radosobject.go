@@ -0,0 +1,171 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"strings"
+	"syscall"
+
+	"github.com/pkg/errors"
+	"github.com/rook/rook/pkg/clusterd"
+	"github.com/rook/rook/pkg/util/exec"
+)
+
+// radosObjectArgs returns the args of a rados command on the objects of a pool and namespace
+func radosObjectArgs(poolName, namespace string, args ...string) []string {
+	radosArgs := []string{"--pool", poolName}
+	if namespace != "" {
+		radosArgs = append(radosArgs, "--namespace", namespace)
+	}
+	return append(radosArgs, args...)
+}
+
+// ListRadosObjects lists the names of the objects in a pool and namespace
+func ListRadosObjects(context *clusterd.Context, clusterInfo *ClusterInfo, poolName, namespace string) ([]string, error) {
+	buf, err := NewRadosCommand(context, clusterInfo, radosObjectArgs(poolName, namespace, "ls")).Run()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list the objects of pool %q namespace %q. %s", poolName, namespace, string(buf))
+	}
+
+	objects := []string{}
+	for _, line := range strings.Split(string(buf), "\n") {
+		if name := strings.TrimSpace(line); name != "" {
+			objects = append(objects, name)
+		}
+	}
+	return objects, nil
+}
+
+// RadosObject is an object of a pool in any of its namespaces
+type RadosObject struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Locator   string `json:"locator,omitempty"`
+}
+
+// ListAllRadosObjects lists the objects in all the namespaces of a pool
+func ListAllRadosObjects(context *clusterd.Context, clusterInfo *ClusterInfo, poolName string) ([]RadosObject, error) {
+	cmd := NewRadosCommand(context, clusterInfo, []string{"--pool", poolName, "ls", "--all"})
+	cmd.JsonOutput = true
+	buf, err := cmd.Run()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list the objects of pool %q. %s", poolName, string(buf))
+	}
+
+	objects := []RadosObject{}
+	if err := json.Unmarshal(buf, &objects); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal the objects of pool %q. %s", poolName, string(buf))
+	}
+	return objects, nil
+}
+
+// CopyRadosObject copies an object with its xattrs and omap to the same namespace and locator of another
+// pool. The object is copied by the OSDs without a cache tier and overwrites the object of the other pool.
+func CopyRadosObject(context *clusterd.Context, clusterInfo *ClusterInfo, poolName string, object RadosObject, targetPool string) error {
+	args := radosObjectArgs(poolName, object.Namespace, "cp", object.Name, "--target-pool", targetPool)
+	if object.Namespace != "" {
+		args = append(args, "--target-nspace", object.Namespace)
+	}
+	if object.Locator != "" {
+		args = append(args, "--object-locator", object.Locator, "--target-locator", object.Locator)
+	}
+	buf, err := NewRadosCommand(context, clusterInfo, args).Run()
+	if err != nil {
+		return errors.Wrapf(err, "failed to copy object %q of pool %q namespace %q to pool %q. %s", object.Name, poolName, object.Namespace, targetPool, string(buf))
+	}
+	return nil
+}
+
+// GetRadosObject returns the content of an object. It returns false if the object does not exist.
+func GetRadosObject(context *clusterd.Context, clusterInfo *ClusterInfo, poolName, namespace, objectName string) ([]byte, bool, error) {
+	buf, err := NewRadosCommand(context, clusterInfo, radosObjectArgs(poolName, namespace, "get", objectName, "-")).Run()
+	if err != nil {
+		if code, ok := exec.ExitStatus(err); ok && code == int(syscall.ENOENT) {
+			return nil, false, nil
+		}
+		return nil, false, errors.Wrapf(err, "failed to get object %q of pool %q namespace %q. %s", objectName, poolName, namespace, string(buf))
+	}
+	return buf, true, nil
+}
+
+// PutRadosObject writes the content of an object, the object is created if it does not exist
+func PutRadosObject(context *clusterd.Context, clusterInfo *ClusterInfo, poolName, namespace, objectName string, content []byte) error {
+	// the rados tool reads the content from a file
+	file, err := ioutil.TempFile("", objectName)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create a file for object %q", objectName)
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.Write(content); err != nil {
+		file.Close()
+		return errors.Wrapf(err, "failed to write the content of object %q", objectName)
+	}
+	if err := file.Close(); err != nil {
+		return errors.Wrapf(err, "failed to close the file of object %q", objectName)
+	}
+
+	buf, err := NewRadosCommand(context, clusterInfo, radosObjectArgs(poolName, namespace, "put", objectName, file.Name())).Run()
+	if err != nil {
+		return errors.Wrapf(err, "failed to put object %q to pool %q namespace %q. %s", objectName, poolName, namespace, string(buf))
+	}
+
+	logger.Debugf("object %q of pool %q namespace %q written", objectName, poolName, namespace)
+	return nil
+}
+
+// RemoveRadosObject removes an object. It succeeds if the object does not exist.
+func RemoveRadosObject(context *clusterd.Context, clusterInfo *ClusterInfo, poolName, namespace, objectName string) error {
+	buf, err := NewRadosCommand(context, clusterInfo, radosObjectArgs(poolName, namespace, "rm", objectName)).Run()
+	if err != nil {
+		if code, ok := exec.ExitStatus(err); ok && code == int(syscall.ENOENT) {
+			logger.Debugf("object %q of pool %q namespace %q does not exist", objectName, poolName, namespace)
+			return nil
+		}
+		return errors.Wrapf(err, "failed to remove object %q of pool %q namespace %q. %s", objectName, poolName, namespace, string(buf))
+	}
+
+	logger.Debugf("object %q of pool %q namespace %q removed", objectName, poolName, namespace)
+	return nil
+}
+
+// NotifyRadosObject notifies the watchers of an object and waits for all of them to acknowledge the notification
+func NotifyRadosObject(context *clusterd.Context, clusterInfo *ClusterInfo, poolName, namespace, objectName, message string) error {
+	buf, err := NewRadosCommand(context, clusterInfo, radosObjectArgs(poolName, namespace, "notify", objectName, message)).Run()
+	if err != nil {
+		return errors.Wrapf(err, "failed to notify the watchers of object %q of pool %q namespace %q. %s", objectName, poolName, namespace, string(buf))
+	}
+	return nil
+}
+
+// CountRadosObjectWatchers returns the number of clients watching an object
+func CountRadosObjectWatchers(context *clusterd.Context, clusterInfo *ClusterInfo, poolName, namespace, objectName string) (int, error) {
+	buf, err := NewRadosCommand(context, clusterInfo, radosObjectArgs(poolName, namespace, "listwatchers", objectName)).Run()
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to list the watchers of object %q of pool %q namespace %q. %s", objectName, poolName, namespace, string(buf))
+	}
+
+	watchers := 0
+	for _, line := range strings.Split(string(buf), "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "watcher=") {
+			watchers++
+		}
+	}
+	return watchers, nil
+}
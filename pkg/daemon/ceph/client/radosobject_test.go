@@ -0,0 +1,138 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"io/ioutil"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/rook/rook/pkg/clusterd"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRadosObjects(t *testing.T) {
+	objects := map[string]string{}
+	notified := []string{}
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutput: func(command string, args ...string) (string, error) {
+			logger.Infof("Command: %s %v", command, args)
+			assert.Equal(t, "rados", command)
+			assert.Equal(t, []string{"--pool", "nfs-ganesha", "--namespace", "my-nfs"}, args[0:4])
+			enoent := exec.Command("sh", "-c", "exit 2").Run()
+			switch args[4] {
+			case "ls":
+				names := []string{}
+				for name := range objects {
+					names = append(names, name)
+				}
+				return strings.Join(names, "\n") + "\n", nil
+			case "get":
+				content, ok := objects[args[5]]
+				if !ok {
+					return "", enoent
+				}
+				return content, nil
+			case "put":
+				content, err := ioutil.ReadFile(args[6])
+				assert.NoError(t, err)
+				objects[args[5]] = string(content)
+				return "", nil
+			case "rm":
+				if _, ok := objects[args[5]]; !ok {
+					return "", enoent
+				}
+				delete(objects, args[5])
+				return "", nil
+			case "notify":
+				notified = append(notified, args[5])
+				return "reply client.4235 cookie 1 : 0 bytes", nil
+			case "listwatchers":
+				return "watcher=10.1.0.5:0/1207 client.4235 cookie=1\nwatcher=10.1.0.6:0/1108 client.4241 cookie=1\n", nil
+			}
+			return "", errors.Errorf("unexpected rados command %q", args)
+		},
+	}
+	context := &clusterd.Context{Executor: executor}
+	clusterInfo := AdminClusterInfo("mycluster")
+
+	_, found, err := GetRadosObject(context, clusterInfo, "nfs-ganesha", "my-nfs", "export-1")
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	assert.NoError(t, PutRadosObject(context, clusterInfo, "nfs-ganesha", "my-nfs", "export-1", []byte("EXPORT {}")))
+	content, found, err := GetRadosObject(context, clusterInfo, "nfs-ganesha", "my-nfs", "export-1")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "EXPORT {}", string(content))
+
+	list, err := ListRadosObjects(context, clusterInfo, "nfs-ganesha", "my-nfs")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"export-1"}, list)
+
+	assert.NoError(t, NotifyRadosObject(context, clusterInfo, "nfs-ganesha", "my-nfs", "conf-nfs.my-nfs", "reload"))
+	assert.Equal(t, []string{"conf-nfs.my-nfs"}, notified)
+	watchers, err := CountRadosObjectWatchers(context, clusterInfo, "nfs-ganesha", "my-nfs", "conf-nfs.my-nfs")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, watchers)
+
+	// removing an object that is already gone succeeds
+	assert.NoError(t, RemoveRadosObject(context, clusterInfo, "nfs-ganesha", "my-nfs", "export-1"))
+	assert.NoError(t, RemoveRadosObject(context, clusterInfo, "nfs-ganesha", "my-nfs", "export-1"))
+	assert.Equal(t, 0, len(objects))
+
+	// a failure that is not an exit status is returned
+	executor.MockExecuteCommandWithOutput = func(command string, args ...string) (string, error) {
+		return "", errors.New("failed")
+	}
+	_, _, err = GetRadosObject(context, clusterInfo, "nfs-ganesha", "my-nfs", "export-1")
+	assert.Error(t, err)
+	assert.Error(t, RemoveRadosObject(context, clusterInfo, "nfs-ganesha", "my-nfs", "export-1"))
+}
+
+func TestCopyRadosObjects(t *testing.T) {
+	copied := [][]string{}
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutput: func(command string, args ...string) (string, error) {
+			logger.Infof("Command: %s %v", command, args)
+			assert.Equal(t, "rados", command)
+			if args[2] == "ls" {
+				assert.Equal(t, []string{"--pool", "my-store.rgw.buckets.data", "ls", "--all"}, args[0:4])
+				return `[{"namespace":"","name":"obj1"},{"namespace":"ns","name":"obj2","locator":"key"}]`, nil
+			}
+			copied = append(copied, args)
+			return "", nil
+		},
+	}
+	context := &clusterd.Context{Executor: executor}
+	clusterInfo := AdminClusterInfo("mycluster")
+
+	objects, err := ListAllRadosObjects(context, clusterInfo, "my-store.rgw.buckets.data")
+	assert.NoError(t, err)
+	assert.Equal(t, []RadosObject{{Name: "obj1"}, {Name: "obj2", Namespace: "ns", Locator: "key"}}, objects)
+
+	for _, object := range objects {
+		assert.NoError(t, CopyRadosObject(context, clusterInfo, "my-store.rgw.buckets.data", object, "my-store.rgw.buckets.data-migration"))
+	}
+	assert.Equal(t, []string{"--pool", "my-store.rgw.buckets.data", "cp", "obj1", "--target-pool", "my-store.rgw.buckets.data-migration"}, copied[0][0:6])
+	// the object keeps its namespace and locator
+	assert.Equal(t, []string{"--pool", "my-store.rgw.buckets.data", "--namespace", "ns", "cp", "obj2", "--target-pool", "my-store.rgw.buckets.data-migration",
+		"--target-nspace", "ns", "--object-locator", "key", "--target-locator", "key"}, copied[1][0:14])
+}
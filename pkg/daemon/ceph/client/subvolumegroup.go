@@ -0,0 +1,115 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"strconv"
+	"syscall"
+
+	"github.com/pkg/errors"
+	"github.com/rook/rook/pkg/clusterd"
+	"github.com/rook/rook/pkg/util/exec"
+)
+
+// The pinning types of a subvolume group and the settings that unset them
+const (
+	SubVolumeGroupPinExport      = "export"
+	SubVolumeGroupPinDistributed = "distributed"
+	SubVolumeGroupPinRandom      = "random"
+
+	subVolumeGroupUnpinExport      = "-1"
+	subVolumeGroupUnpinDistributed = "0"
+	subVolumeGroupUnpinRandom      = "0"
+
+	// unlimitedSubVolumeGroupSize removes the quota of a subvolume group
+	unlimitedSubVolumeGroupSize = "inf"
+)
+
+// SubVolumeGroupUnpinSettings are the settings that unset each pinning type of a subvolume group
+var SubVolumeGroupUnpinSettings = map[string]string{
+	SubVolumeGroupPinExport:      subVolumeGroupUnpinExport,
+	SubVolumeGroupPinDistributed: subVolumeGroupUnpinDistributed,
+	SubVolumeGroupPinRandom:      subVolumeGroupUnpinRandom,
+}
+
+// CreateSubVolumeGroup creates a subvolume group in a filesystem. It succeeds if the group already exists. The
+// files of the group are stored in the data pool if it is not empty.
+func CreateSubVolumeGroup(context *clusterd.Context, clusterInfo *ClusterInfo, fsName, groupName, dataPoolName string) error {
+	logger.Infof("creating subvolume group %q in filesystem %q", groupName, fsName)
+	args := []string{"fs", "subvolumegroup", "create", fsName, groupName}
+	if dataPoolName != "" {
+		args = append(args, "--pool_layout", dataPoolName)
+	}
+	buf, err := NewCephCommand(context, clusterInfo, args).Run()
+	if err != nil {
+		return errors.Wrapf(err, "failed to create subvolume group %q in filesystem %q. %s", groupName, fsName, string(buf))
+	}
+
+	logger.Infof("successfully created subvolume group %q in filesystem %q", groupName, fsName)
+	return nil
+}
+
+// ResizeSubVolumeGroup sets the quota of a subvolume group, the quota is removed if the size is nil
+func ResizeSubVolumeGroup(context *clusterd.Context, clusterInfo *ClusterInfo, fsName, groupName string, size *int64) error {
+	newSize := unlimitedSubVolumeGroupSize
+	if size != nil {
+		newSize = strconv.FormatInt(*size, 10)
+	}
+	args := []string{"fs", "subvolumegroup", "resize", fsName, groupName, newSize}
+	buf, err := NewCephCommand(context, clusterInfo, args).Run()
+	if err != nil {
+		return errors.Wrapf(err, "failed to resize subvolume group %q in filesystem %q to %q. %s", groupName, fsName, newSize, string(buf))
+	}
+
+	logger.Debugf("subvolume group %q in filesystem %q resized to %q", groupName, fsName, newSize)
+	return nil
+}
+
+// PinSubVolumeGroup sets a pinning type of a subvolume group
+func PinSubVolumeGroup(context *clusterd.Context, clusterInfo *ClusterInfo, fsName, groupName, pinType, pinSetting string) error {
+	args := []string{"fs", "subvolumegroup", "pin", fsName, groupName, pinType, pinSetting}
+	buf, err := NewCephCommand(context, clusterInfo, args).Run()
+	if err != nil {
+		return errors.Wrapf(err, "failed to set %s pin of subvolume group %q in filesystem %q to %q. %s", pinType, groupName, fsName, pinSetting, string(buf))
+	}
+
+	logger.Debugf("%s pin of subvolume group %q in filesystem %q set to %q", pinType, groupName, fsName, pinSetting)
+	return nil
+}
+
+// DeleteSubVolumeGroup deletes a subvolume group from a filesystem. It succeeds if the group does not exist and
+// fails if subvolumes still exist in the group.
+func DeleteSubVolumeGroup(context *clusterd.Context, clusterInfo *ClusterInfo, fsName, groupName string) error {
+	logger.Infof("deleting subvolume group %q from filesystem %q", groupName, fsName)
+	args := []string{"fs", "subvolumegroup", "rm", fsName, groupName}
+	buf, err := NewCephCommand(context, clusterInfo, args).Run()
+	if err != nil {
+		if code, ok := exec.ExitStatus(errors.Cause(err)); ok {
+			switch code {
+			case int(syscall.ENOENT):
+				logger.Debugf("subvolume group %q does not exist in filesystem %q", groupName, fsName)
+				return nil
+			case int(syscall.ENOTEMPTY):
+				return errors.Errorf("subvolume group %q in filesystem %q still contains subvolumes", groupName, fsName)
+			}
+		}
+		return errors.Wrapf(err, "failed to delete subvolume group %q from filesystem %q. %s", groupName, fsName, string(buf))
+	}
+
+	logger.Infof("successfully deleted subvolume group %q from filesystem %q", groupName, fsName)
+	return nil
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/rook/rook/pkg/clusterd"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubVolumeGroup(t *testing.T) {
+	commands := []string{}
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutput: func(command string, args ...string) (string, error) {
+			logger.Infof("Command: %s %v", command, args)
+			assert.Equal(t, "ceph", command)
+			assert.Equal(t, []string{"fs", "subvolumegroup"}, args[0:2])
+			// the args of the subcommand, without the connection flags
+			end := 2
+			for end < len(args) && !strings.HasPrefix(args[end], "--connect-timeout") {
+				end++
+			}
+			commands = append(commands, strings.Join(args[2:end], " "))
+			return "", nil
+		},
+	}
+	context := &clusterd.Context{Executor: executor}
+	clusterInfo := AdminClusterInfo("mycluster")
+
+	assert.NoError(t, CreateSubVolumeGroup(context, clusterInfo, "myfs", "csi", ""))
+	assert.NoError(t, CreateSubVolumeGroup(context, clusterInfo, "myfs", "csi", "myfs-data1"))
+	size := int64(1073741824)
+	assert.NoError(t, ResizeSubVolumeGroup(context, clusterInfo, "myfs", "csi", &size))
+	assert.NoError(t, ResizeSubVolumeGroup(context, clusterInfo, "myfs", "csi", nil))
+	assert.NoError(t, PinSubVolumeGroup(context, clusterInfo, "myfs", "csi", SubVolumeGroupPinDistributed, "1"))
+	assert.NoError(t, DeleteSubVolumeGroup(context, clusterInfo, "myfs", "csi"))
+	assert.Equal(t, []string{
+		"create myfs csi",
+		"create myfs csi --pool_layout myfs-data1",
+		"resize myfs csi 1073741824",
+		"resize myfs csi inf",
+		"pin myfs csi distributed 1",
+		"rm myfs csi",
+	}, commands)
+
+	// a failure that is not an exit status is returned
+	executor.MockExecuteCommandWithOutput = func(command string, args ...string) (string, error) {
+		return "", errors.New("failed")
+	}
+	assert.Error(t, CreateSubVolumeGroup(context, clusterInfo, "myfs", "csi", ""))
+	assert.Error(t, DeleteSubVolumeGroup(context, clusterInfo, "myfs", "csi"))
+}
@@ -0,0 +1,294 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/ceph/config"
+	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// appliedCephConfigName is the configmap with the cephConfig options last applied by the operator
+	appliedCephConfigName = "rook-ceph-applied-config"
+	appliedCephConfigKey  = "config"
+
+	// the options of the scrub window are applied to all the osds
+	scrubWindowSection      = "osd"
+	scrubBeginHourOption    = "osd_scrub_begin_hour"
+	scrubEndHourOption      = "osd_scrub_end_hour"
+	scrubBeginWeekDayOption = "osd_scrub_begin_week_day"
+	scrubEndWeekDayOption   = "osd_scrub_end_week_day"
+)
+
+// appliedCephConfigOption is an option applied from the cephConfig with the value as Ceph stored it
+type appliedCephConfigOption struct {
+	Who    string
+	Option string
+	Value  string
+	// Stored is the value normalized by Ceph, which may differ from the value of the spec
+	Stored string `json:",omitempty"`
+}
+
+// applyCephConfig applies the cephConfig of the spec to the mon configuration database. The options
+// applied by a previous reconcile that are no longer in the spec are removed from the database.
+func applyCephConfig(clusterdContext *clusterd.Context, clusterInfo *cephclient.ClusterInfo, spec *cephv1.ClusterSpec) error {
+	applied, err := getAppliedCephConfig(clusterdContext, clusterInfo)
+	if err != nil {
+		return err
+	}
+	options := cephConfigOptions(spec)
+	if len(options) == 0 && len(applied) == 0 {
+		return nil
+	}
+
+	monStore := config.GetMonStore(clusterdContext, clusterInfo)
+	stored := storedCephConfigValues(applied, options)
+	if _, err := monStore.SetAllIfChanged(stored, options...); err != nil {
+		return errors.Wrap(err, "failed to apply the ceph config")
+	}
+
+	desired := map[string]appliedCephConfigOption{}
+	for _, option := range options {
+		desired[config.OptionKey(option)] = appliedCephConfigOption{Who: option.Who, Option: option.Option, Value: option.Value, Stored: stored[config.OptionKey(option)]}
+	}
+	for key, option := range applied {
+		if _, ok := desired[key]; ok {
+			continue
+		}
+		if err := monStore.Delete(option.Who, option.Option); err != nil {
+			return errors.Wrapf(err, "failed to remove option %q of section %q that is no longer in the ceph config", option.Option, option.Who)
+		}
+	}
+
+	return saveAppliedCephConfig(clusterdContext, clusterInfo, desired)
+}
+
+// checkCephConfigDrift restores the options last applied from the cephConfig spec that were changed out
+// of band and returns the drift detected so far. Options that changed in the spec since they were last
+// applied are left to the next reconcile and not reported as drift.
+func checkCephConfigDrift(clusterdContext *clusterd.Context, clusterInfo *cephclient.ClusterInfo, spec *cephv1.ClusterSpec, previous []cephv1.CephConfigDriftStatus) ([]cephv1.CephConfigDriftStatus, error) {
+	applied, err := getAppliedCephConfig(clusterdContext, clusterInfo)
+	if err != nil {
+		return previous, err
+	}
+
+	options := []config.Option{}
+	for _, option := range cephConfigOptions(spec) {
+		if a, ok := applied[config.OptionKey(option)]; ok && a.Value == option.Value {
+			options = append(options, option)
+		}
+	}
+
+	// keep the drift previously detected for the options that are still applied with the same value
+	driftStatus := []cephv1.CephConfigDriftStatus{}
+	for _, drift := range previous {
+		key := config.OptionKey(config.Option{Who: drift.Section, Option: drift.Option})
+		if a, ok := applied[key]; ok && a.Value == drift.Expected && containsCephConfigOption(options, key) {
+			driftStatus = append(driftStatus, drift)
+		}
+	}
+
+	stored := storedCephConfigValues(applied, options)
+	drift, err := config.GetMonStore(clusterdContext, clusterInfo).SetAllIfChanged(stored, options...)
+	for _, d := range drift {
+		logger.Warningf("ceph config option %q of section %q was changed out of band from %q to %q, restoring it", d.Option.Option, d.Who, d.Value, d.Actual)
+		status := cephv1.CephConfigDriftStatus{
+			Section:      d.Who,
+			Option:       d.Option.Option,
+			Expected:     d.Value,
+			Actual:       d.Actual,
+			LastDetected: formatTime(time.Now().UTC()),
+		}
+		replaced := false
+		for i := range driftStatus {
+			if driftStatus[i].Section == status.Section && driftStatus[i].Option == status.Option {
+				driftStatus[i] = status
+				replaced = true
+			}
+		}
+		if !replaced {
+			driftStatus = append(driftStatus, status)
+		}
+	}
+	if err != nil {
+		return driftStatus, errors.Wrap(err, "failed to restore the ceph config")
+	}
+
+	// record the values stored by Ceph that were not known yet so they are not reported as drift again
+	changed := false
+	for _, option := range options {
+		key := config.OptionKey(option)
+		if a := applied[key]; a.Stored != stored[config.OptionKey(option)] {
+			a.Stored = stored[config.OptionKey(option)]
+			applied[key] = a
+			changed = true
+		}
+	}
+	if changed {
+		if err := saveAppliedCephConfig(clusterdContext, clusterInfo, applied); err != nil {
+			return driftStatus, err
+		}
+	}
+	return driftStatus, nil
+}
+
+// storedCephConfigValues returns the values stored by Ceph for the options whose value in the spec did not
+// change since they were applied, keyed by option key
+func storedCephConfigValues(applied map[string]appliedCephConfigOption, options []config.Option) map[string]string {
+	stored := map[string]string{}
+	for _, option := range options {
+		if a, ok := applied[config.OptionKey(option)]; ok && a.Value == option.Value && a.Stored != "" {
+			stored[config.OptionKey(option)] = a.Stored
+		}
+	}
+	return stored
+}
+
+func cephConfigOptions(spec *cephv1.ClusterSpec) []config.Option {
+	sections := map[string]map[string]string{}
+	for section, options := range spec.CephConfig {
+		sections[section] = options
+	}
+
+	// the scrub window is applied to the osds with the cephConfig so it is restored if changed out of band
+	if window := scrubWindowOptions(spec.Scrub); len(window) > 0 {
+		osdOptions := map[string]string{}
+		for option, value := range sections[scrubWindowSection] {
+			osdOptions[option] = value
+		}
+		for option, value := range window {
+			osdOptions[option] = value
+		}
+		sections[scrubWindowSection] = osdOptions
+	}
+	return config.OptionsFromSections(sections)
+}
+
+// scrubWindowOptions returns the osd options of the scrub window
+func scrubWindowOptions(window cephv1.ScrubWindowSpec) map[string]string {
+	options := map[string]string{}
+	if window.BeginHour != nil && window.EndHour != nil {
+		options[scrubBeginHourOption] = strconv.Itoa(*window.BeginHour)
+		options[scrubEndHourOption] = strconv.Itoa(*window.EndHour)
+	}
+	if window.BeginWeekDay != nil && window.EndWeekDay != nil {
+		options[scrubBeginWeekDayOption] = strconv.Itoa(*window.BeginWeekDay)
+		options[scrubEndWeekDayOption] = strconv.Itoa(*window.EndWeekDay)
+	}
+	return options
+}
+
+// validateScrubWindow validates that the bounds of the scrub window are set in pairs and that the
+// cephConfig does not also set the options of the window
+func validateScrubWindow(spec *cephv1.ClusterSpec) error {
+	window := spec.Scrub
+	if (window.BeginHour == nil) != (window.EndHour == nil) {
+		return errors.New("both the begin and end hours of the scrub window must be set")
+	}
+	if (window.BeginWeekDay == nil) != (window.EndWeekDay == nil) {
+		return errors.New("both the begin and end week days of the scrub window must be set")
+	}
+	if window.BeginHour != nil && (*window.BeginHour < 0 || *window.BeginHour > 23 || *window.EndHour < 0 || *window.EndHour > 23) {
+		return errors.New("the hours of the scrub window must be between 0 and 23")
+	}
+	if window.BeginWeekDay != nil && (*window.BeginWeekDay < 0 || *window.BeginWeekDay > 6 || *window.EndWeekDay < 0 || *window.EndWeekDay > 6) {
+		return errors.New("the week days of the scrub window must be between 0 and 6")
+	}
+
+	windowOptions := scrubWindowOptions(window)
+	for section, options := range spec.CephConfig {
+		for option := range options {
+			normalized := strings.NewReplacer("-", "_", " ", "_").Replace(option)
+			if _, ok := windowOptions[normalized]; ok {
+				return errors.Errorf("option %q of section %q in the ceph config is set by the scrub window", option, section)
+			}
+		}
+	}
+	return nil
+}
+
+// containsCephConfigOption returns whether the options contain the option of the key, keyed by config.OptionKey
+func containsCephConfigOption(options []config.Option, key string) bool {
+	for _, option := range options {
+		if config.OptionKey(option) == key {
+			return true
+		}
+	}
+	return false
+}
+
+func getAppliedCephConfig(clusterdContext *clusterd.Context, clusterInfo *cephclient.ClusterInfo) (map[string]appliedCephConfigOption, error) {
+	ctx := context.TODO()
+	applied := map[string]appliedCephConfigOption{}
+	cm, err := clusterdContext.Clientset.CoreV1().ConfigMaps(clusterInfo.Namespace).Get(ctx, appliedCephConfigName, metav1.GetOptions{})
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return applied, nil
+		}
+		return nil, errors.Wrapf(err, "failed to get configmap %q", appliedCephConfigName)
+	}
+	saved := map[string]appliedCephConfigOption{}
+	if err := json.Unmarshal([]byte(cm.Data[appliedCephConfigKey]), &saved); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal configmap %q", appliedCephConfigName)
+	}
+	// key the options as ceph normalizes their names, so only changing the spelling of a name keeps the option
+	for _, option := range saved {
+		applied[config.OptionKey(config.Option{Who: option.Who, Option: option.Option})] = option
+	}
+	return applied, nil
+}
+
+func saveAppliedCephConfig(clusterdContext *clusterd.Context, clusterInfo *cephclient.ClusterInfo, applied map[string]appliedCephConfigOption) error {
+	data, err := json.Marshal(applied)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal the applied ceph config")
+	}
+	cm := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      appliedCephConfigName,
+			Namespace: clusterInfo.Namespace,
+		},
+		Data: map[string]string{appliedCephConfigKey: string(data)},
+	}
+	if err := clusterInfo.OwnerInfo.SetControllerReference(cm); err != nil {
+		return errors.Wrapf(err, "failed to set owner reference to configmap %q", cm.Name)
+	}
+
+	ctx := context.TODO()
+	configMaps := clusterdContext.Clientset.CoreV1().ConfigMaps(clusterInfo.Namespace)
+	if _, err := configMaps.Create(ctx, cm, metav1.CreateOptions{}); err != nil {
+		if !kerrors.IsAlreadyExists(err) {
+			return errors.Wrapf(err, "failed to create configmap %q", cm.Name)
+		}
+		if _, err := configMaps.Update(ctx, cm, metav1.UpdateOptions{}); err != nil {
+			return errors.Wrapf(err, "failed to update configmap %q", cm.Name)
+		}
+	}
+	return nil
+}
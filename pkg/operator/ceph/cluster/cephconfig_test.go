@@ -0,0 +1,198 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/ceph/config"
+	"github.com/rook/rook/pkg/operator/test"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestCephConfig(t *testing.T) {
+	// the mon configuration database, keyed by section and option
+	monDB := map[string]map[string]string{"global": {"mon_allow_pool_delete": "true"}}
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutput: func(command string, args ...string) (string, error) {
+			logger.Infof("Command: %s %v", command, args)
+			if args[0] != "config" {
+				return "", errors.Errorf("unexpected ceph command %q", args)
+			}
+			switch args[1] {
+			case "dump":
+				type entry struct {
+					Section string `json:"section"`
+					Name    string `json:"name"`
+					Value   string `json:"value"`
+				}
+				entries := []entry{}
+				for section, options := range monDB {
+					for name, value := range options {
+						entries = append(entries, entry{section, name, value})
+					}
+				}
+				b, err := json.Marshal(entries)
+				return string(b), err
+			case "set":
+				if monDB[args[2]] == nil {
+					monDB[args[2]] = map[string]string{}
+				}
+				// ceph normalizes the sizes
+				value := args[4]
+				if size, err := resource.ParseQuantity(strings.TrimSuffix(value, "B") + "i"); err == nil && strings.HasSuffix(value, "G") {
+					value = strconv.FormatInt(size.Value(), 10)
+				}
+				monDB[args[2]][args[3]] = value
+				return "", nil
+			case "rm":
+				delete(monDB[args[2]], args[3])
+				return "", nil
+			}
+			return "", errors.Errorf("unexpected ceph command %q", args)
+		},
+	}
+	context := &clusterd.Context{Executor: executor, Clientset: test.New(t, 1)}
+	clusterInfo := cephclient.AdminClusterInfo("rook-ceph")
+	spec := &cephv1.ClusterSpec{}
+
+	// nothing to apply
+	assert.NoError(t, applyCephConfig(context, clusterInfo, spec))
+	drift, err := checkCephConfigDrift(context, clusterInfo, spec, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(drift))
+
+	spec.CephConfig = map[string]cephv1.CephConfigOptions{
+		"global": {"osd_pool_default_size": "2"},
+		"osd.3":  {"osd_max_backfills": "4"},
+	}
+	assert.NoError(t, applyCephConfig(context, clusterInfo, spec))
+	assert.Equal(t, "2", monDB["global"]["osd_pool_default_size"])
+	assert.Equal(t, "4", monDB["osd.3"]["osd_max_backfills"])
+	// options not in the spec are left alone
+	assert.Equal(t, "true", monDB["global"]["mon_allow_pool_delete"])
+
+	// no drift
+	drift, err = checkCephConfigDrift(context, clusterInfo, spec, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(drift))
+
+	// an option is changed and another removed out of band
+	monDB["osd.3"]["osd_max_backfills"] = "10"
+	delete(monDB["global"], "osd_pool_default_size")
+	drift, err = checkCephConfigDrift(context, clusterInfo, spec, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(drift))
+	assert.Equal(t, "global", drift[0].Section)
+	assert.Equal(t, "osd_pool_default_size", drift[0].Option)
+	assert.Equal(t, "", drift[0].Actual)
+	assert.Equal(t, "osd.3", drift[1].Section)
+	assert.Equal(t, "4", drift[1].Expected)
+	assert.Equal(t, "10", drift[1].Actual)
+	assert.NotEqual(t, "", drift[1].LastDetected)
+	// the values are restored
+	assert.Equal(t, "2", monDB["global"]["osd_pool_default_size"])
+	assert.Equal(t, "4", monDB["osd.3"]["osd_max_backfills"])
+
+	// the drift stays reported after the values are restored
+	drift, err = checkCephConfigDrift(context, clusterInfo, spec, drift)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(drift))
+
+	// a spec change that is not applied yet is not drift
+	spec.CephConfig["osd.3"]["osd_max_backfills"] = "6"
+	drift, err = checkCephConfigDrift(context, clusterInfo, spec, drift)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(drift))
+	assert.Equal(t, "global", drift[0].Section)
+	assert.Equal(t, "4", monDB["osd.3"]["osd_max_backfills"])
+
+	// the options removed from the spec are removed from the database
+	delete(spec.CephConfig, "global")
+	assert.NoError(t, applyCephConfig(context, clusterInfo, spec))
+	assert.Equal(t, "6", monDB["osd.3"]["osd_max_backfills"])
+	assert.NotContains(t, monDB["global"], "osd_pool_default_size")
+	assert.Equal(t, "true", monDB["global"]["mon_allow_pool_delete"])
+	drift, err = checkCephConfigDrift(context, clusterInfo, spec, drift)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(drift))
+
+	// the values normalized by ceph are not drift
+	spec.CephConfig["osd"] = cephv1.CephConfigOptions{"osd_memory_target": "4G"}
+	assert.NoError(t, applyCephConfig(context, clusterInfo, spec))
+	assert.Equal(t, "4294967296", monDB["osd"]["osd_memory_target"])
+	drift, err = checkCephConfigDrift(context, clusterInfo, spec, drift)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(drift))
+	monDB["osd"]["osd_memory_target"] = "1073741824"
+	drift, err = checkCephConfigDrift(context, clusterInfo, spec, drift)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(drift))
+	assert.Equal(t, "4G", drift[0].Expected)
+	assert.Equal(t, "4294967296", monDB["osd"]["osd_memory_target"])
+
+	// only changing the spelling of an option keeps it in the database
+	spec.CephConfig["osd.3"] = cephv1.CephConfigOptions{"osd max backfills": "6"}
+	assert.NoError(t, applyCephConfig(context, clusterInfo, spec))
+	assert.Equal(t, "6", monDB["osd.3"]["osd_max_backfills"])
+	drift, err = checkCephConfigDrift(context, clusterInfo, spec, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(drift))
+}
+
+func TestScrubWindow(t *testing.T) {
+	hour := func(h int) *int { return &h }
+	spec := &cephv1.ClusterSpec{CephConfig: map[string]cephv1.CephConfigOptions{"osd": {"osd_max_scrubs": "2"}}}
+	assert.NoError(t, validateScrubWindow(spec))
+	assert.Equal(t, []config.Option{{Who: "osd", Option: "osd_max_scrubs", Value: "2"}}, cephConfigOptions(spec))
+
+	// scrubs are allowed at night during the week days
+	spec.Scrub = cephv1.ScrubWindowSpec{BeginHour: hour(22), EndHour: hour(6), BeginWeekDay: hour(1), EndWeekDay: hour(6)}
+	assert.NoError(t, validateScrubWindow(spec))
+	assert.Equal(t, []config.Option{
+		{Who: "osd", Option: "osd_max_scrubs", Value: "2"},
+		{Who: "osd", Option: "osd_scrub_begin_hour", Value: "22"},
+		{Who: "osd", Option: "osd_scrub_begin_week_day", Value: "1"},
+		{Who: "osd", Option: "osd_scrub_end_hour", Value: "6"},
+		{Who: "osd", Option: "osd_scrub_end_week_day", Value: "6"},
+	}, cephConfigOptions(spec))
+	// the cephConfig of the spec is not modified
+	assert.Equal(t, cephv1.CephConfigOptions{"osd_max_scrubs": "2"}, spec.CephConfig["osd"])
+
+	// the cephConfig cannot set the options of the window
+	spec.CephConfig["global"] = cephv1.CephConfigOptions{"osd scrub begin hour": "1"}
+	assert.Error(t, validateScrubWindow(spec))
+	delete(spec.CephConfig, "global")
+
+	// the bounds are set in pairs
+	spec.Scrub.EndHour = nil
+	assert.Error(t, validateScrubWindow(spec))
+	spec.Scrub.EndHour = hour(24)
+	assert.Error(t, validateScrubWindow(spec))
+	spec.Scrub.EndHour = hour(6)
+	spec.Scrub.BeginWeekDay = nil
+	assert.Error(t, validateScrubWindow(spec))
+}
@@ -21,6 +21,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 	"time"
 
@@ -30,6 +31,7 @@ import (
 	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
 	"github.com/rook/rook/pkg/operator/ceph/config"
 	opcontroller "github.com/rook/rook/pkg/operator/ceph/controller"
+	"github.com/rook/rook/pkg/operator/ceph/pool"
 	"github.com/rook/rook/pkg/operator/ceph/reporting"
 	cephver "github.com/rook/rook/pkg/operator/ceph/version"
 	"github.com/rook/rook/pkg/operator/k8sutil"
@@ -184,6 +186,15 @@ func (c *cephStatusChecker) updateCephStatus(status *cephclient.CephStatus, cond
 		return
 	}
 
+	var previousDrift []cephv1.CephConfigDriftStatus
+	var previousForecast *cephv1.CapacityForecastStatus
+	var previousNetwork *cephv1.NetworkStatus
+	if cephCluster.Status.CephStatus != nil {
+		previousDrift = cephCluster.Status.CephStatus.ConfigDrift
+		previousForecast = cephCluster.Status.CephStatus.CapacityForecast
+		previousNetwork = cephCluster.Status.CephStatus.Network
+	}
+
 	// Update with Ceph Status
 	cephCluster.Status.CephStatus = toCustomResourceStatus(cephCluster.Status, status)
 
@@ -196,6 +207,41 @@ func (c *cephStatusChecker) updateCephStatus(status *cephclient.CephStatus, cond
 		cephCluster.Status.CephStatus.Versions = versions
 	}
 
+	// report the mgr modules that are actually enabled, which may differ from the modules in the spec
+	mgrModules, err := cephclient.MgrListModules(c.context, c.clusterInfo)
+	if err != nil {
+		logger.Errorf("failed to get mgr modules. %v", err)
+	} else {
+		cephCluster.Status.CephStatus.Mgr = toMgrStatus(mgrModules)
+	}
+
+	// restore the ceph config options changed out of band and report them
+	cephCluster.Status.CephStatus.ConfigDrift = previousDrift
+	if !c.isExternal {
+		configDrift, err := checkCephConfigDrift(c.context, c.clusterInfo, &cephCluster.Spec, previousDrift)
+		if err != nil {
+			logger.Errorf("failed to check ceph config drift. %v", err)
+		}
+		cephCluster.Status.CephStatus.ConfigDrift = configDrift
+	}
+
+	// forecast the capacity of the pools and device classes from their usage history
+	if !c.isExternal && cephCluster.Spec.CapacityForecast.Enabled {
+		cephCluster.Status.CephStatus.CapacityForecast = previousForecast
+		forecast, err := pool.UpdateCapacityForecast(c.context, c.clusterInfo, &cephCluster.Spec.CapacityForecast, previousForecast)
+		if err != nil {
+			logger.Errorf("failed to forecast the capacity of the pools. %v", err)
+		}
+		if forecast != nil {
+			cephCluster.Status.CephStatus.CapacityForecast = forecast
+		}
+	}
+
+	// report the pods that are not attached to the public network and the modes of the connections
+	if !c.isExternal {
+		cephCluster.Status.CephStatus.Network = c.networkStatus(cephCluster.Spec.Network, previousNetwork)
+	}
+
 	// Update condition
 	logger.Debugf("updating ceph cluster %q status and condition to %+v, %v, %s, %s", clusterName.Namespace, status, conditionStatus, reason, message)
 	opcontroller.UpdateClusterCondition(c.context, cephCluster, c.clusterInfo.NamespacedName(), condition, conditionStatus, reason, message, true)
@@ -236,6 +282,14 @@ func toCustomResourceStatus(currentStatus cephv1.ClusterStatus, newStatus *cephc
 	return s
 }
 
+// toMgrStatus converts the mgr module list to the struct expected for the CephCluster CR status
+func toMgrStatus(modules *cephclient.MgrModuleList) *cephv1.MgrStatus {
+	enabled := append([]string{}, modules.AlwaysOnModules...)
+	enabled = append(enabled, modules.EnabledModules...)
+	sort.Strings(enabled)
+	return &cephv1.MgrStatus{Modules: enabled}
+}
+
 func formatTime(t time.Time) string {
 	return t.Format(time.RFC3339)
 }
@@ -127,6 +127,18 @@ func TestCephStatus(t *testing.T) {
 	assert.Equal(t, formatTime(time.Now().Add(-time.Minute).UTC()), formatTime(time.Now().Add(-time.Minute).UTC()))
 }
 
+func TestMgrStatus(t *testing.T) {
+	modules := &cephclient.MgrModuleList{
+		AlwaysOnModules: []string{"crash", "balancer"},
+		EnabledModules:  []string{"prometheus", "pg_autoscaler"},
+	}
+	mgrStatus := toMgrStatus(modules)
+	assert.Equal(t, []string{"balancer", "crash", "pg_autoscaler", "prometheus"}, mgrStatus.Modules)
+
+	// the source lists are not modified
+	assert.Equal(t, []string{"crash", "balancer"}, modules.AlwaysOnModules)
+}
+
 func TestNewCephStatusChecker(t *testing.T) {
 	clusterInfo := cephclient.AdminClusterInfo("ns")
 	c := &clusterd.Context{}
@@ -207,7 +207,11 @@ func (c *ClusterController) initializeCluster(cluster *cluster) error {
 
 		err = c.configureLocalCephCluster(cluster)
 		if err != nil {
-			controller.UpdateCondition(c.context, c.namespacedName, cephv1.ConditionProgressing, v1.ConditionFalse, cephv1.ClusterProgressingReason, err.Error())
+			reason := cephv1.ClusterProgressingReason
+			if errors.Is(err, errIPFamilyNotSupported) {
+				reason = cephv1.ClusterNetworkValidationFailedReason
+			}
+			controller.UpdateCondition(c.context, c.namespacedName, cephv1.ConditionProgressing, v1.ConditionFalse, reason, err.Error())
 			return errors.Wrap(err, "failed to configure local ceph cluster")
 		}
 	}
@@ -364,6 +368,9 @@ func preClusterStartValidation(cluster *cluster) error {
 	if err := validateStretchCluster(cluster); err != nil {
 		return err
 	}
+	if err := validateScrubWindow(cluster.Spec); err != nil {
+		return err
+	}
 	if cluster.Spec.Network.IsMultus() {
 		_, isPublic := cluster.Spec.Network.Selectors[config.PublicNetworkSelectorKeyName]
 		_, isCluster := cluster.Spec.Network.Selectors[config.ClusterNetworkSelectorKeyName]
@@ -394,6 +401,10 @@ func preClusterStartValidation(cluster *cluster) error {
 		}
 	}
 
+	if err := validateIPFamily(ctx, cluster.context.Clientset, cluster.Namespace, cluster.Spec.Network); err != nil {
+		return err
+	}
+
 	// Validate on-PVC cluster encryption KMS settings
 	if cluster.Spec.Storage.IsOnPVCEncrypted() && cluster.Spec.Security.KeyManagementService.IsEnabled() {
 		// Validate the KMS details
@@ -573,6 +584,11 @@ func (c *cluster) postMonStartupActions() error {
 		}
 	}
 
+	// Apply the ceph config from the spec to the mon configuration database
+	if err := applyCephConfig(c.context, c.ClusterInfo, c.Spec); err != nil {
+		return errors.Wrap(err, "failed to apply the ceph config from the cluster spec")
+	}
+
 	// Create cluster-wide RBD bootstrap peer token
 	_, err = controller.CreateBootstrapPeerSecret(c.context, c.ClusterInfo, &cephv1.CephCluster{ObjectMeta: metav1.ObjectMeta{Name: c.namespacedName.Name, Namespace: c.Namespace}}, c.ownerInfo)
 	if err != nil {
@@ -51,6 +51,7 @@ func (c *ClusterController) configureExternalCephCluster(cluster *cluster) error
 
 	cluster.ClusterInfo = mon.PopulateExternalClusterInfo(c.context, c.namespacedName.Namespace, cluster.ownerInfo)
 	cluster.ClusterInfo.SetName(c.namespacedName.Name)
+	cluster.ClusterInfo.NetworkSpec = cluster.Spec.Network
 
 	if !client.IsKeyringBase64Encoded(cluster.ClusterInfo.CephCred.Secret) {
 		return errors.Errorf("invalid user health checker key for user %q", cluster.ClusterInfo.CephCred.Username)
@@ -186,6 +186,28 @@ func add(mgr manager.Manager, r reconcile.Reconciler, context *clusterd.Context)
 		return err
 	}
 
+	// Watch for changes on the dashboard certificate, password and identity provider metadata secrets
+	err = c.Watch(
+		&source.Kind{
+			Type: &corev1.Secret{
+				TypeMeta: metav1.TypeMeta{
+					Kind:       "Secret",
+					APIVersion: corev1.SchemeGroupVersion.String(),
+				},
+			},
+		},
+		handler.EnqueueRequestsFromMapFunc(handlerFunc),
+		predicateForDashboardSecretWatcher(mgr.GetClient()))
+	if err != nil {
+		return err
+	}
+
+	// Reconcile the clusters once the ceph-csi version is checked to update the connections of the csi clients
+	err = c.Watch(&source.Channel{Source: csi.VersionChecked}, handler.EnqueueRequestsFromMapFunc(handlerFunc))
+	if err != nil {
+		return err
+	}
+
 	// Watch for changes on the hotplug config map
 	// TODO: to improve, can we run this against the operator namespace only?
 	disableVal := os.Getenv(disableHotplugEnv)
@@ -346,7 +368,7 @@ func NewClusterController(context *clusterd.Context, rookImage string, volumeAtt
 		clusterMap:              make(map[string]*cluster),
 		operatorConfigCallbacks: operatorConfigCallbacks,
 		addClusterCallbacks:     addClusterCallbacks,
-		csiConfigMutex:          &sync.Mutex{},
+		csiConfigMutex:          csi.ConfigMutex,
 	}
 }
 
@@ -120,7 +120,7 @@ func (r *ReconcileNode) createOrUpdateCephCrash(node corev1.Node, tolerations []
 			},
 		}
 
-		return nil
+		return k8sutil.ApplyNetworkSettings(cephCluster.Spec.Network, &deploy.Spec.Template.ObjectMeta, &deploy.Spec.Template.Spec)
 	}
 
 	return controllerutil.CreateOrUpdate(context.TODO(), r.client, deploy, mutateFunc)
@@ -155,6 +155,9 @@ func (r *ReconcileNode) createOrUpdateCephCron(cephCluster cephv1.CephCluster, c
 			Volumes:       volumes,
 		},
 	}
+	if err := k8sutil.ApplyNetworkSettings(cephCluster.Spec.Network, &podTemplateSpec.ObjectMeta, &podTemplateSpec.Spec); err != nil {
+		return controllerutil.OperationResultNone, err
+	}
 
 	// After 100 failures, the cron job will no longer run.
 	// To avoid this, the cronjob is configured to only count the failures
@@ -0,0 +1,159 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"net"
+	"strings"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const ipFamilyCheckServiceName = "rook-ceph-ip-family-check"
+
+// errIPFamilyNotSupported is returned when the kubernetes networks do not support the IP family of the cluster network
+var errIPFamilyNotSupported = errors.New("the ip family of the cluster network is not supported by the kubernetes networks")
+
+// validateIPFamily checks that the service network and the nodes support the IP families of the cluster network,
+// rather than letting the mons fail to form quorum on addresses they are not bound to. Only the clusters requesting
+// the IPv6 family or dual-stack are validated, the default IPv4 family is left to the kubernetes networks.
+func validateIPFamily(ctx context.Context, clientset kubernetes.Interface, namespace string, network cephv1.NetworkSpec) error {
+	if !network.IsIPFamilySet() {
+		return nil
+	}
+	families := network.IPFamilies()
+
+	// the mons are reached through their services unless they are on the host network
+	if !network.IsHost() {
+		if err := validateServiceIPFamilies(ctx, clientset, namespace, families); err != nil {
+			return err
+		}
+	}
+
+	nodes, err := clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		logger.Warningf("failed to list nodes to validate the ip families %v. %v", families, err)
+		return nil
+	}
+
+	for _, family := range families {
+		// the daemons on the host network bind to the addresses of the nodes, the other daemons to the pod network
+		// of the nodes. The pods of a multus cluster have their public addresses on the multus network instead.
+		var configured, supported, missing []string
+		for _, node := range nodes.Items {
+			var addresses []string
+			if network.IsHost() {
+				for _, address := range node.Status.Addresses {
+					if address.Type == v1.NodeInternalIP || address.Type == v1.NodeExternalIP {
+						addresses = append(addresses, address.Address)
+					}
+				}
+			} else if !network.IsMultus() {
+				addresses = node.Spec.PodCIDRs
+			}
+			if len(addresses) == 0 {
+				continue
+			}
+
+			configured = append(configured, node.Name)
+			if hasIPFamily(addresses, family) {
+				supported = append(supported, node.Name)
+			} else {
+				missing = append(missing, node.Name)
+			}
+		}
+
+		if len(configured) > 0 && len(supported) == 0 {
+			if network.IsHost() {
+				return errors.Wrapf(errIPFamilyNotSupported, "none of the nodes has an %s address for the host network", family)
+			}
+			// the pod CIDRs of the nodes are not set by all the network plugins, which may allocate the pod addresses
+			// from other ranges
+			logger.Warningf("none of the nodes has an %s pod CIDR, the ceph daemons may not get %s addresses", family, family)
+			continue
+		}
+		if len(missing) > 0 {
+			logger.Warningf("the ceph daemons cannot run on nodes %q without %s network", strings.Join(missing, ","), family)
+		}
+	}
+
+	return nil
+}
+
+// validateServiceIPFamilies creates a service requesting the IP families in dry-run mode, the API server rejects the
+// families that are not configured on the service network
+func validateServiceIPFamilies(ctx context.Context, clientset kubernetes.Interface, namespace string, families []v1.IPFamily) error {
+	policy := v1.IPFamilyPolicySingleStack
+	if len(families) > 1 {
+		policy = v1.IPFamilyPolicyRequireDualStack
+	}
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ipFamilyCheckServiceName,
+			Namespace: namespace,
+		},
+		Spec: v1.ServiceSpec{
+			Ports:          []v1.ServicePort{{Name: "tcp-msgr2", Port: 3300, Protocol: v1.ProtocolTCP}},
+			IPFamilies:     families,
+			IPFamilyPolicy: &policy,
+		},
+	}
+
+	s, err := clientset.CoreV1().Services(namespace).Create(ctx, svc, metav1.CreateOptions{DryRun: []string{metav1.DryRunAll}})
+	if err != nil {
+		if kerrors.IsInvalid(err) {
+			return errors.Wrapf(errIPFamilyNotSupported, "the service network does not support the ip families %v. %v", families, err)
+		}
+		logger.Warningf("failed to validate the ip families %v of the service network. %v", families, err)
+		return nil
+	}
+
+	// the API servers without dual-stack support ignore the families and allocate an address of their single family
+	ip := s.Spec.ClusterIP
+	if len(s.Spec.ClusterIPs) > 0 {
+		ip = s.Spec.ClusterIPs[0]
+	}
+	if ip != "" && ip != v1.ClusterIPNone && !hasIPFamily([]string{ip}, families[0]) {
+		return errors.Wrapf(errIPFamilyNotSupported, "the service network allocated the address %q instead of %s", ip, families[0])
+	}
+
+	return nil
+}
+
+// hasIPFamily returns whether any of the addresses or CIDRs is of the IP family
+func hasIPFamily(addresses []string, family v1.IPFamily) bool {
+	for _, address := range addresses {
+		ip := net.ParseIP(address)
+		if ip == nil {
+			var err error
+			if ip, _, err = net.ParseCIDR(address); err != nil {
+				continue
+			}
+		}
+		if (ip.To4() != nil) == (family == v1.IPv4Protocol) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	testop "github.com/rook/rook/pkg/operator/test"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// newIPFamilyClientset returns a clientset whose service network only allocates addresses of the family
+func newIPFamilyClientset(t *testing.T, family v1.IPFamily, clusterIP string) *fake.Clientset {
+	clientset := testop.New(t, 2)
+	clientset.PrependReactor("create", "services", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		svc := action.(k8stesting.CreateAction).GetObject().(*v1.Service).DeepCopy()
+		for _, f := range svc.Spec.IPFamilies {
+			if f != family {
+				return true, nil, kerrors.NewInvalid(schema.GroupKind{Kind: "Service"}, svc.Name, field.ErrorList{
+					field.Invalid(field.NewPath("spec", "ipFamilies"), f, "not configured on this cluster"),
+				})
+			}
+		}
+		svc.Spec.ClusterIP = clusterIP
+		return true, svc, nil
+	})
+	return clientset
+}
+
+func TestValidateIPFamily(t *testing.T) {
+	ctx := context.TODO()
+
+	// the default IPv4 family is not validated
+	clientset := newIPFamilyClientset(t, v1.IPv6Protocol, "fd00::1")
+	assert.NoError(t, validateIPFamily(ctx, clientset, "ns", cephv1.NetworkSpec{}))
+	assert.NoError(t, validateIPFamily(ctx, clientset, "ns", cephv1.NetworkSpec{IPFamily: cephv1.IPv4}))
+
+	clientset = newIPFamilyClientset(t, v1.IPv4Protocol, "10.0.0.1")
+
+	// the service network rejects the families it does not support
+	err := validateIPFamily(ctx, clientset, "ns", cephv1.NetworkSpec{IPFamily: cephv1.IPv6})
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, errIPFamilyNotSupported))
+	err = validateIPFamily(ctx, clientset, "ns", cephv1.NetworkSpec{IPFamily: cephv1.IPv4, DualStack: true})
+	assert.True(t, errors.Is(err, errIPFamilyNotSupported))
+
+	// the API servers without dual-stack support allocate an address of their family
+	clientset = newIPFamilyClientset(t, v1.IPv6Protocol, "10.0.0.1")
+	err = validateIPFamily(ctx, clientset, "ns", cephv1.NetworkSpec{IPFamily: cephv1.IPv6})
+	assert.True(t, errors.Is(err, errIPFamilyNotSupported))
+
+	// the nodes without a pod CIDR of the family are only reported
+	clientset = newIPFamilyClientset(t, v1.IPv6Protocol, "fd00::1")
+	assert.NoError(t, validateIPFamily(ctx, clientset, "ns", cephv1.NetworkSpec{IPFamily: cephv1.IPv6}))
+	nodes, err := clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	assert.NoError(t, err)
+	for i := range nodes.Items {
+		nodes.Items[i].Spec.PodCIDRs = []string{"192.168.0.0/24"}
+		_, err = clientset.CoreV1().Nodes().Update(ctx, &nodes.Items[i], metav1.UpdateOptions{})
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, validateIPFamily(ctx, clientset, "ns", cephv1.NetworkSpec{IPFamily: cephv1.IPv6}))
+
+	// the pods of a multus cluster have their public addresses on the multus network
+	assert.NoError(t, validateIPFamily(ctx, clientset, "ns", cephv1.NetworkSpec{IPFamily: cephv1.IPv6, Provider: "multus"}))
+
+	// a single node with the family is enough to run the daemons
+	nodes.Items[0].Spec.PodCIDRs = []string{"192.168.0.0/24", "fd00:10::/64"}
+	_, err = clientset.CoreV1().Nodes().Update(ctx, &nodes.Items[0], metav1.UpdateOptions{})
+	assert.NoError(t, err)
+	assert.NoError(t, validateIPFamily(ctx, clientset, "ns", cephv1.NetworkSpec{IPFamily: cephv1.IPv6}))
+
+	// the daemons on the host network need the addresses of the family on the nodes
+	err = validateIPFamily(ctx, clientset, "ns", cephv1.NetworkSpec{IPFamily: cephv1.IPv6, Provider: "host"})
+	assert.True(t, errors.Is(err, errIPFamilyNotSupported))
+	testop.AddReadyNode(t, clientset, "ipv6-node", "fd00::10")
+	assert.NoError(t, validateIPFamily(ctx, clientset, "ns", cephv1.NetworkSpec{IPFamily: cephv1.IPv6, Provider: "host"}))
+}
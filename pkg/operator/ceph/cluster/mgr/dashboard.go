@@ -74,6 +74,39 @@ func (c *Cluster) configureDashboardService(activeDaemon string) error {
 		}
 	}
 
+	return c.configureDashboardIngress(dashboardService)
+}
+
+func (c *Cluster) configureDashboardIngress(dashboardService *v1.Service) error {
+	ctx := context.TODO()
+	if !c.spec.Dashboard.Enabled || c.spec.Dashboard.Ingress == nil {
+		// delete the dashboard ingress only if one was created for the cluster
+		ingress, err := c.context.Clientset.NetworkingV1().Ingresses(c.clusterInfo.Namespace).Get(ctx, dashboardService.Name, metav1.GetOptions{})
+		if err != nil {
+			if kerrors.IsNotFound(err) {
+				return nil
+			}
+			return errors.Wrap(err, "failed to get dashboard ingress")
+		}
+		if ingress.Labels[k8sutil.AppAttr] != AppName || ingress.Labels[k8sutil.ClusterAttr] != c.clusterInfo.Namespace {
+			logger.Debugf("ingress %q is not managed by rook, not deleting it", ingress.Name)
+			return nil
+		}
+		logger.Infof("deleting dashboard ingress %q", ingress.Name)
+		err = c.context.Clientset.NetworkingV1().Ingresses(c.clusterInfo.Namespace).Delete(ctx, ingress.Name, metav1.DeleteOptions{})
+		if err != nil && !kerrors.IsNotFound(err) {
+			return errors.Wrap(err, "failed to delete dashboard ingress")
+		}
+		return nil
+	}
+
+	ingress, err := c.makeDashboardIngress(dashboardService)
+	if err != nil {
+		return err
+	}
+	if _, err := k8sutil.CreateOrUpdateIngress(c.context.Clientset, ingress); err != nil {
+		return errors.Wrap(err, "failed to configure dashboard ingress")
+	}
 	return nil
 }
 
@@ -106,7 +139,13 @@ func (c *Cluster) configureDashboardModules() error {
 	}
 	if hasChanged {
 		logger.Info("dashboard config has changed. restarting the dashboard module")
-		return c.restartDashboard()
+		if err := c.restartDashboard(); err != nil {
+			return err
+		}
+	}
+
+	if err := c.configureDashboardAccess(); err != nil {
+		return errors.Wrap(err, "failed to configure dashboard access")
 	}
 	return nil
 }
@@ -159,7 +198,13 @@ func (c *Cluster) initializeSecureDashboard() (bool, error) {
 		return false, errors.Wrap(err, "failed to generate a password for the ceph dashboard")
 	}
 
-	if c.spec.Dashboard.SSL {
+	hasChanged := false
+	if c.spec.Dashboard.SSL && c.spec.Dashboard.CertificateSecretName != "" {
+		hasChanged, err = c.configureDashboardCert()
+		if err != nil {
+			return false, errors.Wrap(err, "failed to configure the certificate for the ceph dashboard")
+		}
+	} else if c.spec.Dashboard.SSL {
 		alreadyCreated, err := c.createSelfSignedCert()
 		if err != nil {
 			return false, errors.Wrap(err, "failed to create a self signed cert for the ceph dashboard")
@@ -173,7 +218,42 @@ func (c *Cluster) initializeSecureDashboard() (bool, error) {
 		return false, errors.Wrap(err, "failed to set login credentials for the ceph dashboard")
 	}
 
-	return false, nil
+	return hasChanged, nil
+}
+
+// configureDashboardCert sets the certificate from the user-provided tls secret on the dashboard if it
+// differs from the one currently configured. The module must be restarted to serve a new certificate.
+func (c *Cluster) configureDashboardCert() (bool, error) {
+	name := c.spec.Dashboard.CertificateSecretName
+	secret, err := c.context.Clientset.CoreV1().Secrets(c.clusterInfo.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to get dashboard certificate secret %q", name)
+	}
+	cert, key := secret.Data[v1.TLSCertKey], secret.Data[v1.TLSPrivateKeyKey]
+	if len(cert) == 0 || len(key) == 0 {
+		return false, errors.Errorf("dashboard certificate secret %q must have the %q and %q keys", name, v1.TLSCertKey, v1.TLSPrivateKeyKey)
+	}
+
+	hasChanged := false
+	for _, setting := range []struct {
+		configKey string
+		command   string
+		value     []byte
+	}{
+		{"mgr/dashboard/crt", "set-ssl-certificate", cert},
+		{"mgr/dashboard/key", "set-ssl-certificate-key", key},
+	} {
+		current, err := client.NewCephCommand(c.context, c.clusterInfo, []string{"config-key", "get", setting.configKey}).RunWithTimeout(exec.CephCommandsTimeout)
+		if err == nil && string(current) == string(setting.value) {
+			continue
+		}
+		logger.Infof("setting dashboard %q from secret %q", setting.configKey, name)
+		if err := c.runDashboardCommandWithPassword(string(setting.value), "dashboard", setting.command); err != nil {
+			return false, errors.Wrapf(err, "failed to set dashboard %q", setting.configKey)
+		}
+		hasChanged = true
+	}
+	return hasChanged, nil
 }
 
 func (c *Cluster) createSelfSignedCert() (bool, error) {
@@ -0,0 +1,519 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mgr
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	"github.com/rook/rook/pkg/util/exec"
+	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// #nosec because of the word `Secret`
+	dashboardAccessSecretName = "rook-ceph-dashboard-access"
+	dashboardAccessStateKey   = "state"
+	idpMetadataKeyName        = "metadata"
+)
+
+// the roles that are built into the dashboard and cannot be modified
+var dashboardSystemRoles = []string{
+	"administrator", "read-only", "block-manager", "rgw-manager", "cluster-manager", "pool-manager", "cephfs-manager", "ganesha-manager",
+}
+
+// dashboardAccessState is the access control configuration that was last applied to the dashboard.
+// It is needed to remove the users and roles that are removed from the spec and to detect password changes.
+type dashboardAccessState struct {
+	// Users maps the managed users to the hash of the password last set for them
+	Users map[string]string `json:"users,omitempty"`
+	// Roles are the managed custom roles
+	Roles []string `json:"roles,omitempty"`
+	// SSO is the hash of the SSO configuration last applied
+	SSO string `json:"sso,omitempty"`
+	// Salt is the random salt of the hashes, generated once for the cluster
+	Salt string `json:"salt,omitempty"`
+}
+
+type dashboardUserInfo struct {
+	Roles []string `json:"roles"`
+	Name  string   `json:"name"`
+	Email string   `json:"email"`
+}
+
+type dashboardRoleInfo struct {
+	ScopesPermissions map[string][]string `json:"scopes_permissions"`
+}
+
+// configureDashboardAccess reconciles the dashboard roles, users and SSO settings from the spec
+func (c *Cluster) configureDashboardAccess() error {
+	state, err := c.getDashboardAccessState()
+	if err != nil {
+		return errors.Wrap(err, "failed to get dashboard access state")
+	}
+	// the secret is only written when the state differs from the state that was loaded
+	loaded, err := json.Marshal(state)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal dashboard access state")
+	}
+	if err := initDashboardAccessSalt(state); err != nil {
+		return errors.Wrap(err, "failed to initialize dashboard access state")
+	}
+	if len(state.Users) == 0 && len(state.Roles) == 0 && state.SSO == "" &&
+		len(c.spec.Dashboard.Users) == 0 && len(c.spec.Dashboard.Roles) == 0 && c.spec.Dashboard.SSO == nil {
+		// nothing was ever configured
+		return nil
+	}
+
+	// save the state even if one of the steps failed so the successful changes are not attempted again
+	defer func() {
+		if current, err := json.Marshal(state); err == nil && bytes.Equal(current, loaded) {
+			return
+		}
+		if err := c.saveDashboardAccessState(state); err != nil {
+			logger.Errorf("failed to save dashboard access state. %v", err)
+		}
+	}()
+
+	// the roles must exist before they are assigned to users
+	if err := c.configureDashboardRoles(state); err != nil {
+		return errors.Wrap(err, "failed to configure dashboard roles")
+	}
+	if err := c.configureDashboardUsers(state); err != nil {
+		return errors.Wrap(err, "failed to configure dashboard users")
+	}
+	// the roles are only deleted once no user refers to them anymore
+	if err := c.removeDashboardRoles(state); err != nil {
+		return errors.Wrap(err, "failed to remove dashboard roles")
+	}
+	if err := c.configureDashboardSSO(state); err != nil {
+		return errors.Wrap(err, "failed to configure dashboard sso")
+	}
+
+	return nil
+}
+
+func (c *Cluster) configureDashboardRoles(state *dashboardAccessState) error {
+	if len(c.spec.Dashboard.Roles) == 0 {
+		return nil
+	}
+
+	existingRoles, err := c.listDashboardEntries("ac-role-show")
+	if err != nil {
+		return err
+	}
+
+	for _, role := range c.spec.Dashboard.Roles {
+		if role.Name == "" {
+			return errors.New("name not specified for the dashboard role")
+		}
+		if isDashboardSystemRole(role.Name) {
+			return errors.Errorf("cannot configure dashboard system role %q", role.Name)
+		}
+
+		if !contains(existingRoles, role.Name) {
+			logger.Infof("creating dashboard role %q", role.Name)
+			args := []string{"dashboard", "ac-role-create", role.Name}
+			if role.Description != "" {
+				args = append(args, role.Description)
+			}
+			if _, err := c.runDashboardCommand(args...); err != nil {
+				return errors.Wrapf(err, "failed to create dashboard role %q", role.Name)
+			}
+		}
+		if !contains(state.Roles, role.Name) {
+			state.Roles = append(state.Roles, role.Name)
+		}
+
+		if err := c.configureDashboardRolePermissions(role); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Cluster) configureDashboardRolePermissions(role cephv1.DashboardRoleSpec) error {
+	output, err := c.runDashboardCommand("dashboard", "ac-role-show", role.Name)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get dashboard role %q", role.Name)
+	}
+	var current dashboardRoleInfo
+	if err := json.Unmarshal(output, &current); err != nil {
+		return errors.Wrapf(err, "failed to unmarshal dashboard role %q", role.Name)
+	}
+
+	for scope := range current.ScopesPermissions {
+		if _, ok := role.Scopes[scope]; !ok {
+			if _, err := c.runDashboardCommand("dashboard", "ac-role-del-scope-perms", role.Name, scope); err != nil {
+				return errors.Wrapf(err, "failed to remove scope %q from dashboard role %q", scope, role.Name)
+			}
+		}
+	}
+	scopes := make([]string, 0, len(role.Scopes))
+	for scope := range role.Scopes {
+		scopes = append(scopes, scope)
+	}
+	sort.Strings(scopes)
+	for _, scope := range scopes {
+		permissions := sortedCopy(role.Scopes[scope])
+		if len(permissions) == 0 {
+			return errors.Errorf("no permissions specified for scope %q of dashboard role %q", scope, role.Name)
+		}
+		if reflect.DeepEqual(permissions, sortedCopy(current.ScopesPermissions[scope])) {
+			continue
+		}
+		logger.Infof("setting permissions %v on scope %q of dashboard role %q", permissions, scope, role.Name)
+		args := append([]string{"dashboard", "ac-role-add-scope-perms", role.Name, scope}, permissions...)
+		if _, err := c.runDashboardCommand(args...); err != nil {
+			return errors.Wrapf(err, "failed to set scope %q permissions of dashboard role %q", scope, role.Name)
+		}
+	}
+	return nil
+}
+
+func (c *Cluster) removeDashboardRoles(state *dashboardAccessState) error {
+	var remaining []string
+	var err error
+	for _, name := range state.Roles {
+		if err != nil || dashboardRoleInSpec(c.spec.Dashboard.Roles, name) {
+			remaining = append(remaining, name)
+			continue
+		}
+		logger.Infof("deleting dashboard role %q that was removed from the spec", name)
+		if _, err = c.runDashboardCommand("dashboard", "ac-role-delete", name); err != nil {
+			err = errors.Wrapf(err, "failed to delete dashboard role %q", name)
+			remaining = append(remaining, name)
+		}
+	}
+	state.Roles = remaining
+	return err
+}
+
+func (c *Cluster) configureDashboardUsers(state *dashboardAccessState) error {
+	if len(c.spec.Dashboard.Users) > 0 && !FileBasedPasswordSupported(c.clusterInfo) {
+		return errors.Errorf("dashboard users are not supported with ceph version %q", c.clusterInfo.CephVersion.String())
+	}
+
+	existingUsers, err := c.listDashboardEntries("ac-user-show")
+	if err != nil {
+		return err
+	}
+
+	for _, user := range c.spec.Dashboard.Users {
+		if user.Name == "" {
+			return errors.New("name not specified for the dashboard user")
+		}
+		if user.Name == dashboardUsername {
+			return errors.Errorf("cannot configure dashboard user %q that is managed with the %q secret", user.Name, dashboardPasswordName)
+		}
+
+		if err := c.configureDashboardUser(state, user, contains(existingUsers, user.Name)); err != nil {
+			return errors.Wrapf(err, "failed to configure dashboard user %q", user.Name)
+		}
+	}
+
+	for name := range state.Users {
+		if dashboardUserInSpec(c.spec.Dashboard.Users, name) {
+			continue
+		}
+		logger.Infof("deleting dashboard user %q that was removed from the spec", name)
+		if contains(existingUsers, name) {
+			if _, err := c.runDashboardCommand("dashboard", "ac-user-delete", name); err != nil {
+				return errors.Wrapf(err, "failed to delete dashboard user %q", name)
+			}
+		}
+		delete(state.Users, name)
+	}
+	return nil
+}
+
+func (c *Cluster) configureDashboardUser(state *dashboardAccessState, user cephv1.DashboardUserSpec, exists bool) error {
+	password, passwordHash, err := c.getDashboardUserPassword(state, user)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		logger.Infof("creating dashboard user %q", user.Name)
+		if err := c.runDashboardCommandWithPassword(password, "dashboard", "ac-user-create", user.Name); err != nil {
+			return errors.Wrap(err, "failed to create user")
+		}
+	} else if lastHash, ok := state.Users[user.Name]; passwordHash != "" && (!ok || lastHash != passwordHash) {
+		logger.Infof("updating the password of dashboard user %q", user.Name)
+		if err := c.runDashboardCommandWithPassword(password, "dashboard", "ac-user-set-password", user.Name); err != nil {
+			return errors.Wrap(err, "failed to set password")
+		}
+	}
+	state.Users[user.Name] = passwordHash
+
+	output, err := c.runDashboardCommand("dashboard", "ac-user-show", user.Name)
+	if err != nil {
+		return errors.Wrap(err, "failed to get user")
+	}
+	var current dashboardUserInfo
+	if err := json.Unmarshal(output, &current); err != nil {
+		return errors.Wrap(err, "failed to unmarshal user")
+	}
+
+	desiredRoles := sortedCopy(user.Roles)
+	currentRoles := sortedCopy(current.Roles)
+	if !reflect.DeepEqual(desiredRoles, currentRoles) {
+		logger.Infof("setting roles %v on dashboard user %q", desiredRoles, user.Name)
+		if len(desiredRoles) == 0 {
+			args := append([]string{"dashboard", "ac-user-del-roles", user.Name}, currentRoles...)
+			if _, err := c.runDashboardCommand(args...); err != nil {
+				return errors.Wrap(err, "failed to remove roles")
+			}
+		} else {
+			args := append([]string{"dashboard", "ac-user-set-roles", user.Name}, desiredRoles...)
+			if _, err := c.runDashboardCommand(args...); err != nil {
+				return errors.Wrap(err, "failed to set roles")
+			}
+		}
+	}
+
+	if (user.DisplayName != "" || user.Email != "") && (user.DisplayName != current.Name || user.Email != current.Email) {
+		if _, err := c.runDashboardCommand("dashboard", "ac-user-set-info", user.Name, user.DisplayName, user.Email); err != nil {
+			return errors.Wrap(err, "failed to set user info")
+		}
+	}
+	return nil
+}
+
+// getDashboardUserPassword returns the password of the user and its hash. The hash is empty for generated passwords.
+func (c *Cluster) getDashboardUserPassword(state *dashboardAccessState, user cephv1.DashboardUserSpec) (string, string, error) {
+	if user.PasswordSecretName == "" {
+		password, err := GeneratePassword(passwordLength)
+		if err != nil {
+			return "", "", errors.Wrap(err, "failed to generate password")
+		}
+		return password, "", nil
+	}
+
+	secret, err := c.context.Clientset.CoreV1().Secrets(c.clusterInfo.Namespace).Get(context.TODO(), user.PasswordSecretName, metav1.GetOptions{})
+	if err != nil {
+		return "", "", errors.Wrapf(err, "failed to get password secret %q", user.PasswordSecretName)
+	}
+	password, err := decodeSecret(secret)
+	if err != nil {
+		return "", "", errors.Wrapf(err, "failed to read password secret %q", user.PasswordSecretName)
+	}
+	return password, state.hash(password), nil
+}
+
+func (c *Cluster) configureDashboardSSO(state *dashboardAccessState) error {
+	sso := c.spec.Dashboard.SSO
+	if sso == nil {
+		if state.SSO != "" {
+			logger.Info("disabling dashboard sso")
+			if _, err := c.runDashboardCommand("dashboard", "sso", "disable"); err != nil {
+				return errors.Wrap(err, "failed to disable sso")
+			}
+			state.SSO = ""
+		}
+		return nil
+	}
+
+	if sso.BaseURL == "" || sso.IdPMetadataSecretName == "" {
+		return errors.New("both the base url and the identity provider metadata secret must be specified for sso")
+	}
+	secret, err := c.context.Clientset.CoreV1().Secrets(c.clusterInfo.Namespace).Get(context.TODO(), sso.IdPMetadataSecretName, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to get identity provider metadata secret %q", sso.IdPMetadataSecretName)
+	}
+	metadata, ok := secret.Data[idpMetadataKeyName]
+	if !ok || len(metadata) == 0 {
+		return errors.Errorf("identity provider metadata not found in secret %q", sso.IdPMetadataSecretName)
+	}
+
+	args := []string{"dashboard", "sso", "setup", "saml2", sso.BaseURL, string(metadata)}
+	if sso.UsernameAttribute != "" || sso.EntityID != "" {
+		usernameAttribute := sso.UsernameAttribute
+		if usernameAttribute == "" {
+			usernameAttribute = "uid"
+		}
+		args = append(args, usernameAttribute)
+	}
+	if sso.EntityID != "" {
+		args = append(args, sso.EntityID)
+	}
+
+	ssoHash := state.hash(fmt.Sprintf("%v", args))
+	if ssoHash == state.SSO {
+		logger.Debug("dashboard sso is already configured")
+		return nil
+	}
+
+	logger.Infof("configuring dashboard sso with base url %q", sso.BaseURL)
+	if _, err := c.runDashboardCommand(args...); err != nil {
+		return errors.Wrap(err, "failed to setup saml2")
+	}
+	if _, err := c.runDashboardCommand("dashboard", "sso", "enable", "saml2"); err != nil {
+		return errors.Wrap(err, "failed to enable saml2")
+	}
+	state.SSO = ssoHash
+	return nil
+}
+
+// listDashboardEntries returns the names listed by a "show" command without arguments
+func (c *Cluster) listDashboardEntries(command string) ([]string, error) {
+	output, err := c.runDashboardCommand("dashboard", command)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to run %q", command)
+	}
+	var names []string
+	if err := json.Unmarshal(output, &names); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal %q output", command)
+	}
+	return names, nil
+}
+
+func (c *Cluster) runDashboardCommand(args ...string) ([]byte, error) {
+	return client.ExecuteCephCommandWithRetry(func() (string, []byte, error) {
+		output, err := client.NewCephCommand(c.context, c.clusterInfo, args).RunWithTimeout(exec.CephCommandsTimeout)
+		return args[1], output, err
+	}, c.exitCode, 5, invalidArgErrorCode, dashboardInitWaitTime)
+}
+
+// runDashboardCommandWithPassword passes the password to the command in a file so it is never on the command line
+func (c *Cluster) runDashboardCommandWithPassword(password string, args ...string) error {
+	file, err := CreateTempPasswordFile(password)
+	if err != nil {
+		return errors.Wrap(err, "failed to create a temporary dashboard password file")
+	}
+	defer func() {
+		if err := os.Remove(file.Name()); err != nil {
+			logger.Errorf("failed to clean up dashboard password file %q. %v", file.Name(), err)
+		}
+	}()
+
+	_, err = c.runDashboardCommand(append(args, "-i", file.Name())...)
+	return err
+}
+
+func (c *Cluster) getDashboardAccessState() (*dashboardAccessState, error) {
+	state := &dashboardAccessState{Users: map[string]string{}}
+	secret, err := c.context.Clientset.CoreV1().Secrets(c.clusterInfo.Namespace).Get(context.TODO(), dashboardAccessSecretName, metav1.GetOptions{})
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return state, nil
+		}
+		return nil, errors.Wrapf(err, "failed to get secret %q", dashboardAccessSecretName)
+	}
+	if err := json.Unmarshal(secret.Data[dashboardAccessStateKey], state); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal secret %q", dashboardAccessSecretName)
+	}
+	if state.Users == nil {
+		state.Users = map[string]string{}
+	}
+	return state, nil
+}
+
+// initDashboardAccessSalt generates the salt of the hashes. The states saved before the hashes were salted
+// have no salt, their passwords and SSO settings are applied once more.
+func initDashboardAccessSalt(state *dashboardAccessState) error {
+	if state.Salt != "" {
+		return nil
+	}
+	salt, err := GenerateRandomBytes(16)
+	if err != nil {
+		return errors.Wrap(err, "failed to generate salt")
+	}
+	state.Salt = hex.EncodeToString(salt)
+	return nil
+}
+
+// hash returns the salted hash of the value, so the passwords cannot be recovered from the state secret
+func (s *dashboardAccessState) hash(value string) string {
+	mac := hmac.New(sha256.New, []byte(s.Salt))
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (c *Cluster) saveDashboardAccessState(state *dashboardAccessState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal dashboard access state")
+	}
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      dashboardAccessSecretName,
+			Namespace: c.clusterInfo.Namespace,
+		},
+		Data: map[string][]byte{dashboardAccessStateKey: data},
+		Type: k8sutil.RookType,
+	}
+	err = c.clusterInfo.OwnerInfo.SetControllerReference(secret)
+	if err != nil {
+		return errors.Wrapf(err, "failed to set owner reference to secret %q", secret.Name)
+	}
+	if _, err := k8sutil.CreateOrUpdateSecret(c.context.Clientset, secret); err != nil {
+		return errors.Wrapf(err, "failed to save secret %q", secret.Name)
+	}
+	return nil
+}
+
+func isDashboardSystemRole(name string) bool {
+	return contains(dashboardSystemRoles, name)
+}
+
+func dashboardRoleInSpec(roles []cephv1.DashboardRoleSpec, name string) bool {
+	for _, role := range roles {
+		if role.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func dashboardUserInSpec(users []cephv1.DashboardUserSpec, name string) bool {
+	for _, user := range users {
+		if user.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedCopy(list []string) []string {
+	result := append([]string{}, list...)
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,269 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mgr
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	cephver "github.com/rook/rook/pkg/operator/ceph/version"
+	"github.com/rook/rook/pkg/operator/test"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// fakeDashboard simulates the access control commands of the dashboard module
+type fakeDashboard struct {
+	users     map[string]*dashboardUserInfo
+	passwords map[string]string
+	roles     map[string]*dashboardRoleInfo
+	sso       []string
+	commands  []string
+}
+
+func newFakeDashboard() *fakeDashboard {
+	return &fakeDashboard{
+		users:     map[string]*dashboardUserInfo{"admin": {Roles: []string{"administrator"}}},
+		passwords: map[string]string{},
+		roles:     map[string]*dashboardRoleInfo{"administrator": {}, "read-only": {}},
+	}
+}
+
+func (f *fakeDashboard) run(command string, args ...string) (string, error) {
+	if args[0] != "dashboard" {
+		return "", nil
+	}
+	f.commands = append(f.commands, args[1])
+	password := ""
+	for i := range args {
+		if args[i] == "-i" {
+			b, _ := ioutil.ReadFile(args[i+1])
+			password = string(b)
+		}
+	}
+	// drop the connection flags that are appended to every command
+	for i, arg := range args {
+		if strings.HasPrefix(arg, "--connect-timeout") {
+			args = args[:i]
+			break
+		}
+	}
+	if len(args) > 4 && args[len(args)-2] == "-i" {
+		args = args[:len(args)-2]
+	}
+	toJSON := func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	}
+
+	switch args[1] {
+	case "ac-user-show":
+		if len(args) == 2 {
+			var names []string
+			for name := range f.users {
+				names = append(names, name)
+			}
+			return toJSON(names)
+		}
+		return toJSON(f.users[args[2]])
+	case "ac-user-create":
+		f.users[args[2]] = &dashboardUserInfo{}
+		f.passwords[args[2]] = password
+	case "ac-user-set-password":
+		f.passwords[args[2]] = password
+	case "ac-user-set-roles":
+		f.users[args[2]].Roles = args[3:]
+	case "ac-user-del-roles":
+		f.users[args[2]].Roles = nil
+	case "ac-user-set-info":
+		f.users[args[2]].Name = args[3]
+		f.users[args[2]].Email = args[4]
+	case "ac-user-delete":
+		delete(f.users, args[2])
+	case "ac-role-show":
+		if len(args) == 2 {
+			var names []string
+			for name := range f.roles {
+				names = append(names, name)
+			}
+			return toJSON(names)
+		}
+		return toJSON(f.roles[args[2]])
+	case "ac-role-create":
+		f.roles[args[2]] = &dashboardRoleInfo{ScopesPermissions: map[string][]string{}}
+	case "ac-role-add-scope-perms":
+		f.roles[args[2]].ScopesPermissions[args[3]] = args[4:]
+	case "ac-role-del-scope-perms":
+		delete(f.roles[args[2]].ScopesPermissions, args[3])
+	case "ac-role-delete":
+		delete(f.roles, args[2])
+	case "sso":
+		f.sso = append(f.sso, args[2])
+	default:
+		return "", errors.Errorf("unexpected dashboard command %q", args)
+	}
+	return "", nil
+}
+
+func TestConfigureDashboardAccess(t *testing.T) {
+	ctx := context.TODO()
+	dashboard := newFakeDashboard()
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithTimeout: func(timeout time.Duration, command string, args ...string) (string, error) {
+			logger.Infof("command: %s %v", command, args)
+			return dashboard.run(command, args...)
+		},
+	}
+	clientset := test.New(t, 1)
+	ownerInfo := cephclient.NewMinimumOwnerInfoWithOwnerRef()
+	clusterInfo := &cephclient.ClusterInfo{Namespace: "myns", CephVersion: cephver.Pacific, OwnerInfo: ownerInfo}
+	c := &Cluster{clusterInfo: clusterInfo, context: &clusterd.Context{Clientset: clientset, Executor: executor}}
+	c.exitCode = func(err error) (int, bool) { return 0, false }
+	dashboardInitWaitTime = 0
+
+	createSecret := func(name, key, value string) {
+		secret := &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: clusterInfo.Namespace},
+			Data:       map[string][]byte{key: []byte(value)},
+		}
+		_, err := clientset.CoreV1().Secrets(clusterInfo.Namespace).Create(ctx, secret, metav1.CreateOptions{})
+		assert.NoError(t, err)
+	}
+
+	t.Run("nothing to configure", func(t *testing.T) {
+		assert.NoError(t, c.configureDashboardAccess())
+		assert.Equal(t, 0, len(dashboard.commands))
+	})
+
+	t.Run("create roles and users", func(t *testing.T) {
+		createSecret("alice-password", "password", "secret1")
+		c.spec.Dashboard.Roles = []cephv1.DashboardRoleSpec{
+			{Name: "pool-viewer", Scopes: map[string][]string{"pool": {"read"}, "rbd-image": {"read", "update"}}},
+		}
+		c.spec.Dashboard.Users = []cephv1.DashboardUserSpec{
+			{Name: "alice", Roles: []string{"pool-viewer", "read-only"}, PasswordSecretName: "alice-password", Email: "alice@example.com"},
+			{Name: "bob@example.com", Roles: []string{"administrator"}},
+		}
+		assert.NoError(t, c.configureDashboardAccess())
+
+		assert.Equal(t, map[string][]string{"pool": {"read"}, "rbd-image": {"read", "update"}}, dashboard.roles["pool-viewer"].ScopesPermissions)
+		assert.Equal(t, []string{"pool-viewer", "read-only"}, dashboard.users["alice"].Roles)
+		assert.Equal(t, "alice@example.com", dashboard.users["alice"].Email)
+		assert.Equal(t, "secret1", dashboard.passwords["alice"])
+		assert.Equal(t, []string{"administrator"}, dashboard.users["bob@example.com"].Roles)
+		assert.Equal(t, passwordLength, len(dashboard.passwords["bob@example.com"]))
+
+		state, err := c.getDashboardAccessState()
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"pool-viewer"}, state.Roles)
+		assert.NotEqual(t, "", state.Salt)
+		assert.Equal(t, state.hash("secret1"), state.Users["alice"])
+		assert.NotEqual(t, (&dashboardAccessState{}).hash("secret1"), state.Users["alice"])
+		assert.Equal(t, "", state.Users["bob@example.com"])
+	})
+
+	t.Run("no changes", func(t *testing.T) {
+		dashboard.commands = nil
+		stateWrites := 0
+		clientset.PrependReactor("*", "secrets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			if action.GetVerb() == "create" || action.GetVerb() == "update" {
+				stateWrites++
+			}
+			return false, nil, nil
+		})
+		assert.NoError(t, c.configureDashboardAccess())
+		for _, command := range dashboard.commands {
+			assert.Contains(t, []string{"ac-user-show", "ac-role-show"}, command)
+		}
+		// the unchanged state is not written again
+		assert.Equal(t, 0, stateWrites)
+	})
+
+	t.Run("password rotation", func(t *testing.T) {
+		secret, err := clientset.CoreV1().Secrets(clusterInfo.Namespace).Get(ctx, "alice-password", metav1.GetOptions{})
+		assert.NoError(t, err)
+		secret.Data["password"] = []byte("secret2")
+		_, err = clientset.CoreV1().Secrets(clusterInfo.Namespace).Update(ctx, secret, metav1.UpdateOptions{})
+		assert.NoError(t, err)
+
+		bobPassword := dashboard.passwords["bob@example.com"]
+		assert.NoError(t, c.configureDashboardAccess())
+		assert.Equal(t, "secret2", dashboard.passwords["alice"])
+		// the generated password is not rotated
+		assert.Equal(t, bobPassword, dashboard.passwords["bob@example.com"])
+	})
+
+	t.Run("update and remove roles and users", func(t *testing.T) {
+		c.spec.Dashboard.Roles[0].Scopes = map[string][]string{"pool": {"read", "create"}}
+		c.spec.Dashboard.Users = c.spec.Dashboard.Users[:1]
+		assert.NoError(t, c.configureDashboardAccess())
+		assert.Equal(t, map[string][]string{"pool": {"create", "read"}}, dashboard.roles["pool-viewer"].ScopesPermissions)
+		assert.NotContains(t, dashboard.users, "bob@example.com")
+		assert.Contains(t, dashboard.users, "admin")
+
+		c.spec.Dashboard.Roles = nil
+		c.spec.Dashboard.Users[0].Roles = nil
+		assert.NoError(t, c.configureDashboardAccess())
+		assert.NotContains(t, dashboard.roles, "pool-viewer")
+		assert.Equal(t, 0, len(dashboard.users["alice"].Roles))
+	})
+
+	t.Run("invalid users and roles", func(t *testing.T) {
+		c.spec.Dashboard.Users = []cephv1.DashboardUserSpec{{Name: "admin"}}
+		assert.Error(t, c.configureDashboardAccess())
+
+		c.spec.Dashboard.Users = nil
+		c.spec.Dashboard.Roles = []cephv1.DashboardRoleSpec{{Name: "administrator"}}
+		assert.Error(t, c.configureDashboardAccess())
+		c.spec.Dashboard.Roles = nil
+	})
+
+	t.Run("sso", func(t *testing.T) {
+		c.spec.Dashboard.SSO = &cephv1.DashboardSSOSpec{BaseURL: "https://dashboard.example.com", IdPMetadataSecretName: "idp"}
+		// the metadata secret does not exist
+		assert.Error(t, c.configureDashboardAccess())
+
+		createSecret("idp", "metadata", "<EntityDescriptor/>")
+		assert.NoError(t, c.configureDashboardAccess())
+		assert.Equal(t, []string{"setup", "enable"}, dashboard.sso)
+
+		// nothing changes
+		assert.NoError(t, c.configureDashboardAccess())
+		assert.Equal(t, []string{"setup", "enable"}, dashboard.sso)
+
+		// the configuration is applied again after a change
+		c.spec.Dashboard.SSO.UsernameAttribute = "email"
+		assert.NoError(t, c.configureDashboardAccess())
+		assert.Equal(t, []string{"setup", "enable", "setup", "enable"}, dashboard.sso)
+
+		c.spec.Dashboard.SSO = nil
+		assert.NoError(t, c.configureDashboardAccess())
+		assert.Equal(t, []string{"setup", "enable", "setup", "enable", "disable"}, dashboard.sso)
+	})
+}
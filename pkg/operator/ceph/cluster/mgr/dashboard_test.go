@@ -17,6 +17,7 @@ package mgr
 
 import (
 	"context"
+	"io/ioutil"
 	"testing"
 	"time"
 
@@ -29,6 +30,8 @@ import (
 	exectest "github.com/rook/rook/pkg/util/exec/test"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -184,3 +187,134 @@ func TestFileBasedPasswordSupported(t *testing.T) {
 	value = FileBasedPasswordSupported(clusterInfo)
 	assert.False(t, value)
 }
+
+func TestConfigureDashboardIngress(t *testing.T) {
+	ctx := context.TODO()
+	clientset := test.New(t, 1)
+	ownerInfo := cephclient.NewMinimumOwnerInfoWithOwnerRef()
+	clusterInfo := &cephclient.ClusterInfo{Namespace: "myns", OwnerInfo: ownerInfo}
+	c := &Cluster{clusterInfo: clusterInfo, context: &clusterd.Context{Clientset: clientset},
+		spec: cephv1.ClusterSpec{Dashboard: cephv1.DashboardSpec{Enabled: true, SSL: true}},
+	}
+
+	// no ingress is created unless requested
+	assert.NoError(t, c.configureDashboardService("a"))
+	_, err := clientset.NetworkingV1().Ingresses(clusterInfo.Namespace).Get(ctx, "rook-ceph-mgr-dashboard", metav1.GetOptions{})
+	assert.True(t, kerrors.IsNotFound(err))
+
+	// an ingress that was not created by rook is left alone
+	other := &networkingv1.Ingress{ObjectMeta: metav1.ObjectMeta{Name: "rook-ceph-mgr-dashboard", Namespace: clusterInfo.Namespace}}
+	_, err = clientset.NetworkingV1().Ingresses(clusterInfo.Namespace).Create(ctx, other, metav1.CreateOptions{})
+	assert.NoError(t, err)
+	assert.NoError(t, c.configureDashboardService("a"))
+	_, err = clientset.NetworkingV1().Ingresses(clusterInfo.Namespace).Get(ctx, "rook-ceph-mgr-dashboard", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.NoError(t, clientset.NetworkingV1().Ingresses(clusterInfo.Namespace).Delete(ctx, "rook-ceph-mgr-dashboard", metav1.DeleteOptions{}))
+
+	className := "nginx"
+	c.spec.Dashboard.Ingress = &cephv1.DashboardIngressSpec{
+		Host:             "dashboard.example.com",
+		IngressClassName: &className,
+		Annotations:      map[string]string{"nginx.ingress.kubernetes.io/backend-protocol": "HTTPS"},
+		TLSSecretName:    "dashboard-tls",
+	}
+	assert.NoError(t, c.configureDashboardService("a"))
+	ingress, err := clientset.NetworkingV1().Ingresses(clusterInfo.Namespace).Get(ctx, "rook-ceph-mgr-dashboard", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "nginx", *ingress.Spec.IngressClassName)
+	assert.Equal(t, "HTTPS", ingress.Annotations["nginx.ingress.kubernetes.io/backend-protocol"])
+	assert.Equal(t, "dashboard.example.com", ingress.Spec.Rules[0].Host)
+	path := ingress.Spec.Rules[0].HTTP.Paths[0]
+	assert.Equal(t, "/", path.Path)
+	assert.Equal(t, "rook-ceph-mgr-dashboard", path.Backend.Service.Name)
+	assert.Equal(t, "https-dashboard", path.Backend.Service.Port.Name)
+	assert.Equal(t, "dashboard-tls", ingress.Spec.TLS[0].SecretName)
+
+	// the ingress is updated
+	c.spec.Dashboard.URLPrefix = "/ceph"
+	c.spec.Dashboard.Ingress.TLSSecretName = ""
+	assert.NoError(t, c.configureDashboardService("a"))
+	ingress, err = clientset.NetworkingV1().Ingresses(clusterInfo.Namespace).Get(ctx, "rook-ceph-mgr-dashboard", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "/ceph", ingress.Spec.Rules[0].HTTP.Paths[0].Path)
+	assert.Equal(t, 0, len(ingress.Spec.TLS))
+
+	// the ingress is removed with the dashboard
+	c.spec.Dashboard.Enabled = false
+	assert.NoError(t, c.configureDashboardService("a"))
+	_, err = clientset.NetworkingV1().Ingresses(clusterInfo.Namespace).Get(ctx, "rook-ceph-mgr-dashboard", metav1.GetOptions{})
+	assert.True(t, kerrors.IsNotFound(err))
+}
+
+func TestConfigureDashboardCert(t *testing.T) {
+	ctx := context.TODO()
+	configKeys := map[string]string{}
+	selfSigned := 0
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithTimeout: func(timeout time.Duration, command string, args ...string) (string, error) {
+			switch {
+			case args[0] == "config-key" && args[1] == "get":
+				value, ok := configKeys[args[2]]
+				if !ok {
+					return "", errors.New("ENOENT")
+				}
+				return value, nil
+			case args[0] == "dashboard" && args[1] == "create-self-signed-cert":
+				selfSigned++
+			case args[0] == "dashboard" && (args[1] == "set-ssl-certificate" || args[1] == "set-ssl-certificate-key"):
+				b, err := ioutil.ReadFile(args[3])
+				assert.NoError(t, err)
+				key := map[string]string{"set-ssl-certificate": "mgr/dashboard/crt", "set-ssl-certificate-key": "mgr/dashboard/key"}[args[1]]
+				configKeys[key] = string(b)
+			}
+			return "", nil
+		},
+	}
+	clientset := test.New(t, 1)
+	ownerInfo := cephclient.NewMinimumOwnerInfoWithOwnerRef()
+	clusterInfo := &cephclient.ClusterInfo{Namespace: "myns", CephVersion: cephver.Pacific, OwnerInfo: ownerInfo}
+	c := &Cluster{clusterInfo: clusterInfo, context: &clusterd.Context{Clientset: clientset, Executor: executor},
+		spec: cephv1.ClusterSpec{Dashboard: cephv1.DashboardSpec{Enabled: true, SSL: true, CertificateSecretName: "dashboard-cert"}},
+	}
+	c.exitCode = func(err error) (int, bool) { return 0, false }
+	dashboardInitWaitTime = 0
+
+	// the secret does not exist
+	_, err := c.initializeSecureDashboard()
+	assert.Error(t, err)
+
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "dashboard-cert", Namespace: clusterInfo.Namespace},
+		Type:       v1.SecretTypeTLS,
+		Data:       map[string][]byte{v1.TLSCertKey: []byte("cert1"), v1.TLSPrivateKeyKey: []byte("key1")},
+	}
+	_, err = clientset.CoreV1().Secrets(clusterInfo.Namespace).Create(ctx, secret, metav1.CreateOptions{})
+	assert.NoError(t, err)
+
+	changed, err := c.initializeSecureDashboard()
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, "cert1", configKeys["mgr/dashboard/crt"])
+	assert.Equal(t, "key1", configKeys["mgr/dashboard/key"])
+	assert.Equal(t, 0, selfSigned)
+
+	// nothing changes
+	changed, err = c.initializeSecureDashboard()
+	assert.NoError(t, err)
+	assert.False(t, changed)
+
+	// the certificate is rotated
+	secret.Data[v1.TLSCertKey] = []byte("cert2")
+	_, err = clientset.CoreV1().Secrets(clusterInfo.Namespace).Update(ctx, secret, metav1.UpdateOptions{})
+	assert.NoError(t, err)
+	changed, err = c.initializeSecureDashboard()
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, "cert2", configKeys["mgr/dashboard/crt"])
+
+	// the self-signed cert is generated without a secret
+	c.spec.Dashboard.CertificateSecretName = ""
+	_, err = c.initializeSecureDashboard()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, selfSigned)
+}
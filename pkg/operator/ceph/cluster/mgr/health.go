@@ -0,0 +1,232 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mgr
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	mgrFailoverReason = "MgrFailover"
+	// mgrModuleErrorCheck is the health check raised when a mgr module failed
+	mgrModuleErrorCheck = "MGR_MODULE_ERROR"
+)
+
+var (
+	// HealthCheckInterval is the interval to check the health of the active mgr
+	HealthCheckInterval = 60 * time.Second
+	// metricsRequestTimeout is the time to wait for the metrics endpoint to respond
+	metricsRequestTimeout = 15 * time.Second
+)
+
+// HealthChecker checks the health of the active mgr and fails it over when it is stuck
+type HealthChecker struct {
+	context     *clusterd.Context
+	clusterInfo *cephclient.ClusterInfo
+	spec        cephv1.HealthCheckSpec
+	recorder    *k8sutil.EventReporter
+	interval    time.Duration
+	timeout     time.Duration
+	metricsURL  string
+	httpClient  *http.Client
+	// unhealthySince is when the active mgr was first seen unhealthy, zero if it is healthy
+	unhealthySince time.Time
+	unhealthyMgr   string
+	// metricsReachable is whether the metrics endpoint has ever responded, the endpoint is only
+	// considered a health signal once the operator is known to be able to reach it
+	metricsReachable bool
+	// lastActive and lastEpoch are the active mgr and mgr map epoch of the previous check
+	lastActive string
+	lastEpoch  int
+}
+
+// NewHealthChecker creates a new HealthChecker object. The active mgr is only failed over when a timeout is set.
+func NewHealthChecker(context *clusterd.Context, clusterInfo *cephclient.ClusterInfo, spec cephv1.HealthCheckSpec, recorder *k8sutil.EventReporter) *HealthChecker {
+	hc := &HealthChecker{
+		context:     context,
+		clusterInfo: clusterInfo,
+		spec:        spec,
+		recorder:    recorder,
+		interval:    HealthCheckInterval,
+		metricsURL:  fmt.Sprintf("http://%s.%s.svc:%d/metrics", AppName, clusterInfo.Namespace, DefaultMetricsPort),
+		httpClient:  &http.Client{Timeout: metricsRequestTimeout},
+	}
+	if spec.Interval != nil {
+		hc.interval = spec.Interval.Duration
+	}
+	if spec.Timeout != "" {
+		if timeout, err := time.ParseDuration(spec.Timeout); err == nil {
+			hc.timeout = timeout
+		} else {
+			logger.Warningf("invalid mgr health check timeout %q, mgr failover is disabled. %v", spec.Timeout, err)
+		}
+	}
+	return hc
+}
+
+// Check periodically checks the health of the active mgr
+func (hc *HealthChecker) Check(stopCh chan struct{}) {
+	for {
+		select {
+		case <-stopCh:
+			logger.Infof("stopping monitoring of mgr in namespace %q", hc.clusterInfo.Namespace)
+			return
+
+		case <-time.After(hc.interval):
+			logger.Debug("checking health of mgr")
+			if err := hc.checkHealth(); err != nil {
+				logger.Warningf("failed to check mgr health. %v", err)
+			}
+		}
+	}
+}
+
+func (hc *HealthChecker) checkHealth() error {
+	mgrStat, err := cephclient.CephMgrStat(hc.context, hc.clusterInfo)
+	if err != nil {
+		// the mons may be down, which is not a reason to fail the mgr
+		return errors.Wrap(err, "failed to get mgr stat")
+	}
+	if mgrStat.ActiveName == "" {
+		logger.Debug("no active mgr to check")
+		hc.setHealthy()
+		return nil
+	}
+
+	problem := hc.mgrProblem(mgrStat)
+	hc.lastActive, hc.lastEpoch = mgrStat.ActiveName, mgrStat.Epoch
+	if problem == "" {
+		if !hc.unhealthySince.IsZero() {
+			logger.Infof("mgr %q is healthy again", mgrStat.ActiveName)
+		}
+		hc.setHealthy()
+		return nil
+	}
+
+	if hc.unhealthyMgr != mgrStat.ActiveName || hc.unhealthySince.IsZero() {
+		// start counting from the first time this mgr is seen unhealthy
+		hc.unhealthyMgr = mgrStat.ActiveName
+		hc.unhealthySince = time.Now()
+	}
+	unhealthyFor := time.Since(hc.unhealthySince)
+	logger.Warningf("active mgr %q is unhealthy for %s: %s", mgrStat.ActiveName, unhealthyFor.Round(time.Second).String(), problem)
+
+	if hc.timeout == 0 {
+		logger.Debug("mgr failover is disabled, set the mgr health check timeout to enable it")
+		return nil
+	}
+	if unhealthyFor < hc.timeout {
+		logger.Infof("mgr %q will be failed over if unhealthy for more than %s", mgrStat.ActiveName, hc.timeout.String())
+		return nil
+	}
+
+	if mgrStat.NumStandby == 0 {
+		logger.Warningf("no standby mgr is available, mgr %q will restart after it is failed", mgrStat.ActiveName)
+	}
+	logger.Infof("failing mgr %q since it is unhealthy for more than %s", mgrStat.ActiveName, hc.timeout.String())
+	if err := cephclient.MgrFail(hc.context, hc.clusterInfo, mgrStat.ActiveName); err != nil {
+		return err
+	}
+	hc.reportFailover(mgrStat.ActiveName, problem)
+	hc.setHealthy()
+	return nil
+}
+
+// mgrProblem returns why the active mgr is unhealthy, or an empty string if it is healthy
+func (hc *HealthChecker) mgrProblem(mgrStat *cephclient.MgrStat) string {
+	if !mgrStat.Available {
+		return "mgr is not available"
+	}
+
+	if err := hc.checkMetrics(); err != nil {
+		if !hc.metricsReachable {
+			logger.Debugf("ignoring the mgr metrics endpoint that was never reachable. %v", err)
+			return ""
+		}
+		return err.Error()
+	}
+	hc.metricsReachable = true
+
+	return hc.staleProblem(mgrStat)
+}
+
+// staleProblem returns why the active mgr is stale, or an empty string if it is not. The mgr is stale when a
+// module failed and the same mgr stays active with an unchanged mgr map, so nothing restarts the module.
+func (hc *HealthChecker) staleProblem(mgrStat *cephclient.MgrStat) string {
+	if mgrStat.ActiveName != hc.lastActive || mgrStat.Epoch != hc.lastEpoch {
+		return ""
+	}
+
+	status, err := cephclient.Status(hc.context, hc.clusterInfo)
+	if err != nil {
+		logger.Debugf("failed to get ceph status to check the mgr modules. %v", err)
+		return ""
+	}
+	check, ok := status.Health.Checks[mgrModuleErrorCheck]
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("mgr is stale since mgr map epoch %d: %s", mgrStat.Epoch, check.Summary.Message)
+}
+
+func (hc *HealthChecker) checkMetrics() error {
+	ctx, cancel := context.WithTimeout(context.TODO(), metricsRequestTimeout)
+	defer cancel()
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, hc.metricsURL, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to create metrics request")
+	}
+	response, err := hc.httpClient.Do(request)
+	if err != nil {
+		return errors.Wrap(err, "metrics endpoint failed")
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return errors.Errorf("metrics endpoint returned status %d", response.StatusCode)
+	}
+	return nil
+}
+
+func (hc *HealthChecker) setHealthy() {
+	hc.unhealthySince = time.Time{}
+	hc.unhealthyMgr = ""
+}
+
+func (hc *HealthChecker) reportFailover(name, problem string) {
+	if hc.recorder == nil {
+		return
+	}
+	clusterName := hc.clusterInfo.NamespacedName()
+	cephCluster, err := hc.context.RookClientset.CephV1().CephClusters(clusterName.Namespace).Get(context.TODO(), clusterName.Name, metav1.GetOptions{})
+	if err != nil {
+		logger.Errorf("failed to get ceph cluster %q to report the mgr failover. %v", clusterName.String(), err)
+		return
+	}
+	message := fmt.Sprintf("failed over mgr %q after it was unhealthy for more than %s: %s", name, hc.timeout.String(), problem)
+	hc.recorder.ReportIfNotPresent(cephCluster, v1.EventTypeWarning, mgrFailoverReason, message)
+}
@@ -0,0 +1,165 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mgr
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	rookclient "github.com/rook/rook/pkg/client/clientset/versioned/fake"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestNewHealthChecker(t *testing.T) {
+	clusterInfo := &cephclient.ClusterInfo{Namespace: "rook-ceph"}
+
+	// the failover is disabled by default
+	hc := NewHealthChecker(&clusterd.Context{}, clusterInfo, cephv1.HealthCheckSpec{}, nil)
+	assert.Equal(t, HealthCheckInterval, hc.interval)
+	assert.Equal(t, time.Duration(0), hc.timeout)
+	assert.Equal(t, "http://rook-ceph-mgr.rook-ceph.svc:9283/metrics", hc.metricsURL)
+
+	spec := cephv1.HealthCheckSpec{Interval: &metav1.Duration{Duration: 10 * time.Second}, Timeout: "10m"}
+	hc = NewHealthChecker(&clusterd.Context{}, clusterInfo, spec, nil)
+	assert.Equal(t, 10*time.Second, hc.interval)
+	assert.Equal(t, 10*time.Minute, hc.timeout)
+
+	// an invalid timeout disables the failover
+	spec.Timeout = "invalid"
+	hc = NewHealthChecker(&clusterd.Context{}, clusterInfo, spec, nil)
+	assert.Equal(t, time.Duration(0), hc.timeout)
+}
+
+func TestMgrHealthCheck(t *testing.T) {
+	metricsStatus := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(metricsStatus)
+	}))
+	defer server.Close()
+
+	mgrStat := `{"epoch":10,"available":true,"active_name":"a","num_standby":1}`
+	status := `{"health":{"status":"HEALTH_OK","checks":{}}}`
+	failed := []string{}
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutput: func(command string, args ...string) (string, error) {
+			logger.Infof("Command: %s %v", command, args)
+			if args[0] == "mgr" && args[1] == "stat" {
+				return mgrStat, nil
+			}
+			if args[0] == "mgr" && args[1] == "fail" {
+				failed = append(failed, args[2])
+				return "", nil
+			}
+			if args[0] == "status" {
+				return status, nil
+			}
+			return "", errors.Errorf("unexpected ceph command %q", args)
+		},
+	}
+
+	cephCluster := &cephv1.CephCluster{ObjectMeta: metav1.ObjectMeta{Name: "my-cluster", Namespace: "rook-ceph"}}
+	context := &clusterd.Context{Executor: executor, RookClientset: rookclient.NewSimpleClientset(cephCluster)}
+	clusterInfo := cephclient.AdminClusterInfo("rook-ceph")
+	clusterInfo.SetName("my-cluster")
+	recorder := record.NewFakeRecorder(5)
+	timeout := 5 * time.Minute
+	hc := NewHealthChecker(context, clusterInfo, cephv1.HealthCheckSpec{Timeout: timeout.String()}, k8sutil.NewEventReporter(recorder))
+	hc.metricsURL = server.URL
+
+	// a healthy mgr
+	assert.NoError(t, hc.checkHealth())
+	assert.True(t, hc.metricsReachable)
+	assert.True(t, hc.unhealthySince.IsZero())
+
+	// the metrics endpoint fails, but not for long enough to fail over
+	metricsStatus = http.StatusServiceUnavailable
+	assert.NoError(t, hc.checkHealth())
+	assert.False(t, hc.unhealthySince.IsZero())
+	assert.Equal(t, "a", hc.unhealthyMgr)
+	assert.Equal(t, 0, len(failed))
+
+	// the mgr recovers
+	metricsStatus = http.StatusOK
+	assert.NoError(t, hc.checkHealth())
+	assert.True(t, hc.unhealthySince.IsZero())
+
+	// the mgr is unavailable for longer than the timeout
+	mgrStat = `{"epoch":11,"available":false,"active_name":"a","num_standby":1}`
+	assert.NoError(t, hc.checkHealth())
+	hc.unhealthySince = time.Now().Add(-2 * timeout)
+	assert.NoError(t, hc.checkHealth())
+	assert.Equal(t, []string{"a"}, failed)
+	assert.True(t, hc.unhealthySince.IsZero())
+	assert.Equal(t, 1, len(recorder.Events))
+	assert.Contains(t, <-recorder.Events, mgrFailoverReason)
+
+	// a new active mgr restarts the timer
+	hc.unhealthySince = time.Now().Add(-2 * timeout)
+	hc.unhealthyMgr = "a"
+	mgrStat = `{"epoch":12,"available":false,"active_name":"b","num_standby":1}`
+	assert.NoError(t, hc.checkHealth())
+	assert.Equal(t, "b", hc.unhealthyMgr)
+	assert.Equal(t, []string{"a"}, failed)
+
+	// a module failed while the same mgr stays active
+	mgrStat = `{"epoch":13,"available":true,"active_name":"b","num_standby":1}`
+	status = `{"health":{"status":"HEALTH_ERR","checks":{"MGR_MODULE_ERROR":{"severity":"HEALTH_ERR","summary":{"message":"Module 'prometheus' has failed"}}}}}`
+	assert.NoError(t, hc.checkHealth())
+	assert.True(t, hc.unhealthySince.IsZero())
+	assert.NoError(t, hc.checkHealth())
+	assert.Equal(t, "b", hc.unhealthyMgr)
+	hc.unhealthySince = time.Now().Add(-2 * timeout)
+	assert.NoError(t, hc.checkHealth())
+	assert.Equal(t, []string{"a", "b"}, failed)
+
+	// failover is disabled
+	hc.timeout = 0
+	hc.unhealthySince = time.Now().Add(-2 * timeout)
+	assert.NoError(t, hc.checkHealth())
+	assert.Equal(t, []string{"a", "b"}, failed)
+}
+
+func TestMgrHealthCheckUnreachableMetrics(t *testing.T) {
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutput: func(command string, args ...string) (string, error) {
+			if args[0] == "mgr" && args[1] == "stat" {
+				return `{"epoch":10,"available":true,"active_name":"a","num_standby":1}`, nil
+			}
+			return "", errors.Errorf("unexpected ceph command %q", args)
+		},
+	}
+	clusterInfo := cephclient.AdminClusterInfo("rook-ceph")
+	hc := NewHealthChecker(&clusterd.Context{Executor: executor}, clusterInfo, cephv1.HealthCheckSpec{}, nil)
+	server := httptest.NewServer(http.NotFoundHandler())
+	hc.metricsURL = server.URL
+	server.Close()
+
+	// the metrics endpoint was never reachable from the operator so it is not a health signal
+	assert.NoError(t, hc.checkHealth())
+	assert.False(t, hc.metricsReachable)
+	assert.True(t, hc.unhealthySince.IsZero())
+}
@@ -21,6 +21,7 @@ import (
 	"context"
 	"fmt"
 	"path"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -395,6 +396,11 @@ func (c *Cluster) configureMgrModules() error {
 				}
 			}
 
+			// Apply the settings before the module is enabled so it starts with the expected configuration
+			if err := c.configureModuleSettings(module); err != nil {
+				return errors.Wrapf(err, "failed to configure settings for mgr module %q", module.Name)
+			}
+
 			if err := cephclient.MgrEnableModule(c.context, c.clusterInfo, module.Name, false); err != nil {
 				return errors.Wrapf(err, "failed to enable mgr module %q", module.Name)
 			}
@@ -426,6 +432,29 @@ func (c *Cluster) configureMgrModules() error {
 	return nil
 }
 
+// configureModuleSettings sets the module settings from the spec in the centralized mon configuration database
+func (c *Cluster) configureModuleSettings(module cephv1.Module) error {
+	// Sort the keys so the settings are always applied in the same order
+	keys := make([]string, 0, len(module.Settings))
+	for key := range module.Settings {
+		if key == "" {
+			return errors.Errorf("setting name not specified for mgr module %q", module.Name)
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	monStore := config.GetMonStore(c.context, c.clusterInfo)
+	for _, key := range keys {
+		option := fmt.Sprintf("mgr/%s/%s", module.Name, key)
+		if _, err := monStore.SetIfChanged("mgr", option, module.Settings[key]); err != nil {
+			return errors.Wrapf(err, "failed to set mgr module option %q", option)
+		}
+	}
+
+	return nil
+}
+
 func (c *Cluster) moduleMeetsMinVersion(name string) (*cephver.CephVersion, bool) {
 	minVersions := map[string]cephver.CephVersion{
 		// Put the modules here, example:
@@ -244,6 +244,7 @@ func TestConfigureModules(t *testing.T) {
 	modulesEnabled := 0
 	modulesDisabled := 0
 	configSettings := map[string]string{}
+	mgrSettings := map[string]string{}
 	lastModuleConfigured := ""
 	executor := &exectest.MockExecutor{
 		MockExecuteCommandWithOutput: func(command string, args ...string) (string, error) {
@@ -261,6 +262,9 @@ func TestConfigureModules(t *testing.T) {
 				if args[0] == "config" && args[1] == "set" && args[2] == "global" {
 					configSettings[args[3]] = args[4]
 				}
+				if args[0] == "config" && args[1] == "set" && args[2] == "mgr" {
+					mgrSettings[args[3]] = args[4]
+				}
 			}
 			return "", nil //return "{\"key\":\"mysecurekey\"}", nil
 		},
@@ -312,6 +316,30 @@ func TestConfigureModules(t *testing.T) {
 	assert.Equal(t, 1, modulesDisabled)
 	assert.Equal(t, "pg_autoscaler", lastModuleConfigured)
 	assert.Equal(t, 0, len(configSettings))
+
+	// module settings are applied to the mgr section
+	modulesEnabled = 0
+	c.spec.Mgr.Modules = []cephv1.Module{
+		{Name: "telemetry", Enabled: true, Settings: map[string]string{"channel_basic": "true", "contact": "admin@example.com"}},
+	}
+	assert.NoError(t, c.configureMgrModules())
+	assert.Equal(t, 1, modulesEnabled)
+	assert.Equal(t, "telemetry", lastModuleConfigured)
+	assert.Equal(t, 2, len(mgrSettings))
+	assert.Equal(t, "true", mgrSettings["mgr/telemetry/channel_basic"])
+	assert.Equal(t, "admin@example.com", mgrSettings["mgr/telemetry/contact"])
+
+	// settings are not applied to disabled modules
+	mgrSettings = map[string]string{}
+	c.spec.Mgr.Modules[0].Enabled = false
+	assert.NoError(t, c.configureMgrModules())
+	assert.Equal(t, 0, len(mgrSettings))
+
+	// an empty setting name is rejected
+	c.spec.Mgr.Modules = []cephv1.Module{
+		{Name: "telemetry", Enabled: true, Settings: map[string]string{"": "true"}},
+	}
+	assert.Error(t, c.configureMgrModules())
 }
 
 func TestMgrDaemons(t *testing.T) {
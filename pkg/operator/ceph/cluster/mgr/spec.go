@@ -32,6 +32,7 @@ import (
 	"github.com/rook/rook/pkg/operator/k8sutil"
 	apps "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
@@ -94,12 +95,10 @@ func (c *Cluster) makeDeployment(mgrConfig *mgrConfig) (*apps.Deployment, error)
 	// Replace default unreachable node toleration
 	k8sutil.AddUnreachableNodeToleration(&podSpec.Spec)
 
-	if c.spec.Network.IsHost() {
-		podSpec.Spec.DNSPolicy = v1.DNSClusterFirstWithHostNet
-	} else if c.spec.Network.IsMultus() {
-		if err := k8sutil.ApplyMultus(c.spec.Network, &podSpec.ObjectMeta); err != nil {
-			return nil, err
-		}
+	if err := k8sutil.ApplyNetworkSettings(c.spec.Network, &podSpec.ObjectMeta, &podSpec.Spec); err != nil {
+		return nil, err
+	}
+	if c.spec.Network.IsMultus() {
 		podSpec.Spec.Containers = append(podSpec.Spec.Containers, c.makeCmdProxySidecarContainer(mgrConfig))
 	}
 
@@ -332,6 +331,57 @@ func (c *Cluster) makeDashboardService(name, activeDaemon string) (*v1.Service,
 	return svc, nil
 }
 
+func (c *Cluster) makeDashboardIngress(service *v1.Service) (*networkingv1.Ingress, error) {
+	ingressSpec := c.spec.Dashboard.Ingress
+	path := "/"
+	if c.spec.Dashboard.URLPrefix != "" {
+		path = c.spec.Dashboard.URLPrefix
+	}
+	pathType := networkingv1.PathTypePrefix
+	ingress := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        service.Name,
+			Namespace:   c.clusterInfo.Namespace,
+			Labels:      service.Labels,
+			Annotations: ingressSpec.Annotations,
+		},
+		Spec: networkingv1.IngressSpec{
+			IngressClassName: ingressSpec.IngressClassName,
+			Rules: []networkingv1.IngressRule{
+				{
+					Host: ingressSpec.Host,
+					IngressRuleValue: networkingv1.IngressRuleValue{
+						HTTP: &networkingv1.HTTPIngressRuleValue{
+							Paths: []networkingv1.HTTPIngressPath{
+								{
+									Path:     path,
+									PathType: &pathType,
+									Backend: networkingv1.IngressBackend{
+										Service: &networkingv1.IngressServiceBackend{
+											Name: service.Name,
+											Port: networkingv1.ServiceBackendPort{Name: service.Spec.Ports[0].Name},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	if ingressSpec.TLSSecretName != "" {
+		ingress.Spec.TLS = []networkingv1.IngressTLS{
+			{Hosts: []string{ingressSpec.Host}, SecretName: ingressSpec.TLSSecretName},
+		}
+	}
+	err := c.clusterInfo.OwnerInfo.SetControllerReference(ingress)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to set owner reference to dashboard ingress %q", ingress.Name)
+	}
+	return ingress, nil
+}
+
 func (c *Cluster) getPodLabels(daemonName string, includeNewLabels bool) map[string]string {
 	labels := controller.CephDaemonAppLabels(AppName, c.clusterInfo.Namespace, "mgr", daemonName, includeNewLabels)
 	// leave "instance" key for legacy usage
@@ -233,7 +233,7 @@ func (c *Cluster) startMons(targetCount int) error {
 	// only once and do it as early as possible in the mon orchestration.
 	setConfigsNeedsRetry := false
 	if existingCount > 0 {
-		err := config.SetOrRemoveDefaultConfigs(c.context, c.ClusterInfo, c.spec)
+		err := config.SetOrRemoveDefaultConfigs(c.context, c.ClusterInfo, c.spec, csi.ClientsSecureMode())
 		if err != nil {
 			// If we fail here, it could be because the mons are not healthy, and this might be
 			// fixed by updating the mon deployments. Instead of returning error here, log a
@@ -259,7 +259,7 @@ func (c *Cluster) startMons(targetCount int) error {
 			// values in the config database. Do this only when the existing count is zero so that
 			// this is only done once when the cluster is created.
 			if existingCount == 0 {
-				err := config.SetOrRemoveDefaultConfigs(c.context, c.ClusterInfo, c.spec)
+				err := config.SetOrRemoveDefaultConfigs(c.context, c.ClusterInfo, c.spec, csi.ClientsSecureMode())
 				if err != nil {
 					return errors.Wrap(err, "failed to set Rook and/or user-defined Ceph config options after creating the first mon")
 				}
@@ -267,7 +267,7 @@ func (c *Cluster) startMons(targetCount int) error {
 				// Or if we need to retry, only do this when we are on the first iteration of the
 				// loop. This could be in the same if statement as above, but separate it to get a
 				// different error message.
-				err := config.SetOrRemoveDefaultConfigs(c.context, c.ClusterInfo, c.spec)
+				err := config.SetOrRemoveDefaultConfigs(c.context, c.ClusterInfo, c.spec, csi.ClientsSecureMode())
 				if err != nil {
 					return errors.Wrap(err, "failed to set Rook and/or user-defined Ceph config options after updating the existing mons")
 				}
@@ -281,7 +281,7 @@ func (c *Cluster) startMons(targetCount int) error {
 		}
 
 		if setConfigsNeedsRetry {
-			err := config.SetOrRemoveDefaultConfigs(c.context, c.ClusterInfo, c.spec)
+			err := config.SetOrRemoveDefaultConfigs(c.context, c.ClusterInfo, c.spec, csi.ClientsSecureMode())
 			if err != nil {
 				return errors.Wrap(err, "failed to set Rook and/or user-defined Ceph config options after forcefully updating the existing mons")
 			}
@@ -479,6 +479,7 @@ func (c *Cluster) initClusterInfo(cephVersion cephver.CephVersion) error {
 
 	c.ClusterInfo.CephVersion = cephVersion
 	c.ClusterInfo.OwnerInfo = c.ownerInfo
+	c.ClusterInfo.NetworkSpec = c.spec.Network
 
 	// save cluster monitor config
 	if err = c.saveMonConfig(); err != nil {
@@ -21,6 +21,7 @@ import (
 	"strconv"
 
 	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
 	"github.com/rook/rook/pkg/operator/k8sutil"
 	v1 "k8s.io/api/core/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
@@ -58,6 +59,12 @@ func (c *Cluster) createService(mon *monConfig) (string, error) {
 	// If deploying Nautilus or newer we need a new port for the monitor service
 	addServicePort(svcDef, "tcp-msgr2", DefaultMsgr2Port)
 
+	// Request the IP families the mons bind to rather than the default family of the service network
+	if c.spec.Network.IsIPFamilySet() {
+		svcDef.Spec.IPFamilies = c.spec.Network.IPFamilies()
+		svcDef.Spec.IPFamilyPolicy = ipFamilyPolicy(c.spec.Network)
+	}
+
 	// Set the ClusterIP if the service does not exist and we expect a certain cluster IP
 	// For example, in disaster recovery the service might have been deleted accidentally, but we have the
 	// expected endpoint from the mon configmap.
@@ -86,3 +93,12 @@ func (c *Cluster) createService(mon *monConfig) (string, error) {
 
 	return s.Spec.ClusterIP, nil
 }
+
+// ipFamilyPolicy returns the IP family policy of the services of the cluster network
+func ipFamilyPolicy(network cephv1.NetworkSpec) *v1.IPFamilyPolicyType {
+	policy := v1.IPFamilyPolicySingleStack
+	if network.DualStack {
+		policy = v1.IPFamilyPolicyRequireDualStack
+	}
+	return &policy
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/rook/rook/pkg/operator/k8sutil"
 	"github.com/rook/rook/pkg/operator/test"
 	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -53,4 +54,20 @@ func TestCreateService(t *testing.T) {
 	assert.NoError(t, err)
 	// the clusterIP will now be set to the expected value
 	assert.Equal(t, m.PublicIP, clusterIP)
+
+	// the service keeps the default family of the service network unless the cluster requests its families
+	svc, err := clientset.CoreV1().Services(c.Namespace).Get(ctx, m.ResourceName, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Empty(t, svc.Spec.IPFamilies)
+	assert.Nil(t, svc.Spec.IPFamilyPolicy)
+
+	// the service requests the families the mons bind to
+
+	c.spec.Network = cephv1.NetworkSpec{IPFamily: cephv1.IPv6, DualStack: true}
+	_, err = c.createService(m)
+	assert.NoError(t, err)
+	svc, err = clientset.CoreV1().Services(c.Namespace).Get(ctx, m.ResourceName, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, []v1.IPFamily{v1.IPv6Protocol, v1.IPv4Protocol}, svc.Spec.IPFamilies)
+	assert.Equal(t, v1.IPFamilyPolicyRequireDualStack, *svc.Spec.IPFamilyPolicy)
 }
@@ -210,12 +210,8 @@ func (c *Cluster) makeMonPod(monConfig *monConfig, canary bool) (*corev1.Pod, er
 	cephv1.GetMonAnnotations(c.spec.Annotations).ApplyToObjectMeta(&pod.ObjectMeta)
 	cephv1.GetMonLabels(c.spec.Labels).ApplyToObjectMeta(&pod.ObjectMeta)
 
-	if c.spec.Network.IsHost() {
-		pod.Spec.DNSPolicy = corev1.DNSClusterFirstWithHostNet
-	} else if c.spec.Network.IsMultus() {
-		if err := k8sutil.ApplyMultus(c.spec.Network, &pod.ObjectMeta); err != nil {
-			return nil, err
-		}
+	if err := k8sutil.ApplyNetworkSettings(c.spec.Network, &pod.ObjectMeta, &pod.Spec); err != nil {
+		return nil, err
 	}
 
 	if c.spec.IsStretchCluster() {
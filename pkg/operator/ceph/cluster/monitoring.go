@@ -20,13 +20,14 @@ package cluster
 import (
 	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
 	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/ceph/cluster/mgr"
 	"github.com/rook/rook/pkg/operator/ceph/cluster/mon"
 	"github.com/rook/rook/pkg/operator/ceph/cluster/osd"
 	"github.com/rook/rook/pkg/operator/ceph/object/bucket"
 )
 
 var (
-	monitorDaemonList = []string{"mon", "osd", "status"}
+	monitorDaemonList = []string{"mon", "osd", "status", "mgr"}
 )
 
 func (c *ClusterController) configureCephMonitoring(cluster *cluster, clusterInfo *cephclient.ClusterInfo) {
@@ -103,6 +104,9 @@ func isMonitoringDisabled(daemon string, clusterSpec *cephv1.ClusterSpec) bool {
 
 	case "status":
 		return clusterSpec.HealthCheck.DaemonHealth.Status.Disabled
+
+	case "mgr":
+		return clusterSpec.HealthCheck.DaemonHealth.Manager.Disabled
 	}
 
 	return false
@@ -126,5 +130,12 @@ func (c *ClusterController) startMonitoringCheck(cluster *cluster, clusterInfo *
 		cephChecker := newCephStatusChecker(c.context, clusterInfo, cluster.Spec)
 		logger.Infof("enabling ceph %s monitoring goroutine for cluster %q", daemon, cluster.Namespace)
 		go cephChecker.checkCephStatus(cluster.monitoringChannels[daemon].stopChan)
+
+	case "mgr":
+		if !cluster.Spec.External.Enable {
+			mgrChecker := mgr.NewHealthChecker(c.context, clusterInfo, cluster.Spec.HealthCheck.DaemonHealth.Manager, c.recorder)
+			logger.Infof("enabling ceph %s monitoring goroutine for cluster %q", daemon, cluster.Namespace)
+			go mgrChecker.Check(cluster.monitoringChannels[daemon].stopChan)
+		}
 	}
 }
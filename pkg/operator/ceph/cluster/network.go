@@ -0,0 +1,147 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/ceph/config"
+	"github.com/rook/rook/pkg/operator/ceph/controller"
+	"github.com/rook/rook/pkg/operator/ceph/csi"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// networkStatus returns the network status of the cluster. The previous status is kept for the checks that failed.
+func (c *cephStatusChecker) networkStatus(network cephv1.NetworkSpec, previous *cephv1.NetworkStatus) *cephv1.NetworkStatus {
+	status := &cephv1.NetworkStatus{}
+	if previous != nil {
+		*status = *previous
+	}
+
+	pods, err := checkPublicNetworkAttachments(context.TODO(), c.context.Clientset, c.clusterInfo.Namespace, os.Getenv(k8sutil.PodNamespaceEnvVar), network)
+	if err != nil {
+		logger.Errorf("failed to check the network attachments of the pods. %v", err)
+	} else {
+		status.PodsMissingPublicNetwork = pods
+	}
+
+	connections, err := checkConnectionsStatus(c.context, c.clusterInfo, network, csi.ClientsSecureMode())
+	if err != nil {
+		logger.Errorf("failed to check the connections modes. %v", err)
+	} else {
+		status.Connections = connections
+	}
+
+	return status
+}
+
+// checkPublicNetworkAttachments returns the running pods of the Ceph daemons and of the CSI driver that are not
+// attached to the public network of the cluster. These pods cannot reach the daemons when the clients are on an
+// isolated network, which is easily missed since the pods otherwise run fine.
+func checkPublicNetworkAttachments(ctx context.Context, clientset kubernetes.Interface, namespace, operatorNamespace string, network cephv1.NetworkSpec) ([]string, error) {
+	if !network.IsMultus() {
+		return nil, nil
+	}
+
+	// the pods of all the daemons have a daemon id, except the mon canaries which only check the scheduling
+	selector := fmt.Sprintf("%s=%s,%s,!mon_canary", k8sutil.ClusterAttr, namespace, controller.DaemonIDLabel)
+	pods, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list the ceph pods in namespace %q", namespace)
+	}
+	checked := pods.Items
+
+	if operatorNamespace != "" {
+		selector = fmt.Sprintf("%s in (%s)", k8sutil.AppAttr, strings.Join(csi.PodAppNames(), ","))
+		pods, err = clientset.CoreV1().Pods(operatorNamespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to list the csi pods in namespace %q", operatorNamespace)
+		}
+		checked = append(checked, pods.Items...)
+	}
+
+	missing := []string{}
+	for i := range checked {
+		pod := &checked[i]
+		if pod.Status.Phase != v1.PodRunning {
+			continue
+		}
+		attached, err := k8sutil.IsAttachedToPublicNetwork(network, namespace, pod)
+		if err != nil {
+			return nil, err
+		}
+		if !attached {
+			missing = append(missing, fmt.Sprintf("%s/%s", pod.Namespace, pod.Name))
+		}
+	}
+	sort.Strings(missing)
+
+	if len(missing) > 0 {
+		logger.Warningf("pods not attached to the public network of cluster %q: %v", namespace, missing)
+	}
+	return missing, nil
+}
+
+// checkConnectionsStatus reads the msgr2 modes of the connections from the mon configuration database. The modes
+// may differ from the spec when they are overridden with cephConfig.
+func checkConnectionsStatus(clusterdContext *clusterd.Context, clusterInfo *cephclient.ClusterInfo, network cephv1.NetworkSpec, clients config.ClientsSecureMode) (*cephv1.ConnectionsStatus, error) {
+	monStore := config.GetMonStore(clusterdContext, clusterInfo)
+	get := func(who, option string) (string, error) {
+		value, err := monStore.Get(who, option)
+		// the string options are quoted in the json output
+		return strings.Trim(value, `"`), err
+	}
+
+	status := &cephv1.ConnectionsStatus{}
+	var err error
+	// the modes of the connections between the daemons, accepted by the daemons and of the clients
+	if status.ClusterMode, err = get("osd", "ms_cluster_mode"); err != nil {
+		return nil, err
+	}
+	if status.ServiceMode, err = get("mon", "ms_service_mode"); err != nil {
+		return nil, err
+	}
+	if status.ClientMode, err = get("client", "ms_client_mode"); err != nil {
+		return nil, err
+	}
+	status.Encrypted = status.ClusterMode == config.Msgr2SecureMode && status.ServiceMode == config.Msgr2SecureMode && status.ClientMode == config.Msgr2SecureMode
+	if network.IsEncryptionEnabled() && !status.Encrypted {
+		if clients == config.ClientsSecureModeUnsupported {
+			status.Message = "the clients are not required to connect in the secure mode, the ceph-csi image does not support the kernel mount options of the secure mode"
+		}
+		logger.Warningf("encryption is enabled but the connections of cluster %q do not require the secure mode: %+v", clusterInfo.Namespace, *status)
+	}
+
+	if clusterInfo.CephVersion.IsAtLeastQuincy() {
+		if status.OSDCompressMode, err = get("osd", config.OSDCompressModeOption); err != nil {
+			return nil, err
+		}
+	}
+
+	return status, nil
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/ceph/config"
+	cephver "github.com/rook/rook/pkg/operator/ceph/version"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newNetworkPod(name, namespace string, labels map[string]string, networks string) *v1.Pod {
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels},
+		Status:     v1.PodStatus{Phase: v1.PodRunning},
+	}
+	if networks != "" {
+		pod.Annotations = map[string]string{"k8s.v1.cni.cncf.io/network-status": networks}
+	}
+	return pod
+}
+
+func TestCheckPublicNetworkAttachments(t *testing.T) {
+	ctx := context.TODO()
+	attached := `[{"name": "cbr0", "default": true}, {"name": "rook-ceph/public-net"}]`
+	daemonLabels := func(app string) map[string]string {
+		return map[string]string{"app": app, "rook_cluster": "rook-ceph", "ceph_daemon_id": "a"}
+	}
+	canaryLabels := daemonLabels("rook-ceph-mon")
+	canaryLabels["mon_canary"] = "true"
+	pending := newNetworkPod("rook-ceph-mds-myfs-a", "rook-ceph", daemonLabels("rook-ceph-mds"), "")
+	pending.Status.Phase = v1.PodPending
+
+	clientset := fake.NewSimpleClientset(
+		newNetworkPod("rook-ceph-mon-a", "rook-ceph", daemonLabels("rook-ceph-mon"), attached),
+		newNetworkPod("rook-ceph-nfs-my-nfs-a", "rook-ceph", daemonLabels("rook-ceph-nfs"), ""),
+		newNetworkPod("rook-ceph-rbd-mirror-a", "rook-ceph", daemonLabels("rook-ceph-rbd-mirror"), `[{"name": "cbr0", "default": true}]`),
+		newNetworkPod("rook-ceph-mon-canary", "rook-ceph", canaryLabels, ""),
+		pending,
+		newNetworkPod("rook-ceph-tools", "rook-ceph", map[string]string{"app": "rook-ceph-tools"}, ""),
+		newNetworkPod("csi-rbdplugin-xyz", "rook-ceph-system", map[string]string{"app": "csi-rbdplugin"}, attached),
+		newNetworkPod("csi-cephfsplugin-provisioner-xyz", "rook-ceph-system", map[string]string{"app": "csi-cephfsplugin-provisioner"}, ""),
+		newNetworkPod("rook-ceph-operator-xyz", "rook-ceph-system", map[string]string{"app": "rook-ceph-operator"}, ""),
+	)
+
+	// the attachments are only checked with multus
+	missing, err := checkPublicNetworkAttachments(ctx, clientset, "rook-ceph", "rook-ceph-system", cephv1.NetworkSpec{})
+	assert.NoError(t, err)
+	assert.Nil(t, missing)
+
+	network := cephv1.NetworkSpec{Provider: "multus", Selectors: map[string]string{"public": "public-net"}}
+	missing, err = checkPublicNetworkAttachments(ctx, clientset, "rook-ceph", "rook-ceph-system", network)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"rook-ceph-system/csi-cephfsplugin-provisioner-xyz",
+		"rook-ceph/rook-ceph-nfs-my-nfs-a",
+		"rook-ceph/rook-ceph-rbd-mirror-a",
+	}, missing)
+}
+
+func TestCheckConnectionsStatus(t *testing.T) {
+	// the mon configuration database, keyed by the option
+	monDB := map[string]string{"ms_cluster_mode": "secure", "ms_service_mode": "secure", "ms_client_mode": "crc secure", "ms_osd_compress_mode": "force"}
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutput: func(command string, args ...string) (string, error) {
+			if args[0] == "config" && args[1] == "get" {
+				if value, ok := monDB[args[3]]; ok {
+					return fmt.Sprintf("%q", value), nil
+				}
+			}
+			return "", errors.Errorf("unexpected ceph command %q", args)
+		},
+	}
+	context := &clusterd.Context{Executor: executor}
+	clusterInfo := cephclient.AdminClusterInfo("rook-ceph")
+	clusterInfo.CephVersion = cephver.Pacific
+	network := cephv1.NetworkSpec{Connections: &cephv1.ConnectionsSpec{Encryption: &cephv1.EncryptionSpec{Enabled: true}}}
+
+	// the clients still accept unencrypted connections
+	status, err := checkConnectionsStatus(context, clusterInfo, network, config.ClientsSecureModeSupported)
+	assert.NoError(t, err)
+	assert.Equal(t, cephv1.ConnectionsStatus{ClusterMode: "secure", ServiceMode: "secure", ClientMode: "crc secure"}, *status)
+
+	// the reason is reported when the csi clients do not support the secure mode
+	status, err = checkConnectionsStatus(context, clusterInfo, network, config.ClientsSecureModeUnsupported)
+	assert.NoError(t, err)
+	assert.False(t, status.Encrypted)
+	assert.Contains(t, status.Message, "ceph-csi")
+
+	// the compression is reported from quincy
+	monDB["ms_client_mode"] = "secure"
+	clusterInfo.CephVersion = cephver.Quincy
+	status, err = checkConnectionsStatus(context, clusterInfo, network, config.ClientsSecureModeSupported)
+	assert.NoError(t, err)
+	assert.True(t, status.Encrypted)
+	assert.Empty(t, status.Message)
+	assert.Equal(t, "force", status.OSDCompressMode)
+}
@@ -153,9 +153,6 @@ func (c *Cluster) provisionPodTemplateSpec(osdProps osdProperties, restart v1.Re
 		PriorityClassName: cephv1.GetOSDPriorityClassName(c.spec.PriorityClassNames),
 		SchedulerName:     osdProps.schedulerName,
 	}
-	if c.spec.Network.IsHost() {
-		podSpec.DNSPolicy = v1.DNSClusterFirstWithHostNet
-	}
 	if osdProps.onPVC() {
 		c.applyAllPlacementIfNeeded(&podSpec)
 		// apply storageClassDeviceSets.preparePlacement
@@ -181,6 +178,11 @@ func (c *Cluster) provisionPodTemplateSpec(osdProps osdProperties, restart v1.Re
 	cephv1.GetOSDPrepareAnnotations(c.spec.Annotations).ApplyToObjectMeta(&podMeta)
 	cephv1.GetOSDPrepareLabels(c.spec.Labels).ApplyToObjectMeta(&podMeta)
 
+	// the prepare job connects to the mons to register the OSDs
+	if err := k8sutil.ApplyNetworkSettings(c.spec.Network, &podMeta, &podSpec); err != nil {
+		return nil, err
+	}
+
 	// ceph-volume --dmcrypt uses cryptsetup that synchronizes with udev on
 	// host through semaphore
 	podSpec.HostIPC = osdProps.storeConfig.EncryptedDevice || osdProps.encrypted
@@ -657,12 +657,8 @@ func (c *Cluster) makeDeployment(osdProps osdProperties, osd OSDInfo, provisionC
 	// If the liveness probe is enabled
 	podTemplateSpec.Spec.Containers[0] = opconfig.ConfigureLivenessProbe(cephv1.KeyOSD, podTemplateSpec.Spec.Containers[0], c.spec.HealthCheck)
 
-	if c.spec.Network.IsHost() {
-		podTemplateSpec.Spec.DNSPolicy = v1.DNSClusterFirstWithHostNet
-	} else if c.spec.Network.IsMultus() {
-		if err := k8sutil.ApplyMultus(c.spec.Network, &podTemplateSpec.ObjectMeta); err != nil {
-			return nil, err
-		}
+	if err := k8sutil.ApplyNetworkSettings(c.spec.Network, &podTemplateSpec.ObjectMeta, &podTemplateSpec.Spec); err != nil {
+		return nil, err
 	}
 
 	k8sutil.RemoveDuplicateEnvVars(&podTemplateSpec.Spec)
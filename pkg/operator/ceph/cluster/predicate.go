@@ -18,6 +18,9 @@ limitations under the License.
 package cluster
 
 import (
+	"context"
+	"reflect"
+
 	"github.com/google/go-cmp/cmp"
 	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
 	"github.com/rook/rook/pkg/clusterd"
@@ -86,6 +89,94 @@ func predicateForHotPlugCMWatcher(client client.Client) predicate.Funcs {
 	}
 }
 
+// predicateForDashboardSecretWatcher is the predicate function to trigger a reconcile when the certificate,
+// user password or identity provider metadata secret of the dashboard is created or rotated
+func predicateForDashboardSecretWatcher(client client.Client) predicate.Funcs {
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return isDashboardCertSecret(client, e.Object) || isDashboardAccessSecret(client, e.Object)
+		},
+
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldSecret, ok := e.ObjectOld.(*corev1.Secret)
+			if !ok {
+				return false
+			}
+			newSecret, ok := e.ObjectNew.(*corev1.Secret)
+			if !ok {
+				return false
+			}
+			if reflect.DeepEqual(oldSecret.Data, newSecret.Data) {
+				return false
+			}
+			return isDashboardCertSecret(client, newSecret) || isDashboardAccessSecret(client, newSecret)
+		},
+
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return false
+		},
+
+		GenericFunc: func(e event.GenericEvent) bool {
+			return false
+		},
+	}
+}
+
+// isDashboardCertSecret informs whether the object is the secret with the dashboard certificate of a ceph cluster.
+// The secret may be of any type as long as it has the certificate and key, like the dashboard expects.
+func isDashboardCertSecret(c client.Client, obj runtime.Object) bool {
+	secret, ok := obj.(*corev1.Secret)
+	if !ok {
+		return false
+	}
+
+	cephClusters := &cephv1.CephClusterList{}
+	if err := c.List(context.TODO(), cephClusters, client.InNamespace(secret.Namespace)); err != nil {
+		logger.Debugf("failed to list ceph clusters in namespace %q. %v", secret.Namespace, err)
+		return false
+	}
+	for _, cephCluster := range cephClusters.Items {
+		dashboard := cephCluster.Spec.Dashboard
+		if dashboard.Enabled && dashboard.SSL && dashboard.CertificateSecretName == secret.Name {
+			logger.Infof("dashboard certificate secret %q changed, reconciling ceph cluster %q", secret.Name, cephCluster.Name)
+			return true
+		}
+	}
+	return false
+}
+
+// isDashboardAccessSecret informs whether the object is the secret with the password of a dashboard user or
+// the identity provider metadata of a ceph cluster
+func isDashboardAccessSecret(c client.Client, obj runtime.Object) bool {
+	secret, ok := obj.(*corev1.Secret)
+	if !ok {
+		return false
+	}
+
+	cephClusters := &cephv1.CephClusterList{}
+	if err := c.List(context.TODO(), cephClusters, client.InNamespace(secret.Namespace)); err != nil {
+		logger.Debugf("failed to list ceph clusters in namespace %q. %v", secret.Namespace, err)
+		return false
+	}
+	for _, cephCluster := range cephClusters.Items {
+		dashboard := cephCluster.Spec.Dashboard
+		if !dashboard.Enabled {
+			continue
+		}
+		if dashboard.SSO != nil && dashboard.SSO.IdPMetadataSecretName == secret.Name {
+			logger.Infof("dashboard identity provider metadata secret %q changed, reconciling ceph cluster %q", secret.Name, cephCluster.Name)
+			return true
+		}
+		for _, user := range dashboard.Users {
+			if user.PasswordSecretName == secret.Name {
+				logger.Infof("dashboard password secret %q of user %q changed, reconciling ceph cluster %q", secret.Name, user.Name, cephCluster.Name)
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // isHotPlugCM informs whether the object is the cm for hot-plug disk
 func isHotPlugCM(obj runtime.Object) bool {
 	// If not a ConfigMap, let's not reconcile
@@ -22,6 +22,9 @@ import (
 	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 func TestIsHotPlugCM(t *testing.T) {
@@ -40,3 +43,64 @@ func TestIsHotPlugCM(t *testing.T) {
 	cm.Labels["app"] = "rook-discover"
 	assert.True(t, isHotPlugCM(cm))
 }
+
+func TestIsDashboardCertSecret(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, cephv1.AddToScheme(scheme))
+	cephCluster := &cephv1.CephCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-cluster", Namespace: "rook-ceph"},
+		Spec: cephv1.ClusterSpec{
+			Dashboard: cephv1.DashboardSpec{Enabled: true, SSL: true, CertificateSecretName: "dashboard-cert"},
+		},
+	}
+	client := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(cephCluster).Build()
+
+	assert.False(t, isDashboardCertSecret(client, &corev1.ConfigMap{}))
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "dashboard-cert", Namespace: "rook-ceph"},
+		Type:       corev1.SecretTypeTLS,
+	}
+	assert.True(t, isDashboardCertSecret(client, secret))
+
+	// the certificate may be in an opaque secret
+	secret.Type = corev1.SecretTypeOpaque
+	assert.True(t, isDashboardCertSecret(client, secret))
+
+	// a secret in another namespace
+	secret.Namespace = "other"
+	assert.False(t, isDashboardCertSecret(client, secret))
+
+	// a secret with another name
+	secret.Namespace = "rook-ceph"
+	secret.Name = "other-cert"
+	assert.False(t, isDashboardCertSecret(client, secret))
+}
+
+func TestIsDashboardAccessSecret(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, cephv1.AddToScheme(scheme))
+	cephCluster := &cephv1.CephCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-cluster", Namespace: "rook-ceph"},
+		Spec: cephv1.ClusterSpec{
+			Dashboard: cephv1.DashboardSpec{
+				Enabled: true,
+				Users:   []cephv1.DashboardUserSpec{{Name: "alice", PasswordSecretName: "alice-password"}},
+				SSO:     &cephv1.DashboardSSOSpec{BaseURL: "https://dashboard", IdPMetadataSecretName: "idp-metadata"},
+			},
+		},
+	}
+	client := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(cephCluster).Build()
+
+	assert.False(t, isDashboardAccessSecret(client, &corev1.ConfigMap{}))
+
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "alice-password", Namespace: "rook-ceph"}}
+	assert.True(t, isDashboardAccessSecret(client, secret))
+	secret.Name = "idp-metadata"
+	assert.True(t, isDashboardAccessSecret(client, secret))
+	secret.Name = "other"
+	assert.False(t, isDashboardAccessSecret(client, secret))
+	secret.Name = "alice-password"
+	secret.Namespace = "other"
+	assert.False(t, isDashboardAccessSecret(client, secret))
+}
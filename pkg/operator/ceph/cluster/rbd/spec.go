@@ -60,12 +60,8 @@ func (r *ReconcileCephRBDMirror) makeDeployment(daemonConfig *daemonConfig, rbdM
 	rbdMirror.Spec.Annotations.ApplyToObjectMeta(&podSpec.ObjectMeta)
 	rbdMirror.Spec.Labels.ApplyToObjectMeta(&podSpec.ObjectMeta)
 
-	if r.cephClusterSpec.Network.IsHost() {
-		podSpec.Spec.DNSPolicy = v1.DNSClusterFirstWithHostNet
-	} else if r.cephClusterSpec.Network.IsMultus() {
-		if err := k8sutil.ApplyMultus(r.cephClusterSpec.Network, &podSpec.ObjectMeta); err != nil {
-			return nil, err
-		}
+	if err := k8sutil.ApplyNetworkSettings(r.cephClusterSpec.Network, &podSpec.ObjectMeta, &podSpec.Spec); err != nil {
+		return nil, err
 	}
 	rbdMirror.Spec.Placement.ApplyToPodSpec(&podSpec.Spec)
 
@@ -28,13 +28,19 @@ import (
 	"github.com/rook/rook/pkg/operator/ceph/disruption/machinelabel"
 	"github.com/rook/rook/pkg/operator/ceph/file"
 	"github.com/rook/rook/pkg/operator/ceph/file/mirror"
+	"github.com/rook/rook/pkg/operator/ceph/file/subvolumegroup"
 	"github.com/rook/rook/pkg/operator/ceph/nfs"
+	nfsexport "github.com/rook/rook/pkg/operator/ceph/nfs/export"
 	"github.com/rook/rook/pkg/operator/ceph/object"
 	"github.com/rook/rook/pkg/operator/ceph/object/realm"
 	objectuser "github.com/rook/rook/pkg/operator/ceph/object/user"
 	"github.com/rook/rook/pkg/operator/ceph/object/zone"
 	"github.com/rook/rook/pkg/operator/ceph/object/zonegroup"
 	"github.com/rook/rook/pkg/operator/ceph/pool"
+	"github.com/rook/rook/pkg/operator/ceph/pool/crushrule"
+	"github.com/rook/rook/pkg/operator/ceph/pool/imagemirror"
+	"github.com/rook/rook/pkg/operator/ceph/pool/radosnamespace"
+	"github.com/rook/rook/pkg/operator/ceph/pool/volumeqos"
 
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 )
@@ -69,6 +75,12 @@ var AddToManagerFuncs = []func(manager.Manager, *clusterd.Context) error{
 	rbd.Add,
 	client.Add,
 	mirror.Add,
+	radosnamespace.Add,
+	imagemirror.Add,
+	crushrule.Add,
+	volumeqos.Add,
+	subvolumegroup.Add,
+	nfsexport.Add,
 }
 
 // AddToManager adds all the registered controllers to the passed manager.
@@ -108,6 +108,7 @@ func SetOrRemoveDefaultConfigs(
 	context *clusterd.Context,
 	clusterInfo *cephclient.ClusterInfo,
 	clusterSpec cephv1.ClusterSpec,
+	clients ClientsSecureMode,
 ) error {
 	// ceph.conf is never used. All configurations are made in the centralized mon config database,
 	// or they are specified on the commandline when daemons are called.
@@ -156,6 +157,12 @@ func SetOrRemoveDefaultConfigs(
 		}
 	}
 
+	// Bind the daemons to the IP families of the cluster network and apply the encryption and compression
+	// of the msgr2 connections
+	if err := applyNetworkOptions(context, clusterInfo, clusterSpec, clients); err != nil {
+		return err
+	}
+
 	// This section will remove any previously configured option(s) from the mon centralized store
 	// This is useful for scenarios where options are not needed anymore and we just want to reset to internal's default
 	// On upgrade, the flag will be removed
@@ -18,6 +18,7 @@ package config
 
 import (
 	"encoding/json"
+	"sort"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -136,6 +137,156 @@ func (m *MonStore) GetDaemon(who string) ([]Option, error) {
 	return daemonOptions, nil
 }
 
+// Dump retrieves all the options set in the centralized mon configuration database. Options that
+// only apply to a subset of the daemons with a mask are not returned.
+func (m *MonStore) Dump() ([]Option, error) {
+	args := []string{"config", "dump"}
+	cephCmd := client.NewCephCommand(m.context, m.clusterInfo, args)
+	out, err := cephCmd.Run()
+	if err != nil {
+		return []Option{}, errors.Wrapf(err, "failed to dump config. output: %s", string(out))
+	}
+	var result []struct {
+		Section string `json:"section"`
+		Name    string `json:"name"`
+		Value   string `json:"value"`
+		Mask    string `json:"mask"`
+	}
+	if err := json.Unmarshal(out, &result); err != nil {
+		return []Option{}, errors.Wrapf(err, "failed to parse json config dump. json: %s", string(out))
+	}
+	options := []Option{}
+	for _, r := range result {
+		if r.Mask != "" {
+			continue
+		}
+		options = append(options, Option{r.Section, r.Name, r.Value})
+	}
+	return options, nil
+}
+
+// OptionDrift is an option whose value in the centralized mon configuration database differs from
+// the desired value.
+type OptionDrift struct {
+	Option
+
+	// Actual is the value in the database, empty if the option is not set
+	Actual string
+}
+
+// OptionKey returns the key of the option in the section it applies to
+func OptionKey(option Option) string {
+	return option.Who + "/" + normalizeKey(option.Option)
+}
+
+// SetAllIfChanged sets the options whose values differ from the centralized mon configuration
+// database and returns the options that were found to differ. Ceph normalizes the values it stores
+// (e.g. sizes, booleans and durations), so an option also matches the value Ceph stored when it was
+// last set, given in stored by option key. The values stored by Ceph for the options are saved in stored.
+func (m *MonStore) SetAllIfChanged(stored map[string]string, options ...Option) ([]OptionDrift, error) {
+	if len(options) == 0 {
+		return []OptionDrift{}, nil
+	}
+	values, err := m.dumpValues()
+	if err != nil {
+		return []OptionDrift{}, err
+	}
+
+	drift := []OptionDrift{}
+	var errs []error
+	for _, option := range options {
+		key := OptionKey(option)
+		actual, ok := values[key]
+		if ok && (actual == option.Value || (stored[key] != "" && actual == stored[key])) {
+			stored[key] = actual
+			continue
+		}
+		drift = append(drift, OptionDrift{Option: option, Actual: actual})
+		if err := m.Set(option.Who, option.Option, option.Value); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		retErr := errors.New("failed to set one or more Ceph configs")
+		for _, err := range errs {
+			retErr = errors.Wrapf(err, "%v", retErr)
+		}
+		return drift, retErr
+	}
+
+	if len(drift) > 0 {
+		// read back the values as Ceph stored them
+		values, err := m.dumpValues()
+		if err != nil {
+			return drift, err
+		}
+		for _, d := range drift {
+			key := OptionKey(d.Option)
+			if value, ok := values[key]; ok {
+				stored[key] = value
+			}
+		}
+	}
+	return drift, nil
+}
+
+// DeleteAllIfUnchanged deletes the options whose values in the centralized mon configuration database are
+// still the values of the options. The options changed since they were set are left alone.
+func (m *MonStore) DeleteAllIfUnchanged(options ...Option) error {
+	if len(options) == 0 {
+		return nil
+	}
+	values, err := m.dumpValues()
+	if err != nil {
+		return err
+	}
+
+	unchanged := []Option{}
+	for _, option := range options {
+		actual, ok := values[OptionKey(option)]
+		if !ok {
+			continue
+		}
+		if actual != option.Value {
+			logger.Infof("option %q of section %q was changed to %q, not removing it", option.Option, option.Who, actual)
+			continue
+		}
+		unchanged = append(unchanged, option)
+	}
+	return m.DeleteAll(unchanged...)
+}
+
+// dumpValues returns the values of the options that apply to whole sections, keyed by option key
+func (m *MonStore) dumpValues() (map[string]string, error) {
+	current, err := m.Dump()
+	if err != nil {
+		return nil, err
+	}
+	values := map[string]string{}
+	for _, option := range current {
+		values[OptionKey(option)] = option.Value
+	}
+	return values, nil
+}
+
+// OptionsFromSections converts options keyed by section, then by option name, to a list of options
+// sorted by section and name.
+func OptionsFromSections(sections map[string]map[string]string) []Option {
+	options := []Option{}
+	for who, values := range sections {
+		for option, value := range values {
+			options = append(options, Option{who, option, value})
+		}
+	}
+	sort.Slice(options, func(i, j int) bool {
+		if options[i].Who != options[j].Who {
+			return options[i].Who < options[j].Who
+		}
+		return options[i].Option < options[j].Option
+	})
+	return options
+}
+
 // DeleteDaemon delete all configs for a specific daemon in the centralized mon configuration database.
 func (m *MonStore) DeleteDaemon(who string) error {
 	configOptions, err := m.GetDaemon(who)
@@ -241,3 +241,64 @@ func TestMonStore_SetAll(t *testing.T) {
 	assert.Error(t, e)
 	assert.Len(t, execedCmds, 3)
 }
+
+func TestMonStore_SetAllIfChanged(t *testing.T) {
+	executor := &exectest.MockExecutor{}
+	clientset := testop.New(t, 1)
+	ctx := &clusterd.Context{
+		Clientset: clientset,
+		Executor:  executor,
+	}
+
+	execedCmds := []string{}
+	executor.MockExecuteCommandWithOutput =
+		func(command string, args ...string) (string, error) {
+			if args[0] == "config" && args[1] == "dump" {
+				return `[{"section":"global","name":"osd_pool_default_size","value":"3","level":"advanced","mask":""},
+					{"section":"osd","name":"osd_max_backfills","value":"4","level":"advanced","mask":""},
+					{"section":"osd","name":"osd_memory_target","value":"8589934592","level":"basic","mask":"host:node1"}]`, nil
+			}
+			execedCmds = append(execedCmds, strings.Join(args[:5], " "))
+			return "", nil
+		}
+
+	monStore := GetMonStore(ctx, &client.ClusterInfo{Namespace: "ns"})
+
+	// nothing to do without options
+	drift, err := monStore.SetAllIfChanged(map[string]string{})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(drift))
+
+	options := OptionsFromSections(map[string]map[string]string{
+		"osd":    {"osd max backfills": "2", "osd_memory_target": "4294967296"},
+		"global": {"osd_pool_default_size": "3"},
+	})
+	assert.Equal(t, []Option{
+		{"global", "osd_pool_default_size", "3"},
+		{"osd", "osd max backfills", "2"},
+		{"osd", "osd_memory_target", "4294967296"},
+	}, options)
+
+	stored := map[string]string{}
+	drift, err = monStore.SetAllIfChanged(stored, options...)
+	assert.NoError(t, err)
+	assert.Equal(t, []OptionDrift{
+		{Option: Option{"osd", "osd max backfills", "2"}, Actual: "4"},
+		// the masked option does not count as set for the whole section
+		{Option: Option{"osd", "osd_memory_target", "4294967296"}, Actual: ""},
+	}, drift)
+	assert.Equal(t, []string{
+		"config set osd osd_max_backfills 2",
+		"config set osd osd_memory_target 4294967296",
+	}, execedCmds)
+	// the values read back from the database are saved
+	assert.Equal(t, map[string]string{"global/osd_pool_default_size": "3", "osd/osd_max_backfills": "4"}, stored)
+
+	// the value stored by ceph matches the option it normalized
+	execedCmds = []string{}
+	stored = map[string]string{"osd/osd_max_backfills": "4"}
+	drift, err = monStore.SetAllIfChanged(stored, Option{"osd", "osd_max_backfills", "0x4"})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(drift))
+	assert.Equal(t, 0, len(execedCmds))
+}
@@ -19,12 +19,18 @@ package config
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"reflect"
 	"strings"
 
 	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
 	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/ceph/version"
 	"github.com/rook/rook/pkg/operator/k8sutil"
+	v1 "k8s.io/api/core/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -38,6 +44,15 @@ const (
 	WhereaboutsIPAMType = "whereabouts"
 	hostLocalIPAMType   = "host-local"
 	staticIPAMType      = "static"
+
+	// Msgr2SecureMode only accepts the encrypted connections of the msgr2 protocol
+	Msgr2SecureMode = "secure"
+	// osdForceCompressMode compresses all the messages between the OSDs
+	osdForceCompressMode = "force"
+
+	// appliedNetworkOptionsName is the configmap with the network options last set by the operator
+	appliedNetworkOptionsName = "rook-ceph-applied-network-config"
+	appliedNetworkOptionsKey  = "options"
 )
 
 var (
@@ -122,3 +137,211 @@ func getNetworkRange(netConfig k8sutil.NetworkAttachmentConfig) string {
 		return ""
 	}
 }
+
+// OSDCompressModeOption is the option of the compression of the connections between the OSDs
+const OSDCompressModeOption = "ms_osd_compress_mode"
+
+// rbdMapOptionsOption is the option of the default options of the rbd kernel clients mapping the images
+const rbdMapOptionsOption = "rbd_default_map_options"
+
+// secureMapOptions are the options of the rbd kernel clients mapping the images in the msgr2 secure mode
+const secureMapOptions = "ms_mode=secure"
+
+// ClientsSecureMode is whether the csi clients of a cluster can connect to the daemons in the msgr2 secure mode
+type ClientsSecureMode int
+
+const (
+	// ClientsSecureModeUnknown is the mode until the version of the csi clients is detected, the options of the
+	// secure mode of the clients already applied are kept
+	ClientsSecureModeUnknown ClientsSecureMode = iota
+	// ClientsSecureModeSupported is the mode of the csi clients that mount in the secure mode
+	ClientsSecureModeSupported
+	// ClientsSecureModeUnsupported is the mode of the csi clients too old to mount in the secure mode, the daemons
+	// do not require the secure mode from the clients
+	ClientsSecureModeUnsupported
+)
+
+// clientsSecureModeOptions are the options that require the secure mode from the clients
+var clientsSecureModeOptions = []string{"ms_service_mode", "ms_client_mode", rbdMapOptionsOption}
+
+// generateConnectionsSettings returns the msgr2 options of the encryption and compression of the connections
+// to set in the mon configuration database. The clients are only required to connect in the secure mode when
+// the csi clients support it.
+func generateConnectionsSettings(network cephv1.NetworkSpec, cephVersion version.CephVersion, clients ClientsSecureMode) []Option {
+	options := []Option{}
+	if network.IsEncryptionEnabled() {
+		options = append(options, configOverride("global", "ms_cluster_mode", Msgr2SecureMode))
+		if clients == ClientsSecureModeSupported {
+			options = append(options, configOverride("global", "ms_service_mode", Msgr2SecureMode))
+			options = append(options, configOverride("global", "ms_client_mode", Msgr2SecureMode))
+			// the rbd kernel clients only connect to the daemons requiring the secure mode when mapped in that mode
+			options = append(options, configOverride("client", rbdMapOptionsOption, secureMapOptions))
+		} else if clients == ClientsSecureModeUnsupported {
+			logger.Warning("the csi clients do not support the secure mode, only the connections between the daemons are encrypted")
+		}
+	}
+
+	if network.IsCompressionEnabled() {
+		if !cephVersion.IsAtLeastQuincy() {
+			logger.Warningf("on-wire compression requires ceph quincy, the connections of ceph %q are not compressed", cephVersion.String())
+		} else {
+			options = append(options, configOverride("global", OSDCompressModeOption, osdForceCompressMode))
+		}
+	}
+
+	return options
+}
+
+// NetworkBindingOptions returns the ms_bind_ipv4 and ms_bind_ipv6 options of the IP family of the cluster network
+func NetworkBindingOptions(network cephv1.NetworkSpec, cephVersion version.CephVersion) []Option {
+	options := []Option{}
+
+	// As of Pacific, Ceph supports dual-stack, so setting IPv6 family without disabling IPv4 binding actually enables dual-stack
+	// This is likely not user's intent, so on Pacific let's make sure to disable IPv4 when IPv6 is selected
+	if !network.DualStack {
+		switch network.IPFamily {
+		case cephv1.IPv4:
+			options = append(options, configOverride("global", "ms_bind_ipv4", "true"))
+			options = append(options, configOverride("global", "ms_bind_ipv6", "false"))
+
+		case cephv1.IPv6:
+			options = append(options, configOverride("global", "ms_bind_ipv4", "false"))
+			options = append(options, configOverride("global", "ms_bind_ipv6", "true"))
+		}
+	} else {
+		if cephVersion.IsAtLeastPacific() {
+			options = append(options, configOverride("global", "ms_bind_ipv4", "true"))
+			options = append(options, configOverride("global", "ms_bind_ipv6", "true"))
+		} else {
+			logger.Info("dual-stack is only supported on ceph pacific")
+			// Still acknowledge IPv6, nothing to do for IPv4 since it will always be "on"
+			if network.IPFamily == cephv1.IPv6 {
+				options = append(options, configOverride("global", "ms_bind_ipv6", "true"))
+			}
+		}
+	}
+
+	return options
+}
+
+// applyNetworkOptions sets the binding and connections options of the network in the mon configuration
+// database. The options the cephConfig of the spec sets are left to the cephConfig. The options set by a
+// previous reconcile that are no longer desired are removed to restore the Ceph defaults, unless their
+// values were changed since.
+func applyNetworkOptions(clusterdContext *clusterd.Context, clusterInfo *cephclient.ClusterInfo, clusterSpec cephv1.ClusterSpec, clients ClientsSecureMode) error {
+	applied, err := getAppliedNetworkOptions(clusterdContext, clusterInfo)
+	if err != nil {
+		return err
+	}
+
+	options := append(NetworkBindingOptions(clusterSpec.Network, clusterInfo.CephVersion), generateConnectionsSettings(clusterSpec.Network, clusterInfo.CephVersion, clients)...)
+	if clients == ClientsSecureModeUnknown && clusterSpec.Network.IsEncryptionEnabled() {
+		// keep requiring the secure mode from the clients until the csi clients are known not to support it
+		for _, option := range applied {
+			if isClientsSecureModeOption(option) && !containsOption(options, option) {
+				options = append(options, option)
+			}
+		}
+	}
+	desired := []Option{}
+	for _, option := range options {
+		if setByCephConfig(clusterSpec, option) {
+			logger.Infof("option %q of section %q is set by the ceph config, not applying the network setting", option.Option, option.Who)
+			continue
+		}
+		desired = append(desired, option)
+	}
+
+	monStore := GetMonStore(clusterdContext, clusterInfo)
+	if err := monStore.SetAll(desired...); err != nil {
+		return errors.Wrap(err, "failed to apply network settings")
+	}
+
+	removed := []Option{}
+	for _, option := range applied {
+		if !containsOption(desired, option) && !setByCephConfig(clusterSpec, option) {
+			removed = append(removed, option)
+		}
+	}
+	if err := monStore.DeleteAllIfUnchanged(removed...); err != nil {
+		return errors.Wrap(err, "failed to restore default network settings")
+	}
+
+	if reflect.DeepEqual(applied, desired) {
+		return nil
+	}
+	return saveAppliedNetworkOptions(clusterdContext, clusterInfo, desired)
+}
+
+// setByCephConfig returns whether the cephConfig of the spec sets the option
+func setByCephConfig(clusterSpec cephv1.ClusterSpec, option Option) bool {
+	for name := range clusterSpec.CephConfig[option.Who] {
+		if normalizeKey(name) == normalizeKey(option.Option) {
+			return true
+		}
+	}
+	return false
+}
+
+func isClientsSecureModeOption(option Option) bool {
+	for _, name := range clientsSecureModeOptions {
+		if option.Option == name {
+			return true
+		}
+	}
+	return false
+}
+
+func containsOption(options []Option, option Option) bool {
+	for _, o := range options {
+		if OptionKey(o) == OptionKey(option) {
+			return true
+		}
+	}
+	return false
+}
+
+func getAppliedNetworkOptions(clusterdContext *clusterd.Context, clusterInfo *cephclient.ClusterInfo) ([]Option, error) {
+	ctx := context.TODO()
+	applied := []Option{}
+	cm, err := clusterdContext.Clientset.CoreV1().ConfigMaps(clusterInfo.Namespace).Get(ctx, appliedNetworkOptionsName, metav1.GetOptions{})
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return applied, nil
+		}
+		return nil, errors.Wrapf(err, "failed to get configmap %q", appliedNetworkOptionsName)
+	}
+	if err := json.Unmarshal([]byte(cm.Data[appliedNetworkOptionsKey]), &applied); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal configmap %q", appliedNetworkOptionsName)
+	}
+	return applied, nil
+}
+
+func saveAppliedNetworkOptions(clusterdContext *clusterd.Context, clusterInfo *cephclient.ClusterInfo, applied []Option) error {
+	data, err := json.Marshal(applied)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal the applied network options")
+	}
+	cm := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      appliedNetworkOptionsName,
+			Namespace: clusterInfo.Namespace,
+		},
+		Data: map[string]string{appliedNetworkOptionsKey: string(data)},
+	}
+	if err := clusterInfo.OwnerInfo.SetControllerReference(cm); err != nil {
+		return errors.Wrapf(err, "failed to set owner reference to configmap %q", cm.Name)
+	}
+
+	ctx := context.TODO()
+	configMaps := clusterdContext.Clientset.CoreV1().ConfigMaps(clusterInfo.Namespace)
+	if _, err := configMaps.Create(ctx, cm, metav1.CreateOptions{}); err != nil {
+		if !kerrors.IsAlreadyExists(err) {
+			return errors.Wrapf(err, "failed to create configmap %q", cm.Name)
+		}
+		if _, err := configMaps.Update(ctx, cm, metav1.UpdateOptions{}); err != nil {
+			return errors.Wrapf(err, "failed to update configmap %q", cm.Name)
+		}
+	}
+	return nil
+}
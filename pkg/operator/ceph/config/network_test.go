@@ -19,13 +19,18 @@ package config
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 
 	networkv1 "github.com/k8snetworkplumbingwg/network-attachment-definition-client/pkg/apis/k8s.cni.cncf.io/v1"
 	fakenetclient "github.com/k8snetworkplumbingwg/network-attachment-definition-client/pkg/client/clientset/versioned/fake"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
 	"github.com/rook/rook/pkg/clusterd"
+	"github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/ceph/version"
 	"github.com/rook/rook/pkg/operator/k8sutil"
 	testop "github.com/rook/rook/pkg/operator/test"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
 	"github.com/stretchr/testify/assert"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -349,3 +354,142 @@ func TestGetMultusNamespace(t *testing.T) {
 	assert.Empty(t, namespace)
 	assert.Equal(t, "public-nad", nad)
 }
+
+func TestGenerateConnectionsSettings(t *testing.T) {
+	// the options are left alone when the connections are not configured
+	assert.Empty(t, generateConnectionsSettings(cephv1.NetworkSpec{}, version.Quincy, ClientsSecureModeSupported))
+
+	network := cephv1.NetworkSpec{Connections: &cephv1.ConnectionsSpec{
+		Encryption:  &cephv1.EncryptionSpec{Enabled: true},
+		Compression: &cephv1.CompressionSpec{Enabled: true},
+	}}
+	assert.Equal(t, []Option{
+		{Who: "global", Option: "ms_cluster_mode", Value: "secure"},
+		{Who: "global", Option: "ms_service_mode", Value: "secure"},
+		{Who: "global", Option: "ms_client_mode", Value: "secure"},
+		{Who: "client", Option: "rbd_default_map_options", Value: "ms_mode=secure"},
+		{Who: "global", Option: "ms_osd_compress_mode", Value: "force"},
+	}, generateConnectionsSettings(network, version.Quincy, ClientsSecureModeSupported))
+
+	// the compression requires quincy
+	assert.Equal(t, 4, len(generateConnectionsSettings(network, version.Pacific, ClientsSecureModeSupported)))
+
+	// the clients are not required to connect in the secure mode when the csi clients do not support it
+	assert.Equal(t, []Option{
+		{Who: "global", Option: "ms_cluster_mode", Value: "secure"},
+	}, generateConnectionsSettings(network, version.Pacific, ClientsSecureModeUnsupported))
+	assert.Equal(t, 1, len(generateConnectionsSettings(network, version.Pacific, ClientsSecureModeUnknown)))
+
+	network.Connections.Encryption.Enabled = false
+	network.Connections.Compression.Enabled = false
+	assert.Empty(t, generateConnectionsSettings(network, version.Quincy, ClientsSecureModeSupported))
+}
+
+func TestApplyNetworkOptions(t *testing.T) {
+	executor := &exectest.MockExecutor{}
+	clusterdContext := &clusterd.Context{
+		Clientset: testop.New(t, 1),
+		Executor:  executor,
+	}
+	clusterInfo := client.AdminClusterInfo("ns")
+	clusterInfo.CephVersion = version.Quincy
+
+	dump := "[]"
+	execedCmds := []string{}
+	executor.MockExecuteCommandWithOutput =
+		func(command string, args ...string) (string, error) {
+			if args[0] == "config" && args[1] == "dump" {
+				return dump, nil
+			}
+			execedCmds = append(execedCmds, strings.Join(args[:4], " "))
+			return "", nil
+		}
+
+	spec := cephv1.ClusterSpec{Network: cephv1.NetworkSpec{
+		IPFamily:    cephv1.IPv4,
+		Connections: &cephv1.ConnectionsSpec{Encryption: &cephv1.EncryptionSpec{Enabled: true}},
+	}}
+	// the cephConfig takes precedence over the network settings
+	spec.CephConfig = map[string]cephv1.CephConfigOptions{"global": {"ms client mode": "crc secure"}}
+	err := applyNetworkOptions(clusterdContext, clusterInfo, spec, ClientsSecureModeSupported)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"config set global ms_bind_ipv4",
+		"config set global ms_bind_ipv6",
+		"config set global ms_cluster_mode",
+		"config set global ms_service_mode",
+		"config set client rbd_default_map_options",
+	}, execedCmds)
+	applied, err := getAppliedNetworkOptions(clusterdContext, clusterInfo)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, len(applied))
+
+	// the secure mode of the clients is kept until the version of the csi clients is known
+	execedCmds = []string{}
+	err = applyNetworkOptions(clusterdContext, clusterInfo, spec, ClientsSecureModeUnknown)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"config set global ms_bind_ipv4",
+		"config set global ms_bind_ipv6",
+		"config set global ms_cluster_mode",
+		"config set global ms_service_mode",
+		"config set client rbd_default_map_options",
+	}, execedCmds)
+	applied, err = getAppliedNetworkOptions(clusterdContext, clusterInfo)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, len(applied))
+
+	// the secure mode of the clients is removed when the csi clients do not support it
+	dump = `[{"section":"global","name":"ms_service_mode","value":"secure","mask":""},
+		{"section":"client","name":"rbd_default_map_options","value":"ms_mode=secure","mask":""}]`
+	execedCmds = []string{}
+	err = applyNetworkOptions(clusterdContext, clusterInfo, spec, ClientsSecureModeUnsupported)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"config set global ms_bind_ipv4",
+		"config set global ms_bind_ipv6",
+		"config set global ms_cluster_mode",
+		"config rm global ms_service_mode",
+		"config rm client rbd_default_map_options",
+	}, execedCmds)
+
+	// the secure mode of the clients is restored when supported
+	dump = "[]"
+	execedCmds = []string{}
+	err = applyNetworkOptions(clusterdContext, clusterInfo, spec, ClientsSecureModeSupported)
+	assert.NoError(t, err)
+	applied, err = getAppliedNetworkOptions(clusterdContext, clusterInfo)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, len(applied))
+
+	// only the options rook set with their values unchanged are removed
+	dump = `[{"section":"global","name":"ms_cluster_mode","value":"secure","mask":""},
+		{"section":"global","name":"ms_service_mode","value":"crc secure","mask":""},
+		{"section":"global","name":"ms_client_mode","value":"crc secure","mask":""},
+		{"section":"client","name":"rbd_default_map_options","value":"ms_mode=secure","mask":""}]`
+	execedCmds = []string{}
+	spec.Network.Connections.Encryption.Enabled = false
+	err = applyNetworkOptions(clusterdContext, clusterInfo, spec, ClientsSecureModeSupported)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"config set global ms_bind_ipv4",
+		"config set global ms_bind_ipv6",
+		"config rm global ms_cluster_mode",
+		"config rm client rbd_default_map_options",
+	}, execedCmds)
+	applied, err = getAppliedNetworkOptions(clusterdContext, clusterInfo)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(applied))
+
+	// the binding options set by the user are kept when no family is set
+	execedCmds = []string{}
+	spec.Network.IPFamily = ""
+	dump = `[{"section":"global","name":"ms_bind_ipv4","value":"false","mask":""},
+		{"section":"global","name":"ms_bind_ipv6","value":"false","mask":""}]`
+	err = applyNetworkOptions(clusterdContext, clusterInfo, spec, ClientsSecureModeSupported)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"config rm global ms_bind_ipv6"}, execedCmds)
+	applied, err = getAppliedNetworkOptions(clusterdContext, clusterInfo)
+	assert.NoError(t, err)
+	assert.Empty(t, applied)
+}
@@ -336,32 +336,11 @@ func AdminFlags(cluster *client.ClusterInfo) []string {
 	)
 }
 
+// NetworkBindingFlags returns the command line flags binding the daemons to the IP families of the cluster network
 func NetworkBindingFlags(cluster *client.ClusterInfo, spec *cephv1.ClusterSpec) []string {
 	var args []string
-
-	// As of Pacific, Ceph supports dual-stack, so setting IPv6 family without disabling IPv4 binding actually enables dual-stack
-	// This is likely not user's intent, so on Pacific let's make sure to disable IPv4 when IPv6 is selected
-	if !spec.Network.DualStack {
-		switch spec.Network.IPFamily {
-		case cephv1.IPv4:
-			args = append(args, config.NewFlag("ms-bind-ipv4", "true"))
-			args = append(args, config.NewFlag("ms-bind-ipv6", "false"))
-
-		case cephv1.IPv6:
-			args = append(args, config.NewFlag("ms-bind-ipv4", "false"))
-			args = append(args, config.NewFlag("ms-bind-ipv6", "true"))
-		}
-	} else {
-		if cluster.CephVersion.IsAtLeastPacific() {
-			args = append(args, config.NewFlag("ms-bind-ipv4", "true"))
-			args = append(args, config.NewFlag("ms-bind-ipv6", "true"))
-		} else {
-			logger.Info("dual-stack is only supported on ceph pacific")
-			// Still acknowledge IPv6, nothing to do for IPv4 since it will always be "on"
-			if spec.Network.IPFamily == cephv1.IPv6 {
-				args = append(args, config.NewFlag("ms-bind-ipv6", "true"))
-			}
-		}
+	for _, option := range config.NetworkBindingOptions(spec.Network, cluster.CephVersion) {
+		args = append(args, config.NewFlag(option.Option, option.Value))
 	}
 
 	return args
@@ -19,28 +19,48 @@ package csi
 import (
 	"context"
 	"encoding/json"
+	"net"
 	"os"
+	"strconv"
 	"sync"
 
 	"github.com/coreos/pkg/capnslog"
 	"github.com/pkg/errors"
 	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/ceph/config"
 	"github.com/rook/rook/pkg/operator/k8sutil"
 	v1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 )
 
 var (
 	logger = capnslog.NewPackageLogger("github.com/rook/rook", "ceph-csi")
+
+	// ConfigMutex serializes the updates of the csi config map by the controllers of all the clusters
+	ConfigMutex = &sync.Mutex{}
 )
 
 type csiClusterConfigEntry struct {
 	ClusterID string   `json:"clusterID"`
 	Monitors  []string `json:"monitors"`
+	// Namespace is the namespace of the cluster of an entry that is not the cluster entry itself
+	Namespace      string         `json:"namespace,omitempty"`
+	RadosNamespace string         `json:"radosNamespace,omitempty"`
+	CephFS         *CsiCephFSSpec `json:"cephFS,omitempty"`
+}
+
+// CsiCephFSSpec is the cephfs settings of an entry of the csi config
+type CsiCephFSSpec struct {
+	SubvolumeGroup     string `json:"subvolumeGroup,omitempty"`
+	KernelMountOptions string `json:"kernelMountOptions,omitempty"`
 }
 
+// secureKernelMountOptions are the mount options of the kernel clients for the msgr2 secure mode
+const secureKernelMountOptions = "ms_mode=secure"
+
 type csiClusterConfig []csiClusterConfigEntry
 
 // FormatCsiClusterConfig returns a json-formatted string containing
@@ -87,6 +107,118 @@ func monEndpoints(mons map[string]*cephclient.MonInfo) []string {
 	return endpoints
 }
 
+// UpdateCsiConnectionsConfig returns a json-formatted string containing the csi cluster config with
+// the connections of the entries of a cluster updated. When the msgr2 secure mode is required, the
+// clients connect to the msgr2 port of the mons and the kernel clients mount in the secure mode.
+func UpdateCsiConnectionsConfig(curr, clusterNamespace string, encrypted bool) (string, error) {
+	cc, err := parseCsiClusterConfig(curr)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to parse current csi cluster config")
+	}
+
+	for i := range cc {
+		if cc[i].ClusterID != clusterNamespace && cc[i].Namespace != clusterNamespace {
+			continue
+		}
+		for j, endpoint := range cc[i].Monitors {
+			cc[i].Monitors[j] = connectionsEndpoint(endpoint, encrypted)
+		}
+		if encrypted {
+			if cc[i].CephFS == nil {
+				cc[i].CephFS = &CsiCephFSSpec{}
+			}
+			cc[i].CephFS.KernelMountOptions = secureKernelMountOptions
+		} else if cc[i].CephFS != nil {
+			cc[i].CephFS.KernelMountOptions = ""
+			if *cc[i].CephFS == (CsiCephFSSpec{}) {
+				cc[i].CephFS = nil
+			}
+		}
+	}
+	return formatCsiClusterConfig(cc)
+}
+
+// secureConnections returns whether the csi clients of a cluster connect in the msgr2 secure mode. Until the
+// ceph-csi version is detected, the entries of the cluster keep the mode of the current config.
+func secureConnections(curr string, clusterInfo *cephclient.ClusterInfo) bool {
+	if !clusterInfo.NetworkSpec.IsEncryptionEnabled() {
+		return false
+	}
+	switch ClientsSecureMode() {
+	case config.ClientsSecureModeSupported:
+		return true
+	case config.ClientsSecureModeUnsupported:
+		logger.Warningf("the connections of cluster %q are encrypted but the ceph-csi image does not support the kernel mount options, ceph-csi %s or newer is required", clusterInfo.Namespace, kernelMountOptionsSupportedVersion.String())
+		return false
+	}
+	return secureConnectionsConfigured(curr, clusterInfo.Namespace)
+}
+
+// secureConnectionsConfigured returns whether the entries of a cluster in the current csi config mount in the
+// msgr2 secure mode
+func secureConnectionsConfigured(curr, clusterNamespace string) bool {
+	cc, err := parseCsiClusterConfig(curr)
+	if err != nil {
+		return false
+	}
+	for _, entry := range cc {
+		if entry.ClusterID != clusterNamespace && entry.Namespace != clusterNamespace {
+			continue
+		}
+		if entry.CephFS != nil && entry.CephFS.KernelMountOptions == secureKernelMountOptions {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientsSecureMode returns whether the csi clients support the msgr2 secure mode. The mode is unknown until
+// the operator checked the ceph-csi version.
+func ClientsSecureMode() config.ClientsSecureMode {
+	if AllowUnsupported {
+		return config.ClientsSecureModeSupported
+	}
+
+	detectedCSIVersionLock.Lock()
+	defer detectedCSIVersionLock.Unlock()
+	if !csiVersionChecked {
+		return config.ClientsSecureModeUnknown
+	}
+	// there are no csi clients when csi is disabled
+	if detectedCSIVersion == nil || detectedCSIVersion.SupportsKernelMountOptions() {
+		return config.ClientsSecureModeSupported
+	}
+	return config.ClientsSecureModeUnsupported
+}
+
+// setDetectedCSIVersion records the checked ceph-csi version, nil when csi is disabled, and notifies the
+// clusters to update their connections
+func setDetectedCSIVersion(v *CephCSIVersion) {
+	detectedCSIVersionLock.Lock()
+	detectedCSIVersion = v
+	csiVersionChecked = true
+	detectedCSIVersionLock.Unlock()
+
+	select {
+	case VersionChecked <- event.GenericEvent{Object: &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: ConfigName}}}:
+	default:
+		// a notification is already pending
+	}
+}
+
+// connectionsEndpoint returns the endpoint of a mon on the msgr2 port when the connections are
+// encrypted, since the kernel clients only support the secure mode with msgr2
+func connectionsEndpoint(endpoint string, encrypted bool) string {
+	if !encrypted {
+		return endpoint
+	}
+	host, _, err := net.SplitHostPort(endpoint)
+	if err != nil {
+		return endpoint
+	}
+	return net.JoinHostPort(host, strconv.Itoa(int(cephclient.Msgr2port)))
+}
+
 // UpdateCsiClusterConfig returns a json-formatted string containing
 // the cluster-to-mon mapping required to configure ceph csi.
 func UpdateCsiClusterConfig(
@@ -107,7 +239,10 @@ func UpdateCsiClusterConfig(
 			centry.Monitors = monEndpoints(mons)
 			found = true
 			cc[i] = centry
-			break
+		} else if centry.Namespace == clusterKey {
+			// the entries of the rados namespaces and subvolume groups of the cluster use the same mons
+			centry.Monitors = monEndpoints(mons)
+			cc[i] = centry
 		}
 	}
 	if !found {
@@ -118,6 +253,87 @@ func UpdateCsiClusterConfig(
 	return formatCsiClusterConfig(cc)
 }
 
+// UpdateCsiRadosNamespaceConfig returns a json-formatted string containing
+// the csi cluster config with the entry of a rados namespace added or updated.
+// The clusterNamespace is the namespace of the cluster the rados namespace
+// belongs to, which keeps the mons of the entry updated with the cluster.
+func UpdateCsiRadosNamespaceConfig(
+	curr, clusterID, clusterNamespace, radosNamespace string, mons map[string]*cephclient.MonInfo) (string, error) {
+
+	cc, err := parseCsiClusterConfig(curr)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to parse current csi cluster config")
+	}
+
+	centry := csiClusterConfigEntry{
+		ClusterID:      clusterID,
+		Monitors:       monEndpoints(mons),
+		Namespace:      clusterNamespace,
+		RadosNamespace: radosNamespace,
+	}
+	found := false
+	for i := range cc {
+		if cc[i].ClusterID == clusterID {
+			cc[i] = centry
+			found = true
+			break
+		}
+	}
+	if !found {
+		cc = append(cc, centry)
+	}
+	return formatCsiClusterConfig(cc)
+}
+
+// UpdateCsiSubvolumeGroupConfig returns a json-formatted string containing
+// the csi cluster config with the entry of a cephfs subvolume group added or
+// updated. The clusterNamespace is the namespace of the cluster the group
+// belongs to, which keeps the mons of the entry updated with the cluster.
+func UpdateCsiSubvolumeGroupConfig(
+	curr, clusterID, clusterNamespace, subvolumeGroup string, mons map[string]*cephclient.MonInfo) (string, error) {
+
+	cc, err := parseCsiClusterConfig(curr)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to parse current csi cluster config")
+	}
+
+	centry := csiClusterConfigEntry{
+		ClusterID: clusterID,
+		Monitors:  monEndpoints(mons),
+		Namespace: clusterNamespace,
+		CephFS:    &CsiCephFSSpec{SubvolumeGroup: subvolumeGroup},
+	}
+	found := false
+	for i := range cc {
+		if cc[i].ClusterID == clusterID {
+			cc[i] = centry
+			found = true
+			break
+		}
+	}
+	if !found {
+		cc = append(cc, centry)
+	}
+	return formatCsiClusterConfig(cc)
+}
+
+// RemoveCsiClusterConfigEntry returns a json-formatted string containing
+// the csi cluster config without the entry of the given clusterID.
+func RemoveCsiClusterConfigEntry(curr, clusterID string) (string, error) {
+	cc, err := parseCsiClusterConfig(curr)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to parse current csi cluster config")
+	}
+
+	updated := csiClusterConfig{}
+	for _, centry := range cc {
+		if centry.ClusterID != clusterID {
+			updated = append(updated, centry)
+		}
+	}
+	return formatCsiClusterConfig(updated)
+}
+
 // CreateCsiConfigMap creates an empty config map that will be later used
 // to provide cluster configuration to ceph-csi. If a config map already
 // exists, it will return it.
@@ -158,6 +374,55 @@ func CreateCsiConfigMap(namespace string, clientset kubernetes.Interface, ownerI
 func SaveClusterConfig(
 	clientset kubernetes.Interface, clusterNamespace string,
 	clusterInfo *cephclient.ClusterInfo, l sync.Locker) error {
+	return updateCsiConfigMap(clientset, l, func(currData string) (string, error) {
+		s, err := UpdateCsiClusterConfig(currData, clusterNamespace, clusterInfo.Monitors)
+		if err != nil {
+			return "", err
+		}
+		return UpdateCsiConnectionsConfig(s, clusterNamespace, secureConnections(currData, clusterInfo))
+	})
+}
+
+// SaveRadosNamespaceConfig adds or updates the entry of a rados namespace in
+// the config map used to provide ceph-csi with the cluster configuration. The
+// clusterID is the value provided to ceph-csi in the storage class.
+func SaveRadosNamespaceConfig(
+	clientset kubernetes.Interface, clusterID string,
+	clusterInfo *cephclient.ClusterInfo, radosNamespace string, l sync.Locker) error {
+	return updateCsiConfigMap(clientset, l, func(currData string) (string, error) {
+		s, err := UpdateCsiRadosNamespaceConfig(currData, clusterID, clusterInfo.Namespace, radosNamespace, clusterInfo.Monitors)
+		if err != nil {
+			return "", err
+		}
+		return UpdateCsiConnectionsConfig(s, clusterInfo.Namespace, secureConnections(currData, clusterInfo))
+	})
+}
+
+// SaveSubvolumeGroupConfig adds or updates the entry of a cephfs subvolume
+// group in the config map used to provide ceph-csi with the cluster
+// configuration. The clusterID is the value provided to ceph-csi in the
+// storage class.
+func SaveSubvolumeGroupConfig(
+	clientset kubernetes.Interface, clusterID string,
+	clusterInfo *cephclient.ClusterInfo, subvolumeGroup string, l sync.Locker) error {
+	return updateCsiConfigMap(clientset, l, func(currData string) (string, error) {
+		s, err := UpdateCsiSubvolumeGroupConfig(currData, clusterID, clusterInfo.Namespace, subvolumeGroup, clusterInfo.Monitors)
+		if err != nil {
+			return "", err
+		}
+		return UpdateCsiConnectionsConfig(s, clusterInfo.Namespace, secureConnections(currData, clusterInfo))
+	})
+}
+
+// RemoveClusterConfig removes the entry of the given clusterID from the
+// config map used to provide ceph-csi with the cluster configuration.
+func RemoveClusterConfig(clientset kubernetes.Interface, clusterID string, l sync.Locker) error {
+	return updateCsiConfigMap(clientset, l, func(currData string) (string, error) {
+		return RemoveCsiClusterConfigEntry(currData, clusterID)
+	})
+}
+
+func updateCsiConfigMap(clientset kubernetes.Interface, l sync.Locker, update func(currData string) (string, error)) error {
 	ctx := context.TODO()
 
 	if !CSIEnabled() {
@@ -179,16 +444,18 @@ func SaveClusterConfig(
 		return errors.Wrap(err, "failed to fetch current csi config map")
 	}
 
-	// update ConfigMap contents for current cluster
+	// update ConfigMap contents
 	currData := configMap.Data[ConfigKey]
 	if currData == "" {
 		currData = "[]"
 	}
-	newData, err := UpdateCsiClusterConfig(
-		currData, clusterNamespace, clusterInfo.Monitors)
+	newData, err := update(currData)
 	if err != nil {
 		return errors.Wrap(err, "failed to update csi config map data")
 	}
+	if configMap.Data == nil {
+		configMap.Data = map[string]string{}
+	}
 	configMap.Data[ConfigKey] = newData
 
 	// update ConfigMap with new contents
@@ -19,7 +19,9 @@ package csi
 import (
 	"testing"
 
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
 	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/ceph/config"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -87,3 +89,143 @@ func TestUpdateCsiClusterConfig(t *testing.T) {
 	_, err = UpdateCsiClusterConfig("qqq", "beta", mons2)
 	assert.Error(t, err)
 }
+
+func TestUpdateCsiRadosNamespaceConfig(t *testing.T) {
+	mons := map[string]*cephclient.MonInfo{
+		"foo": {Name: "foo", Endpoint: "1.2.3.4:5000"},
+	}
+	s, err := UpdateCsiClusterConfig("[]", "rook-ceph", mons)
+	assert.NoError(t, err)
+
+	// add a rados namespace of the cluster
+	s, err = UpdateCsiRadosNamespaceConfig(s, "abc123", "rook-ceph", "team-a", mons)
+	assert.NoError(t, err)
+	assert.Equal(t,
+		`[{"clusterID":"rook-ceph","monitors":["1.2.3.4:5000"]},{"clusterID":"abc123","monitors":["1.2.3.4:5000"],"namespace":"rook-ceph","radosNamespace":"team-a"}]`, s)
+
+	// the mons of the rados namespace follow the cluster
+	mons["bar"] = &cephclient.MonInfo{Name: "bar", Endpoint: "10.11.12.13:5000"}
+	s, err = UpdateCsiClusterConfig(s, "rook-ceph", mons)
+	assert.NoError(t, err)
+	cc, err := parseCsiClusterConfig(s)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(cc))
+	assert.Equal(t, 2, len(cc[1].Monitors))
+	assert.Equal(t, "team-a", cc[1].RadosNamespace)
+
+	// the entry of another cluster is not updated
+	s, err = UpdateCsiClusterConfig(s, "other", map[string]*cephclient.MonInfo{"a": {Name: "a", Endpoint: "20.1.1.1:5000"}})
+	assert.NoError(t, err)
+	cc, err = parseCsiClusterConfig(s)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(cc))
+	assert.Equal(t, 2, len(cc[1].Monitors))
+
+	// remove the rados namespace
+	s, err = RemoveCsiClusterConfigEntry(s, "abc123")
+	assert.NoError(t, err)
+	cc, err = parseCsiClusterConfig(s)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(cc))
+	assert.Equal(t, "rook-ceph", cc[0].ClusterID)
+	assert.Equal(t, "other", cc[1].ClusterID)
+
+	// removing an entry that does not exist is a no-op
+	s2, err := RemoveCsiClusterConfigEntry(s, "abc123")
+	assert.NoError(t, err)
+	assert.Equal(t, s, s2)
+}
+
+func TestUpdateCsiSubvolumeGroupConfig(t *testing.T) {
+	mons := map[string]*cephclient.MonInfo{
+		"foo": {Name: "foo", Endpoint: "1.2.3.4:5000"},
+	}
+	s, err := UpdateCsiClusterConfig("[]", "rook-ceph", mons)
+	assert.NoError(t, err)
+
+	// add a subvolume group of the cluster
+	s, err = UpdateCsiSubvolumeGroupConfig(s, "def456", "rook-ceph", "group-a", mons)
+	assert.NoError(t, err)
+	assert.Equal(t,
+		`[{"clusterID":"rook-ceph","monitors":["1.2.3.4:5000"]},{"clusterID":"def456","monitors":["1.2.3.4:5000"],"namespace":"rook-ceph","cephFS":{"subvolumeGroup":"group-a"}}]`, s)
+
+	// the mons of the subvolume group follow the cluster
+	mons["bar"] = &cephclient.MonInfo{Name: "bar", Endpoint: "10.11.12.13:5000"}
+	s, err = UpdateCsiClusterConfig(s, "rook-ceph", mons)
+	assert.NoError(t, err)
+	cc, err := parseCsiClusterConfig(s)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(cc))
+	assert.Equal(t, 2, len(cc[1].Monitors))
+	assert.Equal(t, "group-a", cc[1].CephFS.SubvolumeGroup)
+}
+
+func TestUpdateCsiConnectionsConfig(t *testing.T) {
+	mons := map[string]*cephclient.MonInfo{
+		"foo": {Name: "foo", Endpoint: "1.2.3.4:6789"},
+	}
+	s, err := UpdateCsiClusterConfig("[]", "rook-ceph", mons)
+	assert.NoError(t, err)
+	s, err = UpdateCsiSubvolumeGroupConfig(s, "def456", "rook-ceph", "group-a", mons)
+	assert.NoError(t, err)
+	s, err = UpdateCsiClusterConfig(s, "other", map[string]*cephclient.MonInfo{"a": {Name: "a", Endpoint: "20.1.1.1:6789"}})
+	assert.NoError(t, err)
+
+	// the clients of the cluster connect to the msgr2 port in the secure mode
+	s, err = UpdateCsiConnectionsConfig(s, "rook-ceph", true)
+	assert.NoError(t, err)
+	assert.Equal(t,
+		`[{"clusterID":"rook-ceph","monitors":["1.2.3.4:3300"],"cephFS":{"kernelMountOptions":"ms_mode=secure"}},`+
+			`{"clusterID":"def456","monitors":["1.2.3.4:3300"],"namespace":"rook-ceph","cephFS":{"subvolumeGroup":"group-a","kernelMountOptions":"ms_mode=secure"}},`+
+			`{"clusterID":"other","monitors":["20.1.1.1:6789"]}]`, s)
+
+	// the entries are restored when the encryption is disabled
+	s, err = UpdateCsiClusterConfig(s, "rook-ceph", mons)
+	assert.NoError(t, err)
+	s, err = UpdateCsiConnectionsConfig(s, "rook-ceph", false)
+	assert.NoError(t, err)
+	assert.Equal(t,
+		`[{"clusterID":"rook-ceph","monitors":["1.2.3.4:6789"]},`+
+			`{"clusterID":"def456","monitors":["1.2.3.4:6789"],"namespace":"rook-ceph","cephFS":{"subvolumeGroup":"group-a"}},`+
+			`{"clusterID":"other","monitors":["20.1.1.1:6789"]}]`, s)
+}
+
+func TestSecureConnections(t *testing.T) {
+	defer resetDetectedCSIVersion()
+	resetDetectedCSIVersion()
+	AllowUnsupported = false
+	clusterInfo := cephclient.AdminClusterInfo("rook-ceph")
+	clusterInfo.NetworkSpec.Connections = &cephv1.ConnectionsSpec{Encryption: &cephv1.EncryptionSpec{Enabled: true}}
+	secure := `[{"clusterID":"rook-ceph","monitors":["1.2.3.4:3300"],"cephFS":{"kernelMountOptions":"ms_mode=secure"}}]`
+	insecure := `[{"clusterID":"rook-ceph","monitors":["1.2.3.4:6789"]}]`
+
+	// the entries keep their mode until the version is detected
+	assert.Equal(t, config.ClientsSecureModeUnknown, ClientsSecureMode())
+	assert.True(t, secureConnections(secure, clusterInfo))
+	assert.False(t, secureConnections(insecure, clusterInfo))
+
+	// the default image does not support the kernel mount options
+	setDetectedCSIVersion(&releasev340)
+	<-VersionChecked
+	assert.Equal(t, config.ClientsSecureModeUnsupported, ClientsSecureMode())
+	assert.False(t, secureConnections(secure, clusterInfo))
+
+	setDetectedCSIVersion(&CephCSIVersion{3, 6, 0})
+	assert.Equal(t, config.ClientsSecureModeSupported, ClientsSecureMode())
+	assert.True(t, secureConnections(insecure, clusterInfo))
+
+	// a pending notification is not blocking
+	setDetectedCSIVersion(nil)
+	<-VersionChecked
+	assert.Equal(t, config.ClientsSecureModeSupported, ClientsSecureMode())
+
+	clusterInfo.NetworkSpec.Connections.Encryption.Enabled = false
+	assert.False(t, secureConnections(secure, clusterInfo))
+}
+
+func resetDetectedCSIVersion() {
+	detectedCSIVersionLock.Lock()
+	defer detectedCSIVersionLock.Unlock()
+	detectedCSIVersion = nil
+	csiVersionChecked = false
+}
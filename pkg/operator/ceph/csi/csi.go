@@ -50,8 +50,10 @@ func ValidateAndConfigureDrivers(context *clusterd.Context, namespace, rookImage
 			logger.Errorf("invalid csi version. %+v", err)
 			return
 		}
+		setDetectedCSIVersion(v)
 	} else {
 		logger.Info("Skipping csi version check, since unsupported versions are allowed or csi is disabled")
+		setDetectedCSIVersion(nil)
 	}
 
 	if CSIEnabled() {
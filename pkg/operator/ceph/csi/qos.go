@@ -0,0 +1,138 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package csi
+
+import (
+	"context"
+	"os"
+	"strconv"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// The StorageClass parameters of the rbd QoS limits of the provisioned images. ceph-csi passes the
+// parameters of the StorageClass to the volume attributes of the PersistentVolumes.
+const (
+	QoSIOPSLimitParam      = "qosIOPSLimit"
+	QoSReadIOPSLimitParam  = "qosReadIOPSLimit"
+	QoSWriteIOPSLimitParam = "qosWriteIOPSLimit"
+	QoSBPSLimitParam       = "qosBPSLimit"
+	QoSReadBPSLimitParam   = "qosReadBPSLimit"
+	QoSWriteBPSLimitParam  = "qosWriteBPSLimit"
+)
+
+// RBDVolume is the rbd image of a PersistentVolume provisioned by the rbd driver
+type RBDVolume struct {
+	ClusterID string
+	Pool      string
+	ImageName string
+	// QoS are the limits of the StorageClass of the volume, nil if the StorageClass has none
+	QoS *cephv1.QoSSpec
+}
+
+// GetRBDVolume returns the rbd image of a PersistentVolume, nil if the volume was not provisioned by the
+// rbd driver
+func GetRBDVolume(pv *v1.PersistentVolume) (*RBDVolume, error) {
+	if pv.Spec.CSI == nil || pv.Spec.CSI.Driver != RBDDriverName {
+		return nil, nil
+	}
+	attributes := pv.Spec.CSI.VolumeAttributes
+	volume := &RBDVolume{
+		ClusterID: attributes["clusterID"],
+		Pool:      attributes["pool"],
+		ImageName: attributes["imageName"],
+	}
+	if volume.ClusterID == "" || volume.Pool == "" || volume.ImageName == "" {
+		// statically provisioned volumes do not have the attributes of the image
+		return nil, nil
+	}
+
+	qos, err := parseQoSParameters(attributes)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid qos parameters of volume %q", pv.Name)
+	}
+	volume.QoS = qos
+	return volume, nil
+}
+
+// parseQoSParameters returns the QoS limits of the StorageClass parameters, nil if none is set
+func parseQoSParameters(parameters map[string]string) (*cephv1.QoSSpec, error) {
+	qos := &cephv1.QoSSpec{}
+	limits := map[string]**int64{
+		QoSIOPSLimitParam:      &qos.IOPSLimit,
+		QoSReadIOPSLimitParam:  &qos.ReadIOPSLimit,
+		QoSWriteIOPSLimitParam: &qos.WriteIOPSLimit,
+		QoSBPSLimitParam:       &qos.BPSLimit,
+		QoSReadBPSLimitParam:   &qos.ReadBPSLimit,
+		QoSWriteBPSLimitParam:  &qos.WriteBPSLimit,
+	}
+	for param, limit := range limits {
+		value, ok := parameters[param]
+		if !ok {
+			continue
+		}
+		l, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || l < 0 {
+			return nil, errors.Errorf("invalid %s %q, must be a non-negative integer", param, value)
+		}
+		*limit = &l
+	}
+	if qos.IsEmpty() {
+		return nil, nil
+	}
+	return qos, nil
+}
+
+// GetClusterIDLocation returns the namespace of the CephCluster and the rados namespace of a clusterID of the
+// csi config. The rados namespace is empty for the clusterID of the CephCluster itself. It returns false if
+// the clusterID is not in the csi config.
+func GetClusterIDLocation(clientset kubernetes.Interface, clusterID string) (string, string, bool, error) {
+	// csi is deployed into the same namespace as the operator
+	csiNamespace := os.Getenv(k8sutil.PodNamespaceEnvVar)
+	if csiNamespace == "" {
+		return "", "", false, errors.Errorf("namespace value missing for %s", k8sutil.PodNamespaceEnvVar)
+	}
+	configMap, err := clientset.CoreV1().ConfigMaps(csiNamespace).Get(context.TODO(), ConfigName, metav1.GetOptions{})
+	if err != nil {
+		return "", "", false, errors.Wrap(err, "failed to fetch current csi config map")
+	}
+
+	currData := configMap.Data[ConfigKey]
+	if currData == "" {
+		currData = "[]"
+	}
+	cc, err := parseCsiClusterConfig(currData)
+	if err != nil {
+		return "", "", false, errors.Wrap(err, "failed to parse current csi cluster config")
+	}
+	for _, centry := range cc {
+		if centry.ClusterID != clusterID {
+			continue
+		}
+		if centry.Namespace == "" {
+			// the clusterID of the cluster entry is the namespace of the cluster
+			return clusterID, "", true, nil
+		}
+		return centry.Namespace, centry.RadosNamespace, true, nil
+	}
+	return "", "", false, nil
+}
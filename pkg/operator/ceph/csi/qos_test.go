@@ -0,0 +1,98 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package csi
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	testop "github.com/rook/rook/pkg/operator/test"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetRBDVolume(t *testing.T) {
+	RBDDriverName = "rook-ceph.rbd.csi.ceph.com"
+	defer func() { RBDDriverName = "" }()
+
+	pv := &v1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: "pvc-1"}}
+	volume, err := GetRBDVolume(pv)
+	assert.NoError(t, err)
+	assert.Nil(t, volume)
+
+	pv.Spec.CSI = &v1.CSIPersistentVolumeSource{
+		Driver: RBDDriverName,
+		VolumeAttributes: map[string]string{
+			"clusterID": "rook-ceph",
+			"pool":      "replicapool",
+			"imageName": "csi-vol-1",
+		},
+	}
+	volume, err = GetRBDVolume(pv)
+	assert.NoError(t, err)
+	assert.Equal(t, &RBDVolume{ClusterID: "rook-ceph", Pool: "replicapool", ImageName: "csi-vol-1"}, volume)
+
+	pv.Spec.CSI.VolumeAttributes[QoSIOPSLimitParam] = "500"
+	pv.Spec.CSI.VolumeAttributes[QoSWriteBPSLimitParam] = "1048576"
+	volume, err = GetRBDVolume(pv)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(500), *volume.QoS.IOPSLimit)
+	assert.Equal(t, int64(1048576), *volume.QoS.WriteBPSLimit)
+	assert.Nil(t, volume.QoS.ReadIOPSLimit)
+
+	pv.Spec.CSI.VolumeAttributes[QoSReadIOPSLimitParam] = "-1"
+	_, err = GetRBDVolume(pv)
+	assert.Error(t, err)
+
+	// another driver
+	pv.Spec.CSI.Driver = "rook-ceph.cephfs.csi.ceph.com"
+	volume, err = GetRBDVolume(pv)
+	assert.NoError(t, err)
+	assert.Nil(t, volume)
+}
+
+func TestGetClusterIDLocation(t *testing.T) {
+	os.Setenv(k8sutil.PodNamespaceEnvVar, "rook-ceph-operator")
+	defer os.Unsetenv(k8sutil.PodNamespaceEnvVar)
+	clientset := testop.New(t, 1)
+	cm := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: ConfigName, Namespace: "rook-ceph-operator"},
+		Data: map[string]string{ConfigKey: `[{"clusterID":"rook-ceph","monitors":["1.2.3.4:6789"]},` +
+			`{"clusterID":"59a1353572e8938141082fc6e4f4ce5c","monitors":["1.2.3.4:6789"],"namespace":"rook-ceph","radosNamespace":"team-a"}]`},
+	}
+	_, err := clientset.CoreV1().ConfigMaps("rook-ceph-operator").Create(context.TODO(), cm, metav1.CreateOptions{})
+	assert.NoError(t, err)
+
+	clusterNamespace, radosNamespace, found, err := GetClusterIDLocation(clientset, "rook-ceph")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "rook-ceph", clusterNamespace)
+	assert.Equal(t, "", radosNamespace)
+
+	clusterNamespace, radosNamespace, found, err = GetClusterIDLocation(clientset, "59a1353572e8938141082fc6e4f4ce5c")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "rook-ceph", clusterNamespace)
+	assert.Equal(t, "team-a", radosNamespace)
+
+	_, _, found, err = GetClusterIDLocation(clientset, "other")
+	assert.NoError(t, err)
+	assert.False(t, found)
+}
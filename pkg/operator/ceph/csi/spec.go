@@ -37,6 +37,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/version"
 	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 )
 
 type Param struct {
@@ -94,6 +95,15 @@ var (
 
 	csiLock      sync.Mutex
 	csiDriverobj csiDriver
+
+	// detectedCSIVersion is the version of the ceph-csi image last detected by the operator
+	detectedCSIVersion     *CephCSIVersion
+	detectedCSIVersionLock sync.Mutex
+	// csiVersionChecked is whether the operator checked the ceph-csi version since it started
+	csiVersionChecked bool
+
+	// VersionChecked notifies the cluster controller that the ceph-csi version was checked
+	VersionChecked = make(chan event.GenericEvent, 1)
 )
 
 // Specify default images as var instead of const so that they can be overridden with the Go
@@ -187,6 +197,11 @@ const (
 	csiCephFSProvisioner = "csi-cephfsplugin-provisioner"
 )
 
+// PodAppNames returns the app labels of the CSI pods, which connect to the Ceph daemons
+func PodAppNames() []string {
+	return []string{csiRBDPlugin, csiCephFSPlugin, csiRBDProvisioner, csiCephFSProvisioner}
+}
+
 func CSIEnabled() bool {
 	return EnableRBD || EnableCephFS
 }
@@ -662,20 +677,16 @@ func deleteCSIDriverResources(
 	return succeeded
 }
 
+// applyCephClusterNetworkConfig attaches the CSI pods to the public network of the clusters using Multus. The CSI
+// driver is shared by the clusters of all the namespaces, so the pods are attached to the network of each cluster.
 func applyCephClusterNetworkConfig(ctx context.Context, objectMeta *metav1.ObjectMeta, rookclientset rookclient.Interface) (bool, error) {
-	var isMultusApplied bool
-	cephClusters, err := rookclientset.CephV1().CephClusters(objectMeta.Namespace).List(ctx, metav1.ListOptions{})
+	cephClusters, err := rookclientset.CephV1().CephClusters(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
 	if err != nil {
 		return false, errors.Wrap(err, "failed to find CephClusters")
 	}
-	for _, cephCluster := range cephClusters.Items {
-		if cephCluster.Spec.Network.IsMultus() {
-			err = k8sutil.ApplyMultus(cephCluster.Spec.Network, objectMeta)
-			if err != nil {
-				return false, errors.Wrapf(err, "failed to apply multus configuration to CephCluster %q", cephCluster.Name)
-			}
-			isMultusApplied = true
-		}
+	isMultusApplied, err := k8sutil.ApplyMultusPublicNetworks(cephClusters.Items, objectMeta)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to apply multus configuration of the CephClusters")
 	}
 
 	return isMultusApplied, nil
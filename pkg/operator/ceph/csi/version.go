@@ -41,6 +41,8 @@ var (
 	}
 	// omap generator is supported in v3.2.0+
 	omapSupportedVersions = releasev320
+	// the kernel mount options of the cluster config are supported in v3.6.0+
+	kernelMountOptionsSupportedVersion = CephCSIVersion{3, 6, 0}
 	// for parsing the output of `cephcsi`
 	versionCSIPattern = regexp.MustCompile(`v(\d+)\.(\d+)\.(\d+)`)
 )
@@ -84,6 +86,16 @@ func (v *CephCSIVersion) SupportsOMAPController() bool {
 	return false
 }
 
+// SupportsKernelMountOptions checks if the detected version supports the kernel mount options of the cluster config
+func (v *CephCSIVersion) SupportsKernelMountOptions() bool {
+	// if AllowUnsupported is set also a csi-image greater than the supported ones are allowed
+	if AllowUnsupported {
+		return true
+	}
+
+	return v.isAtLeast(&kernelMountOptionsSupportedVersion)
+}
+
 // Supported checks if the detected version is part of the known supported CSI versions
 func (v *CephCSIVersion) Supported() bool {
 	if !v.isAtLeast(&minimum) {
@@ -132,6 +132,16 @@ func TestSupportOMAPController(t *testing.T) {
 	ret = testReleaseV330.SupportsOMAPController()
 	assert.True(t, ret)
 }
+
+func TestSupportsKernelMountOptions(t *testing.T) {
+	AllowUnsupported = true
+	assert.True(t, testReleaseV340.SupportsKernelMountOptions())
+
+	AllowUnsupported = false
+	assert.False(t, testReleaseV340.SupportsKernelMountOptions())
+	assert.True(t, (&CephCSIVersion{3, 6, 0}).SupportsKernelMountOptions())
+	assert.True(t, (&CephCSIVersion{4, 0, 0}).SupportsKernelMountOptions())
+}
 func Test_extractCephCSIVersion(t *testing.T) {
 	expectedVersion := CephCSIVersion{3, 0, 0}
 	csiString := []byte(`Cephcsi Version: v3.0.0
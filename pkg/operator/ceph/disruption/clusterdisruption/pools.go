@@ -182,7 +182,7 @@ func (r *ReconcileClusterDisruption) reconcileCephFilesystem(cephFilesystemList
 			MatchLabels: map[string]string{"rook_file_system": fsName},
 		}
 
-		activeCount := filesystem.Spec.MetadataServer.ActiveCount
+		activeCount := filesystem.ActiveMDSCount()
 		minAvailable := &intstr.IntOrString{IntVal: activeCount - 1}
 		if filesystem.Spec.MetadataServer.ActiveStandby {
 			minAvailable.IntVal++
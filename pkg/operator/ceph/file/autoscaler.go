@@ -0,0 +1,344 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package file
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	opconfig "github.com/rook/rook/pkg/operator/ceph/config"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	defaultMDSAutoscalingInterval                = 1 * time.Minute
+	defaultMDSScaleDownStabilizationWindow       = 10 * time.Minute
+	defaultMDSScaleUpRequestRate           int64 = 1000
+	defaultMDSCachePressureThreshold             = 80
+	// timeout if the active ranks do not become desired after scaling
+	mdsAutoscalingWaitForActiveTimeout = 3 * time.Minute
+)
+
+// mdsAutoscalingSettings are the autoscaling settings of a filesystem with the defaults applied
+type mdsAutoscalingSettings struct {
+	minActiveCount         int32
+	maxActiveCount         int32
+	scaleUpRequestRate     int64
+	scaleDownRequestRate   int64
+	cachePressureThreshold int
+	interval               time.Duration
+	stabilizationWindow    time.Duration
+}
+
+// mdsLoad is the load of the active metadata servers of a filesystem
+type mdsLoad struct {
+	// requestRate is the client request rate per second of all the active metadata servers
+	requestRate float64
+	// cacheUsage is the highest usage of the cache of the active metadata servers in percent
+	cacheUsage int
+}
+
+type mdsAutoscaler struct {
+	context        *clusterd.Context
+	client         client.Client
+	clusterInfo    *cephclient.ClusterInfo
+	namespacedName types.NamespacedName
+	interval       time.Duration
+	// lastRequests are the client request counters of the active metadata servers at the last check
+	lastRequests map[string]int64
+	lastChecked  time.Time
+	// lowLoadSince is the time since which the load allows to deactivate a metadata server
+	lowLoadSince time.Time
+}
+
+func newMDSAutoscalingSettings(spec *cephv1.MetadataServerSpec) mdsAutoscalingSettings {
+	s := mdsAutoscalingSettings{
+		minActiveCount:         spec.MinActiveCount(),
+		maxActiveCount:         spec.MaxActiveCount(),
+		scaleUpRequestRate:     defaultMDSScaleUpRequestRate,
+		cachePressureThreshold: defaultMDSCachePressureThreshold,
+		interval:               defaultMDSAutoscalingInterval,
+		stabilizationWindow:    defaultMDSScaleDownStabilizationWindow,
+	}
+	if spec.Autoscaling == nil {
+		s.scaleDownRequestRate = s.scaleUpRequestRate / 2
+		return s
+	}
+	if spec.Autoscaling.ScaleUpRequestRate > 0 {
+		s.scaleUpRequestRate = spec.Autoscaling.ScaleUpRequestRate
+	}
+	s.scaleDownRequestRate = s.scaleUpRequestRate / 2
+	if spec.Autoscaling.ScaleDownRequestRate > 0 {
+		s.scaleDownRequestRate = spec.Autoscaling.ScaleDownRequestRate
+	}
+	if spec.Autoscaling.CachePressureThreshold > 0 {
+		s.cachePressureThreshold = spec.Autoscaling.CachePressureThreshold
+	}
+	if spec.Autoscaling.Interval != nil && spec.Autoscaling.Interval.Duration > 0 {
+		s.interval = spec.Autoscaling.Interval.Duration
+	}
+	if spec.Autoscaling.ScaleDownStabilizationWindow != nil {
+		s.stabilizationWindow = spec.Autoscaling.ScaleDownStabilizationWindow.Duration
+	}
+	return s
+}
+
+// validateMDSAutoscaling validates the autoscaling settings of a filesystem
+func validateMDSAutoscaling(spec *cephv1.MetadataServerSpec) error {
+	if !spec.IsAutoscalingEnabled() {
+		return nil
+	}
+	if spec.Autoscaling.MaxActiveCount < spec.MinActiveCount() {
+		return errors.Errorf("autoscaling maxActiveCount %d must be at least the minimum number of active mds %d", spec.Autoscaling.MaxActiveCount, spec.MinActiveCount())
+	}
+	s := newMDSAutoscalingSettings(spec)
+	if s.scaleDownRequestRate >= s.scaleUpRequestRate {
+		return errors.Errorf("autoscaling scaleDownRequestRate %d must be lower than the scaleUpRequestRate %d", s.scaleDownRequestRate, s.scaleUpRequestRate)
+	}
+	return nil
+}
+
+// newMDSAutoscaler creates a new autoscaler of the active metadata servers of a filesystem
+func newMDSAutoscaler(context *clusterd.Context, client client.Client, clusterInfo *cephclient.ClusterInfo, namespacedName types.NamespacedName, spec *cephv1.MetadataServerSpec) *mdsAutoscaler {
+	return &mdsAutoscaler{
+		context:        context,
+		client:         client,
+		clusterInfo:    clusterInfo,
+		namespacedName: namespacedName,
+		interval:       newMDSAutoscalingSettings(spec).interval,
+	}
+}
+
+// run periodically adjusts the number of active metadata servers to the load until the filesystem is deleted
+func (a *mdsAutoscaler) run(stopCh chan struct{}) {
+	logger.Infof("starting the autoscaling of the active mds of filesystem %q every %s", a.namespacedName.Name, a.interval)
+	for {
+		select {
+		case <-stopCh:
+			logger.Infof("stopping the autoscaling of the active mds of filesystem %q", a.namespacedName.Name)
+			return
+
+		case <-time.After(a.interval):
+			if err := a.check(time.Now()); err != nil {
+				logger.Errorf("failed to autoscale the active mds of filesystem %q. %v", a.namespacedName.Name, err)
+			}
+		}
+	}
+}
+
+// check measures the load of the active metadata servers and activates or deactivates one of them if needed
+func (a *mdsAutoscaler) check(now time.Time) error {
+	fs := &cephv1.CephFilesystem{}
+	if err := a.client.Get(context.TODO(), a.namespacedName, fs); err != nil {
+		if kerrors.IsNotFound(err) {
+			return nil
+		}
+		return errors.Wrapf(err, "failed to get filesystem %q", a.namespacedName.Name)
+	}
+	// the next check follows the interval of the current spec
+	settings := newMDSAutoscalingSettings(&fs.Spec.MetadataServer)
+	if settings.interval != a.interval {
+		logger.Infof("autoscaling the active mds of filesystem %q every %s", a.namespacedName.Name, settings.interval)
+		a.interval = settings.interval
+	}
+	if !fs.Spec.MetadataServer.IsAutoscalingEnabled() {
+		a.reset()
+		return nil
+	}
+
+	details, err := cephclient.GetFilesystem(a.context, a.clusterInfo, fs.Name)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get filesystem %q", fs.Name)
+	}
+	active := details.MDSMap.ActiveDaemons()
+	if details.MDSMap.MaxMDS != int(fs.ActiveMDSCount()) || len(active) != details.MDSMap.MaxMDS {
+		// the ranks are changing, e.g. during an upgrade, measure again once they are settled
+		logger.Debugf("waiting for the %d active mds of filesystem %q to become %d before autoscaling", len(active), fs.Name, fs.ActiveMDSCount())
+		a.reset()
+		return nil
+	}
+
+	load, measured, err := a.measureLoad(active, now)
+	if err != nil {
+		return err
+	}
+	if !measured {
+		// the request rate is measured from two samples of the counters
+		return nil
+	}
+
+	current := int32(len(active))
+	status := &cephv1.MDSAutoscalingStatus{
+		ActiveCount: current,
+		RequestRate: int64(load.requestRate),
+		CacheUsage:  load.cacheUsage,
+		LastChecked: now.UTC().Format(time.RFC3339),
+	}
+	var previous *cephv1.MDSAutoscalingStatus
+	if fs.Status != nil && fs.Status.MDSAutoscaling != nil {
+		previous = fs.Status.MDSAutoscaling
+		status.LastScaled = previous.LastScaled
+		status.Reason = previous.Reason
+	}
+
+	desired, reason := a.desiredActiveCount(settings, current, load, now)
+	if desired == current {
+		if mdsAutoscalingStatusChanged(previous, status) {
+			updateStatusMDSAutoscaling(a.client, a.namespacedName, status)
+		}
+		return nil
+	}
+
+	logger.Infof("scaling the active mds of filesystem %q from %d to %d, %s", fs.Name, current, desired, reason)
+	if err := cephclient.SetNumMDSRanks(a.context, a.clusterInfo, fs.Name, desired); err != nil {
+		return errors.Wrapf(err, "failed to scale the active mds of filesystem %q to %d", fs.Name, desired)
+	}
+	status.ActiveCount = desired
+	status.LastScaled = status.LastChecked
+	status.Reason = reason
+	updateStatusMDSAutoscaling(a.client, a.namespacedName, status)
+	a.reset()
+
+	// do not scale further before the ranks are settled
+	if err := cephclient.WaitForActiveRanks(a.context, a.clusterInfo, fs.Name, desired, false, mdsAutoscalingWaitForActiveTimeout); err != nil {
+		return errors.Wrapf(err, "failed to wait for the active mds of filesystem %q to become %d", fs.Name, desired)
+	}
+	return nil
+}
+
+// measureLoad measures the load of the active metadata servers since the last check. It returns false if there
+// is no previous sample of the counters of all the active metadata servers.
+func (a *mdsAutoscaler) measureLoad(active []cephclient.MDSInfo, now time.Time) (mdsLoad, bool, error) {
+	load := mdsLoad{}
+	requests := map[string]int64{}
+	monStore := opconfig.GetMonStore(a.context, a.clusterInfo)
+	for _, daemon := range active {
+		counters, err := cephclient.GetMDSPerfCounters(a.context, a.clusterInfo, daemon.Name)
+		if err != nil {
+			return load, false, err
+		}
+		requests[daemon.Name] = counters.MDSServer.HandleClientRequest
+
+		limit, err := monStore.Get(fmt.Sprintf("mds.%s", daemon.Name), "mds_cache_memory_limit")
+		if err != nil {
+			return load, false, errors.Wrapf(err, "failed to get the cache memory limit of mds %q", daemon.Name)
+		}
+		cacheLimit, err := strconv.ParseInt(limit, 10, 64)
+		if err != nil {
+			return load, false, errors.Wrapf(err, "failed to parse the cache memory limit %q of mds %q", limit, daemon.Name)
+		}
+		if cacheLimit > 0 {
+			usage := int(counters.Mempool.CacheBytes * 100 / cacheLimit)
+			if usage > load.cacheUsage {
+				load.cacheUsage = usage
+			}
+		}
+	}
+
+	previous, elapsed := a.lastRequests, now.Sub(a.lastChecked).Seconds()
+	a.lastRequests, a.lastChecked = requests, now
+	if previous == nil || elapsed <= 0 {
+		return load, false, nil
+	}
+	for name, count := range requests {
+		last, ok := previous[name]
+		if !ok || count < last {
+			// the daemon became active or restarted since the last check
+			return load, false, nil
+		}
+		load.requestRate += float64(count-last) / elapsed
+	}
+	return load, true, nil
+}
+
+// desiredActiveCount returns the number of active metadata servers for the load and the reason to change it.
+// A metadata server is deactivated only after the load stayed low for the stabilization window.
+func (a *mdsAutoscaler) desiredActiveCount(s mdsAutoscalingSettings, current int32, load mdsLoad, now time.Time) (int32, string) {
+	switch {
+	case current < s.minActiveCount:
+		a.lowLoadSince = time.Time{}
+		return s.minActiveCount, fmt.Sprintf("below the minimum of %d active mds", s.minActiveCount)
+	case current > s.maxActiveCount:
+		a.lowLoadSince = time.Time{}
+		return s.maxActiveCount, fmt.Sprintf("above the maximum of %d active mds", s.maxActiveCount)
+	}
+
+	if load.cacheUsage > s.cachePressureThreshold {
+		a.lowLoadSince = time.Time{}
+		if current < s.maxActiveCount {
+			return current + 1, fmt.Sprintf("cache usage of %d%% above %d%%", load.cacheUsage, s.cachePressureThreshold)
+		}
+		return current, ""
+	}
+	perRank := load.requestRate / float64(current)
+	if perRank > float64(s.scaleUpRequestRate) {
+		a.lowLoadSince = time.Time{}
+		if current < s.maxActiveCount {
+			return current + 1, fmt.Sprintf("request rate of %.0f/s per active mds above %d/s", perRank, s.scaleUpRequestRate)
+		}
+		return current, ""
+	}
+
+	if current <= s.minActiveCount || !isLowMDSLoad(s, current, load) {
+		a.lowLoadSince = time.Time{}
+		return current, ""
+	}
+	if a.lowLoadSince.IsZero() {
+		a.lowLoadSince = now
+	}
+	if now.Sub(a.lowLoadSince) < s.stabilizationWindow {
+		return current, ""
+	}
+	a.lowLoadSince = time.Time{}
+	return current - 1, fmt.Sprintf("request rate of %.0f/s per active mds below %d/s for %s", perRank, s.scaleDownRequestRate, s.stabilizationWindow)
+}
+
+// isLowMDSLoad returns whether one less active metadata server could handle the load
+func isLowMDSLoad(s mdsAutoscalingSettings, current int32, load mdsLoad) bool {
+	if current <= 1 {
+		return false
+	}
+	remaining := float64(current - 1)
+	if load.requestRate/remaining >= float64(s.scaleDownRequestRate) {
+		return false
+	}
+	// the cache of the deactivated metadata server is spread on the remaining ones
+	return float64(load.cacheUsage)*float64(current)/remaining <= float64(s.cachePressureThreshold)
+}
+
+// mdsAutoscalingStatusChanged returns whether the scaling decision of the status changed. The measured load
+// changes with every sample and is only written along with a change of the decision.
+func mdsAutoscalingStatusChanged(previous, status *cephv1.MDSAutoscalingStatus) bool {
+	if previous == nil {
+		return true
+	}
+	return previous.ActiveCount != status.ActiveCount || previous.LastScaled != status.LastScaled || previous.Reason != status.Reason
+}
+
+// reset drops the samples of the counters, the active metadata servers changed
+func (a *mdsAutoscaler) reset() {
+	a.lastRequests = nil
+	a.lowLoadSince = time.Time{}
+}
@@ -0,0 +1,228 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package file
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/client/clientset/versioned/scheme"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestMDSAutoscalingSettings(t *testing.T) {
+	spec := &cephv1.MetadataServerSpec{ActiveCount: 1}
+	assert.NoError(t, validateMDSAutoscaling(spec))
+
+	spec.Autoscaling = &cephv1.MDSAutoscalingSpec{Enabled: true, MaxActiveCount: 3}
+	s := newMDSAutoscalingSettings(spec)
+	assert.Equal(t, int32(1), s.minActiveCount)
+	assert.Equal(t, int32(3), s.maxActiveCount)
+	assert.Equal(t, int64(1000), s.scaleUpRequestRate)
+	assert.Equal(t, int64(500), s.scaleDownRequestRate)
+	assert.Equal(t, 80, s.cachePressureThreshold)
+	assert.Equal(t, time.Minute, s.interval)
+	assert.Equal(t, 10*time.Minute, s.stabilizationWindow)
+	assert.NoError(t, validateMDSAutoscaling(spec))
+
+	spec.Autoscaling.ScaleUpRequestRate = 200
+	spec.Autoscaling.ScaleDownRequestRate = 200
+	assert.Error(t, validateMDSAutoscaling(spec))
+	spec.Autoscaling.ScaleDownRequestRate = 50
+	assert.NoError(t, validateMDSAutoscaling(spec))
+
+	spec.Autoscaling.MinActiveCount = 4
+	assert.Error(t, validateMDSAutoscaling(spec))
+}
+
+func TestMDSDesiredActiveCount(t *testing.T) {
+	s := newMDSAutoscalingSettings(&cephv1.MetadataServerSpec{
+		ActiveCount: 1,
+		Autoscaling: &cephv1.MDSAutoscalingSpec{Enabled: true, MaxActiveCount: 3, ScaleUpRequestRate: 100},
+	})
+	a := &mdsAutoscaler{}
+	now := time.Now()
+
+	// scale up on the request rate
+	desired, reason := a.desiredActiveCount(s, 1, mdsLoad{requestRate: 150}, now)
+	assert.Equal(t, int32(2), desired)
+	assert.Contains(t, reason, "request rate of 150/s")
+	desired, _ = a.desiredActiveCount(s, 2, mdsLoad{requestRate: 150}, now)
+	assert.Equal(t, int32(2), desired)
+
+	// scale up on the cache pressure, not above the max
+	desired, reason = a.desiredActiveCount(s, 2, mdsLoad{cacheUsage: 90}, now)
+	assert.Equal(t, int32(3), desired)
+	assert.Contains(t, reason, "cache usage of 90%")
+	desired, _ = a.desiredActiveCount(s, 3, mdsLoad{cacheUsage: 90}, now)
+	assert.Equal(t, int32(3), desired)
+
+	// scale down only after the stabilization window
+	desired, _ = a.desiredActiveCount(s, 3, mdsLoad{requestRate: 20, cacheUsage: 10}, now)
+	assert.Equal(t, int32(3), desired)
+	desired, _ = a.desiredActiveCount(s, 3, mdsLoad{requestRate: 20, cacheUsage: 10}, now.Add(5*time.Minute))
+	assert.Equal(t, int32(3), desired)
+	desired, reason = a.desiredActiveCount(s, 3, mdsLoad{requestRate: 20, cacheUsage: 10}, now.Add(10*time.Minute))
+	assert.Equal(t, int32(2), desired)
+	assert.Contains(t, reason, "below 50/s")
+
+	// a load spike restarts the stabilization window
+	desired, _ = a.desiredActiveCount(s, 2, mdsLoad{requestRate: 20}, now)
+	assert.Equal(t, int32(2), desired)
+	desired, _ = a.desiredActiveCount(s, 2, mdsLoad{requestRate: 80}, now.Add(5*time.Minute))
+	assert.Equal(t, int32(2), desired)
+	desired, _ = a.desiredActiveCount(s, 2, mdsLoad{requestRate: 20}, now.Add(10*time.Minute))
+	assert.Equal(t, int32(2), desired)
+
+	// the cache of the remaining mds would be under pressure
+	a.lowLoadSince = time.Time{}
+	desired, _ = a.desiredActiveCount(s, 2, mdsLoad{requestRate: 20, cacheUsage: 50}, now)
+	assert.Equal(t, int32(2), desired)
+	desired, _ = a.desiredActiveCount(s, 2, mdsLoad{requestRate: 20, cacheUsage: 50}, now.Add(time.Hour))
+	assert.Equal(t, int32(2), desired)
+
+	// never below the min
+	desired, _ = a.desiredActiveCount(s, 1, mdsLoad{}, now.Add(time.Hour))
+	assert.Equal(t, int32(1), desired)
+
+	// back within the bounds
+	s.minActiveCount = 2
+	desired, _ = a.desiredActiveCount(s, 1, mdsLoad{}, now)
+	assert.Equal(t, int32(2), desired)
+	desired, _ = a.desiredActiveCount(s, 4, mdsLoad{}, now)
+	assert.Equal(t, int32(3), desired)
+}
+
+func TestMDSAutoscalerCheck(t *testing.T) {
+	ctx := context.TODO()
+	namespacedName := types.NamespacedName{Name: "myfs", Namespace: "rook-ceph"}
+	fs := &cephv1.CephFilesystem{
+		ObjectMeta: metav1.ObjectMeta{Name: namespacedName.Name, Namespace: namespacedName.Namespace},
+		Spec: cephv1.FilesystemSpec{
+			MetadataServer: cephv1.MetadataServerSpec{
+				ActiveCount: 1,
+				Autoscaling: &cephv1.MDSAutoscalingSpec{Enabled: true, MaxActiveCount: 2, ScaleUpRequestRate: 100},
+			},
+		},
+	}
+
+	maxMDS := 1
+	requests := 0
+	maxMDSSet := ""
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutput: func(command string, args ...string) (string, error) {
+			switch {
+			case args[0] == "fs" && args[1] == "get":
+				info := `"gid_1":{"gid":1,"name":"myfs-a","rank":0,"state":"up:active"},"gid_2":{"gid":2,"name":"myfs-b","rank":-1,"state":"up:standby-replay"}`
+				up := `"mds_0":1`
+				if maxMDS == 2 {
+					info = `"gid_1":{"gid":1,"name":"myfs-a","rank":0,"state":"up:active"},"gid_2":{"gid":2,"name":"myfs-b","rank":1,"state":"up:active"}`
+					up = `"mds_0":1,"mds_1":2`
+				}
+				return fmt.Sprintf(`{"mdsmap":{"fs_name":"myfs","max_mds":%d,"up":{%s},"info":{%s}},"id":1}`, maxMDS, up, info), nil
+			case args[0] == "tell" && args[1] == "mds.myfs-a":
+				return fmt.Sprintf(`{"mds_server":{"handle_client_request":%d},"mempool":{"mds_co_bytes":100}}`, requests), nil
+			case args[0] == "config" && args[1] == "get":
+				return "1000", nil
+			case args[0] == "fs" && args[1] == "set" && args[3] == "max_mds":
+				maxMDSSet = args[4]
+				maxMDS = 2
+				return "", nil
+			}
+			return "", errors.Errorf("unexpected ceph command %q", args)
+		},
+	}
+
+	s := scheme.Scheme
+	s.AddKnownTypes(cephv1.SchemeGroupVersion, &cephv1.CephFilesystem{})
+	cl := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(fs).Build()
+	a := newMDSAutoscaler(&clusterd.Context{Executor: executor}, cl, cephclient.AdminClusterInfo("rook-ceph"), namespacedName, &fs.Spec.MetadataServer)
+	assert.Equal(t, time.Minute, a.interval)
+
+	// the first check only samples the counters
+	now := time.Now()
+	assert.NoError(t, a.check(now))
+	assert.Equal(t, "", maxMDSSet)
+
+	// the request rate per active mds is below the threshold
+	requests = 3000
+	assert.NoError(t, a.check(now.Add(time.Minute)))
+	assert.Equal(t, "", maxMDSSet)
+	assert.NoError(t, cl.Get(ctx, namespacedName, fs))
+	assert.Equal(t, int64(50), fs.Status.MDSAutoscaling.RequestRate)
+	resourceVersion := fs.ResourceVersion
+
+	// the status is not written again if only the measured load changed
+	requests = 7000
+	assert.NoError(t, a.check(now.Add(2*time.Minute)))
+	assert.NoError(t, cl.Get(ctx, namespacedName, fs))
+	assert.Equal(t, resourceVersion, fs.ResourceVersion)
+
+	// the request rate per active mds is above the threshold
+	requests = 19000
+	assert.NoError(t, a.check(now.Add(3*time.Minute)))
+	assert.Equal(t, "2", maxMDSSet)
+
+	assert.NoError(t, cl.Get(ctx, namespacedName, fs))
+	assert.Equal(t, int32(2), fs.Status.MDSAutoscaling.ActiveCount)
+	assert.Equal(t, int64(200), fs.Status.MDSAutoscaling.RequestRate)
+	assert.Equal(t, 10, fs.Status.MDSAutoscaling.CacheUsage)
+	assert.Contains(t, fs.Status.MDSAutoscaling.Reason, "request rate of 200/s")
+	assert.Equal(t, int32(2), fs.ActiveMDSCount())
+
+	// the ranks are settled, the counters are sampled again
+	assert.Nil(t, a.lastRequests)
+
+	// autoscaling is disabled, the interval of the spec applies to the next check
+	fs.Spec.MetadataServer.Autoscaling.Enabled = false
+	fs.Spec.MetadataServer.Autoscaling.Interval = &metav1.Duration{Duration: 5 * time.Minute}
+	assert.NoError(t, cl.Update(ctx, fs))
+	maxMDSSet = ""
+	assert.NoError(t, a.check(now.Add(4*time.Minute)))
+	assert.Equal(t, "", maxMDSSet)
+	assert.Equal(t, 5*time.Minute, a.interval)
+}
+
+func TestMDSAutoscalingStatusChanged(t *testing.T) {
+	status := &cephv1.MDSAutoscalingStatus{ActiveCount: 2, RequestRate: 100, CacheUsage: 10, LastChecked: "2021-10-01T10:01:00Z"}
+	assert.True(t, mdsAutoscalingStatusChanged(nil, status))
+
+	previous := *status
+	previous.LastChecked = "2021-10-01T10:00:00Z"
+	assert.False(t, mdsAutoscalingStatusChanged(&previous, status))
+
+	// the measured load changes with every sample
+	previous.RequestRate = 90
+	previous.CacheUsage = 20
+	assert.False(t, mdsAutoscalingStatusChanged(&previous, status))
+
+	previous.ActiveCount = 1
+	assert.True(t, mdsAutoscalingStatusChanged(&previous, status))
+	previous.ActiveCount = status.ActiveCount
+	previous.Reason = "request rate of 150/s per active mds above 100/s"
+	assert.True(t, mdsAutoscalingStatusChanged(&previous, status))
+}
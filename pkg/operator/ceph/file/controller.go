@@ -80,6 +80,7 @@ type ReconcileCephFilesystem struct {
 type fsHealth struct {
 	stopChan          chan struct{}
 	monitoringRunning bool
+	autoscalerRunning bool
 }
 
 // Add creates a new CephFilesystem Controller and adds it to the Manager. The Manager will set fields on the Controller
@@ -284,6 +285,17 @@ func (r *ReconcileCephFilesystem) reconcile(request reconcile.Request) (reconcil
 		return reconcileResponse, err
 	}
 
+	// Start the autoscaling of the active mds if needed, the autoscaler stays idle if it is disabled later
+	if cephFilesystem.Spec.MetadataServer.IsAutoscalingEnabled() {
+		if r.fsChannels[fsChannelKeyName(cephFilesystem)].autoscalerRunning {
+			logger.Debug("ceph filesystem mds autoscaling go routine already running!")
+		} else {
+			autoscaler := newMDSAutoscaler(r.context, r.client, r.clusterInfo, request.NamespacedName, &cephFilesystem.Spec.MetadataServer)
+			r.fsChannels[fsChannelKeyName(cephFilesystem)].autoscalerRunning = true
+			go autoscaler.run(r.fsChannels[fsChannelKeyName(cephFilesystem)].stopChan)
+		}
+	}
+
 	// Enable mirroring if needed
 	if r.clusterInfo.CephVersion.IsAtLeast(mirror.PeerAdditionMinVersion) {
 		// Disable mirroring on that filesystem if needed
@@ -69,10 +69,11 @@ func createFilesystem(
 		}
 	}
 
-	// set the number of active mds instances
-	if fs.Spec.MetadataServer.ActiveCount > 1 {
-		if err = cephclient.SetNumMDSRanks(context, clusterInfo, fs.Name, fs.Spec.MetadataServer.ActiveCount); err != nil {
-			logger.Warningf("failed setting active mds count to %d. %v", fs.Spec.MetadataServer.ActiveCount, err)
+	// set the number of active mds instances, with autoscaling it is the last number set by the autoscaler
+	activeCount := fs.ActiveMDSCount()
+	if activeCount > 1 || fs.Spec.MetadataServer.IsAutoscalingEnabled() {
+		if err = cephclient.SetNumMDSRanks(context, clusterInfo, fs.Name, activeCount); err != nil {
+			logger.Warningf("failed setting active mds count to %d. %v", activeCount, err)
 		}
 	}
 
@@ -105,7 +106,7 @@ func deleteFilesystem(
 	c := mds.NewCluster(clusterInfo, context, clusterSpec, fs, filesystem, ownerInfo, dataDirHostPath)
 
 	// Delete mds CephX keys and configuration in centralized mon database
-	replicas := fs.Spec.MetadataServer.ActiveCount * 2
+	replicas := fs.Spec.MetadataServer.MaxActiveCount() * 2
 	for i := 0; i < int(replicas); i++ {
 		daemonLetterID := k8sutil.IndexToName(i)
 		daemonName := fmt.Sprintf("%s-%s", fs.Name, daemonLetterID)
@@ -142,6 +143,9 @@ func validateFilesystem(context *clusterd.Context, clusterInfo *cephclient.Clust
 	if f.Spec.MetadataServer.ActiveCount < 1 {
 		return errors.New("MetadataServer.ActiveCount must be at least 1")
 	}
+	if err := validateMDSAutoscaling(&f.Spec.MetadataServer); err != nil {
+		return errors.Wrap(err, "invalid mds autoscaling")
+	}
 	// No data pool means that we expect the fs to exist already
 	if len(f.Spec.DataPools) == 0 {
 		return nil
@@ -189,8 +193,11 @@ func SetPoolSize(f *Filesystem, context *clusterd.Context, clusterInfo *cephclie
 
 // updateFilesystem ensures that a filesystem which already exists matches the provided spec.
 func (f *Filesystem) updateFilesystem(context *clusterd.Context, clusterInfo *cephclient.ClusterInfo, clusterSpec *cephv1.ClusterSpec, spec cephv1.FilesystemSpec) error {
-	// Even if the fs already exists, the num active mdses may have changed
-	if err := cephclient.SetNumMDSRanks(context, clusterInfo, f.Name, spec.MetadataServer.ActiveCount); err != nil {
+	// Even if the fs already exists, the num active mdses may have changed.
+	// With autoscaling, the number is set by createFilesystem from the status of the autoscaler.
+	if spec.MetadataServer.IsAutoscalingEnabled() {
+		logger.Debugf("mds autoscaling is enabled for filesystem %q", f.Name)
+	} else if err := cephclient.SetNumMDSRanks(context, clusterInfo, f.Name, spec.MetadataServer.ActiveCount); err != nil {
 		logger.Errorf(
 			fmt.Sprintf("failed to set num mds ranks (max_mds) to %d for filesystem %s, still continuing. ", spec.MetadataServer.ActiveCount, f.Name) +
 				"this error is not critical, but mdses may not be as failure tolerant as desired. " +
@@ -123,13 +123,14 @@ func (c *Cluster) Start() error {
 		if fsPreparedForUpgrade {
 			if err := finishedWithDaemonUpgrade(c.context, c.clusterInfo, c.fs); err != nil {
 				logger.Errorf("for filesystem %q, USER should make sure the Ceph fs max_mds property is set to %d. %v",
-					c.fs.Name, c.fs.Spec.MetadataServer.ActiveCount, err)
+					c.fs.Name, c.fs.ActiveMDSCount(), err)
 			}
 		}
 	}()
 
-	// Always create double the number of metadata servers to have standby mdses available
-	replicas := c.fs.Spec.MetadataServer.ActiveCount * 2
+	// Always create double the number of metadata servers to have standby mdses available. With autoscaling,
+	// there are enough daemons for the maximum number of active mdses.
+	replicas := c.fs.Spec.MetadataServer.MaxActiveCount() * 2
 
 	// keep list of deployments we want so unwanted ones can be deleted later
 	desiredDeployments := map[string]bool{} // improvised set
@@ -142,7 +143,7 @@ func (c *Cluster) Start() error {
 		desiredDeployments[deployment] = true
 	}
 
-	if err := c.scaleDownDeployments(replicas, c.fs.Spec.MetadataServer.ActiveCount, desiredDeployments, true); err != nil {
+	if err := c.scaleDownDeployments(replicas, c.fs.ActiveMDSCount(), desiredDeployments, true); err != nil {
 		return errors.Wrap(err, "failed to scale down mds deployments")
 	}
 
@@ -386,7 +387,7 @@ func (c *Cluster) DeleteMdsCephObjects(mdsID string) error {
 // ideal state following an upgrade of its daemon(s).
 func finishedWithDaemonUpgrade(context *clusterd.Context, clusterInfo *cephclient.ClusterInfo, fs cephv1.CephFilesystem) error {
 	fsName := fs.Name
-	activeMDSCount := fs.Spec.MetadataServer.ActiveCount
+	activeMDSCount := fs.ActiveMDSCount()
 	logger.Debugf("restoring filesystem %s from daemon upgrade", fsName)
 	logger.Debugf("bringing num active MDS daemons for fs %s back to %d", fsName, activeMDSCount)
 	// TODO: Unknown (Apr 2020) if this can be removed once Rook no longer supports Nautilus.
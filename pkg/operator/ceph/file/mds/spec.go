@@ -99,12 +99,8 @@ func (c *Cluster) makeDeployment(mdsConfig *mdsConfig, namespace string) (*apps.
 		},
 	}
 
-	if c.clusterSpec.Network.IsHost() {
-		d.Spec.Template.Spec.DNSPolicy = v1.DNSClusterFirstWithHostNet
-	} else if c.clusterSpec.Network.IsMultus() {
-		if err := k8sutil.ApplyMultus(c.clusterSpec.Network, &podSpec.ObjectMeta); err != nil {
-			return nil, err
-		}
+	if err := k8sutil.ApplyNetworkSettings(c.clusterSpec.Network, &d.Spec.Template.ObjectMeta, &d.Spec.Template.Spec); err != nil {
+		return nil, err
 	}
 
 	k8sutil.AddRookVersionLabelToDeployment(d)
@@ -116,3 +116,13 @@ func TestHostNetwork(t *testing.T) {
 	assert.NotContains(t, d.Spec.Template.Spec.Containers[0].Args,
 		config.NewFlag("public-addr", controller.ContainerEnvVarReference(podIPEnvVar)))
 }
+
+func TestMultusNetwork(t *testing.T) {
+	network := cephv1.NetworkSpec{Provider: "multus", Selectors: map[string]string{"public": "public-net", "cluster": "cluster-net"}}
+	d, err := testDeploymentObject(t, network)
+	assert.Nil(t, err)
+
+	// the mds is attached to the public network only
+	assert.Equal(t, "public-net", d.Spec.Template.Annotations["k8s.v1.cni.cncf.io/networks"])
+	assert.False(t, d.Spec.Template.Spec.HostNetwork)
+}
@@ -54,6 +54,29 @@ func updateStatus(client client.Client, namespacedName types.NamespacedName, sta
 	logger.Debugf("filesystem %q status updated to %q", fs.Name, status)
 }
 
+// updateStatusMDSAutoscaling updates the autoscaling status of a fs CR
+func updateStatusMDSAutoscaling(client client.Client, namespacedName types.NamespacedName, autoscalingStatus *cephv1.MDSAutoscalingStatus) {
+	fs := &cephv1.CephFilesystem{}
+	if err := client.Get(context.TODO(), namespacedName, fs); err != nil {
+		if kerrors.IsNotFound(err) {
+			logger.Debug("CephFilesystem resource not found. Ignoring since object must be deleted.")
+			return
+		}
+		logger.Warningf("failed to retrieve ceph filesystem %q to update mds autoscaling status. %v", namespacedName.Name, err)
+		return
+	}
+	if fs.Status == nil {
+		fs.Status = &cephv1.CephFilesystemStatus{}
+	}
+
+	fs.Status.MDSAutoscaling = autoscalingStatus
+	if err := reporting.UpdateStatus(client, fs); err != nil {
+		logger.Errorf("failed to set ceph filesystem %q mds autoscaling status. %v", namespacedName.Name, err)
+		return
+	}
+	logger.Debugf("ceph filesystem %q mds autoscaling status updated", namespacedName.Name)
+}
+
 // updateStatusBucket updates an object with a given status
 func (c *mirrorChecker) updateStatusMirroring(mirrorStatus []cephv1.FilesystemMirroringInfo, snapSchedStatus []cephv1.FilesystemSnapshotSchedulesSpec, details string) {
 	fs := &cephv1.CephFilesystem{}
@@ -110,5 +133,5 @@ func toCustomResourceStatus(currentStatus *cephv1.CephFilesystemStatus, mirrorSt
 	// Always display the details, typically an error
 	mirrorSnapScheduleStatusSpec.Details = details
 
-	return &cephv1.CephFilesystemStatus{MirroringStatus: mirrorStatusSpec, SnapshotScheduleStatus: mirrorSnapScheduleStatusSpec, Phase: currentStatus.Phase, Info: currentStatus.Info}
+	return &cephv1.CephFilesystemStatus{MirroringStatus: mirrorStatusSpec, SnapshotScheduleStatus: mirrorSnapScheduleStatusSpec, Phase: currentStatus.Phase, Info: currentStatus.Info, MDSAutoscaling: currentStatus.MDSAutoscaling}
 }
@@ -0,0 +1,439 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package subvolumegroup to manage the subvolume groups of a CephFilesystem
+package subvolumegroup
+
+import (
+	"context"
+	"crypto/md5"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/coreos/pkg/capnslog"
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/ceph/cluster/mon"
+	opcontroller "github.com/rook/rook/pkg/operator/ceph/controller"
+	"github.com/rook/rook/pkg/operator/ceph/csi"
+	"github.com/rook/rook/pkg/operator/ceph/reporting"
+	cephver "github.com/rook/rook/pkg/operator/ceph/version"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	controllerName = "ceph-fs-subvolumegroup-controller"
+	// dataPoolNameStatusKey is the status info key of the data pool the group was created with
+	dataPoolNameStatusKey = "dataPoolName"
+)
+
+var logger = capnslog.NewPackageLogger("github.com/rook/rook", controllerName)
+
+var cephFilesystemSubVolumeGroupKind = reflect.TypeOf(cephv1.CephFilesystemSubVolumeGroup{}).Name()
+
+// Sets the type meta for the controller main object
+var controllerTypeMeta = metav1.TypeMeta{
+	Kind:       cephFilesystemSubVolumeGroupKind,
+	APIVersion: fmt.Sprintf("%s/%s", cephv1.CustomResourceGroup, cephv1.Version),
+}
+
+// quotaMinVersion is the first version with the quotas of the subvolume groups
+var quotaMinVersion = cephver.CephVersion{Major: 16, Minor: 2, Extra: 11}
+
+// ReconcileCephFilesystemSubVolumeGroup reconciles a CephFilesystemSubVolumeGroup object
+type ReconcileCephFilesystemSubVolumeGroup struct {
+	client      client.Client
+	scheme      *runtime.Scheme
+	context     *clusterd.Context
+	clusterInfo *cephclient.ClusterInfo
+}
+
+// Add creates a new CephFilesystemSubVolumeGroup Controller and adds it to the Manager. The Manager will set fields on the Controller
+// and Start it when the Manager is Started.
+func Add(mgr manager.Manager, context *clusterd.Context) error {
+	return add(mgr, newReconciler(mgr, context))
+}
+
+// newReconciler returns a new reconcile.Reconciler
+func newReconciler(mgr manager.Manager, context *clusterd.Context) reconcile.Reconciler {
+	return &ReconcileCephFilesystemSubVolumeGroup{
+		client:  mgr.GetClient(),
+		scheme:  mgr.GetScheme(),
+		context: context,
+	}
+}
+
+func add(mgr manager.Manager, r reconcile.Reconciler) error {
+	// Create a new controller
+	c, err := controller.New(controllerName, mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+	logger.Info("successfully started")
+
+	// Watch for changes on the CephFilesystemSubVolumeGroup CRD object
+	err = c.Watch(&source.Kind{Type: &cephv1.CephFilesystemSubVolumeGroup{TypeMeta: controllerTypeMeta}}, &handler.EnqueueRequestForObject{}, opcontroller.WatchControllerPredicate())
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Reconcile reads that state of the cluster for a CephFilesystemSubVolumeGroup object and makes changes based on the state read
+// and what is in the CephFilesystemSubVolumeGroup.Spec
+// The Controller will requeue the Request to be processed again if the returned error is non-nil or
+// Result.Requeue is true, otherwise upon completion it will remove the work from the queue.
+func (r *ReconcileCephFilesystemSubVolumeGroup) Reconcile(context context.Context, request reconcile.Request) (reconcile.Result, error) {
+	// workaround because the rook logging mechanism is not compatible with the controller-runtime logging interface
+	reconcileResponse, err := r.reconcile(request)
+	if err != nil {
+		logger.Errorf("failed to reconcile %v", err)
+	}
+
+	return reconcileResponse, err
+}
+
+func (r *ReconcileCephFilesystemSubVolumeGroup) reconcile(request reconcile.Request) (reconcile.Result, error) {
+	// Fetch the CephFilesystemSubVolumeGroup instance
+	cephFilesystemSubVolumeGroup := &cephv1.CephFilesystemSubVolumeGroup{}
+	err := r.client.Get(context.TODO(), request.NamespacedName, cephFilesystemSubVolumeGroup)
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			logger.Debug("cephFilesystemSubVolumeGroup resource not found. Ignoring since object must be deleted.")
+			return reconcile.Result{}, nil
+		}
+		// Error reading the object - requeue the request.
+		return reconcile.Result{}, errors.Wrap(err, "failed to get cephFilesystemSubVolumeGroup")
+	}
+
+	// Set a finalizer so we can do cleanup before the object goes away
+	err = opcontroller.AddFinalizerIfNotPresent(r.client, cephFilesystemSubVolumeGroup)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrap(err, "failed to add finalizer")
+	}
+
+	// The CR was just created, initializing status fields
+	if cephFilesystemSubVolumeGroup.Status == nil {
+		updateStatus(r.client, request.NamespacedName, cephv1.ConditionProgressing, nil)
+	}
+
+	// Make sure a CephCluster is present otherwise do nothing
+	cephCluster, isReadyToReconcile, cephClusterExists, reconcileResponse := opcontroller.IsReadyToReconcile(r.client, r.context, request.NamespacedName, controllerName)
+	if !isReadyToReconcile {
+		// This handles the case where the Ceph Cluster is gone and we want to delete that CR
+		// We skip the deleteSubVolumeGroup() function since everything is gone already
+		//
+		// Also, only remove the finalizer if the CephCluster is gone
+		// If not, we should wait for it to be ready
+		// This handles the case where the operator is not ready to accept Ceph command but the cluster exists
+		if !cephFilesystemSubVolumeGroup.GetDeletionTimestamp().IsZero() && !cephClusterExists {
+			// The csi config entry is not owned by the cluster, remove it
+			if err := csi.RemoveClusterConfig(r.context.Clientset, buildClusterID(cephFilesystemSubVolumeGroup), csi.ConfigMutex); err != nil {
+				return reconcile.Result{}, errors.Wrap(err, "failed to remove the subvolume group from the csi config")
+			}
+
+			// Remove finalizer
+			err = opcontroller.RemoveFinalizer(r.client, cephFilesystemSubVolumeGroup)
+			if err != nil {
+				return opcontroller.ImmediateRetryResult, errors.Wrap(err, "failed to remove finalizer")
+			}
+
+			// Return and do not requeue. Successful deletion.
+			return reconcile.Result{}, nil
+		}
+		return reconcileResponse, nil
+	}
+
+	// Populate clusterInfo during each reconcile
+	r.clusterInfo, _, _, err = mon.LoadClusterInfo(r.context, request.NamespacedName.Namespace)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrap(err, "failed to populate cluster info")
+	}
+
+	// DELETE: the CR was deleted
+	if !cephFilesystemSubVolumeGroup.GetDeletionTimestamp().IsZero() {
+		logger.Debugf("deleting subvolume group %q", cephFilesystemSubVolumeGroup.Name)
+		err := r.deleteSubVolumeGroup(cephFilesystemSubVolumeGroup)
+		if err != nil {
+			return reconcile.Result{}, errors.Wrapf(err, "failed to delete ceph filesystem subvolume group %q", cephFilesystemSubVolumeGroup.Name)
+		}
+
+		// Remove finalizer
+		err = opcontroller.RemoveFinalizer(r.client, cephFilesystemSubVolumeGroup)
+		if err != nil {
+			return reconcile.Result{}, errors.Wrap(err, "failed to remove finalizer")
+		}
+
+		// Return and do not requeue. Successful deletion.
+		return reconcile.Result{}, nil
+	}
+
+	// validate the subvolume group settings
+	err = validateSubVolumeGroup(cephFilesystemSubVolumeGroup)
+	if err != nil {
+		updateStatus(r.client, request.NamespacedName, cephv1.ConditionFailure, nil)
+		return reconcile.Result{}, errors.Wrapf(err, "invalid subvolume group %q arguments", cephFilesystemSubVolumeGroup.Name)
+	}
+
+	// ceph only applies the data pool when the group is created, so a change cannot be applied to the group
+	err = validateDataPoolUnchanged(cephFilesystemSubVolumeGroup)
+	if err != nil {
+		updateStatus(r.client, request.NamespacedName, cephv1.ConditionFailure, buildFailedStatusInfo(cephFilesystemSubVolumeGroup, err))
+		return reconcile.Result{}, errors.Wrapf(err, "invalid subvolume group %q arguments", cephFilesystemSubVolumeGroup.Name)
+	}
+
+	// The filesystem must exist and be ready before creating a group in it
+	cephFilesystem := &cephv1.CephFilesystem{}
+	fsName := types.NamespacedName{Name: cephFilesystemSubVolumeGroup.Spec.FilesystemName, Namespace: request.Namespace}
+	err = r.client.Get(context.TODO(), fsName, cephFilesystem)
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			logger.Infof("waiting for ceph filesystem %q to be created before creating subvolume group %q", fsName.Name, cephFilesystemSubVolumeGroup.Name)
+			return opcontroller.WaitForRequeueIfCephClusterNotReady, nil
+		}
+		return reconcile.Result{}, errors.Wrapf(err, "failed to get ceph filesystem %q", fsName.Name)
+	}
+	if cephFilesystem.Status == nil || cephFilesystem.Status.Phase != cephv1.ConditionReady {
+		logger.Infof("waiting for ceph filesystem %q to be ready before creating subvolume group %q", fsName.Name, cephFilesystemSubVolumeGroup.Name)
+		return opcontroller.WaitForRequeueIfCephClusterNotReady, nil
+	}
+
+	// Get CephCluster version
+	cephVersion, err := opcontroller.GetImageVersion(cephCluster)
+	if err != nil {
+		return opcontroller.ImmediateRetryResult, errors.Wrapf(err, "failed to fetch ceph version from cephcluster %q", cephCluster.Name)
+	}
+	r.clusterInfo.CephVersion = *cephVersion
+
+	// Create or Update the subvolume group
+	err = r.createOrUpdateSubVolumeGroup(cephFilesystemSubVolumeGroup)
+	if err != nil {
+		if strings.Contains(err.Error(), opcontroller.UninitializedCephConfigError) {
+			logger.Info(opcontroller.OperatorNotInitializedMessage)
+			return opcontroller.WaitForRequeueIfOperatorNotInitialized, nil
+		}
+		updateStatus(r.client, request.NamespacedName, cephv1.ConditionFailure, nil)
+		return reconcile.Result{}, errors.Wrapf(err, "failed to create or update subvolume group %q", cephFilesystemSubVolumeGroup.Name)
+	}
+
+	// Register the subvolume group in the csi config so storage classes can use it
+	err = csi.SaveSubvolumeGroupConfig(r.context.Clientset, buildClusterID(cephFilesystemSubVolumeGroup), r.clusterInfo, cephFilesystemSubVolumeGroup.Name, csi.ConfigMutex)
+	if err != nil {
+		updateStatus(r.client, request.NamespacedName, cephv1.ConditionFailure, nil)
+		return reconcile.Result{}, errors.Wrapf(err, "failed to save the csi config of subvolume group %q", cephFilesystemSubVolumeGroup.Name)
+	}
+
+	// Success! Let's update the status
+	updateStatus(r.client, request.NamespacedName, cephv1.ConditionReady, buildStatusInfo(cephFilesystemSubVolumeGroup))
+
+	// Return and do not requeue
+	logger.Debug("done reconciling")
+	return reconcile.Result{}, nil
+}
+
+// Create the subvolume group and apply its quota and pinning
+func (r *ReconcileCephFilesystemSubVolumeGroup) createOrUpdateSubVolumeGroup(cephFilesystemSubVolumeGroup *cephv1.CephFilesystemSubVolumeGroup) error {
+	fsName := cephFilesystemSubVolumeGroup.Spec.FilesystemName
+	groupName := cephFilesystemSubVolumeGroup.Name
+	err := cephclient.CreateSubVolumeGroup(r.context, r.clusterInfo, fsName, groupName, cephFilesystemSubVolumeGroup.Spec.DataPoolName)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create ceph filesystem subvolume group %q", groupName)
+	}
+
+	if err := r.applyQuota(cephFilesystemSubVolumeGroup); err != nil {
+		return err
+	}
+
+	return r.applyPinning(cephFilesystemSubVolumeGroup)
+}
+
+// applyQuota sets the quota of the group, or removes it if the quota is not set
+func (r *ReconcileCephFilesystemSubVolumeGroup) applyQuota(cephFilesystemSubVolumeGroup *cephv1.CephFilesystemSubVolumeGroup) error {
+	quota := cephFilesystemSubVolumeGroup.Spec.Quota
+	if !r.clusterInfo.CephVersion.IsAtLeast(quotaMinVersion) {
+		if quota != nil {
+			return errors.Errorf("the quota of subvolume groups requires ceph %q or newer", quotaMinVersion.String())
+		}
+		return nil
+	}
+
+	var size *int64
+	if quota != nil {
+		// the quota was validated already
+		q := resource.MustParse(*quota)
+		value := q.Value()
+		size = &value
+	}
+	return cephclient.ResizeSubVolumeGroup(r.context, r.clusterInfo, cephFilesystemSubVolumeGroup.Spec.FilesystemName, cephFilesystemSubVolumeGroup.Name, size)
+}
+
+// applyPinning sets the pinning type of the group and unsets the other types
+func (r *ReconcileCephFilesystemSubVolumeGroup) applyPinning(cephFilesystemSubVolumeGroup *cephv1.CephFilesystemSubVolumeGroup) error {
+	settings := pinSettings(cephFilesystemSubVolumeGroup.Spec.Pinning)
+	if !r.clusterInfo.CephVersion.IsAtLeastPacific() {
+		if len(settings) > 0 {
+			return errors.New("the pinning of subvolume groups requires ceph pacific or newer")
+		}
+		return nil
+	}
+
+	for _, pinType := range []string{cephclient.SubVolumeGroupPinExport, cephclient.SubVolumeGroupPinDistributed, cephclient.SubVolumeGroupPinRandom} {
+		setting, ok := settings[pinType]
+		if !ok {
+			setting = cephclient.SubVolumeGroupUnpinSettings[pinType]
+		}
+		err := cephclient.PinSubVolumeGroup(r.context, r.clusterInfo, cephFilesystemSubVolumeGroup.Spec.FilesystemName, cephFilesystemSubVolumeGroup.Name, pinType, setting)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pinSettings returns the settings of the pinning types that are set
+func pinSettings(pinning cephv1.SubVolumeGroupPinningSpec) map[string]string {
+	settings := map[string]string{}
+	if pinning.Export != nil {
+		settings[cephclient.SubVolumeGroupPinExport] = fmt.Sprintf("%d", *pinning.Export)
+	}
+	if pinning.Distributed != nil {
+		settings[cephclient.SubVolumeGroupPinDistributed] = fmt.Sprintf("%d", *pinning.Distributed)
+	}
+	if pinning.Random != nil {
+		settings[cephclient.SubVolumeGroupPinRandom] = fmt.Sprintf("%g", *pinning.Random)
+	}
+	return settings
+}
+
+// Delete the subvolume group
+func (r *ReconcileCephFilesystemSubVolumeGroup) deleteSubVolumeGroup(cephFilesystemSubVolumeGroup *cephv1.CephFilesystemSubVolumeGroup) error {
+	logger.Infof("deleting ceph filesystem subvolume group object %q", cephFilesystemSubVolumeGroup.Name)
+	if err := cephclient.DeleteSubVolumeGroup(r.context, r.clusterInfo, cephFilesystemSubVolumeGroup.Spec.FilesystemName, cephFilesystemSubVolumeGroup.Name); err != nil {
+		return err
+	}
+
+	if err := csi.RemoveClusterConfig(r.context.Clientset, buildClusterID(cephFilesystemSubVolumeGroup), csi.ConfigMutex); err != nil {
+		return errors.Wrap(err, "failed to remove the subvolume group from the csi config")
+	}
+
+	logger.Infof("deleted ceph filesystem subvolume group %q", cephFilesystemSubVolumeGroup.Name)
+	return nil
+}
+
+func validateSubVolumeGroup(cephFilesystemSubVolumeGroup *cephv1.CephFilesystemSubVolumeGroup) error {
+	if cephFilesystemSubVolumeGroup.Name == "" {
+		return errors.New("missing name")
+	}
+	if cephFilesystemSubVolumeGroup.Namespace == "" {
+		return errors.New("missing namespace")
+	}
+	spec := cephFilesystemSubVolumeGroup.Spec
+	if spec.FilesystemName == "" {
+		return errors.New("missing filesystemName")
+	}
+
+	if spec.Quota != nil {
+		if _, err := resource.ParseQuantity(*spec.Quota); err != nil {
+			return errors.Wrapf(err, "invalid quota %q", *spec.Quota)
+		}
+	}
+
+	if len(pinSettings(spec.Pinning)) > 1 {
+		return errors.New("only one of export, distributed and random pinning can be set")
+	}
+	if spec.Pinning.Random != nil && (*spec.Pinning.Random < 0 || *spec.Pinning.Random > 1) {
+		return errors.Errorf("invalid random pinning %g, must be between 0 and 1", *spec.Pinning.Random)
+	}
+
+	return nil
+}
+
+// buildClusterID returns the clusterID of the subvolume group that storage classes refer to. The
+// group name is not unique across filesystems and clusters, so a hash of all of them is used instead.
+func buildClusterID(cephFilesystemSubVolumeGroup *cephv1.CephFilesystemSubVolumeGroup) string {
+	clusterID := fmt.Sprintf("%s-%s-file-%s", cephFilesystemSubVolumeGroup.Namespace, cephFilesystemSubVolumeGroup.Spec.FilesystemName, cephFilesystemSubVolumeGroup.Name)
+	return fmt.Sprintf("%x", md5.Sum([]byte(clusterID)))
+}
+
+// validateDataPoolUnchanged checks that the data pool is the one the group was created with, which the status
+// records once the group is ready
+func validateDataPoolUnchanged(cephFilesystemSubVolumeGroup *cephv1.CephFilesystemSubVolumeGroup) error {
+	if cephFilesystemSubVolumeGroup.Status == nil {
+		return nil
+	}
+	appliedDataPoolName, ok := cephFilesystemSubVolumeGroup.Status.Info[dataPoolNameStatusKey]
+	if !ok || appliedDataPoolName == cephFilesystemSubVolumeGroup.Spec.DataPoolName {
+		return nil
+	}
+	return errors.Errorf("dataPoolName cannot be changed from %q to %q after the group is created", appliedDataPoolName, cephFilesystemSubVolumeGroup.Spec.DataPoolName)
+}
+
+func buildStatusInfo(cephFilesystemSubVolumeGroup *cephv1.CephFilesystemSubVolumeGroup) map[string]string {
+	return map[string]string{
+		"clusterID":           buildClusterID(cephFilesystemSubVolumeGroup),
+		dataPoolNameStatusKey: cephFilesystemSubVolumeGroup.Spec.DataPoolName,
+	}
+}
+
+// buildFailedStatusInfo keeps the status info of the group and adds the reason of the failure
+func buildFailedStatusInfo(cephFilesystemSubVolumeGroup *cephv1.CephFilesystemSubVolumeGroup, err error) map[string]string {
+	info := map[string]string{}
+	for key, value := range cephFilesystemSubVolumeGroup.Status.Info {
+		info[key] = value
+	}
+	info["error"] = err.Error()
+	return info
+}
+
+// updateStatus updates an object with a given status
+func updateStatus(client client.Client, name types.NamespacedName, status cephv1.ConditionType, info map[string]string) {
+	cephFilesystemSubVolumeGroup := &cephv1.CephFilesystemSubVolumeGroup{}
+	if err := client.Get(context.TODO(), name, cephFilesystemSubVolumeGroup); err != nil {
+		if kerrors.IsNotFound(err) {
+			logger.Debug("CephFilesystemSubVolumeGroup resource not found. Ignoring since object must be deleted.")
+			return
+		}
+		logger.Warningf("failed to retrieve ceph filesystem subvolume group %q to update status to %q. %v", name, status, err)
+		return
+	}
+	if cephFilesystemSubVolumeGroup.Status == nil {
+		cephFilesystemSubVolumeGroup.Status = &cephv1.CephFilesystemSubVolumeGroupStatus{}
+	}
+
+	cephFilesystemSubVolumeGroup.Status.Phase = status
+	if info != nil {
+		cephFilesystemSubVolumeGroup.Status.Info = info
+	}
+	if err := reporting.UpdateStatus(client, cephFilesystemSubVolumeGroup); err != nil {
+		logger.Errorf("failed to set ceph filesystem subvolume group %q status to %q. %v", name, status, err)
+		return
+	}
+	logger.Debugf("ceph filesystem subvolume group %q status updated to %q", name, status)
+}
@@ -0,0 +1,303 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package subvolumegroup
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	rookclient "github.com/rook/rook/pkg/client/clientset/versioned/fake"
+	"github.com/rook/rook/pkg/client/clientset/versioned/scheme"
+	"github.com/rook/rook/pkg/clusterd"
+	"github.com/rook/rook/pkg/operator/ceph/csi"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	testop "github.com/rook/rook/pkg/operator/test"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/tevino/abool"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestValidateSubVolumeGroup(t *testing.T) {
+	g := &cephv1.CephFilesystemSubVolumeGroup{ObjectMeta: metav1.ObjectMeta{Name: "group-a", Namespace: "rook-ceph"}}
+	assert.Error(t, validateSubVolumeGroup(g))
+
+	g.Spec.FilesystemName = "myfs"
+	assert.NoError(t, validateSubVolumeGroup(g))
+
+	quota := "10Gi"
+	g.Spec.Quota = &quota
+	assert.NoError(t, validateSubVolumeGroup(g))
+	quota = "ten"
+	assert.Error(t, validateSubVolumeGroup(g))
+	quota = "10Gi"
+
+	// only one pinning type
+	export := 1
+	random := 0.5
+	g.Spec.Pinning.Export = &export
+	assert.NoError(t, validateSubVolumeGroup(g))
+	g.Spec.Pinning.Random = &random
+	assert.Error(t, validateSubVolumeGroup(g))
+	g.Spec.Pinning.Export = nil
+	assert.NoError(t, validateSubVolumeGroup(g))
+	random = 1.5
+	assert.Error(t, validateSubVolumeGroup(g))
+
+	g.Namespace = ""
+	g.Spec.Pinning.Random = nil
+	assert.Error(t, validateSubVolumeGroup(g))
+}
+
+func TestValidateDataPoolUnchanged(t *testing.T) {
+	g := &cephv1.CephFilesystemSubVolumeGroup{Spec: cephv1.CephFilesystemSubVolumeGroupSpec{DataPoolName: "myfs-data1"}}
+	assert.NoError(t, validateDataPoolUnchanged(g))
+
+	// groups created before the data pool was recorded are not checked
+	g.Status = &cephv1.CephFilesystemSubVolumeGroupStatus{Info: map[string]string{"clusterID": "abc"}}
+	assert.NoError(t, validateDataPoolUnchanged(g))
+
+	g.Status.Info["dataPoolName"] = "myfs-data1"
+	assert.NoError(t, validateDataPoolUnchanged(g))
+	g.Spec.DataPoolName = "myfs-data2"
+	assert.Error(t, validateDataPoolUnchanged(g))
+	g.Spec.DataPoolName = ""
+	assert.Error(t, validateDataPoolUnchanged(g))
+
+	// the default data pool cannot be changed either
+	g.Status.Info["dataPoolName"] = ""
+	assert.NoError(t, validateDataPoolUnchanged(g))
+	g.Spec.DataPoolName = "myfs-data1"
+	assert.Error(t, validateDataPoolUnchanged(g))
+}
+
+func TestPinSettings(t *testing.T) {
+	assert.Equal(t, map[string]string{}, pinSettings(cephv1.SubVolumeGroupPinningSpec{}))
+	distributed := 1
+	assert.Equal(t, map[string]string{"distributed": "1"}, pinSettings(cephv1.SubVolumeGroupPinningSpec{Distributed: &distributed}))
+	random := 0.01
+	assert.Equal(t, map[string]string{"random": "0.01"}, pinSettings(cephv1.SubVolumeGroupPinningSpec{Random: &random}))
+}
+
+func TestBuildClusterID(t *testing.T) {
+	g := &cephv1.CephFilesystemSubVolumeGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "group-a", Namespace: "rook-ceph"},
+		Spec:       cephv1.CephFilesystemSubVolumeGroupSpec{FilesystemName: "myfs"},
+	}
+	clusterID := buildClusterID(g)
+	assert.Equal(t, 32, len(clusterID))
+	assert.Equal(t, clusterID, buildClusterID(g))
+	assert.Equal(t, map[string]string{"clusterID": clusterID, "dataPoolName": ""}, buildStatusInfo(g))
+
+	// the same group in another filesystem has another clusterID
+	g.Spec.FilesystemName = "otherfs"
+	assert.NotEqual(t, clusterID, buildClusterID(g))
+}
+
+func TestCephFilesystemSubVolumeGroupController(t *testing.T) {
+	ctx := context.TODO()
+	var (
+		name        = "group-a"
+		namespace   = "rook-ceph"
+		quota       = "1Gi"
+		distributed = 1
+	)
+
+	subVolumeGroup := &cephv1.CephFilesystemSubVolumeGroup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			UID:       types.UID("c47cac40-9bee-4d52-823b-ccd803ba5bfe"),
+		},
+		Spec: cephv1.CephFilesystemSubVolumeGroupSpec{
+			FilesystemName: "myfs",
+			Quota:          &quota,
+			DataPoolName:   "myfs-data1",
+			Pinning:        cephv1.SubVolumeGroupPinningSpec{Distributed: &distributed},
+		},
+		Status: &cephv1.CephFilesystemSubVolumeGroupStatus{},
+	}
+	cephCluster := &cephv1.CephCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      namespace,
+			Namespace: namespace,
+		},
+		Spec: cephv1.ClusterSpec{
+			CephVersion: cephv1.CephVersionSpec{Image: "quay.io/ceph/ceph:v16.2.11"},
+		},
+		Status: cephv1.ClusterStatus{
+			Phase: cephv1.ConditionReady,
+			CephVersion: &cephv1.ClusterVersion{
+				Image:   "quay.io/ceph/ceph:v16.2.11",
+				Version: "16.2.11-0",
+			},
+			CephStatus: &cephv1.CephStatus{
+				Health: "HEALTH_OK",
+			},
+		},
+	}
+	cephFilesystem := &cephv1.CephFilesystem{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "myfs",
+			Namespace: namespace,
+		},
+		Status: &cephv1.CephFilesystemStatus{
+			Phase: cephv1.ConditionProgressing,
+		},
+	}
+
+	commands := []string{}
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutput: func(command string, args ...string) (string, error) {
+			if args[0] == "status" {
+				return `{"fsid":"c47cac40-9bee-4d52-823b-ccd803ba5bfe","health":{"checks":{},"status":"HEALTH_OK"},"pgmap":{"num_pgs":100,"pgs_by_state":[{"state_name":"active+clean","count":100}]}}`, nil
+			}
+			if args[0] == "fs" && args[1] == "subvolumegroup" {
+				end := 2
+				for end < len(args) && !strings.HasPrefix(args[end], "--connect-timeout") {
+					end++
+				}
+				commands = append(commands, strings.Join(args[2:end], " "))
+			}
+			return "", nil
+		},
+	}
+	c := &clusterd.Context{
+		Executor:                   executor,
+		Clientset:                  testop.New(t, 1),
+		RookClientset:              rookclient.NewSimpleClientset(),
+		RequestCancelOrchestration: abool.New(),
+	}
+
+	// Mock clusterInfo
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "rook-ceph-mon",
+			Namespace: namespace,
+		},
+		Data: map[string][]byte{
+			"fsid":         []byte(name),
+			"mon-secret":   []byte("monsecret"),
+			"admin-secret": []byte("adminsecret"),
+		},
+		Type: k8sutil.RookType,
+	}
+	_, err := c.Clientset.CoreV1().Secrets(namespace).Create(ctx, secret, metav1.CreateOptions{})
+	assert.NoError(t, err)
+
+	// Mock the csi config map in the operator namespace
+	os.Setenv(k8sutil.PodNamespaceEnvVar, "rook-ceph-operator")
+	defer os.Unsetenv(k8sutil.PodNamespaceEnvVar)
+	csi.EnableCephFS = true
+	defer func() { csi.EnableCephFS = false }()
+	csiConfig := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: csi.ConfigName, Namespace: "rook-ceph-operator"},
+		Data:       map[string]string{csi.ConfigKey: "[]"},
+	}
+	_, err = c.Clientset.CoreV1().ConfigMaps("rook-ceph-operator").Create(ctx, csiConfig, metav1.CreateOptions{})
+	assert.NoError(t, err)
+
+	// Register operator types with the runtime scheme.
+	s := scheme.Scheme
+	s.AddKnownTypes(cephv1.SchemeGroupVersion, &cephv1.CephFilesystemSubVolumeGroup{}, &cephv1.CephCluster{}, &cephv1.CephClusterList{}, &cephv1.CephFilesystem{})
+
+	objects := []runtime.Object{subVolumeGroup, cephCluster, cephFilesystem}
+	cl := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(objects...).Build()
+	c.Client = cl
+	r := &ReconcileCephFilesystemSubVolumeGroup{
+		client:  cl,
+		scheme:  s,
+		context: c,
+	}
+	req := reconcile.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      name,
+			Namespace: namespace,
+		},
+	}
+
+	// the filesystem is not ready yet
+	res, err := r.Reconcile(ctx, req)
+	assert.NoError(t, err)
+	assert.True(t, res.Requeue)
+	assert.Equal(t, 0, len(commands))
+
+	// the filesystem is ready
+	cephFilesystem.Status.Phase = cephv1.ConditionReady
+	assert.NoError(t, cl.Update(ctx, cephFilesystem))
+	res, err = r.Reconcile(ctx, req)
+	assert.NoError(t, err)
+	assert.False(t, res.Requeue)
+	assert.Equal(t, []string{
+		"create myfs group-a --pool_layout myfs-data1",
+		"resize myfs group-a 1073741824",
+		"pin myfs group-a export -1",
+		"pin myfs group-a distributed 1",
+		"pin myfs group-a random 0",
+	}, commands)
+
+	err = cl.Get(ctx, req.NamespacedName, subVolumeGroup)
+	assert.NoError(t, err)
+	assert.Equal(t, cephv1.ConditionReady, subVolumeGroup.Status.Phase)
+	clusterID := subVolumeGroup.Status.Info["clusterID"]
+	assert.Equal(t, buildClusterID(subVolumeGroup), clusterID)
+	assert.Equal(t, "myfs-data1", subVolumeGroup.Status.Info["dataPoolName"])
+
+	// the subvolume group is in the csi config
+	cm, err := c.Clientset.CoreV1().ConfigMaps("rook-ceph-operator").Get(ctx, csi.ConfigName, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Contains(t, cm.Data[csi.ConfigKey], `"clusterID":"`+clusterID+`"`)
+	assert.Contains(t, cm.Data[csi.ConfigKey], `"subvolumeGroup":"group-a"`)
+
+	// the data pool cannot be changed once the group is created
+	commands = []string{}
+	subVolumeGroup.Spec.DataPoolName = "myfs-data2"
+	assert.NoError(t, cl.Update(ctx, subVolumeGroup))
+	_, err = r.Reconcile(ctx, req)
+	assert.Error(t, err)
+	assert.Equal(t, 0, len(commands))
+	err = cl.Get(ctx, req.NamespacedName, subVolumeGroup)
+	assert.NoError(t, err)
+	assert.Equal(t, cephv1.ConditionFailure, subVolumeGroup.Status.Phase)
+	assert.Equal(t, "myfs-data1", subVolumeGroup.Status.Info["dataPoolName"])
+	assert.Contains(t, subVolumeGroup.Status.Info["error"], `from "myfs-data1" to "myfs-data2"`)
+
+	// the group is ready again once the change is reverted
+	subVolumeGroup.Spec.DataPoolName = "myfs-data1"
+	assert.NoError(t, cl.Update(ctx, subVolumeGroup))
+	_, err = r.Reconcile(ctx, req)
+	assert.NoError(t, err)
+	subVolumeGroup = &cephv1.CephFilesystemSubVolumeGroup{}
+	err = cl.Get(ctx, req.NamespacedName, subVolumeGroup)
+	assert.NoError(t, err)
+	assert.Equal(t, cephv1.ConditionReady, subVolumeGroup.Status.Phase)
+	assert.NotContains(t, subVolumeGroup.Status.Info, "error")
+
+	// the subvolume group is deleted
+	assert.NoError(t, r.deleteSubVolumeGroup(subVolumeGroup))
+	cm, err = c.Clientset.CoreV1().ConfigMaps("rook-ceph-operator").Get(ctx, csi.ConfigName, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "[]", cm.Data[csi.ConfigKey])
+}
@@ -18,11 +18,15 @@ package nfs
 
 import (
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/pkg/errors"
 	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
 	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
 	"github.com/rook/rook/pkg/operator/ceph/config/keyring"
+	"github.com/rook/rook/pkg/operator/ceph/object"
 	cephver "github.com/rook/rook/pkg/operator/ceph/version"
 	"github.com/rook/rook/pkg/operator/k8sutil"
 )
@@ -34,6 +38,14 @@ const (
         caps mon = "allow r"
         caps osd = "%s"
 `
+
+	// rgwOSDCaps gives access to the pools of the object stores
+	rgwOSDCaps = "allow rwx tag rgw *=*"
+
+	defaultKerberosPrincipal = "nfs"
+	krb5KeytabPath           = "/etc/krb5.keytab"
+	krb5ConfigPath           = "/etc/krb5.conf"
+	idmapConfigPath          = "/etc/idmapd.conf"
 )
 
 func getNFSUserID(nodeID string) string {
@@ -44,6 +56,11 @@ func getNFSClientID(n *cephv1.CephNFS, name string) string {
 	return fmt.Sprintf("client.%s", getNFSUserID(getNFSNodeID(n, name)))
 }
 
+// getNFSRGWClientID returns the user loading the RGW library in a ganesha server
+func getNFSRGWClientID(n *cephv1.CephNFS, name string) string {
+	return fmt.Sprintf("%s.rgw", getNFSClientID(n, name))
+}
+
 func getNFSNodeID(n *cephv1.CephNFS, name string) string {
 	return fmt.Sprintf("%s.%s", n.Name, name)
 }
@@ -59,6 +76,19 @@ func getGaneshaConfigObject(n *cephv1.CephNFS, version cephver.CephVersion, name
 	return fmt.Sprintf("conf-%s", getNFSNodeID(n, name))
 }
 
+// GaneshaConfigObjects returns the RADOS config objects watched by the ganesha servers of a CephNFS.
+// The servers share the same object since Octopus, so exports are added to each of the objects.
+func GaneshaConfigObjects(n *cephv1.CephNFS, version cephver.CephVersion) []string {
+	objects := []string{}
+	for i := 0; i < n.Spec.Server.Active; i++ {
+		object := getGaneshaConfigObject(n, version, k8sutil.IndexToName(i))
+		if len(objects) == 0 || objects[len(objects)-1] != object {
+			objects = append(objects, object)
+		}
+	}
+	return objects
+}
+
 func getRadosURL(n *cephv1.CephNFS, version cephver.CephVersion, name string) string {
 	url := fmt.Sprintf("rados://%s/", n.Spec.RADOS.Pool)
 
@@ -88,18 +118,137 @@ func (r *ReconcileCephNFS) generateKeyring(n *cephv1.CephNFS, name string) error
 	}
 
 	keyring := fmt.Sprintf(keyringTemplate, user, key, osdCaps)
+
+	// The buckets are exported through the RGW library, which needs access to the object store pools
+	if n.Spec.RGW != nil {
+		rgwUser := getNFSRGWClientID(n, name)
+		rgwKey, err := s.GenerateKey(rgwUser, []string{"mon", "allow r", "osd", rgwOSDCaps})
+		if err != nil {
+			return errors.Wrapf(err, "failed to create user %s", rgwUser)
+		}
+		keyring += fmt.Sprintf(keyringTemplate, rgwUser, rgwKey, rgwOSDCaps)
+	}
+
 	return s.CreateOrUpdate(instanceName(n, name), keyring)
 }
 
+// getGaneshaRGWConfig returns the RGW block of the ganesha config, the RGW library joins the zone of the object store.
+// The minimal ceph.conf only points the ganesha user to the keyring, so the keyring is passed in the init args.
+func getGaneshaRGWConfig(n *cephv1.CephNFS, name string, objContext *object.Context) string {
+	if n.Spec.RGW == nil || objContext == nil {
+		return ""
+	}
+	return `
+RGW {
+	ceph_conf = '` + cephclient.DefaultConfigFilePath() + `';
+	name = "` + getNFSRGWClientID(n, name) + `";
+	cluster = "ceph";
+	init_args = "--keyring=` + keyring.VolumeMount().KeyringFilePath() + ` --rgw-realm=` + objContext.Realm + ` --rgw-zonegroup=` + objContext.ZoneGroup + ` --rgw-zone=` + objContext.Zone + `";
+}
+`
+}
+
+// ganeshaProtocols returns the NFS protocol versions served by the servers
+func ganeshaProtocols(n *cephv1.CephNFS) []int {
+	if len(n.Spec.Server.Protocols) == 0 {
+		return []int{4}
+	}
+	protocols := []int{}
+	for _, p := range n.Spec.Server.Protocols {
+		if !servesProtocol(protocols, p) {
+			protocols = append(protocols, p)
+		}
+	}
+	sort.Ints(protocols)
+	return protocols
+}
+
+func servesProtocol(protocols []int, version int) bool {
+	for _, p := range protocols {
+		if p == version {
+			return true
+		}
+	}
+	return false
+}
+
+func joinProtocols(protocols []int) string {
+	versions := []string{}
+	for _, p := range protocols {
+		versions = append(versions, strconv.Itoa(p))
+	}
+	return strings.Join(versions, ", ")
+}
+
+func getGaneshaCoreParams(n *cephv1.CephNFS) string {
+	protocols := ganeshaProtocols(n)
+	params := `
+	Enable_NLM = false;
+	Enable_RQUOTA = false;
+	Protocols = ` + joinProtocols(protocols) + `;`
+	if servesProtocol(protocols, 3) {
+		// the MOUNT protocol of NFSv3 listens on a fixed port since there is no rpcbind to look it up
+		params += `
+	MNT_Port = ` + strconv.Itoa(mountPort) + `;`
+	}
+	return params
+}
+
+func getGaneshaNFSv4Params(n *cephv1.CephNFS) string {
+	params := ""
+	if n.Spec.Server.Security == nil || n.Spec.Server.Security.IDMapping == nil {
+		return params
+	}
+	idmap := n.Spec.Server.Security.IDMapping
+	if idmap.Domain != "" {
+		params += `
+	DomainName = "` + idmap.Domain + `";`
+	}
+	if idmap.ConfigConfigMapName != "" {
+		params += `
+	IdmapConf = "` + idmapConfigPath + `";`
+	}
+	if idmap.OnlyNumericOwners {
+		params += `
+	Only_Numeric_Owners = true;`
+	}
+	return params
+}
+
+func getGaneshaExportDefaults(n *cephv1.CephNFS) string {
+	params := `
+	Protocols = ` + joinProtocols(ganeshaProtocols(n)) + `;`
+	if n.Spec.Server.Security != nil && len(n.Spec.Server.Security.Flavors) > 0 {
+		params += `
+	SecType = "` + strings.Join(n.Spec.Server.Security.Flavors, `", "`) + `";`
+	}
+	return params
+}
+
+// getGaneshaKerberosConfig returns the NFS_KRB5 block of the ganesha config when the clients authenticate with Kerberos
+func getGaneshaKerberosConfig(n *cephv1.CephNFS) string {
+	if n.Spec.Server.Security == nil || n.Spec.Server.Security.Kerberos == nil {
+		return ""
+	}
+	principal := n.Spec.Server.Security.Kerberos.PrincipalName
+	if principal == "" {
+		principal = defaultKerberosPrincipal
+	}
+	return `
+NFS_KRB5 {
+	PrincipalName = "` + principal + `";
+	KeytabPath = "` + krb5KeytabPath + `";
+	Active_krb5 = true;
+}
+`
+}
+
 func getGaneshaConfig(n *cephv1.CephNFS, version cephver.CephVersion, name string) string {
 	nodeID := getNFSNodeID(n, name)
 	userID := getNFSUserID(nodeID)
 	url := getRadosURL(n, version, name)
 	return `
-NFS_CORE_PARAM {
-	Enable_NLM = false;
-	Enable_RQUOTA = false;
-	Protocols = 4;
+NFS_CORE_PARAM {` + getGaneshaCoreParams(n) + `
 }
 
 MDCACHE {
@@ -107,13 +256,13 @@ MDCACHE {
 }
 
 EXPORT_DEFAULTS {
-	Attr_Expiration_Time = 0;
+	Attr_Expiration_Time = 0;` + getGaneshaExportDefaults(n) + `
 }
 
 NFSv4 {
 	Delegations = false;
 	RecoveryBackend = 'rados_cluster';
-	Minor_Versions = 1, 2;
+	Minor_Versions = 1, 2;` + getGaneshaNFSv4Params(n) + `
 }
 
 RADOS_KV {
@@ -129,7 +278,7 @@ RADOS_URLS {
 	userid = ` + userID + `;
 	watch_url = '` + url + `';
 }
-
+` + getGaneshaKerberosConfig(n) + `
 %url	` + url + `
 `
 }
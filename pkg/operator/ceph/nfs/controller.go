@@ -30,6 +30,7 @@ import (
 	"github.com/rook/rook/pkg/operator/ceph/cluster/mon"
 	opconfig "github.com/rook/rook/pkg/operator/ceph/config"
 	opcontroller "github.com/rook/rook/pkg/operator/ceph/controller"
+	"github.com/rook/rook/pkg/operator/ceph/object"
 	"github.com/rook/rook/pkg/operator/ceph/reporting"
 	"github.com/rook/rook/pkg/operator/k8sutil"
 	appsv1 "k8s.io/api/apps/v1"
@@ -74,6 +75,8 @@ type ReconcileCephNFS struct {
 	context         *clusterd.Context
 	cephClusterSpec *cephv1.ClusterSpec
 	clusterInfo     *cephclient.ClusterInfo
+	objContext      *object.Context
+	nfsChannels     map[string]*nfsHealth
 }
 
 // Add creates a new cephNFS Controller and adds it to the Manager. The Manager will set fields on the Controller
@@ -85,9 +88,10 @@ func Add(mgr manager.Manager, context *clusterd.Context) error {
 // newReconciler returns a new reconcile.Reconciler
 func newReconciler(mgr manager.Manager, context *clusterd.Context) reconcile.Reconciler {
 	return &ReconcileCephNFS{
-		client:  mgr.GetClient(),
-		scheme:  mgr.GetScheme(),
-		context: context,
+		client:      mgr.GetClient(),
+		scheme:      mgr.GetScheme(),
+		context:     context,
+		nfsChannels: make(map[string]*nfsHealth),
 	}
 }
 
@@ -201,6 +205,11 @@ func (r *ReconcileCephNFS) reconcile(request reconcile.Request) (reconcile.Resul
 	// DELETE: the CR was deleted
 	if !cephNFS.GetDeletionTimestamp().IsZero() {
 		logger.Infof("deleting ceph nfs %q", cephNFS.Name)
+		if health, ok := r.nfsChannels[nfsChannelKeyName(request.NamespacedName)]; ok {
+			close(health.stopChan)
+			delete(r.nfsChannels, nfsChannelKeyName(request.NamespacedName))
+		}
+
 		err := r.removeServersFromDatabase(cephNFS, 0)
 		if err != nil {
 			return reconcile.Result{}, errors.Wrapf(err, "failed to delete filesystem %q. ", cephNFS.Name)
@@ -221,6 +230,16 @@ func (r *ReconcileCephNFS) reconcile(request reconcile.Request) (reconcile.Resul
 		return reconcile.Result{}, errors.Wrapf(err, "invalid ceph nfs %q arguments", cephNFS.Name)
 	}
 
+	// The ganesha servers join the zone of the object store whose buckets they export
+	r.objContext = nil
+	if cephNFS.Spec.RGW != nil {
+		r.objContext, err = r.getObjectContext(cephNFS)
+		if err != nil {
+			updateStatus(r.client, request.NamespacedName, k8sutil.FailedStatus)
+			return reconcile.Result{}, errors.Wrapf(err, "failed to get the object store of ceph nfs %q", cephNFS.Name)
+		}
+	}
+
 	// CREATE/UPDATE
 	logger.Debug("reconciling ceph nfs deployments")
 	_, err = r.reconcileCreateCephNFS(cephNFS)
@@ -229,6 +248,22 @@ func (r *ReconcileCephNFS) reconcile(request reconcile.Request) (reconcile.Resul
 		return reconcile.Result{}, errors.Wrap(err, "failed to create ceph nfs deployments")
 	}
 
+	err = r.reconcileIngress(cephNFS)
+	if err != nil {
+		updateStatus(r.client, request.NamespacedName, k8sutil.FailedStatus)
+		return reconcile.Result{}, errors.Wrap(err, "failed to reconcile ceph nfs ingress")
+	}
+
+	// Start the failover check of the servers behind the ingress, the check stays idle if the ingress is disabled later
+	if cephNFS.Spec.Ingress != nil {
+		r.startGraceChecker(request.NamespacedName)
+	}
+
+	// Collect the clients and the usage of the exports of the servers, the collector clears them if it is disabled later
+	if cephNFS.Spec.Statistics.Enabled {
+		r.startStatsCollector(request.NamespacedName)
+	}
+
 	// Set Ready status, we are done reconciling
 	updateStatus(r.client, request.NamespacedName, k8sutil.ReadyStatus)
 
@@ -281,6 +316,65 @@ func (r *ReconcileCephNFS) reconcileCreateCephNFS(cephNFS *cephv1.CephNFS) (reco
 	return reconcile.Result{}, nil
 }
 
+type nfsHealth struct {
+	stopChan     chan struct{}
+	graceRunning bool
+	statsRunning bool
+}
+
+func (r *ReconcileCephNFS) getHealth(namespacedName types.NamespacedName) *nfsHealth {
+	health, ok := r.nfsChannels[nfsChannelKeyName(namespacedName)]
+	if !ok {
+		health = &nfsHealth{stopChan: make(chan struct{})}
+		r.nfsChannels[nfsChannelKeyName(namespacedName)] = health
+	}
+	return health
+}
+
+// nfsChannelKeyName returns the key of the channels of a ceph nfs, the name is not unique across namespaces
+func nfsChannelKeyName(namespacedName types.NamespacedName) string {
+	return fmt.Sprintf("%s-%s", namespacedName.Namespace, namespacedName.Name)
+}
+
+func (r *ReconcileCephNFS) startGraceChecker(namespacedName types.NamespacedName) {
+	health := r.getHealth(namespacedName)
+	if health.graceRunning {
+		logger.Debug("ceph nfs failover check go routine already running!")
+		return
+	}
+
+	checker := newGraceChecker(r.context, r.client, namespacedName)
+	health.graceRunning = true
+	go checker.run(health.stopChan)
+}
+
+func (r *ReconcileCephNFS) startStatsCollector(namespacedName types.NamespacedName) {
+	health := r.getHealth(namespacedName)
+	if health.statsRunning {
+		logger.Debug("ceph nfs statistics go routine already running!")
+		return
+	}
+
+	collector := newStatsCollector(r.context, r.client, namespacedName)
+	health.statsRunning = true
+	go collector.run(health.stopChan)
+}
+
+// getObjectContext returns the context of the object store whose buckets the ganesha servers export
+func (r *ReconcileCephNFS) getObjectContext(cephNFS *cephv1.CephNFS) (*object.Context, error) {
+	store := &cephv1.CephObjectStore{}
+	storeName := types.NamespacedName{Name: cephNFS.Spec.RGW.ObjectStoreName, Namespace: cephNFS.Namespace}
+	if err := r.client.Get(context.TODO(), storeName, store); err != nil {
+		return nil, errors.Wrapf(err, "failed to get ceph object store %q", storeName.Name)
+	}
+
+	objContext, err := object.NewMultisiteContext(r.context, r.clusterInfo, store)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get the zone of ceph object store %q", storeName.Name)
+	}
+	return objContext, nil
+}
+
 // updateStatus updates an object with a given status
 func updateStatus(client client.Client, name types.NamespacedName, status string) {
 	nfs := &cephv1.CephNFS{}
@@ -294,7 +388,7 @@ func updateStatus(client client.Client, name types.NamespacedName, status string
 		return
 	}
 	if nfs.Status == nil {
-		nfs.Status = &cephv1.Status{}
+		nfs.Status = &cephv1.NFSStatus{}
 	}
 
 	nfs.Status.Phase = status
@@ -28,6 +28,7 @@ import (
 	rookclient "github.com/rook/rook/pkg/client/clientset/versioned/fake"
 	"github.com/rook/rook/pkg/client/clientset/versioned/scheme"
 	"github.com/rook/rook/pkg/clusterd"
+	"github.com/rook/rook/pkg/operator/ceph/object"
 	cephver "github.com/rook/rook/pkg/operator/ceph/version"
 	"github.com/rook/rook/pkg/operator/k8sutil"
 	"github.com/rook/rook/pkg/operator/test"
@@ -132,7 +133,7 @@ func TestCephNFSController(t *testing.T) {
 	// Create a fake client to mock API calls.
 	cl := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(object...).Build()
 	// Create a ReconcileCephNFS object with the scheme and fake client.
-	r := &ReconcileCephNFS{client: cl, scheme: s, context: c}
+	r := &ReconcileCephNFS{client: cl, scheme: s, context: c, nfsChannels: make(map[string]*nfsHealth)}
 
 	// Mock request to simulate Reconcile() being called on an event for a
 	// watched resource .
@@ -169,7 +170,7 @@ func TestCephNFSController(t *testing.T) {
 	// Create a fake client to mock API calls.
 	cl = fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(object...).Build()
 	// Create a ReconcileCephNFS object with the scheme and fake client.
-	r = &ReconcileCephNFS{client: cl, scheme: s, context: c}
+	r = &ReconcileCephNFS{client: cl, scheme: s, context: c, nfsChannels: make(map[string]*nfsHealth)}
 	logger.Info("STARTING PHASE 2")
 	res, err = r.Reconcile(ctx, req)
 	assert.NoError(t, err)
@@ -244,7 +245,7 @@ func TestCephNFSController(t *testing.T) {
 	c.Executor = executor
 
 	// Create a ReconcileCephNFS object with the scheme and fake client.
-	r = &ReconcileCephNFS{client: cl, scheme: s, context: c}
+	r = &ReconcileCephNFS{client: cl, scheme: s, context: c, nfsChannels: make(map[string]*nfsHealth)}
 
 	logger.Info("STARTING PHASE 3")
 	res, err = r.Reconcile(ctx, req)
@@ -278,3 +279,103 @@ func TestGetGaneshaConfigObject(t *testing.T) {
 	logger.Infof("Config Object for Nautilus is %s", res)
 	assert.Equal(t, "conf-my-nfs.a", res)
 }
+
+func TestGaneshaConfigObjects(t *testing.T) {
+	cephNFS := &cephv1.CephNFS{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: cephv1.NFSGaneshaSpec{
+			Server: cephv1.GaneshaServerSpec{Active: 2},
+		},
+	}
+
+	// the servers share the config object
+	res := GaneshaConfigObjects(cephNFS, cephver.CephVersion{Major: 16})
+	assert.Equal(t, []string{"conf-nfs.my-nfs"}, res)
+
+	// each server has its own config object
+	res = GaneshaConfigObjects(cephNFS, cephver.CephVersion{Major: 14, Minor: 2, Extra: 5})
+	assert.Equal(t, []string{"conf-my-nfs.a", "conf-my-nfs.b"}, res)
+}
+
+func TestGaneshaRGWConfig(t *testing.T) {
+	cephNFS := &cephv1.CephNFS{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+	}
+	objContext := &object.Context{Realm: "my-store", ZoneGroup: "my-store", Zone: "my-store"}
+
+	// no bucket is exported
+	assert.Equal(t, "", getGaneshaRGWConfig(cephNFS, "a", objContext))
+
+	cephNFS.Spec.RGW = &cephv1.GaneshaRGWSpec{ObjectStoreName: "my-store"}
+	config := getGaneshaRGWConfig(cephNFS, "a", objContext)
+	assert.Contains(t, config, `name = "client.nfs-ganesha.my-nfs.a.rgw";`)
+	assert.Contains(t, config, `init_args = "--keyring=/etc/ceph/keyring-store/keyring --rgw-realm=my-store --rgw-zonegroup=my-store --rgw-zone=my-store";`)
+}
+
+func TestGaneshaSecurityConfig(t *testing.T) {
+	n := &cephv1.CephNFS{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-nfs", Namespace: namespace},
+		Spec: cephv1.NFSGaneshaSpec{
+			RADOS:  cephv1.GaneshaRADOSSpec{Pool: "foo", Namespace: namespace},
+			Server: cephv1.GaneshaServerSpec{Active: 1},
+		},
+	}
+
+	// only NFSv4 is served by default without security settings
+	config := getGaneshaConfig(n, cephver.Octopus, "a")
+	assert.Contains(t, config, "Enable_NLM = false;\n\tEnable_RQUOTA = false;\n\tProtocols = 4;\n}")
+	assert.Contains(t, config, "Attr_Expiration_Time = 0;\n\tProtocols = 4;\n}")
+	assert.NotContains(t, config, "MNT_Port")
+	assert.NotContains(t, config, "NFS_KRB5")
+	assert.NotContains(t, config, "SecType")
+	assert.NoError(t, validateGaneshaSecurity(n))
+
+	n.Spec.Server.Protocols = []int{4, 3}
+	n.Spec.Server.Security = &cephv1.GaneshaSecuritySpec{
+		Flavors:  []string{"krb5p", "krb5i"},
+		Kerberos: &cephv1.GaneshaKerberosSpec{KeytabSecretName: "nfs-keytab", ConfigConfigMapName: "krb5"},
+		IDMapping: &cephv1.GaneshaIDMappingSpec{
+			Domain:              "example.com",
+			ConfigConfigMapName: "idmapd",
+		},
+	}
+	assert.NoError(t, validateGaneshaSecurity(n))
+	config = getGaneshaConfig(n, cephver.Octopus, "a")
+	assert.Contains(t, config, "Protocols = 3, 4;\n\tMNT_Port = 20048;\n}")
+	assert.Contains(t, config, "Protocols = 3, 4;\n\tSecType = \"krb5p\", \"krb5i\";\n}")
+	assert.Contains(t, config, "DomainName = \"example.com\";\n\tIdmapConf = \"/etc/idmapd.conf\";\n}")
+	assert.Contains(t, config, `
+NFS_KRB5 {
+	PrincipalName = "nfs";
+	KeytabPath = "/etc/krb5.keytab";
+	Active_krb5 = true;
+}
+`)
+	ports := servicePorts(n)
+	assert.Equal(t, 2, len(ports))
+	assert.Equal(t, int32(20048), ports[1].Port)
+	volumes, mounts := securityVolumesAndMounts(n)
+	assert.Equal(t, 3, len(volumes))
+	assert.Equal(t, "nfs-keytab", volumes[0].Secret.SecretName)
+	assert.Equal(t, []string{"/etc/krb5.keytab", "/etc/krb5.conf", "/etc/idmapd.conf"}, []string{mounts[0].MountPath, mounts[1].MountPath, mounts[2].MountPath})
+
+	// invalid settings
+	n.Spec.Server.Protocols = []int{2}
+	assert.Error(t, validateGaneshaSecurity(n))
+	n.Spec.Server.Protocols = nil
+	n.Spec.Server.Security.Flavors = []string{"krb6"}
+	assert.Error(t, validateGaneshaSecurity(n))
+	n.Spec.Server.Security.Kerberos.KeytabSecretName = ""
+	assert.Error(t, validateGaneshaSecurity(n))
+	n.Spec.Server.Security.Kerberos = nil
+	n.Spec.Server.Security.Flavors = []string{"sys", "krb5"}
+	assert.Error(t, validateGaneshaSecurity(n))
+	n.Spec.Server.Security.Flavors = []string{"sys"}
+	assert.NoError(t, validateGaneshaSecurity(n))
+}
@@ -0,0 +1,440 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package export to manage the exports of a CephNFS
+package export
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/coreos/pkg/capnslog"
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/ceph/cluster/mon"
+	opcontroller "github.com/rook/rook/pkg/operator/ceph/controller"
+	"github.com/rook/rook/pkg/operator/ceph/object"
+	"github.com/rook/rook/pkg/operator/ceph/reporting"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	controllerName = "ceph-nfs-export-controller"
+)
+
+var logger = capnslog.NewPackageLogger("github.com/rook/rook", controllerName)
+
+var cephNFSExportKind = reflect.TypeOf(cephv1.CephNFSExport{}).Name()
+
+// Sets the type meta for the controller main object
+var controllerTypeMeta = metav1.TypeMeta{
+	Kind:       cephNFSExportKind,
+	APIVersion: fmt.Sprintf("%s/%s", cephv1.CustomResourceGroup, cephv1.Version),
+}
+
+// waitForServersReload is the result when some ganesha servers did not reload the exports yet
+var waitForServersReload = reconcile.Result{Requeue: true, RequeueAfter: 30 * time.Second}
+
+// ReconcileCephNFSExport reconciles a CephNFSExport object
+type ReconcileCephNFSExport struct {
+	client      client.Client
+	scheme      *runtime.Scheme
+	context     *clusterd.Context
+	clusterInfo *cephclient.ClusterInfo
+	objContext  *object.Context
+}
+
+// Add creates a new CephNFSExport Controller and adds it to the Manager. The Manager will set fields on the Controller
+// and Start it when the Manager is Started.
+func Add(mgr manager.Manager, context *clusterd.Context) error {
+	return add(mgr, newReconciler(mgr, context))
+}
+
+// newReconciler returns a new reconcile.Reconciler
+func newReconciler(mgr manager.Manager, context *clusterd.Context) reconcile.Reconciler {
+	return &ReconcileCephNFSExport{
+		client:  mgr.GetClient(),
+		scheme:  mgr.GetScheme(),
+		context: context,
+	}
+}
+
+func add(mgr manager.Manager, r reconcile.Reconciler) error {
+	// Create a new controller
+	c, err := controller.New(controllerName, mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+	logger.Info("successfully started")
+
+	// Watch for changes on the CephNFSExport CRD object
+	err = c.Watch(&source.Kind{Type: &cephv1.CephNFSExport{TypeMeta: controllerTypeMeta}}, &handler.EnqueueRequestForObject{}, opcontroller.WatchControllerPredicate())
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Reconcile reads that state of the cluster for a CephNFSExport object and makes changes based on the state read
+// and what is in the CephNFSExport.Spec
+// The Controller will requeue the Request to be processed again if the returned error is non-nil or
+// Result.Requeue is true, otherwise upon completion it will remove the work from the queue.
+func (r *ReconcileCephNFSExport) Reconcile(context context.Context, request reconcile.Request) (reconcile.Result, error) {
+	// workaround because the rook logging mechanism is not compatible with the controller-runtime logging interface
+	reconcileResponse, err := r.reconcile(request)
+	if err != nil {
+		logger.Errorf("failed to reconcile %v", err)
+	}
+
+	return reconcileResponse, err
+}
+
+func (r *ReconcileCephNFSExport) reconcile(request reconcile.Request) (reconcile.Result, error) {
+	// Fetch the CephNFSExport instance
+	cephNFSExport := &cephv1.CephNFSExport{}
+	err := r.client.Get(context.TODO(), request.NamespacedName, cephNFSExport)
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			logger.Debug("cephNFSExport resource not found. Ignoring since object must be deleted.")
+			return reconcile.Result{}, nil
+		}
+		// Error reading the object - requeue the request.
+		return reconcile.Result{}, errors.Wrap(err, "failed to get cephNFSExport")
+	}
+
+	// Set a finalizer so we can do cleanup before the object goes away
+	err = opcontroller.AddFinalizerIfNotPresent(r.client, cephNFSExport)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrap(err, "failed to add finalizer")
+	}
+
+	// The CR was just created, initializing status fields
+	if cephNFSExport.Status == nil {
+		updateStatus(r.client, request.NamespacedName, cephv1.CephNFSExportStatus{Phase: cephv1.ConditionProgressing})
+	}
+
+	// Make sure a CephCluster is present otherwise do nothing
+	cephCluster, isReadyToReconcile, cephClusterExists, reconcileResponse := opcontroller.IsReadyToReconcile(r.client, r.context, request.NamespacedName, controllerName)
+	if !isReadyToReconcile {
+		// This handles the case where the Ceph Cluster is gone and we want to delete that CR
+		// We skip the deleteExport() function since everything is gone already
+		//
+		// Also, only remove the finalizer if the CephCluster is gone
+		// If not, we should wait for it to be ready
+		// This handles the case where the operator is not ready to accept Ceph command but the cluster exists
+		if !cephNFSExport.GetDeletionTimestamp().IsZero() && !cephClusterExists {
+			// Remove finalizer
+			err = opcontroller.RemoveFinalizer(r.client, cephNFSExport)
+			if err != nil {
+				return opcontroller.ImmediateRetryResult, errors.Wrap(err, "failed to remove finalizer")
+			}
+
+			// Return and do not requeue. Successful deletion.
+			return reconcile.Result{}, nil
+		}
+		return reconcileResponse, nil
+	}
+
+	// Populate clusterInfo during each reconcile
+	r.clusterInfo, _, _, err = mon.LoadClusterInfo(r.context, request.NamespacedName.Namespace)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrap(err, "failed to populate cluster info")
+	}
+
+	// Get CephCluster version, the config objects of the ganesha servers depend on it
+	cephVersion, err := opcontroller.GetImageVersion(cephCluster)
+	if err != nil {
+		return opcontroller.ImmediateRetryResult, errors.Wrapf(err, "failed to fetch ceph version from cephcluster %q", cephCluster.Name)
+	}
+	r.clusterInfo.CephVersion = *cephVersion
+
+	// The CephNFS holds the pool and namespace of the exports
+	cephNFS := &cephv1.CephNFS{}
+	nfsName := types.NamespacedName{Name: cephNFSExport.Spec.NFSName, Namespace: request.Namespace}
+	err = r.client.Get(context.TODO(), nfsName, cephNFS)
+	if err != nil {
+		if !kerrors.IsNotFound(err) {
+			return reconcile.Result{}, errors.Wrapf(err, "failed to get ceph nfs %q", nfsName.Name)
+		}
+		cephNFS = nil
+	}
+
+	// DELETE: the CR was deleted
+	if !cephNFSExport.GetDeletionTimestamp().IsZero() {
+		logger.Debugf("deleting nfs export %q", cephNFSExport.Name)
+		// the object store user created for the export is removed with it, unless the object store is gone
+		if cephNFSExport.Spec.RGW != nil {
+			if _, err := r.loadObjectContext(cephNFSExport, cephCluster); err != nil {
+				return reconcile.Result{}, err
+			}
+		}
+		err := r.deleteExport(cephNFSExport, cephNFS)
+		if err != nil {
+			return reconcile.Result{}, errors.Wrapf(err, "failed to delete ceph nfs export %q", cephNFSExport.Name)
+		}
+
+		// Remove finalizer
+		err = opcontroller.RemoveFinalizer(r.client, cephNFSExport)
+		if err != nil {
+			return reconcile.Result{}, errors.Wrap(err, "failed to remove finalizer")
+		}
+
+		// Return and do not requeue. Successful deletion.
+		return reconcile.Result{}, nil
+	}
+
+	// validate the export settings
+	err = r.validateExport(cephNFSExport)
+	if err != nil {
+		updateStatus(r.client, request.NamespacedName, cephv1.CephNFSExportStatus{Phase: cephv1.ConditionFailure, Message: err.Error()})
+		return reconcile.Result{}, errors.Wrapf(err, "invalid nfs export %q arguments", cephNFSExport.Name)
+	}
+
+	// The ganesha servers must be running before they can serve the export
+	if cephNFS == nil {
+		logger.Infof("waiting for ceph nfs %q to be created before creating export %q", nfsName.Name, cephNFSExport.Name)
+		return opcontroller.WaitForRequeueIfCephClusterNotReady, nil
+	}
+	if cephNFS.Status == nil || cephNFS.Status.Phase != k8sutil.ReadyStatus {
+		logger.Infof("waiting for ceph nfs %q to be ready before creating export %q", nfsName.Name, cephNFSExport.Name)
+		return opcontroller.WaitForRequeueIfCephClusterNotReady, nil
+	}
+
+	if cephNFSExport.Spec.RGW != nil {
+		// The ganesha servers load the RGW library for a single object store
+		storeName := cephNFSExport.Spec.RGW.ObjectStoreName
+		if cephNFS.Spec.RGW == nil || cephNFS.Spec.RGW.ObjectStoreName != storeName {
+			err := errors.Errorf("ceph nfs %q does not export the buckets of ceph object store %q", nfsName.Name, storeName)
+			updateStatus(r.client, request.NamespacedName, cephv1.CephNFSExportStatus{Phase: cephv1.ConditionFailure, Message: err.Error()})
+			return reconcile.Result{}, errors.Wrapf(err, "failed to create nfs export %q", cephNFSExport.Name)
+		}
+
+		found, err := r.loadObjectContext(cephNFSExport, cephCluster)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		if !found {
+			logger.Infof("waiting for ceph object store %q to be created before creating export %q", storeName, cephNFSExport.Name)
+			return opcontroller.WaitForRequeueIfCephClusterNotReady, nil
+		}
+	} else {
+		// The filesystem must exist and be ready before exporting it
+		cephFilesystem := &cephv1.CephFilesystem{}
+		fsName := types.NamespacedName{Name: cephNFSExport.Spec.CephFS.FilesystemName, Namespace: request.Namespace}
+		err = r.client.Get(context.TODO(), fsName, cephFilesystem)
+		if err != nil {
+			if kerrors.IsNotFound(err) {
+				logger.Infof("waiting for ceph filesystem %q to be created before creating export %q", fsName.Name, cephNFSExport.Name)
+				return opcontroller.WaitForRequeueIfCephClusterNotReady, nil
+			}
+			return reconcile.Result{}, errors.Wrapf(err, "failed to get ceph filesystem %q", fsName.Name)
+		}
+		if cephFilesystem.Status == nil || cephFilesystem.Status.Phase != cephv1.ConditionReady {
+			logger.Infof("waiting for ceph filesystem %q to be ready before creating export %q", fsName.Name, cephNFSExport.Name)
+			return opcontroller.WaitForRequeueIfCephClusterNotReady, nil
+		}
+	}
+
+	// Pick the export ID and save it before writing the export so it is never assigned twice
+	exportID, err := r.exportID(cephNFSExport, cephNFS)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrapf(err, "failed to assign an id to nfs export %q", cephNFSExport.Name)
+	}
+	previousID := 0
+	if cephNFSExport.Status != nil {
+		previousID = cephNFSExport.Status.ExportID
+	}
+	if previousID != exportID {
+		if previousID != 0 {
+			logger.Infof("export id of nfs export %q changed from %d to %d", cephNFSExport.Name, previousID, exportID)
+			if err := r.removeExportObject(cephNFS, previousID); err != nil {
+				return reconcile.Result{}, errors.Wrapf(err, "failed to remove the previous export of nfs export %q", cephNFSExport.Name)
+			}
+		}
+		if err := saveExportID(r.client, request.NamespacedName, exportID); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+
+	// Create or Update the export
+	changed, err := r.createOrUpdateExport(cephNFSExport, cephNFS, exportID)
+	if err != nil {
+		if strings.Contains(err.Error(), opcontroller.UninitializedCephConfigError) {
+			logger.Info(opcontroller.OperatorNotInitializedMessage)
+			return opcontroller.WaitForRequeueIfOperatorNotInitialized, nil
+		}
+		updateStatus(r.client, request.NamespacedName, cephv1.CephNFSExportStatus{Phase: cephv1.ConditionFailure, ExportID: exportID, Message: err.Error()})
+		return reconcile.Result{}, errors.Wrapf(err, "failed to create or update nfs export %q", cephNFSExport.Name)
+	}
+
+	// Nothing to reload if the servers already picked up the export
+	if !changed && cephNFSExport.Status != nil && cephNFSExport.Status.Phase == cephv1.ConditionReady && previousID == exportID {
+		logger.Debug("done reconciling")
+		return reconcile.Result{}, nil
+	}
+
+	// Ask the ganesha servers to reload their exports
+	reloaded, err := r.reloadServers(cephNFS)
+	if err != nil || reloaded < cephNFS.Spec.Server.Active {
+		message := fmt.Sprintf("%d of %d ganesha servers reloaded the exports", reloaded, cephNFS.Spec.Server.Active)
+		if err != nil {
+			message = err.Error()
+		}
+		logger.Infof("waiting for the ganesha servers of ceph nfs %q to reload export %q. %s", nfsName.Name, cephNFSExport.Name, message)
+		updateStatus(r.client, request.NamespacedName, cephv1.CephNFSExportStatus{Phase: cephv1.ConditionProgressing, ExportID: exportID, ReloadedServers: reloaded, Message: message})
+		return waitForServersReload, nil
+	}
+
+	// Success! Let's update the status
+	updateStatus(r.client, request.NamespacedName, cephv1.CephNFSExportStatus{Phase: cephv1.ConditionReady, ExportID: exportID, ReloadedServers: reloaded})
+
+	// Return and do not requeue
+	logger.Debug("done reconciling")
+	return reconcile.Result{}, nil
+}
+
+// loadObjectContext loads the context of the object store of a bucket export. It returns false and leaves
+// the context unset if the object store does not exist.
+func (r *ReconcileCephNFSExport) loadObjectContext(cephNFSExport *cephv1.CephNFSExport, cephCluster cephv1.CephCluster) (bool, error) {
+	r.objContext = nil
+	storeName := cephNFSExport.Spec.RGW.ObjectStoreName
+	cephObjectStore := &cephv1.CephObjectStore{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: storeName, Namespace: cephNFSExport.Namespace}, cephObjectStore)
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, errors.Wrapf(err, "failed to get ceph object store %q", storeName)
+	}
+	objContext, err := object.NewMultisiteContext(r.context, r.clusterInfo, cephObjectStore)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to get the zone of ceph object store %q", storeName)
+	}
+	// The object store context needs the CephCluster spec to read the network settings
+	objContext.CephClusterSpec = cephCluster.Spec
+	r.objContext = objContext
+	return true, nil
+}
+
+func (r *ReconcileCephNFSExport) validateExport(cephNFSExport *cephv1.CephNFSExport) error {
+	if cephNFSExport.Name == "" {
+		return errors.New("missing name")
+	}
+	if cephNFSExport.Namespace == "" {
+		return errors.New("missing namespace")
+	}
+	spec := cephNFSExport.Spec
+	if spec.NFSName == "" {
+		return errors.New("missing nfsName")
+	}
+	if !strings.HasPrefix(spec.PseudoPath, "/") {
+		return errors.Errorf("invalid pseudoPath %q, must be an absolute path", spec.PseudoPath)
+	}
+
+	if (spec.CephFS == nil) == (spec.RGW == nil) {
+		return errors.New("exactly one of cephfs and rgw must be set")
+	}
+	if spec.CephFS != nil {
+		if spec.CephFS.FilesystemName == "" {
+			return errors.New("missing cephfs filesystemName")
+		}
+		if spec.CephFS.Path != "" && !strings.HasPrefix(spec.CephFS.Path, "/") {
+			return errors.Errorf("invalid cephfs path %q, must be an absolute path", spec.CephFS.Path)
+		}
+	}
+	if spec.RGW != nil {
+		if spec.RGW.ObjectStoreName == "" || spec.RGW.Bucket == "" {
+			return errors.New("rgw objectStoreName and bucket must be set")
+		}
+	}
+
+	for i, c := range spec.Clients {
+		if len(c.Addresses) == 0 {
+			return errors.Errorf("missing addresses of clients %d", i)
+		}
+		for _, address := range c.Addresses {
+			if address == "" {
+				return errors.Errorf("empty address in clients %d", i)
+			}
+		}
+	}
+
+	// ganesha refuses exports with the same pseudo path
+	exports := &cephv1.CephNFSExportList{}
+	if err := r.client.List(context.TODO(), exports, client.InNamespace(cephNFSExport.Namespace)); err != nil {
+		return errors.Wrap(err, "failed to list nfs exports")
+	}
+	for _, other := range exports.Items {
+		if other.Name != cephNFSExport.Name && other.Spec.NFSName == spec.NFSName && other.Spec.PseudoPath == spec.PseudoPath {
+			return errors.Errorf("pseudoPath %q is already used by nfs export %q", spec.PseudoPath, other.Name)
+		}
+	}
+
+	return nil
+}
+
+// saveExportID saves the export ID in the status of an object
+func saveExportID(client client.Client, name types.NamespacedName, exportID int) error {
+	cephNFSExport := &cephv1.CephNFSExport{}
+	if err := client.Get(context.TODO(), name, cephNFSExport); err != nil {
+		return errors.Wrapf(err, "failed to retrieve ceph nfs export %q to save export id %d", name, exportID)
+	}
+
+	cephNFSExport.Status = &cephv1.CephNFSExportStatus{Phase: cephv1.ConditionProgressing, ExportID: exportID}
+	if err := reporting.UpdateStatus(client, cephNFSExport); err != nil {
+		return errors.Wrapf(err, "failed to save export id %d of ceph nfs export %q", exportID, name)
+	}
+	return nil
+}
+
+// updateStatus updates an object with a given status. The export ID is kept if the given status has none.
+func updateStatus(client client.Client, name types.NamespacedName, status cephv1.CephNFSExportStatus) {
+	cephNFSExport := &cephv1.CephNFSExport{}
+	if err := client.Get(context.TODO(), name, cephNFSExport); err != nil {
+		if kerrors.IsNotFound(err) {
+			logger.Debug("CephNFSExport resource not found. Ignoring since object must be deleted.")
+			return
+		}
+		logger.Warningf("failed to retrieve ceph nfs export %q to update status to %q. %v", name, status.Phase, err)
+		return
+	}
+	if status.ExportID == 0 && cephNFSExport.Status != nil {
+		status.ExportID = cephNFSExport.Status.ExportID
+	}
+
+	cephNFSExport.Status = &status
+	if err := reporting.UpdateStatus(client, cephNFSExport); err != nil {
+		logger.Errorf("failed to set ceph nfs export %q status to %q. %v", name, status.Phase, err)
+		return
+	}
+	logger.Debugf("ceph nfs export %q status updated to %q", name, status.Phase)
+}
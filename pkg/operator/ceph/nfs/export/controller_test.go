@@ -0,0 +1,402 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package export
+
+import (
+	"context"
+	"io/ioutil"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	rookclient "github.com/rook/rook/pkg/client/clientset/versioned/fake"
+	"github.com/rook/rook/pkg/client/clientset/versioned/scheme"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/ceph/object"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	testop "github.com/rook/rook/pkg/operator/test"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/tevino/abool"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func newExport(name, pseudoPath string) *cephv1.CephNFSExport {
+	return &cephv1.CephNFSExport{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "rook-ceph"},
+		Spec: cephv1.CephNFSExportSpec{
+			NFSName:    "my-nfs",
+			PseudoPath: pseudoPath,
+			CephFS:     &cephv1.NFSExportCephFSSpec{FilesystemName: "myfs", Path: "/volumes/share"},
+		},
+	}
+}
+
+func TestValidateExport(t *testing.T) {
+	s := scheme.Scheme
+	s.AddKnownTypes(cephv1.SchemeGroupVersion, &cephv1.CephNFSExport{}, &cephv1.CephNFSExportList{})
+	other := newExport("other", "/other")
+	r := &ReconcileCephNFSExport{client: fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(other).Build()}
+
+	e := newExport("share", "/share")
+	assert.NoError(t, r.validateExport(e))
+
+	e.Spec.PseudoPath = "share"
+	assert.Error(t, r.validateExport(e))
+	e.Spec.PseudoPath = "/other"
+	assert.Error(t, r.validateExport(e))
+	e.Spec.PseudoPath = "/share"
+
+	// exactly one backend
+	e.Spec.RGW = &cephv1.NFSExportRGWSpec{ObjectStoreName: "my-store", Bucket: "lake", UserID: "analytics", SecretName: "analytics-keys"}
+	assert.Error(t, r.validateExport(e))
+	e.Spec.CephFS = nil
+	assert.NoError(t, r.validateExport(e))
+	// the user and its keys are optional
+	e.Spec.RGW = &cephv1.NFSExportRGWSpec{ObjectStoreName: "my-store", Bucket: "lake"}
+	assert.NoError(t, r.validateExport(e))
+	e.Spec.RGW.Bucket = ""
+	assert.Error(t, r.validateExport(e))
+	e.Spec.RGW = nil
+	assert.Error(t, r.validateExport(e))
+	e.Spec.CephFS = &cephv1.NFSExportCephFSSpec{FilesystemName: "myfs", Path: "volumes"}
+	assert.Error(t, r.validateExport(e))
+	e.Spec.CephFS.Path = ""
+	assert.NoError(t, r.validateExport(e))
+
+	e.Spec.Clients = []cephv1.NFSExportClientSpec{{Addresses: []string{"10.0.0.0/8"}}}
+	assert.NoError(t, r.validateExport(e))
+	e.Spec.Clients[0].Addresses = []string{}
+	assert.Error(t, r.validateExport(e))
+
+	e.Spec.Clients = nil
+	e.Spec.NFSName = ""
+	assert.Error(t, r.validateExport(e))
+}
+
+func TestRenderExport(t *testing.T) {
+	e := newExport("share", "/share")
+	e.Spec.AccessType = "RO"
+	e.Spec.Squash = "All"
+	e.Spec.Clients = []cephv1.NFSExportClientSpec{{Addresses: []string{"10.0.0.0/8", "build.example.com"}, AccessType: "RW", Squash: "Root"}}
+
+	assert.Equal(t, `# managed by rook: rook-ceph/share
+EXPORT {
+	Export_ID = 3;
+	Path = "/volumes/share";
+	Pseudo = "/share";
+	Access_Type = "RO";
+	Squash = "All_Squash";
+	Transports = "TCP";
+	CLIENT {
+		Clients = "10.0.0.0/8", "build.example.com";
+		Access_Type = "RW";
+		Squash = "Root_Squash";
+	}
+	FSAL {
+		Name = "CEPH";
+		User_Id = "nfs-export.share";
+		Secret_Access_Key = "secret";
+		Filesystem = "myfs";
+	}
+}
+`, renderExport(e, 3, "/volumes/share", cephfsFSAL(e, "secret")))
+
+	assert.Equal(t, []string{"mon", "allow r", "mds", "allow r path=/volumes/share", "osd", "allow rw tag cephfs data=myfs"}, cephfsCaps(e))
+	e.Spec.AccessType = ""
+	e.Spec.CephFS.Path = ""
+	assert.Equal(t, "allow rw path=/", cephfsCaps(e)[3])
+}
+
+func TestRGWExport(t *testing.T) {
+	ctx := context.TODO()
+	e := newExport("lake", "/lake")
+	e.Spec.CephFS = nil
+	e.Spec.AccessType = "RO"
+	e.Spec.RGW = &cephv1.NFSExportRGWSpec{ObjectStoreName: "my-store", Bucket: "lake"}
+
+	adminCommands := []string{}
+	nfsUserCreated := false
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithTimeout: func(timeout time.Duration, command string, args ...string) (string, error) {
+			assert.Equal(t, "radosgw-admin", command)
+			adminCommands = append(adminCommands, strings.Join(args[0:2], " "))
+			switch {
+			case args[0] == "bucket" && args[1] == "stats":
+				return `{"bucket":"lake","owner":"analytics","usage":{}}`, nil
+			case args[0] == "user" && args[1] == "info" && args[3] == "analytics":
+				return `{"user_id":"analytics","display_name":"analytics","keys":[{"user":"analytics","access_key":"AK","secret_key":"SK"}]}`, nil
+			case args[0] == "user" && args[1] == "info" && args[3] == "nfs-lake" && !nfsUserCreated:
+				return "could not fetch user info: no user info saved", errors.New("exit status 22")
+			case args[0] == "user" && args[1] == "info" && args[3] == "nfs-lake":
+				return `{"user_id":"nfs-lake","display_name":"nfs export rook-ceph/lake","keys":[{"user":"nfs-lake","access_key":"AK3","secret_key":"SK3"}]}`, nil
+			case args[0] == "user" && args[1] == "create":
+				assert.Equal(t, []string{"--uid", "nfs-lake", "--display-name", "nfs export rook-ceph/lake"}, args[2:6])
+				nfsUserCreated = true
+				return `{"user_id":"nfs-lake","display_name":"nfs export rook-ceph/lake","keys":[{"user":"nfs-lake","access_key":"AK3","secret_key":"SK3"}]}`, nil
+			case args[0] == "user" && args[1] == "rm":
+				assert.Equal(t, "nfs-lake", args[3])
+				return "", nil
+			}
+			return "", errors.Errorf("unexpected radosgw-admin command %q", args)
+		},
+	}
+	c := &clusterd.Context{Executor: executor, Clientset: testop.New(t, 1)}
+	clusterInfo := cephclient.AdminClusterInfo("rook-ceph")
+	r := &ReconcileCephNFSExport{context: c, clusterInfo: clusterInfo, objContext: object.NewContext(c, clusterInfo, "my-store")}
+
+	// the owner of the bucket and its keys are read from the object store
+	path, backend, err := r.exportBackend(e)
+	assert.NoError(t, err)
+	assert.Equal(t, "lake", path)
+	assert.Equal(t, rgwFSAL("analytics", "AK", "SK"), backend)
+	assert.Equal(t, []string{"bucket stats", "user info"}, adminCommands)
+	assert.Contains(t, renderExport(e, 4, path, backend), `	Path = "lake";
+	Pseudo = "/lake";
+	Access_Type = "RO";
+	Squash = "No_Root_Squash";
+	Transports = "TCP";
+	FSAL {
+		Name = "RGW";
+		User_Id = "analytics";
+		Access_Key_Id = "AK";
+		Secret_Access_Key = "SK";
+	}
+`)
+
+	// the keys of the given user are read from a secret
+	e.Spec.RGW.UserID = "reader"
+	e.Spec.RGW.SecretName = "reader-keys"
+	_, _, err = r.exportBackend(e)
+	assert.Error(t, err)
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "reader-keys", Namespace: "rook-ceph"},
+		Data:       map[string][]byte{"AccessKey": []byte("AK2"), "SecretKey": []byte("SK2")},
+	}
+	_, err = c.Clientset.CoreV1().Secrets("rook-ceph").Create(ctx, secret, metav1.CreateOptions{})
+	assert.NoError(t, err)
+	adminCommands = []string{}
+	_, backend, err = r.exportBackend(e)
+	assert.NoError(t, err)
+	assert.Equal(t, rgwFSAL("reader", "AK2", "SK2"), backend)
+	assert.Equal(t, 0, len(adminCommands))
+	// the user with the keys of a secret is not removed with the export
+	assert.NoError(t, r.deleteRGWUser(e))
+	assert.Equal(t, 0, len(adminCommands))
+
+	// a user without a secret is created if it does not exist
+	e.Spec.RGW.UserID = "nfs-lake"
+	e.Spec.RGW.SecretName = ""
+	_, backend, err = r.exportBackend(e)
+	assert.NoError(t, err)
+	assert.Equal(t, rgwFSAL("nfs-lake", "AK3", "SK3"), backend)
+	assert.Equal(t, "user create", adminCommands[len(adminCommands)-1])
+	assert.True(t, nfsUserCreated)
+
+	// the created user is removed with the export, the other users are kept
+	adminCommands = []string{}
+	assert.NoError(t, r.deleteRGWUser(e))
+	assert.Equal(t, []string{"user info", "user rm"}, adminCommands)
+	adminCommands = []string{}
+	e.Spec.RGW.UserID = "analytics"
+	assert.NoError(t, r.deleteRGWUser(e))
+	assert.Equal(t, []string{"user info"}, adminCommands)
+}
+
+func TestCephNFSExportController(t *testing.T) {
+	ctx := context.TODO()
+	namespace := "rook-ceph"
+	cephNFSExport := newExport("share", "/share")
+	cephNFSExport.UID = types.UID("c47cac40-9bee-4d52-823b-ccd803ba5bfe")
+	cephCluster := &cephv1.CephCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      namespace,
+			Namespace: namespace,
+		},
+		Spec: cephv1.ClusterSpec{
+			CephVersion: cephv1.CephVersionSpec{Image: "quay.io/ceph/ceph:v16.2.6"},
+		},
+		Status: cephv1.ClusterStatus{
+			Phase: cephv1.ConditionReady,
+			CephVersion: &cephv1.ClusterVersion{
+				Image:   "quay.io/ceph/ceph:v16.2.6",
+				Version: "16.2.6-0",
+			},
+			CephStatus: &cephv1.CephStatus{
+				Health: "HEALTH_OK",
+			},
+		},
+	}
+	cephNFS := &cephv1.CephNFS{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-nfs", Namespace: namespace},
+		Spec: cephv1.NFSGaneshaSpec{
+			RADOS:  cephv1.GaneshaRADOSSpec{Pool: "nfs-ganesha", Namespace: "my-nfs"},
+			Server: cephv1.GaneshaServerSpec{Active: 2},
+		},
+		Status: &cephv1.NFSStatus{Phase: k8sutil.ReadyStatus},
+	}
+	cephFilesystem := &cephv1.CephFilesystem{
+		ObjectMeta: metav1.ObjectMeta{Name: "myfs", Namespace: namespace},
+		Status:     &cephv1.CephFilesystemStatus{Phase: cephv1.ConditionReady},
+	}
+
+	// the dashboard created export-1 and the servers are watching their config object
+	objects := map[string]string{
+		"conf-nfs.my-nfs": "%url \"rados://nfs-ganesha/my-nfs/export-1\"\n",
+		"export-1":        "EXPORT {}\n",
+	}
+	watchers := 1
+	users := map[string][]string{}
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutput: func(command string, args ...string) (string, error) {
+			if command == "rados" {
+				enoent := exec.Command("sh", "-c", "exit 2").Run()
+				switch args[4] {
+				case "ls":
+					names := []string{}
+					for name := range objects {
+						names = append(names, name)
+					}
+					return strings.Join(names, "\n"), nil
+				case "get":
+					content, ok := objects[args[5]]
+					if !ok {
+						return "", enoent
+					}
+					return content, nil
+				case "put":
+					content, err := ioutil.ReadFile(args[6])
+					assert.NoError(t, err)
+					objects[args[5]] = string(content)
+					return "", nil
+				case "rm":
+					delete(objects, args[5])
+					return "", nil
+				case "notify":
+					return "", nil
+				case "listwatchers":
+					return strings.Repeat("watcher=10.1.0.5:0/1207 client.4235 cookie=1\n", watchers), nil
+				}
+				return "", errors.Errorf("unexpected rados command %q", args)
+			}
+			switch {
+			case args[0] == "status":
+				return `{"fsid":"c47cac40-9bee-4d52-823b-ccd803ba5bfe","health":{"checks":{},"status":"HEALTH_OK"},"pgmap":{"num_pgs":100,"pgs_by_state":[{"state_name":"active+clean","count":100}]}}`, nil
+			case args[0] == "auth" && args[1] == "get-or-create-key":
+				return `{"key":"AQCvzWBeIV9lFRAAninzm+8XFxbSfTiPwoX50g=="}`, nil
+			case args[0] == "auth" && args[1] == "caps":
+				users[args[2]] = args[3:9]
+				return "", nil
+			case args[0] == "auth" && args[1] == "del":
+				delete(users, args[2])
+				return "", nil
+			}
+			return "", errors.Errorf("unexpected ceph command %q", args)
+		},
+	}
+	c := &clusterd.Context{
+		Executor:                   executor,
+		Clientset:                  testop.New(t, 1),
+		RookClientset:              rookclient.NewSimpleClientset(),
+		RequestCancelOrchestration: abool.New(),
+	}
+
+	// Mock clusterInfo
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "rook-ceph-mon",
+			Namespace: namespace,
+		},
+		Data: map[string][]byte{
+			"fsid":         []byte("share"),
+			"mon-secret":   []byte("monsecret"),
+			"admin-secret": []byte("adminsecret"),
+		},
+		Type: k8sutil.RookType,
+	}
+	_, err := c.Clientset.CoreV1().Secrets(namespace).Create(ctx, secret, metav1.CreateOptions{})
+	assert.NoError(t, err)
+
+	// Register operator types with the runtime scheme.
+	s := scheme.Scheme
+	s.AddKnownTypes(cephv1.SchemeGroupVersion, &cephv1.CephNFSExport{}, &cephv1.CephNFSExportList{}, &cephv1.CephCluster{}, &cephv1.CephClusterList{}, &cephv1.CephNFS{}, &cephv1.CephFilesystem{})
+
+	cl := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects([]runtime.Object{cephNFSExport, cephCluster, cephNFS, cephFilesystem}...).Build()
+	c.Client = cl
+	r := &ReconcileCephNFSExport{
+		client:  cl,
+		scheme:  s,
+		context: c,
+	}
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: "share", Namespace: namespace}}
+
+	// only one of the two servers reloaded the exports
+	res, err := r.Reconcile(ctx, req)
+	assert.NoError(t, err)
+	assert.True(t, res.Requeue)
+	assert.NoError(t, cl.Get(ctx, req.NamespacedName, cephNFSExport))
+	assert.Equal(t, cephv1.ConditionProgressing, cephNFSExport.Status.Phase)
+	assert.Equal(t, 2, cephNFSExport.Status.ExportID)
+	assert.Equal(t, 1, cephNFSExport.Status.ReloadedServers)
+
+	// the export is written next to the one of the dashboard
+	assert.True(t, strings.HasPrefix(objects["export-2"], "# managed by rook: rook-ceph/share\nEXPORT {\n\tExport_ID = 2;\n"))
+	assert.Contains(t, objects["export-2"], `Secret_Access_Key = "AQCvzWBeIV9lFRAAninzm+8XFxbSfTiPwoX50g==";`)
+	assert.Equal(t, "%url \"rados://nfs-ganesha/my-nfs/export-1\"\n%url \"rados://nfs-ganesha/my-nfs/export-2\"\n", objects["conf-nfs.my-nfs"])
+	assert.Equal(t, []string{"mon", "allow r", "mds", "allow rw path=/volumes/share", "osd", "allow rw tag cephfs data=myfs"}, users["client.nfs-export.share"])
+
+	// both servers reloaded the exports
+	watchers = 2
+	res, err = r.Reconcile(ctx, req)
+	assert.NoError(t, err)
+	assert.False(t, res.Requeue)
+	cephNFSExport = &cephv1.CephNFSExport{}
+	assert.NoError(t, cl.Get(ctx, req.NamespacedName, cephNFSExport))
+	assert.Equal(t, cephv1.ConditionReady, cephNFSExport.Status.Phase)
+	assert.Equal(t, 2, cephNFSExport.Status.ExportID)
+	assert.Equal(t, 2, cephNFSExport.Status.ReloadedServers)
+	assert.Equal(t, "", cephNFSExport.Status.Message)
+
+	// the export id of the dashboard export is refused
+	cephNFSExport.Spec.ExportID = 1
+	assert.NoError(t, cl.Update(ctx, cephNFSExport))
+	_, err = r.Reconcile(ctx, req)
+	assert.Error(t, err)
+	assert.Equal(t, "EXPORT {}\n", objects["export-1"])
+	assert.NoError(t, cl.Get(ctx, req.NamespacedName, cephNFSExport))
+	assert.Equal(t, cephv1.ConditionFailure, cephNFSExport.Status.Phase)
+	assert.Contains(t, cephNFSExport.Status.Message, "export id 1 is already used")
+
+	// the export is deleted, the dashboard export is kept
+	cephNFSExport.Spec.ExportID = 0
+	cephNFSExport.Status.ExportID = 2
+	assert.NoError(t, r.deleteExport(cephNFSExport, cephNFS))
+	_, found := objects["export-2"]
+	assert.False(t, found)
+	assert.Equal(t, "EXPORT {}\n", objects["export-1"])
+	assert.Equal(t, "%url \"rados://nfs-ganesha/my-nfs/export-1\"\n", objects["conf-nfs.my-nfs"])
+	assert.Equal(t, 0, len(users))
+}
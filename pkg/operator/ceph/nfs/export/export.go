@@ -0,0 +1,433 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package export
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/ceph/nfs"
+	"github.com/rook/rook/pkg/operator/ceph/object"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// exportObjectPrefix is the prefix of the RADOS objects holding the exports, as the dashboard names them
+	exportObjectPrefix = "export-"
+)
+
+var squashOptions = map[string]string{
+	"":     "No_Root_Squash",
+	"None": "No_Root_Squash",
+	"Root": "Root_Squash",
+	"All":  "All_Squash",
+}
+
+func exportObjectName(exportID int) string {
+	return fmt.Sprintf("%s%d", exportObjectPrefix, exportID)
+}
+
+// exportMarker is the first line of the exports written by the operator, exports of other owners are never overwritten
+func exportMarker(cephNFSExport *cephv1.CephNFSExport) string {
+	return fmt.Sprintf("# managed by rook: %s/%s", cephNFSExport.Namespace, cephNFSExport.Name)
+}
+
+// exportUserID returns the ceph user of the export, without the "client." prefix as ganesha expects it
+func exportUserID(cephNFSExport *cephv1.CephNFSExport) string {
+	return fmt.Sprintf("nfs-export.%s", cephNFSExport.Name)
+}
+
+// rgwUserDisplayName returns the display name of the object store user created for a bucket export, the user
+// is only removed with the export if it has this name
+func rgwUserDisplayName(cephNFSExport *cephv1.CephNFSExport) string {
+	return fmt.Sprintf("nfs export %s/%s", cephNFSExport.Namespace, cephNFSExport.Name)
+}
+
+func exportURL(cephNFS *cephv1.CephNFS, exportID int) string {
+	url := fmt.Sprintf("rados://%s/", cephNFS.Spec.RADOS.Pool)
+	if cephNFS.Spec.RADOS.Namespace != "" {
+		url += cephNFS.Spec.RADOS.Namespace + "/"
+	}
+	return url + exportObjectName(exportID)
+}
+
+func exportPath(spec *cephv1.NFSExportCephFSSpec) string {
+	if spec.Path == "" {
+		return "/"
+	}
+	return spec.Path
+}
+
+// cephfsCaps returns the caps of the ceph user of an export, it only accesses the exported path
+func cephfsCaps(cephNFSExport *cephv1.CephNFSExport) []string {
+	mdsCaps := "allow rw"
+	if cephNFSExport.Spec.AccessType == "RO" {
+		mdsCaps = "allow r"
+	}
+	fsName := cephNFSExport.Spec.CephFS.FilesystemName
+	return []string{
+		"mon", "allow r",
+		"mds", fmt.Sprintf("%s path=%s", mdsCaps, exportPath(cephNFSExport.Spec.CephFS)),
+		"osd", fmt.Sprintf("allow rw tag cephfs data=%s", fsName),
+	}
+}
+
+func quote(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = strconv.Quote(v)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// fsal represents the FSAL block of an export, the backend serving it
+type fsal struct {
+	name     string
+	settings [][2]string
+}
+
+func cephfsFSAL(cephNFSExport *cephv1.CephNFSExport, key string) fsal {
+	return fsal{name: "CEPH", settings: [][2]string{
+		{"User_Id", exportUserID(cephNFSExport)},
+		{"Secret_Access_Key", key},
+		{"Filesystem", cephNFSExport.Spec.CephFS.FilesystemName},
+	}}
+}
+
+func rgwFSAL(userID, accessKey, secretKey string) fsal {
+	return fsal{name: "RGW", settings: [][2]string{
+		{"User_Id", userID},
+		{"Access_Key_Id", accessKey},
+		{"Secret_Access_Key", secretKey},
+	}}
+}
+
+// renderExport returns the ganesha export block of an export
+func renderExport(cephNFSExport *cephv1.CephNFSExport, exportID int, path string, backend fsal) string {
+	spec := cephNFSExport.Spec
+	accessType := spec.AccessType
+	if accessType == "" {
+		accessType = "RW"
+	}
+
+	var b strings.Builder
+	b.WriteString(exportMarker(cephNFSExport) + "\n")
+	b.WriteString("EXPORT {\n")
+	fmt.Fprintf(&b, "\tExport_ID = %d;\n", exportID)
+	fmt.Fprintf(&b, "\tPath = %s;\n", strconv.Quote(path))
+	fmt.Fprintf(&b, "\tPseudo = %s;\n", strconv.Quote(spec.PseudoPath))
+	fmt.Fprintf(&b, "\tAccess_Type = %s;\n", strconv.Quote(accessType))
+	fmt.Fprintf(&b, "\tSquash = %s;\n", strconv.Quote(squashOptions[spec.Squash]))
+	// the protocols and security flavors are inherited from the EXPORT_DEFAULTS of the servers
+	b.WriteString("\tTransports = \"TCP\";\n")
+	for _, c := range spec.Clients {
+		b.WriteString("\tCLIENT {\n")
+		fmt.Fprintf(&b, "\t\tClients = %s;\n", quote(c.Addresses))
+		if c.AccessType != "" {
+			fmt.Fprintf(&b, "\t\tAccess_Type = %s;\n", strconv.Quote(c.AccessType))
+		}
+		if c.Squash != "" {
+			fmt.Fprintf(&b, "\t\tSquash = %s;\n", strconv.Quote(squashOptions[c.Squash]))
+		}
+		b.WriteString("\t}\n")
+	}
+	b.WriteString("\tFSAL {\n")
+	fmt.Fprintf(&b, "\t\tName = %s;\n", strconv.Quote(backend.name))
+	for _, setting := range backend.settings {
+		fmt.Fprintf(&b, "\t\t%s = %s;\n", setting[0], strconv.Quote(setting[1]))
+	}
+	b.WriteString("\t}\n")
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// exportID returns the ID of the export. The ID is taken from the spec, then from the status, otherwise
+// the next ID after the exports in the pool is picked.
+func (r *ReconcileCephNFSExport) exportID(cephNFSExport *cephv1.CephNFSExport, cephNFS *cephv1.CephNFS) (int, error) {
+	if cephNFSExport.Spec.ExportID != 0 {
+		return cephNFSExport.Spec.ExportID, nil
+	}
+	if cephNFSExport.Status != nil && cephNFSExport.Status.ExportID != 0 {
+		return cephNFSExport.Status.ExportID, nil
+	}
+
+	objects, err := cephclient.ListRadosObjects(r.context, r.clusterInfo, cephNFS.Spec.RADOS.Pool, cephNFS.Spec.RADOS.Namespace)
+	if err != nil {
+		return 0, err
+	}
+	maxID := 0
+	for _, name := range objects {
+		if !strings.HasPrefix(name, exportObjectPrefix) {
+			continue
+		}
+		id, err := strconv.Atoi(strings.TrimPrefix(name, exportObjectPrefix))
+		if err == nil && id > maxID {
+			maxID = id
+		}
+	}
+	return maxID + 1, nil
+}
+
+// createOrUpdateExport writes the export and adds it to the config objects of the ganesha servers. It
+// returns true if the servers must reload their exports.
+func (r *ReconcileCephNFSExport) createOrUpdateExport(cephNFSExport *cephv1.CephNFSExport, cephNFS *cephv1.CephNFS, exportID int) (bool, error) {
+	pool := cephNFS.Spec.RADOS.Pool
+	namespace := cephNFS.Spec.RADOS.Namespace
+	objectName := exportObjectName(exportID)
+
+	current, found, err := cephclient.GetRadosObject(r.context, r.clusterInfo, pool, namespace, objectName)
+	if err != nil {
+		return false, err
+	}
+	if found && !strings.HasPrefix(string(current), exportMarker(cephNFSExport)+"\n") {
+		return false, errors.Errorf("export id %d is already used by another export", exportID)
+	}
+
+	path, backend, err := r.exportBackend(cephNFSExport)
+	if err != nil {
+		return false, err
+	}
+
+	changed := false
+	export := renderExport(cephNFSExport, exportID, path, backend)
+	if !found || string(current) != export {
+		if err := cephclient.PutRadosObject(r.context, r.clusterInfo, pool, namespace, objectName, []byte(export)); err != nil {
+			return false, err
+		}
+		logger.Infof("nfs export %q written to object %q", cephNFSExport.Name, objectName)
+		changed = true
+	}
+
+	urlLine := fmt.Sprintf("%%url %s", strconv.Quote(exportURL(cephNFS, exportID)))
+	for _, configObject := range nfs.GaneshaConfigObjects(cephNFS, r.clusterInfo.CephVersion) {
+		updated, err := r.updateConfigObject(cephNFS, configObject, func(lines []string) []string {
+			for _, line := range lines {
+				if line == urlLine {
+					return lines
+				}
+			}
+			return append(lines, urlLine)
+		})
+		if err != nil {
+			return false, err
+		}
+		changed = changed || updated
+	}
+
+	return changed, nil
+}
+
+// exportBackend returns the exported path and the FSAL block of an export
+func (r *ReconcileCephNFSExport) exportBackend(cephNFSExport *cephv1.CephNFSExport) (string, fsal, error) {
+	if cephNFSExport.Spec.RGW != nil {
+		userID, accessKey, secretKey, err := r.rgwCredentials(cephNFSExport)
+		if err != nil {
+			return "", fsal{}, err
+		}
+		return cephNFSExport.Spec.RGW.Bucket, rgwFSAL(userID, accessKey, secretKey), nil
+	}
+
+	// the caps are not given on creation so the key is returned when the user exists with other caps
+	user := "client." + exportUserID(cephNFSExport)
+	key, err := cephclient.AuthGetOrCreateKey(r.context, r.clusterInfo, user, []string{})
+	if err != nil {
+		return "", fsal{}, errors.Wrapf(err, "failed to create the ceph user of nfs export %q", cephNFSExport.Name)
+	}
+	if err := cephclient.AuthUpdateCaps(r.context, r.clusterInfo, user, cephfsCaps(cephNFSExport)); err != nil {
+		return "", fsal{}, errors.Wrapf(err, "failed to set the caps of the ceph user of nfs export %q", cephNFSExport.Name)
+	}
+	return exportPath(cephNFSExport.Spec.CephFS), cephfsFSAL(cephNFSExport, key), nil
+}
+
+// rgwCredentials returns the user accessing the exported bucket and its keys. The user is the owner of the
+// bucket if not set, and the keys are read from the object store if no secret is given. A user without a secret
+// is created if it does not exist yet.
+func (r *ReconcileCephNFSExport) rgwCredentials(cephNFSExport *cephv1.CephNFSExport) (string, string, string, error) {
+	spec := cephNFSExport.Spec.RGW
+	userID := spec.UserID
+	if userID == "" {
+		owner, notFound, err := object.GetBucketOwner(r.objContext, spec.Bucket)
+		if notFound {
+			return "", "", "", errors.Errorf("bucket %q not found in object store %q", spec.Bucket, spec.ObjectStoreName)
+		}
+		if err != nil {
+			return "", "", "", errors.Wrapf(err, "failed to get the owner of bucket %q", spec.Bucket)
+		}
+		userID = owner
+	}
+
+	if spec.SecretName != "" {
+		secret, err := r.context.Clientset.CoreV1().Secrets(cephNFSExport.Namespace).Get(context.TODO(), spec.SecretName, metav1.GetOptions{})
+		if err != nil {
+			return "", "", "", errors.Wrapf(err, "failed to get secret %q", spec.SecretName)
+		}
+		accessKey, secretKey := string(secret.Data["AccessKey"]), string(secret.Data["SecretKey"])
+		if accessKey == "" || secretKey == "" {
+			return "", "", "", errors.Errorf("secret %q must have an AccessKey and a SecretKey", spec.SecretName)
+		}
+		return userID, accessKey, secretKey, nil
+	}
+
+	user, errCode, err := object.GetUser(r.objContext, userID)
+	if errCode == object.RGWErrorNotFound && spec.UserID != "" {
+		logger.Infof("creating object store user %q of nfs export %q", userID, cephNFSExport.Name)
+		displayName := rgwUserDisplayName(cephNFSExport)
+		user, _, err = object.CreateUser(r.objContext, object.ObjectUser{UserID: userID, DisplayName: &displayName})
+		if err != nil {
+			return "", "", "", errors.Wrapf(err, "failed to create object store user %q", userID)
+		}
+	} else if err != nil {
+		return "", "", "", errors.Wrapf(err, "failed to get the keys of object store user %q", userID)
+	}
+	return userID, *user.AccessKey, *user.SecretKey, nil
+}
+
+// deleteRGWUser removes the object store user that was created for a bucket export
+func (r *ReconcileCephNFSExport) deleteRGWUser(cephNFSExport *cephv1.CephNFSExport) error {
+	spec := cephNFSExport.Spec.RGW
+	if spec.UserID == "" || spec.SecretName != "" || r.objContext == nil {
+		return nil
+	}
+	user, errCode, err := object.GetUser(r.objContext, spec.UserID)
+	if errCode == object.RGWErrorNotFound {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to get object store user %q", spec.UserID)
+	}
+	// never remove a user that was not created for the export
+	if user.DisplayName == nil || *user.DisplayName != rgwUserDisplayName(cephNFSExport) {
+		return nil
+	}
+	if _, err := object.DeleteUser(r.objContext, spec.UserID); err != nil {
+		return errors.Wrapf(err, "failed to delete object store user %q", spec.UserID)
+	}
+	logger.Infof("deleted object store user %q of nfs export %q", spec.UserID, cephNFSExport.Name)
+	return nil
+}
+
+// updateConfigObject rewrites the lines of a config object of the ganesha servers. The other lines of
+// the object, like the exports created with the dashboard, are kept.
+func (r *ReconcileCephNFSExport) updateConfigObject(cephNFS *cephv1.CephNFS, configObject string, update func([]string) []string) (bool, error) {
+	pool := cephNFS.Spec.RADOS.Pool
+	namespace := cephNFS.Spec.RADOS.Namespace
+	content, _, err := cephclient.GetRadosObject(r.context, r.clusterInfo, pool, namespace, configObject)
+	if err != nil {
+		return false, err
+	}
+
+	lines := []string{}
+	for _, line := range strings.Split(string(content), "\n") {
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+	updated := update(append([]string{}, lines...))
+	if strings.Join(updated, "\n") == strings.Join(lines, "\n") {
+		return false, nil
+	}
+
+	newContent := ""
+	if len(updated) > 0 {
+		newContent = strings.Join(updated, "\n") + "\n"
+	}
+	if err := cephclient.PutRadosObject(r.context, r.clusterInfo, pool, namespace, configObject, []byte(newContent)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// removeExportObject removes an export from the config objects of the ganesha servers and deletes it
+func (r *ReconcileCephNFSExport) removeExportObject(cephNFS *cephv1.CephNFS, exportID int) error {
+	urlLine := fmt.Sprintf("%%url %s", strconv.Quote(exportURL(cephNFS, exportID)))
+	for _, configObject := range nfs.GaneshaConfigObjects(cephNFS, r.clusterInfo.CephVersion) {
+		_, err := r.updateConfigObject(cephNFS, configObject, func(lines []string) []string {
+			kept := []string{}
+			for _, line := range lines {
+				if line != urlLine {
+					kept = append(kept, line)
+				}
+			}
+			return kept
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return cephclient.RemoveRadosObject(r.context, r.clusterInfo, cephNFS.Spec.RADOS.Pool, cephNFS.Spec.RADOS.Namespace, exportObjectName(exportID))
+}
+
+// reloadServers notifies the ganesha servers watching the config objects to reload their exports. It
+// returns the number of servers that acknowledged the notification.
+func (r *ReconcileCephNFSExport) reloadServers(cephNFS *cephv1.CephNFS) (int, error) {
+	pool := cephNFS.Spec.RADOS.Pool
+	namespace := cephNFS.Spec.RADOS.Namespace
+	reloaded := 0
+	for _, configObject := range nfs.GaneshaConfigObjects(cephNFS, r.clusterInfo.CephVersion) {
+		// the notification fails if a watcher does not acknowledge it
+		if err := cephclient.NotifyRadosObject(r.context, r.clusterInfo, pool, namespace, configObject, "reload"); err != nil {
+			return reloaded, err
+		}
+		watchers, err := cephclient.CountRadosObjectWatchers(r.context, r.clusterInfo, pool, namespace, configObject)
+		if err != nil {
+			return reloaded, err
+		}
+		reloaded += watchers
+	}
+	return reloaded, nil
+}
+
+// Delete the export and its ceph user, or the object store user created for it
+func (r *ReconcileCephNFSExport) deleteExport(cephNFSExport *cephv1.CephNFSExport, cephNFS *cephv1.CephNFS) error {
+	logger.Infof("deleting ceph nfs export object %q", cephNFSExport.Name)
+	if cephNFS != nil && cephNFSExport.Status != nil && cephNFSExport.Status.ExportID != 0 {
+		exportID := cephNFSExport.Status.ExportID
+		current, found, err := cephclient.GetRadosObject(r.context, r.clusterInfo, cephNFS.Spec.RADOS.Pool, cephNFS.Spec.RADOS.Namespace, exportObjectName(exportID))
+		if err != nil {
+			return err
+		}
+		// never remove an export that the operator did not write
+		if !found || strings.HasPrefix(string(current), exportMarker(cephNFSExport)+"\n") {
+			if err := r.removeExportObject(cephNFS, exportID); err != nil {
+				return err
+			}
+			if _, err := r.reloadServers(cephNFS); err != nil {
+				logger.Warningf("failed to reload the ganesha servers of ceph nfs %q. %v", cephNFS.Name, err)
+			}
+		}
+	}
+
+	if cephNFSExport.Spec.CephFS != nil {
+		if err := cephclient.AuthDelete(r.context, r.clusterInfo, "client."+exportUserID(cephNFSExport)); err != nil {
+			return err
+		}
+	}
+	if cephNFSExport.Spec.RGW != nil {
+		if err := r.deleteRGWUser(cephNFSExport); err != nil {
+			return err
+		}
+	}
+
+	logger.Infof("deleted ceph nfs export %q", cephNFSExport.Name)
+	return nil
+}
@@ -0,0 +1,210 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nfs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	"github.com/rook/rook/pkg/operator/ceph/controller"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	apps "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	// the clients stay on the same server for 3 hours by default, like the default of Kubernetes
+	defaultSessionAffinityTimeout int32 = 10800
+	defaultGraceCheckInterval           = 15 * time.Second
+)
+
+func ingressServiceName(n *cephv1.CephNFS) string {
+	return fmt.Sprintf("%s-%s", AppName, n.Name)
+}
+
+// ingressLabels select the pods of all the servers of a ceph nfs
+func ingressLabels(n *cephv1.CephNFS) map[string]string {
+	labels := controller.AppLabels(AppName, n.Namespace)
+	labels["ceph_nfs"] = n.Name
+	return labels
+}
+
+func (r *ReconcileCephNFS) generateIngressService(n *cephv1.CephNFS) *v1.Service {
+	labels := ingressLabels(n)
+	timeout := defaultSessionAffinityTimeout
+	if n.Spec.Ingress.SessionAffinityTimeoutSeconds != nil {
+		timeout = *n.Spec.Ingress.SessionAffinityTimeoutSeconds
+	}
+
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ingressServiceName(n),
+			Namespace: n.Namespace,
+			Labels:    labels,
+		},
+		Spec: v1.ServiceSpec{
+			Selector: labels,
+			Type:     n.Spec.Ingress.ServiceType,
+			Ports:    servicePorts(n),
+			// NFS clients keep state on the server, they must reconnect to the same server as long as it is up
+			SessionAffinity: v1.ServiceAffinityClientIP,
+			SessionAffinityConfig: &v1.SessionAffinityConfig{
+				ClientIP: &v1.ClientIPConfig{TimeoutSeconds: &timeout},
+			},
+		},
+	}
+	if n.Spec.Ingress.ServiceType == v1.ServiceTypeLoadBalancer {
+		svc.Spec.LoadBalancerIP = n.Spec.Ingress.LoadBalancerIP
+	}
+	n.Spec.Ingress.Annotations.ApplyToObjectMeta(&svc.ObjectMeta)
+
+	return svc
+}
+
+// reconcileIngress creates or updates the service in front of all the servers, or removes it once the ingress is disabled
+func (r *ReconcileCephNFS) reconcileIngress(n *cephv1.CephNFS) error {
+	if n.Spec.Ingress == nil {
+		if err := k8sutil.DeleteService(r.context.Clientset, n.Namespace, ingressServiceName(n)); err != nil {
+			return errors.Wrapf(err, "failed to delete ceph nfs ingress service %q", ingressServiceName(n))
+		}
+		return nil
+	}
+
+	s := r.generateIngressService(n)
+	err := controllerutil.SetControllerReference(n, s, r.scheme)
+	if err != nil {
+		return errors.Wrapf(err, "failed to set owner reference to ceph nfs ingress service %q", s.Name)
+	}
+
+	svc, err := k8sutil.CreateOrUpdateService(r.context.Clientset, n.Namespace, s)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create ceph nfs ingress service %q", s.Name)
+	}
+
+	logger.Infof("ceph nfs %q ingress service running at %s:%d", n.Name, svc.Spec.ClusterIP, nfsPort)
+	return nil
+}
+
+// graceChecker starts a grace period of the ganesha cluster when a server fails. The clients of the
+// failed server can reclaim their state before the other servers grant them conflicting locks.
+type graceChecker struct {
+	context        *clusterd.Context
+	client         client.Client
+	namespacedName types.NamespacedName
+	interval       time.Duration
+	// available are the servers that were available at the last check
+	available map[string]bool
+}
+
+func newGraceChecker(context *clusterd.Context, client client.Client, namespacedName types.NamespacedName) *graceChecker {
+	return &graceChecker{
+		context:        context,
+		client:         client,
+		namespacedName: namespacedName,
+		interval:       defaultGraceCheckInterval,
+		available:      map[string]bool{},
+	}
+}
+
+// run periodically checks the servers for failures until the ceph nfs is deleted
+func (c *graceChecker) run(stopCh chan struct{}) {
+	logger.Infof("starting the failover check of the servers of ceph nfs %q", c.namespacedName.Name)
+	for {
+		select {
+		case <-stopCh:
+			logger.Infof("stopping the failover check of the servers of ceph nfs %q", c.namespacedName.Name)
+			return
+
+		case <-time.After(c.interval):
+			if err := c.check(); err != nil {
+				logger.Errorf("failed to check the servers of ceph nfs %q for failover. %v", c.namespacedName.Name, err)
+			}
+		}
+	}
+}
+
+// check starts a grace period for each server that became unavailable since the last check
+func (c *graceChecker) check() error {
+	ctx := context.TODO()
+	n := &cephv1.CephNFS{}
+	if err := c.client.Get(ctx, c.namespacedName, n); err != nil {
+		if kerrors.IsNotFound(err) {
+			return nil
+		}
+		return errors.Wrapf(err, "failed to get ceph nfs %q", c.namespacedName.Name)
+	}
+	if n.Spec.Ingress == nil {
+		// the clients do not move between servers without the ingress
+		c.available = map[string]bool{}
+		return nil
+	}
+
+	for i := 0; i < n.Spec.Server.Active; i++ {
+		id := k8sutil.IndexToName(i)
+		d, err := c.context.Clientset.AppsV1().Deployments(n.Namespace).Get(ctx, instanceName(n, id), metav1.GetOptions{})
+		if err != nil {
+			if kerrors.IsNotFound(err) {
+				continue
+			}
+			return errors.Wrapf(err, "failed to get ceph nfs deployment %q", instanceName(n, id))
+		}
+
+		if d.Status.AvailableReplicas > 0 {
+			c.available[id] = true
+			continue
+		}
+		// a server that was never available is still starting
+		if !c.available[id] {
+			continue
+		}
+		// a server that is restarted by an update starts a grace period itself when it comes back
+		if isRollingOut(d) {
+			logger.Debugf("ganesha server %q of ceph nfs %q is being updated, not starting a grace period", id, n.Name)
+			c.available[id] = false
+			continue
+		}
+
+		logger.Infof("ganesha server %q of ceph nfs %q is unavailable, starting a grace period for its clients to reclaim their state", id, n.Name)
+		if err := runGaneshaRadosGrace(c.context, n, id, "start"); err != nil {
+			return errors.Wrapf(err, "failed to start a grace period for ganesha server %q", id)
+		}
+		c.available[id] = false
+	}
+
+	return nil
+}
+
+// isRollingOut returns whether the pods of a deployment are being replaced after a change of its spec
+func isRollingOut(d *apps.Deployment) bool {
+	if d.Status.ObservedGeneration < d.Generation {
+		return true
+	}
+	replicas := int32(1)
+	if d.Spec.Replicas != nil {
+		replicas = *d.Spec.Replicas
+	}
+	return d.Status.UpdatedReplicas < replicas
+}
@@ -0,0 +1,196 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nfs
+
+import (
+	"context"
+	"testing"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	rook "github.com/rook/rook/pkg/apis/rook.io"
+	"github.com/rook/rook/pkg/client/clientset/versioned/scheme"
+	"github.com/rook/rook/pkg/clusterd"
+	"github.com/rook/rook/pkg/operator/test"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+	apps "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newIngressNFS() *cephv1.CephNFS {
+	return &cephv1.CephNFS{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: cephv1.NFSGaneshaSpec{
+			RADOS: cephv1.GaneshaRADOSSpec{
+				Pool:      "foo",
+				Namespace: namespace,
+			},
+			Server: cephv1.GaneshaServerSpec{
+				Active: 2,
+			},
+			Ingress: &cephv1.GaneshaIngressSpec{},
+		},
+		TypeMeta: controllerTypeMeta,
+	}
+}
+
+func TestIsRollingOut(t *testing.T) {
+	replicas := int32(1)
+	d := &apps.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Generation: 2},
+		Spec:       apps.DeploymentSpec{Replicas: &replicas},
+		Status:     apps.DeploymentStatus{ObservedGeneration: 2, UpdatedReplicas: 1},
+	}
+	assert.False(t, isRollingOut(d))
+
+	// the new spec is not observed yet
+	d.Generation = 3
+	assert.True(t, isRollingOut(d))
+
+	// the pod is not updated yet
+	d.Status.ObservedGeneration = 3
+	d.Status.UpdatedReplicas = 0
+	assert.True(t, isRollingOut(d))
+}
+
+func TestIngressService(t *testing.T) {
+	ctx := context.TODO()
+	s := scheme.Scheme
+	s.AddKnownTypes(cephv1.SchemeGroupVersion, &cephv1.CephNFS{})
+	c := &clusterd.Context{Clientset: test.New(t, 1)}
+	r := &ReconcileCephNFS{scheme: s, context: c}
+	n := newIngressNFS()
+
+	// the service selects the pods of all the servers and keeps the clients on the same server
+	assert.NoError(t, r.reconcileIngress(n))
+	svc, err := c.Clientset.CoreV1().Services(namespace).Get(ctx, "rook-ceph-nfs-my-nfs", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"app": AppName, "rook_cluster": namespace, "ceph_nfs": name}, svc.Spec.Selector)
+	assert.Equal(t, v1.ServiceAffinityClientIP, svc.Spec.SessionAffinity)
+	assert.Equal(t, int32(10800), *svc.Spec.SessionAffinityConfig.ClientIP.TimeoutSeconds)
+	assert.Equal(t, int32(2049), svc.Spec.Ports[0].Port)
+	assert.Equal(t, name, svc.OwnerReferences[0].Name)
+
+	// the load balancer settings are updated
+	timeout := int32(600)
+	n.Spec.Ingress = &cephv1.GaneshaIngressSpec{
+		ServiceType:                   v1.ServiceTypeLoadBalancer,
+		LoadBalancerIP:                "192.168.10.100",
+		Annotations:                   rook.Annotations{"metallb.universe.tf/address-pool": "nfs"},
+		SessionAffinityTimeoutSeconds: &timeout,
+	}
+	assert.NoError(t, r.reconcileIngress(n))
+	svc, err = c.Clientset.CoreV1().Services(namespace).Get(ctx, "rook-ceph-nfs-my-nfs", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, v1.ServiceTypeLoadBalancer, svc.Spec.Type)
+	assert.Equal(t, "192.168.10.100", svc.Spec.LoadBalancerIP)
+	assert.Equal(t, "nfs", svc.Annotations["metallb.universe.tf/address-pool"])
+	assert.Equal(t, int32(600), *svc.Spec.SessionAffinityConfig.ClientIP.TimeoutSeconds)
+
+	// the service is removed with the ingress
+	n.Spec.Ingress = nil
+	assert.NoError(t, r.reconcileIngress(n))
+	_, err = c.Clientset.CoreV1().Services(namespace).Get(ctx, "rook-ceph-nfs-my-nfs", metav1.GetOptions{})
+	assert.True(t, kerrors.IsNotFound(err))
+	assert.NoError(t, r.reconcileIngress(n))
+}
+
+func TestGraceChecker(t *testing.T) {
+	ctx := context.TODO()
+	graceStarted := []string{}
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithEnv: func(env []string, command string, args ...string) error {
+			assert.Equal(t, ganeshaRadosGraceCmd, command)
+			assert.Equal(t, []string{"--pool", "foo", "--ns", namespace}, args[0:4])
+			if args[4] == "start" {
+				graceStarted = append(graceStarted, args[5])
+			}
+			return nil
+		},
+	}
+	clientset := test.New(t, 1)
+	c := &clusterd.Context{Executor: executor, Clientset: clientset}
+	n := newIngressNFS()
+	s := scheme.Scheme
+	s.AddKnownTypes(cephv1.SchemeGroupVersion, &cephv1.CephNFS{})
+	cl := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(n).Build()
+	checker := newGraceChecker(c, cl, types.NamespacedName{Name: name, Namespace: namespace})
+
+	setAvailable := func(id string, available int32) {
+		d := &apps.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: instanceName(n, id), Namespace: namespace},
+			Status:     apps.DeploymentStatus{AvailableReplicas: available, UpdatedReplicas: 1},
+		}
+		_, err := clientset.AppsV1().Deployments(namespace).Update(ctx, d, metav1.UpdateOptions{})
+		if kerrors.IsNotFound(err) {
+			_, err = clientset.AppsV1().Deployments(namespace).Create(ctx, d, metav1.CreateOptions{})
+		}
+		assert.NoError(t, err)
+	}
+
+	// servers that are still starting do not start a grace period
+	setAvailable("a", 0)
+	assert.NoError(t, checker.check())
+	assert.Equal(t, 0, len(graceStarted))
+
+	setAvailable("a", 1)
+	setAvailable("b", 1)
+	assert.NoError(t, checker.check())
+	assert.Equal(t, 0, len(graceStarted))
+
+	// a failed server starts a grace period once
+	setAvailable("b", 0)
+	assert.NoError(t, checker.check())
+	assert.NoError(t, checker.check())
+	assert.Equal(t, []string{"my-nfs.b"}, graceStarted)
+
+	// the server fails again after it is back
+	setAvailable("b", 1)
+	assert.NoError(t, checker.check())
+	setAvailable("b", 0)
+	assert.NoError(t, checker.check())
+	assert.Equal(t, []string{"my-nfs.b", "my-nfs.b"}, graceStarted)
+
+	// a server restarted by an update does not start a grace period
+	setAvailable("b", 1)
+	assert.NoError(t, checker.check())
+	d := &apps.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: instanceName(n, "b"), Namespace: namespace, Generation: 2},
+		Status:     apps.DeploymentStatus{ObservedGeneration: 2},
+	}
+	_, err := clientset.AppsV1().Deployments(namespace).Update(ctx, d, metav1.UpdateOptions{})
+	assert.NoError(t, err)
+	assert.NoError(t, checker.check())
+	assert.Equal(t, 2, len(graceStarted))
+
+	// failures are ignored without the ingress
+	setAvailable("b", 1)
+	assert.NoError(t, checker.check())
+	n.Spec.Ingress = nil
+	assert.NoError(t, cl.Update(ctx, n))
+	assert.NoError(t, checker.check())
+	setAvailable("b", 0)
+	assert.NoError(t, checker.check())
+	assert.Equal(t, 2, len(graceStarted))
+}
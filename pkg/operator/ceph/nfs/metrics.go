@@ -0,0 +1,119 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nfs
+
+import (
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	exportLabels  = []string{"namespace", "nfs", "server", "export_id", "path"}
+	exportReadOps = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "rook_ceph_nfs_export_read_ops",
+		Help: "Read operations on the export since the ganesha server started",
+	}, exportLabels)
+	exportWriteOps = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "rook_ceph_nfs_export_write_ops",
+		Help: "Write operations on the export since the ganesha server started",
+	}, exportLabels)
+	exportReadBytes = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "rook_ceph_nfs_export_read_bytes",
+		Help: "Bytes read from the export since the ganesha server started",
+	}, exportLabels)
+	exportWrittenBytes = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "rook_ceph_nfs_export_written_bytes",
+		Help: "Bytes written to the export since the ganesha server started",
+	}, exportLabels)
+	serverClient = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "rook_ceph_nfs_client_info",
+		Help: "A client connected to the ganesha server",
+	}, []string{"namespace", "nfs", "server", "client"})
+)
+
+func init() {
+	// the metrics are served by the metrics endpoint of the operator manager
+	metrics.Registry.MustRegister(exportReadOps, exportWriteOps, exportReadBytes, exportWrittenBytes, serverClient)
+}
+
+// nfsMetrics are the metrics set for the servers of a ceph nfs, to remove the metrics of the exports
+// and clients that are gone
+type nfsMetrics struct {
+	exports map[string][]string
+	clients map[string][]string
+}
+
+func newNFSMetrics() *nfsMetrics {
+	return &nfsMetrics{exports: map[string][]string{}, clients: map[string][]string{}}
+}
+
+// set sets the metrics of the servers and removes the metrics that are not reported anymore
+func (m *nfsMetrics) set(namespace, name string, servers []cephv1.NFSServerStatus) {
+	exports := map[string][]string{}
+	clients := map[string][]string{}
+	for _, server := range servers {
+		for _, e := range server.Exports {
+			labels := []string{namespace, name, server.Name, strconv.Itoa(e.ExportID), e.Path}
+			exportReadOps.WithLabelValues(labels...).Set(float64(e.ReadOps))
+			exportWriteOps.WithLabelValues(labels...).Set(float64(e.WriteOps))
+			exportReadBytes.WithLabelValues(labels...).Set(float64(e.ReadBytes))
+			exportWrittenBytes.WithLabelValues(labels...).Set(float64(e.WrittenBytes))
+			exports[labelsKey(labels)] = labels
+		}
+		for _, client := range server.Clients {
+			labels := []string{namespace, name, server.Name, client}
+			serverClient.WithLabelValues(labels...).Set(1)
+			clients[labelsKey(labels)] = labels
+		}
+	}
+
+	for key, labels := range m.exports {
+		if _, ok := exports[key]; !ok {
+			deleteExportMetrics(labels)
+		}
+	}
+	for key, labels := range m.clients {
+		if _, ok := clients[key]; !ok {
+			serverClient.DeleteLabelValues(labels...)
+		}
+	}
+	m.exports = exports
+	m.clients = clients
+}
+
+// clear removes all the metrics of the servers
+func (m *nfsMetrics) clear() {
+	m.set("", "", nil)
+}
+
+func deleteExportMetrics(labels []string) {
+	exportReadOps.DeleteLabelValues(labels...)
+	exportWriteOps.DeleteLabelValues(labels...)
+	exportReadBytes.DeleteLabelValues(labels...)
+	exportWrittenBytes.DeleteLabelValues(labels...)
+}
+
+func labelsKey(labels []string) string {
+	key := ""
+	for _, l := range labels {
+		key += strconv.Quote(l)
+	}
+	return key
+}
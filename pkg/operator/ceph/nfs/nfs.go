@@ -147,7 +147,7 @@ func (r *ReconcileCephNFS) addRADOSConfigFile(n *cephv1.CephNFS, name string) er
 func (r *ReconcileCephNFS) addServerToDatabase(nfs *cephv1.CephNFS, name string) error {
 	logger.Infof("adding ganesha %q to grace db", name)
 
-	if err := r.runGaneshaRadosGrace(nfs, name, "add"); err != nil {
+	if err := runGaneshaRadosGrace(r.context, nfs, name, "add"); err != nil {
 		return errors.Wrapf(err, "failed to add %q to grace db", name)
 	}
 
@@ -157,24 +157,24 @@ func (r *ReconcileCephNFS) addServerToDatabase(nfs *cephv1.CephNFS, name string)
 func (r *ReconcileCephNFS) removeServerFromDatabase(nfs *cephv1.CephNFS, name string) {
 	logger.Infof("removing ganesha %q from grace db", name)
 
-	if err := r.runGaneshaRadosGrace(nfs, name, "remove"); err != nil {
+	if err := runGaneshaRadosGrace(r.context, nfs, name, "remove"); err != nil {
 		logger.Errorf("failed to remove %q from grace db. %v", name, err)
 	}
 }
 
-func (r *ReconcileCephNFS) runGaneshaRadosGrace(nfs *cephv1.CephNFS, name, action string) error {
+func runGaneshaRadosGrace(context *clusterd.Context, nfs *cephv1.CephNFS, name, action string) error {
 	nodeID := getNFSNodeID(nfs, name)
 	cmd := ganeshaRadosGraceCmd
 	args := []string{"--pool", nfs.Spec.RADOS.Pool, "--ns", nfs.Spec.RADOS.Namespace, action, nodeID}
-	env := []string{fmt.Sprintf("CEPH_CONF=%s", cephclient.CephConfFilePath(r.context.ConfigDir, nfs.Namespace))}
+	env := []string{fmt.Sprintf("CEPH_CONF=%s", cephclient.CephConfFilePath(context.ConfigDir, nfs.Namespace))}
 
-	return r.context.Executor.ExecuteCommandWithEnv(env, cmd, args...)
+	return context.Executor.ExecuteCommandWithEnv(env, cmd, args...)
 }
 
 func (r *ReconcileCephNFS) generateConfigMap(n *cephv1.CephNFS, name string) *v1.ConfigMap {
 
 	data := map[string]string{
-		"config": getGaneshaConfig(n, r.clusterInfo.CephVersion, name),
+		"config": getGaneshaConfig(n, r.clusterInfo.CephVersion, name) + getGaneshaRGWConfig(n, name, r.objContext),
 	}
 	configMap := &v1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
@@ -273,6 +273,18 @@ func validateGanesha(context *clusterd.Context, clusterInfo *cephclient.ClusterI
 		return errors.New("at least one active server required")
 	}
 
+	if n.Spec.RGW != nil && n.Spec.RGW.ObjectStoreName == "" {
+		return errors.New("missing rgw.objectStoreName")
+	}
+
+	if err := validateGaneshaSecurity(n); err != nil {
+		return err
+	}
+
+	if n.Spec.Ingress != nil && n.Spec.Ingress.LoadBalancerIP != "" && n.Spec.Ingress.ServiceType != v1.ServiceTypeLoadBalancer {
+		return errors.New("ingress.loadBalancerIP requires the LoadBalancer ingress.serviceType")
+	}
+
 	// The existence of the pool provided in n.Spec.RADOS.Pool is necessary otherwise addRADOSConfigFile() will fail
 	_, err := cephclient.GetPoolDetails(context, clusterInfo, n.Spec.RADOS.Pool)
 	if err != nil {
@@ -281,3 +293,31 @@ func validateGanesha(context *clusterd.Context, clusterInfo *cephclient.ClusterI
 
 	return nil
 }
+
+func validateGaneshaSecurity(n *cephv1.CephNFS) error {
+	for _, p := range n.Spec.Server.Protocols {
+		if p != 3 && p != 4 {
+			return errors.Errorf("invalid server.protocols %d, only NFS versions 3 and 4 are supported", p)
+		}
+	}
+
+	security := n.Spec.Server.Security
+	if security == nil {
+		return nil
+	}
+	if security.Kerberos != nil && security.Kerberos.KeytabSecretName == "" {
+		return errors.New("missing server.security.kerberos.keytabSecretName")
+	}
+	for _, flavor := range security.Flavors {
+		switch flavor {
+		case "sys":
+		case "krb5", "krb5i", "krb5p":
+			if security.Kerberos == nil {
+				return errors.Errorf("server.security.flavors %q requires server.security.kerberos", flavor)
+			}
+		default:
+			return errors.Errorf("invalid server.security.flavors %q, must be one of sys, krb5, krb5i or krb5p", flavor)
+		}
+	}
+	return nil
+}
@@ -38,6 +38,7 @@ const (
 	AppName             = "rook-ceph-nfs"
 	ganeshaConfigVolume = "ganesha-config"
 	nfsPort             = 2049
+	mountPort           = 20048
 	ganeshaPid          = "/var/run/ganesha/ganesha.pid"
 )
 
@@ -52,14 +53,7 @@ func (r *ReconcileCephNFS) generateCephNFSService(nfs *cephv1.CephNFS, cfg daemo
 		},
 		Spec: v1.ServiceSpec{
 			Selector: labels,
-			Ports: []v1.ServicePort{
-				{
-					Name:       "nfs",
-					Port:       nfsPort,
-					TargetPort: intstr.FromInt(int(nfsPort)),
-					Protocol:   v1.ProtocolTCP,
-				},
-			},
+			Ports:    servicePorts(nfs),
 		},
 	}
 
@@ -110,6 +104,7 @@ func (r *ReconcileCephNFS) makeDeployment(nfs *cephv1.CephNFS, cfg daemonConfig)
 	cephConfigVol, _ := cephConfigVolumeAndMount()
 	nfsConfigVol, _ := nfsConfigVolumeAndMount(cfg.ConfigConfigMap)
 	dbusVol, _ := dbusVolumeAndMount()
+	securityVols, _ := securityVolumesAndMounts(nfs)
 	podSpec := v1.PodSpec{
 		InitContainers: []v1.Container{
 			r.connectionConfigInitContainer(nfs, cfg.ID),
@@ -132,12 +127,10 @@ func (r *ReconcileCephNFS) makeDeployment(nfs *cephv1.CephNFS, cfg daemonConfig)
 		HostNetwork:       r.cephClusterSpec.Network.IsHost(),
 		PriorityClassName: nfs.Spec.Server.PriorityClassName,
 	}
+	podSpec.Volumes = append(podSpec.Volumes, securityVols...)
 	// Replace default unreachable node toleration
 	k8sutil.AddUnreachableNodeToleration(&podSpec)
 
-	if r.cephClusterSpec.Network.IsHost() {
-		podSpec.DNSPolicy = v1.DNSClusterFirstWithHostNet
-	}
 	nfs.Spec.Server.Placement.ApplyToPodSpec(&podSpec)
 
 	podTemplateSpec := v1.PodTemplateSpec{
@@ -148,12 +141,8 @@ func (r *ReconcileCephNFS) makeDeployment(nfs *cephv1.CephNFS, cfg daemonConfig)
 		Spec: podSpec,
 	}
 
-	if r.cephClusterSpec.Network.IsHost() {
-		podSpec.DNSPolicy = v1.DNSClusterFirstWithHostNet
-	} else if r.cephClusterSpec.Network.IsMultus() {
-		if err := k8sutil.ApplyMultus(r.cephClusterSpec.Network, &podTemplateSpec.ObjectMeta); err != nil {
-			return nil, err
-		}
+	if err := k8sutil.ApplyNetworkSettings(r.cephClusterSpec.Network, &podTemplateSpec.ObjectMeta, &podTemplateSpec.Spec); err != nil {
+		return nil, err
 	}
 
 	nfs.Spec.Server.Annotations.ApplyToObjectMeta(&podTemplateSpec.ObjectMeta)
@@ -192,6 +181,7 @@ func (r *ReconcileCephNFS) daemonContainer(nfs *cephv1.CephNFS, cfg daemonConfig
 	_, cephConfigMount := cephConfigVolumeAndMount()
 	_, nfsConfigMount := nfsConfigVolumeAndMount(cfg.ConfigConfigMap)
 	_, dbusMount := dbusVolumeAndMount()
+	_, securityMounts := securityVolumesAndMounts(nfs)
 	logLevel := "NIV_INFO" // Default log level
 	if nfs.Spec.Server.LogLevel != "" {
 		logLevel = nfs.Spec.Server.LogLevel
@@ -209,12 +199,12 @@ func (r *ReconcileCephNFS) daemonContainer(nfs *cephv1.CephNFS, cfg daemonConfig
 			"-N", logLevel, // Change Log level
 		},
 		Image: r.cephClusterSpec.CephVersion.Image,
-		VolumeMounts: []v1.VolumeMount{
+		VolumeMounts: append([]v1.VolumeMount{
 			cephConfigMount,
 			keyring.VolumeMount().Resource(instanceName(nfs, cfg.ID)),
 			nfsConfigMount,
 			dbusMount,
-		},
+		}, securityMounts...),
 		Env:             controller.DaemonEnvVars(r.cephClusterSpec.CephVersion.Image),
 		Resources:       nfs.Spec.Server.Resources,
 		SecurityContext: controller.PodSecurityContext(),
@@ -280,3 +270,64 @@ func dbusVolumeAndMount() (v1.Volume, v1.VolumeMount) {
 	m := v1.VolumeMount{Name: volName, MountPath: dbusSocketDir}
 	return v, m
 }
+
+// securityVolumesAndMounts returns the Kerberos keytab and config and the idmapd config of the servers
+func securityVolumesAndMounts(nfs *cephv1.CephNFS) ([]v1.Volume, []v1.VolumeMount) {
+	volumes := []v1.Volume{}
+	mounts := []v1.VolumeMount{}
+	security := nfs.Spec.Server.Security
+	if security == nil {
+		return volumes, mounts
+	}
+
+	if security.Kerberos != nil {
+		mode := int32(0400)
+		volumes = append(volumes, v1.Volume{Name: "krb5-keytab", VolumeSource: v1.VolumeSource{Secret: &v1.SecretVolumeSource{
+			SecretName:  security.Kerberos.KeytabSecretName,
+			Items:       []v1.KeyToPath{{Key: "krb5.keytab", Path: "krb5.keytab"}},
+			DefaultMode: &mode,
+		}}})
+		mounts = append(mounts, v1.VolumeMount{Name: "krb5-keytab", MountPath: krb5KeytabPath, SubPath: "krb5.keytab", ReadOnly: true})
+
+		if security.Kerberos.ConfigConfigMapName != "" {
+			volumes = append(volumes, configMapFileVolume("krb5-config", security.Kerberos.ConfigConfigMapName, "krb5.conf"))
+			mounts = append(mounts, v1.VolumeMount{Name: "krb5-config", MountPath: krb5ConfigPath, SubPath: "krb5.conf", ReadOnly: true})
+		}
+	}
+
+	if security.IDMapping != nil && security.IDMapping.ConfigConfigMapName != "" {
+		volumes = append(volumes, configMapFileVolume("idmap-config", security.IDMapping.ConfigConfigMapName, "idmapd.conf"))
+		mounts = append(mounts, v1.VolumeMount{Name: "idmap-config", MountPath: idmapConfigPath, SubPath: "idmapd.conf", ReadOnly: true})
+	}
+
+	return volumes, mounts
+}
+
+func configMapFileVolume(volName, configMap, key string) v1.Volume {
+	configMapSource := &v1.ConfigMapVolumeSource{
+		LocalObjectReference: v1.LocalObjectReference{Name: configMap},
+		Items:                []v1.KeyToPath{{Key: key, Path: key}},
+	}
+	return v1.Volume{Name: volName, VolumeSource: v1.VolumeSource{ConfigMap: configMapSource}}
+}
+
+// servicePorts returns the ports of the NFS protocol, and of the MOUNT protocol when NFSv3 is served
+func servicePorts(nfs *cephv1.CephNFS) []v1.ServicePort {
+	ports := []v1.ServicePort{
+		{
+			Name:       "nfs",
+			Port:       nfsPort,
+			TargetPort: intstr.FromInt(int(nfsPort)),
+			Protocol:   v1.ProtocolTCP,
+		},
+	}
+	if servesProtocol(ganeshaProtocols(nfs), 3) {
+		ports = append(ports, v1.ServicePort{
+			Name:       "mount",
+			Port:       mountPort,
+			TargetPort: intstr.FromInt(int(mountPort)),
+			Protocol:   v1.ProtocolTCP,
+		})
+	}
+	return ports
+}
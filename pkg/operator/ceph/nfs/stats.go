@@ -0,0 +1,361 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nfs
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	"github.com/rook/rook/pkg/operator/ceph/reporting"
+	"github.com/rook/rook/pkg/util/exec"
+	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	defaultStatsInterval = 1 * time.Minute
+	dbusContainerName    = "dbus-daemon"
+	ganeshaDBusDest      = "org.ganesha.nfsd"
+	exportMgrPath        = "/org/ganesha/nfsd/ExportMgr"
+	clientMgrPath        = "/org/ganesha/nfsd/ClientMgr"
+)
+
+// execInPod runs a command in a container of a pod, it is replaced in the tests
+var execInPod = func(context *clusterd.Context, namespace, pod, container string, cmd ...string) (string, error) {
+	stdout, stderr, err := context.RemoteExecutor.ExecWithOptions(exec.ExecOptions{
+		Command:            cmd,
+		Namespace:          namespace,
+		PodName:            pod,
+		ContainerName:      container,
+		CaptureStdout:      true,
+		CaptureStderr:      true,
+		PreserveWhitespace: true,
+	})
+	if err != nil {
+		return "", errors.Wrapf(err, "%s", strings.TrimSpace(stderr))
+	}
+	return stdout, nil
+}
+
+// statsCollector collects the clients and the usage of the exports of the ganesha servers through
+// their dbus sidecar, and reports them in the status and the metrics of the ceph nfs
+type statsCollector struct {
+	context        *clusterd.Context
+	client         client.Client
+	namespacedName types.NamespacedName
+	interval       time.Duration
+	metrics        *nfsMetrics
+}
+
+// exportIO are the io statistics of an export for a NFS protocol version
+type exportIO struct {
+	readOps      int64
+	writeOps     int64
+	readBytes    int64
+	writtenBytes int64
+}
+
+func newStatsCollector(context *clusterd.Context, client client.Client, namespacedName types.NamespacedName) *statsCollector {
+	return &statsCollector{
+		context:        context,
+		client:         client,
+		namespacedName: namespacedName,
+		interval:       defaultStatsInterval,
+		metrics:        newNFSMetrics(),
+	}
+}
+
+// run periodically collects the statistics of the servers until the ceph nfs is deleted
+func (c *statsCollector) run(stopCh chan struct{}) {
+	logger.Infof("starting the collection of the statistics of ceph nfs %q", c.namespacedName.Name)
+	for {
+		select {
+		case <-stopCh:
+			c.metrics.clear()
+			logger.Infof("stopping the collection of the statistics of ceph nfs %q", c.namespacedName.Name)
+			return
+
+		case <-time.After(c.interval):
+			if err := c.collect(time.Now()); err != nil {
+				logger.Errorf("failed to collect the statistics of ceph nfs %q. %v", c.namespacedName.Name, err)
+			}
+		}
+	}
+}
+
+// collect gets the statistics of the running servers and reports them
+func (c *statsCollector) collect(now time.Time) error {
+	ctx := context.TODO()
+	n := &cephv1.CephNFS{}
+	if err := c.client.Get(ctx, c.namespacedName, n); err != nil {
+		if kerrors.IsNotFound(err) {
+			return nil
+		}
+		return errors.Wrapf(err, "failed to get ceph nfs %q", c.namespacedName.Name)
+	}
+	var previous []cephv1.NFSServerStatus
+	if n.Status != nil {
+		previous = n.Status.Servers
+	}
+	if !n.Spec.Statistics.Enabled {
+		// the statistics were disabled since the collector started
+		c.metrics.clear()
+		if len(previous) > 0 {
+			updateStatusServers(c.client, c.namespacedName, nil)
+		}
+		return nil
+	}
+
+	selector := fmt.Sprintf("app=%s,ceph_nfs=%s", AppName, n.Name)
+	pods, err := c.context.Clientset.CoreV1().Pods(n.Namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return errors.Wrapf(err, "failed to list the pods of ceph nfs %q", n.Name)
+	}
+
+	servers := []cephv1.NFSServerStatus{}
+	for _, pod := range pods.Items {
+		if pod.Status.Phase != v1.PodRunning {
+			continue
+		}
+		server, err := c.serverStats(n, pod.Name)
+		if err != nil {
+			logger.Debugf("failed to get the statistics of ganesha server %q of ceph nfs %q. %v", pod.Labels["instance"], n.Name, err)
+			server.Message = err.Error()
+		}
+		server.Name = pod.Labels["instance"]
+		server.LastChecked = now.UTC().Format(time.RFC3339)
+		servers = append(servers, server)
+	}
+	sort.Slice(servers, func(i, j int) bool { return servers[i].Name < servers[j].Name })
+
+	c.metrics.set(n.Namespace, n.Name, servers)
+	if serversChanged(previous, servers) {
+		updateStatusServers(c.client, c.namespacedName, servers)
+	}
+	return nil
+}
+
+// serversChanged returns whether the statistics of the servers changed other than the time they were collected
+func serversChanged(previous, servers []cephv1.NFSServerStatus) bool {
+	if len(previous) != len(servers) {
+		return true
+	}
+	for i := range servers {
+		unchanged := previous[i]
+		unchanged.LastChecked = servers[i].LastChecked
+		if !reflect.DeepEqual(unchanged, servers[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// serverStats gets the clients and the usage of the exports of a server
+func (c *statsCollector) serverStats(n *cephv1.CephNFS, pod string) (cephv1.NFSServerStatus, error) {
+	server := cephv1.NFSServerStatus{}
+
+	reply, err := c.dbusCall(n, pod, clientMgrPath, "org.ganesha.nfsd.clientmgr.ShowClients")
+	if err != nil {
+		return server, errors.Wrap(err, "failed to list the clients")
+	}
+	// the reply is a timestamp and an array of clients, whose first field is the address
+	for _, clientInfo := range dbusArray(reply, 1) {
+		if address, ok := dbusField(clientInfo, 0).(string); ok {
+			server.Clients = append(server.Clients, address)
+		}
+	}
+
+	reply, err = c.dbusCall(n, pod, exportMgrPath, "org.ganesha.nfsd.exportmgr.ShowExports")
+	if err != nil {
+		return server, errors.Wrap(err, "failed to list the exports")
+	}
+	// the reply is a timestamp and an array of exports, whose first fields are the id and the path
+	for _, exportInfo := range dbusArray(reply, 1) {
+		id, ok := dbusField(exportInfo, 0).(int64)
+		if !ok || id == 0 {
+			// the export 0 is the root of the pseudo filesystem
+			continue
+		}
+		path, _ := dbusField(exportInfo, 1).(string)
+		usage := cephv1.NFSExportUsage{ExportID: int(id), Path: path}
+		for _, method := range exportIOMethods(n) {
+			io, err := c.exportIO(n, pod, method, id)
+			if err != nil {
+				// the stats of the protocol versions that ganesha does not know are not available
+				logger.Debugf("failed to get the %s statistics of export %d. %v", method, id, err)
+				continue
+			}
+			usage.ReadOps += io.readOps
+			usage.WriteOps += io.writeOps
+			usage.ReadBytes += io.readBytes
+			usage.WrittenBytes += io.writtenBytes
+		}
+		server.Exports = append(server.Exports, usage)
+	}
+
+	return server, nil
+}
+
+// exportIOMethods returns the dbus methods of the io statistics of the protocol versions served
+func exportIOMethods(n *cephv1.CephNFS) []string {
+	methods := []string{}
+	protocols := ganeshaProtocols(n)
+	if servesProtocol(protocols, 3) {
+		methods = append(methods, "GetNFSv3IO")
+	}
+	if servesProtocol(protocols, 4) {
+		methods = append(methods, "GetNFSv41IO", "GetNFSv42IO")
+	}
+	return methods
+}
+
+func (c *statsCollector) exportIO(n *cephv1.CephNFS, pod, method string, id int64) (exportIO, error) {
+	io := exportIO{}
+	reply, err := c.dbusCall(n, pod, exportMgrPath, "org.ganesha.nfsd.exportstats."+method, fmt.Sprintf("uint16:%d", id))
+	if err != nil {
+		return io, err
+	}
+	// the reply is the status, an error message, a timestamp and the read and write statistics
+	if status, _ := dbusField(reply, 0).(bool); !status {
+		message, _ := dbusField(reply, 1).(string)
+		return io, errors.Errorf("statistics not available. %s", message)
+	}
+	// the statistics are the requested and transferred bytes, the total and failed operations and the latency
+	read, _ := dbusField(reply, 3).([]interface{})
+	write, _ := dbusField(reply, 4).([]interface{})
+	io.readBytes, _ = dbusField(read, 1).(int64)
+	io.readOps, _ = dbusField(read, 2).(int64)
+	io.writtenBytes, _ = dbusField(write, 1).(int64)
+	io.writeOps, _ = dbusField(write, 2).(int64)
+	return io, nil
+}
+
+func (c *statsCollector) dbusCall(n *cephv1.CephNFS, pod, path, method string, args ...string) ([]interface{}, error) {
+	cmd := append([]string{"dbus-send", "--system", "--print-reply", "--dest=" + ganeshaDBusDest, path, method}, args...)
+	output, err := execInPod(c.context, n.Namespace, pod, dbusContainerName, cmd...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to call %s", method)
+	}
+	return parseDBusReply(output)
+}
+
+// parseDBusReply parses the values printed by dbus-send. The structs and arrays are returned as slices,
+// the integers as int64.
+func parseDBusReply(output string) ([]interface{}, error) {
+	stack := [][]interface{}{{}}
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "" || strings.HasPrefix(line, "method return"):
+			continue
+		case line == "struct {" || line == "array [" || line == "dict entry(":
+			stack = append(stack, []interface{}{})
+			continue
+		case line == "}" || line == "]" || line == ")":
+			if len(stack) < 2 {
+				return nil, errors.Errorf("unbalanced dbus reply %q", output)
+			}
+			value := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			stack[len(stack)-1] = append(stack[len(stack)-1], value)
+			continue
+		}
+
+		line = strings.TrimPrefix(line, "variant ")
+		fields := strings.SplitN(line, " ", 2)
+		if len(fields) != 2 {
+			return nil, errors.Errorf("failed to parse dbus value %q", line)
+		}
+		var value interface{}
+		switch fields[0] {
+		case "string", "object":
+			value = strings.Trim(fields[1], `"`)
+		case "boolean":
+			value = fields[1] == "true"
+		case "double":
+			d, err := strconv.ParseFloat(fields[1], 64)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to parse dbus value %q", line)
+			}
+			value = d
+		default:
+			i, err := strconv.ParseInt(fields[1], 10, 64)
+			if err != nil {
+				// the counters are unsigned 64 bits values, which are not expected to reach the max int64
+				if _, uerr := strconv.ParseUint(fields[1], 10, 64); uerr != nil {
+					return nil, errors.Wrapf(err, "failed to parse dbus value %q", line)
+				}
+				i = math.MaxInt64
+			}
+			value = i
+		}
+		stack[len(stack)-1] = append(stack[len(stack)-1], value)
+	}
+	if len(stack) != 1 {
+		return nil, errors.Errorf("unbalanced dbus reply %q", output)
+	}
+	return stack[0], nil
+}
+
+// dbusField returns a field of a struct of a dbus reply, or nil if there is no such field
+func dbusField(value interface{}, index int) interface{} {
+	fields, ok := value.([]interface{})
+	if !ok || index >= len(fields) {
+		return nil
+	}
+	return fields[index]
+}
+
+// dbusArray returns the elements of an array field of a dbus reply
+func dbusArray(value interface{}, index int) []interface{} {
+	array, _ := dbusField(value, index).([]interface{})
+	return array
+}
+
+// updateStatusServers updates the statistics of the servers in the status of a ceph nfs
+func updateStatusServers(client client.Client, name types.NamespacedName, servers []cephv1.NFSServerStatus) {
+	nfs := &cephv1.CephNFS{}
+	err := client.Get(context.TODO(), name, nfs)
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			logger.Debug("CephNFS resource not found. Ignoring since object must be deleted.")
+			return
+		}
+		logger.Warningf("failed to retrieve nfs %q to update the server statistics. %v", name, err)
+		return
+	}
+	if nfs.Status == nil {
+		nfs.Status = &cephv1.NFSStatus{}
+	}
+
+	nfs.Status.Servers = servers
+	if err := reporting.UpdateStatus(client, nfs); err != nil {
+		logger.Errorf("failed to set nfs %q server statistics. %v", nfs.Name, err)
+	}
+}
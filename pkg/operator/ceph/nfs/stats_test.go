@@ -0,0 +1,272 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nfs
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/client/clientset/versioned/scheme"
+	"github.com/rook/rook/pkg/clusterd"
+	"github.com/rook/rook/pkg/operator/test"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const (
+	showClientsReply = `method return time=1629896023.451521 sender=:1.1 -> destination=:1.7 serial=42 reply_serial=2
+   struct {
+      uint64 1629896023
+      uint64 451490219
+   }
+   array [
+      struct {
+         string "10.1.0.21"
+         boolean false
+         boolean false
+         boolean false
+         boolean false
+         boolean false
+         boolean true
+         boolean false
+         boolean false
+         struct {
+            uint64 1629895990
+            uint64 117403545
+         }
+      }
+      struct {
+         string "10.1.0.22"
+         boolean false
+         boolean false
+         boolean false
+         boolean false
+         boolean false
+         boolean true
+         boolean false
+         boolean false
+         struct {
+            uint64 1629896001
+            uint64 903514022
+         }
+      }
+   ]
+`
+	showExportsReply = `method return time=1629896023.512318 sender=:1.1 -> destination=:1.8 serial=43 reply_serial=2
+   struct {
+      uint64 1629896023
+      uint64 512290861
+   }
+   array [
+      struct {
+         uint16 0
+         string "/"
+         boolean false
+         boolean false
+         boolean false
+         boolean false
+         boolean false
+         boolean true
+         boolean false
+         boolean false
+         struct {
+            uint64 1629895990
+            uint64 117403545
+         }
+      }
+      struct {
+         uint16 1
+         string "/volumes/_nogroup/share"
+         boolean false
+         boolean false
+         boolean false
+         boolean false
+         boolean false
+         boolean true
+         boolean false
+         boolean false
+         struct {
+            uint64 1629895990
+            uint64 117403545
+         }
+      }
+   ]
+`
+	exportIOReply = `method return time=1629896023.601833 sender=:1.1 -> destination=:1.9 serial=44 reply_serial=2
+   boolean true
+   string "OK"
+   struct {
+      uint64 1629896023
+      uint64 601812736
+   }
+   struct {
+      uint64 4194304
+      uint64 2097152
+      uint64 32
+      uint64 0
+      uint64 18446744073709551615
+   }
+   struct {
+      uint64 1048576
+      uint64 1048576
+      uint64 8
+      uint64 1
+      uint64 523411
+   }
+`
+)
+
+func TestParseDBusReply(t *testing.T) {
+	reply, err := parseDBusReply(exportIOReply)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, len(reply))
+	assert.Equal(t, true, reply[0])
+	assert.Equal(t, "OK", reply[1])
+	assert.Equal(t, []interface{}{int64(4194304), int64(2097152), int64(32), int64(0), int64(9223372036854775807)}, reply[3])
+
+	reply, err = parseDBusReply(showClientsReply)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(dbusArray(reply, 1)))
+	assert.Equal(t, "10.1.0.22", dbusField(dbusArray(reply, 1)[1], 0))
+	assert.Nil(t, dbusField(reply, 5))
+
+	_, err = parseDBusReply("   struct {\n      uint64 1\n")
+	assert.Error(t, err)
+	_, err = parseDBusReply("   ]\n")
+	assert.Error(t, err)
+	_, err = parseDBusReply("   uint64 abc\n")
+	assert.Error(t, err)
+}
+
+func TestStatsCollector(t *testing.T) {
+	ctx := context.TODO()
+	n := &cephv1.CephNFS{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec: cephv1.NFSGaneshaSpec{
+			RADOS:      cephv1.GaneshaRADOSSpec{Pool: "foo", Namespace: namespace},
+			Server:     cephv1.GaneshaServerSpec{Active: 2},
+			Statistics: cephv1.GaneshaStatisticsSpec{Enabled: true},
+		},
+		TypeMeta: controllerTypeMeta,
+	}
+	s := scheme.Scheme
+	s.AddKnownTypes(cephv1.SchemeGroupVersion, &cephv1.CephNFS{})
+	cl := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(n).Build()
+	clientset := test.New(t, 1)
+	c := &clusterd.Context{Clientset: clientset}
+
+	for _, id := range []string{"a", "b"} {
+		pod := &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: instanceName(n, id) + "-5d8f", Namespace: namespace, Labels: getLabels(n, id, true)},
+			Status:     v1.PodStatus{Phase: v1.PodRunning},
+		}
+		_, err := clientset.CoreV1().Pods(namespace).Create(ctx, pod, metav1.CreateOptions{})
+		assert.NoError(t, err)
+	}
+
+	calls := []string{}
+	execInPod = func(context *clusterd.Context, namespace, pod, container string, cmd ...string) (string, error) {
+		assert.Equal(t, dbusContainerName, container)
+		assert.Equal(t, []string{"dbus-send", "--system", "--print-reply", "--dest=org.ganesha.nfsd"}, cmd[0:4])
+		if strings.HasPrefix(pod, "rook-ceph-nfs-my-nfs-b") {
+			return "", errors.New("Error org.freedesktop.DBus.Error.ServiceUnknown")
+		}
+		calls = append(calls, strings.Join(cmd[4:], " "))
+		switch cmd[5] {
+		case "org.ganesha.nfsd.clientmgr.ShowClients":
+			return showClientsReply, nil
+		case "org.ganesha.nfsd.exportmgr.ShowExports":
+			return showExportsReply, nil
+		case "org.ganesha.nfsd.exportstats.GetNFSv41IO":
+			return exportIOReply, nil
+		}
+		return "", errors.New("Error org.freedesktop.DBus.Error.UnknownMethod")
+	}
+
+	collector := newStatsCollector(c, cl, types.NamespacedName{Name: name, Namespace: namespace})
+	now := time.Date(2021, 8, 25, 12, 0, 0, 0, time.UTC)
+	assert.NoError(t, collector.collect(now))
+	assert.Equal(t, []string{
+		"/org/ganesha/nfsd/ClientMgr org.ganesha.nfsd.clientmgr.ShowClients",
+		"/org/ganesha/nfsd/ExportMgr org.ganesha.nfsd.exportmgr.ShowExports",
+		"/org/ganesha/nfsd/ExportMgr org.ganesha.nfsd.exportstats.GetNFSv41IO uint16:1",
+		"/org/ganesha/nfsd/ExportMgr org.ganesha.nfsd.exportstats.GetNFSv42IO uint16:1",
+	}, calls)
+
+	nfs := &cephv1.CephNFS{}
+	assert.NoError(t, cl.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, nfs))
+	assert.Equal(t, 2, len(nfs.Status.Servers))
+	a := nfs.Status.Servers[0]
+	assert.Equal(t, "a", a.Name)
+	assert.Equal(t, []string{"10.1.0.21", "10.1.0.22"}, a.Clients)
+	assert.Equal(t, []cephv1.NFSExportUsage{{ExportID: 1, Path: "/volumes/_nogroup/share", ReadOps: 32, WriteOps: 8, ReadBytes: 2097152, WrittenBytes: 1048576}}, a.Exports)
+	assert.Equal(t, "2021-08-25T12:00:00Z", a.LastChecked)
+	assert.Equal(t, "", a.Message)
+	b := nfs.Status.Servers[1]
+	assert.Equal(t, "b", b.Name)
+	assert.Contains(t, b.Message, "failed to list the clients")
+
+	labels := []string{namespace, name, "a", "1", "/volumes/_nogroup/share"}
+	assert.Equal(t, float64(32), testutil.ToFloat64(exportReadOps.WithLabelValues(labels...)))
+	assert.Equal(t, float64(1048576), testutil.ToFloat64(exportWrittenBytes.WithLabelValues(labels...)))
+	assert.Equal(t, 2, testutil.CollectAndCount(serverClient))
+
+	// the status is not written again if only the time of the collection changed
+	resourceVersion := nfs.ResourceVersion
+	assert.NoError(t, collector.collect(now.Add(time.Minute)))
+	nfs = &cephv1.CephNFS{}
+	assert.NoError(t, cl.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, nfs))
+	assert.Equal(t, resourceVersion, nfs.ResourceVersion)
+	assert.Equal(t, "2021-08-25T12:00:00Z", nfs.Status.Servers[0].LastChecked)
+
+	// the metrics of the clients that are gone are removed
+	execInPod = func(context *clusterd.Context, namespace, pod, container string, cmd ...string) (string, error) {
+		return "", errors.New("Error org.freedesktop.DBus.Error.ServiceUnknown")
+	}
+	assert.NoError(t, collector.collect(now))
+	assert.Equal(t, 0, testutil.CollectAndCount(serverClient))
+	assert.Equal(t, 0, testutil.CollectAndCount(exportReadOps))
+
+	// the statistics are removed from the status once disabled
+	nfs = &cephv1.CephNFS{}
+	assert.NoError(t, cl.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, nfs))
+	nfs.Spec.Statistics.Enabled = false
+	assert.NoError(t, cl.Update(ctx, nfs))
+	assert.NoError(t, collector.collect(now))
+	nfs = &cephv1.CephNFS{}
+	assert.NoError(t, cl.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, nfs))
+	assert.Equal(t, 0, len(nfs.Status.Servers))
+}
+
+func TestServersChanged(t *testing.T) {
+	servers := []cephv1.NFSServerStatus{{Name: "a", Clients: []string{"10.1.0.21"}, LastChecked: "2021-08-25T12:01:00Z"}}
+	assert.True(t, serversChanged(nil, servers))
+
+	previous := []cephv1.NFSServerStatus{{Name: "a", Clients: []string{"10.1.0.21"}, LastChecked: "2021-08-25T12:00:00Z"}}
+	assert.False(t, serversChanged(previous, servers))
+
+	previous[0].Clients = nil
+	assert.True(t, serversChanged(previous, servers))
+}
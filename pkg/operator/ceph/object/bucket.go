@@ -49,6 +49,7 @@ type ObjectBucket struct {
 
 type rgwBucketStats struct {
 	Bucket string `json:"bucket"`
+	Owner  string `json:"owner"`
 	Usage  map[string]struct {
 		Size            uint64 `json:"size"`
 		NumberOfObjects uint64 `json:"num_objects"`
@@ -103,6 +104,23 @@ func GetBucketStats(c *Context, bucketName string) (*ObjectBucketStats, bool, er
 	return &stat, false, nil
 }
 
+// GetBucketOwner returns the user owning a bucket. It returns true if the bucket does not exist.
+func GetBucketOwner(c *Context, bucketName string) (string, bool, error) {
+	result, err := runAdminCommand(c, true, "bucket", "stats", "--bucket", bucketName)
+	if err != nil {
+		if strings.Contains(err.Error(), "exit status 2") {
+			return "", true, errors.New("not found")
+		}
+		return "", false, errors.Wrap(err, "failed to get bucket stats")
+	}
+
+	var rgwStats rgwBucketStats
+	if err := json.Unmarshal([]byte(result), &rgwStats); err != nil {
+		return "", false, errors.Wrapf(err, "failed to read buckets stats result=%s", result)
+	}
+	return rgwStats.Owner, false, nil
+}
+
 func GetBucketsStats(c *Context) (map[string]ObjectBucketStats, error) {
 	result, err := runAdminCommand(c,
 		true,
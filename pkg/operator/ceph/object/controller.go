@@ -412,6 +412,9 @@ func (r *ReconcileCephObjectStore) reconcileCreateObjectStore(cephObjectStore *c
 		// Reconcile Pool Creation
 		if !cephObjectStore.Spec.IsMultisite() {
 			logger.Info("reconciling object store pools")
+			if err := r.reconcileDataPoolMigration(&cfg, objContext, cephObjectStore, namespacedName); err != nil {
+				return r.setFailedStatus(namespacedName, "failed to migrate the data pool", err)
+			}
 			err = CreatePools(objContext, r.clusterSpec, cephObjectStore.Spec.MetadataPool, cephObjectStore.Spec.DataPool)
 			if err != nil {
 				return r.setFailedStatus(namespacedName, "failed to create object pools", err)
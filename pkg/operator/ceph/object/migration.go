@@ -0,0 +1,211 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package object
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/ceph/pool"
+	"github.com/rook/rook/pkg/operator/ceph/reporting"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	rgwStopPollInterval = 5 * time.Second
+	rgwStopTimeout      = 5 * time.Minute
+)
+
+// copyStatusInterval is the number of objects copied between the updates of the progress of the copy
+const copyStatusInterval = 1000
+
+// reconcileDataPoolMigration copies the data pool of the object store to a new pool when the erasure code
+// chunks of the data pool changed, then replaces the data pool with the new pool. The rgw daemons are
+// stopped during the copy and the swap, and are started again by the reconcile of the store. When the copy
+// fails, the new pool is deleted and the rgw daemons are started again on the unchanged data pool. When the
+// swap fails, the rgw daemons stay stopped since the data pool may not exist until the swap completes.
+func (r *ReconcileCephObjectStore) reconcileDataPoolMigration(cfg *clusterConfig, objContext *Context, store *cephv1.CephObjectStore, namespacedName types.NamespacedName) error {
+	dataPool := poolName(store.Name, dataPoolName)
+	migrationNeeded, sourceProfile, err := pool.ErasureCodeMigrationNeeded(r.context, r.clusterInfo, dataPool, store.Spec.DataPool)
+	if err != nil {
+		return err
+	}
+	var status *cephv1.PoolMigrationStatus
+	if store.Status != nil {
+		status = store.Status.DataPoolMigration
+	}
+	if !migrationNeeded && !pool.IsMigrationInProgress(status) {
+		return nil
+	}
+
+	if !pool.IsMigrationInProgress(status) {
+		if !store.Spec.DataPool.ErasureCoded.AllowMigration {
+			return errors.Errorf("the erasure code chunks of data pool %q changed from %s to %s, set allowMigration to migrate the pool", dataPool, sourceProfile, cephclient.ErasureCodedSpecString(store.Spec.DataPool))
+		}
+		status = pool.NewMigrationStatus(dataPool, sourceProfile, store.Spec.DataPool)
+		logger.Infof("migrating data pool %q of object store %q from erasure code profile %s to %s", dataPool, store.Name, status.SourceProfile, status.TargetProfile)
+		updateMigrationStatus(r.client, namespacedName, status)
+	}
+
+	if status.Phase == cephv1.PoolMigrationMigrating {
+		if err := createMigrationPool(objContext, r.clusterSpec, store.Spec.DataPool); err != nil {
+			return err
+		}
+
+		if err := r.copyDataPool(cfg, dataPool, namespacedName, status); err != nil {
+			status.Message = err.Error()
+			updateMigrationStatus(r.client, namespacedName, status)
+			r.restoreDataPool(cfg, objContext, status.TargetPool)
+			return err
+		}
+
+		status.Phase = cephv1.PoolMigrationSwapping
+		status.Message = ""
+		updateMigrationStatus(r.client, namespacedName, status)
+	}
+
+	// the objects written during the swap would be lost
+	if err := cfg.stopGateways(); err != nil {
+		return err
+	}
+	if err := pool.SwapMigratedPool(r.context, r.clusterInfo, dataPool, status.TargetPool, store.Spec.DataPool); err != nil {
+		status.Message = err.Error()
+		updateMigrationStatus(r.client, namespacedName, status)
+		return err
+	}
+	pool.CompleteMigration(status)
+	updateMigrationStatus(r.client, namespacedName, status)
+	logger.Infof("completed the migration of data pool %q to erasure code profile %s", dataPool, status.TargetProfile)
+
+	return nil
+}
+
+// copyDataPool stops the rgw daemons and copies the objects of the data pool to the target pool. The objects
+// are copied one by one by the OSDs, without a cache tier, with their namespace and locator.
+func (r *ReconcileCephObjectStore) copyDataPool(cfg *clusterConfig, dataPool string, namespacedName types.NamespacedName, status *cephv1.PoolMigrationStatus) error {
+	// the objects written during the copy would be lost
+	if err := cfg.stopGateways(); err != nil {
+		return err
+	}
+
+	objects, err := cephclient.ListAllRadosObjects(r.context, r.clusterInfo, dataPool)
+	if err != nil {
+		return err
+	}
+	logger.Infof("copying %d objects of data pool %q to pool %q", len(objects), dataPool, status.TargetPool)
+	status.Total = len(objects)
+	status.Migrated = 0
+	updateMigrationStatus(r.client, namespacedName, status)
+	for _, object := range objects {
+		if err := cephclient.CopyRadosObject(r.context, r.clusterInfo, dataPool, object, status.TargetPool); err != nil {
+			return err
+		}
+		status.Migrated++
+		if status.Migrated%copyStatusInterval == 0 {
+			updateMigrationStatus(r.client, namespacedName, status)
+		}
+	}
+
+	logger.Infof("copied the objects of data pool %q to pool %q", dataPool, status.TargetPool)
+	return nil
+}
+
+// restoreDataPool deletes the pool of a failed copy and starts the rgw daemons again on the data pool. The
+// copy is retried from a new pool by the next reconcile so the objects deleted in the meantime are not copied.
+func (r *ReconcileCephObjectStore) restoreDataPool(cfg *clusterConfig, objContext *Context, targetPool string) {
+	if err := cephclient.DeletePool(r.context, r.clusterInfo, targetPool); err != nil {
+		logger.Errorf("failed to delete pool %q of the failed copy. %v", targetPool, err)
+	}
+	if err := cfg.startRGWPods(objContext.Realm, objContext.ZoneGroup, objContext.Zone); err != nil {
+		logger.Errorf("failed to start the rgw daemons of object store %q after the failed copy of the data pool. %v", cfg.store.Name, err)
+	}
+}
+
+func createMigrationPool(objContext *Context, clusterSpec *cephv1.ClusterSpec, poolSpec cephv1.PoolSpec) error {
+	targetPool := pool.GetMigrationPoolName(dataPoolName)
+	ecProfileName := cephclient.GetErasureCodeProfileForPool(poolName(objContext.Name, targetPool))
+	if _, err := cephclient.GetPoolDetails(objContext.Context, objContext.clusterInfo, poolName(objContext.Name, targetPool)); err != nil {
+		if err := cephclient.CreateErasureCodeProfile(objContext.Context, objContext.clusterInfo, ecProfileName, poolSpec); err != nil {
+			return errors.Wrap(err, "failed to create erasure code profile of the migrated data pool")
+		}
+	}
+	if err := createRGWPool(objContext, clusterSpec, poolSpec, cephclient.DefaultPGCount, ecProfileName, targetPool); err != nil {
+		return errors.Wrap(err, "failed to create the migrated data pool")
+	}
+	return nil
+}
+
+// stopGateways deletes the rgw deployments of the store and waits for their pods to stop
+func (c *clusterConfig) stopGateways() error {
+	deps, err := k8sutil.GetDeployments(c.context.Clientset, c.store.Namespace, c.storeLabelSelector())
+	if err != nil {
+		return errors.Wrapf(err, "failed to get rgw deployments of object store %q", c.store.Name)
+	}
+	for _, d := range deps.Items {
+		if err := k8sutil.DeleteDeployment(c.context.Clientset, c.store.Namespace, d.Name); err != nil {
+			return errors.Wrapf(err, "failed to delete rgw deployment %q", d.Name)
+		}
+	}
+
+	logger.Infof("waiting for the rgw pods of object store %q to stop", c.store.Name)
+	err = wait.PollImmediate(rgwStopPollInterval, rgwStopTimeout, func() (bool, error) {
+		pods, err := c.context.Clientset.CoreV1().Pods(c.store.Namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: c.storeLabelSelector()})
+		if err != nil {
+			return false, err
+		}
+		return len(pods.Items) == 0, nil
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to wait for the rgw pods of object store %q to stop", c.store.Name)
+	}
+	return nil
+}
+
+// updateMigrationStatus updates the data pool migration status of an object store
+func updateMigrationStatus(client client.Client, namespacedName types.NamespacedName, migration *cephv1.PoolMigrationStatus) {
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		objectStore := &cephv1.CephObjectStore{}
+		if err := client.Get(context.TODO(), namespacedName, objectStore); err != nil {
+			if kerrors.IsNotFound(err) {
+				logger.Debug("CephObjectStore resource not found. Ignoring since object must be deleted.")
+				return nil
+			}
+			return errors.Wrapf(err, "failed to retrieve object store %q to update migration status", namespacedName.String())
+		}
+		if objectStore.Status == nil {
+			objectStore.Status = &cephv1.ObjectStoreStatus{}
+		}
+		objectStore.Status.DataPoolMigration = migration
+
+		if err := reporting.UpdateStatus(client, objectStore); err != nil {
+			return errors.Wrapf(err, "failed to set object store %q migration status", namespacedName.String())
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Error(err)
+	}
+}
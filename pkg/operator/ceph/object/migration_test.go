@@ -0,0 +1,223 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package object
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/client/clientset/versioned/scheme"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	clienttest "github.com/rook/rook/pkg/daemon/ceph/client/test"
+	"github.com/rook/rook/pkg/operator/ceph/config"
+	testop "github.com/rook/rook/pkg/operator/test"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// fakeDataPools simulates the pools, erasure code profiles and rados objects of an object store
+type fakeDataPools struct {
+	pools    map[string]string
+	profiles map[string]string
+	rules    map[string]bool
+	// objects are the names of the objects by pool
+	objects map[string][]string
+	copyErr error
+}
+
+func (f *fakeDataPools) execute(command string, args ...string) (string, error) {
+	logger.Infof("Command: %s %v", command, args)
+	enoent := exec.Command("sh", "-c", "exit 2").Run()
+	switch command {
+	case "rados":
+		if args[2] == "ls" {
+			objects := []cephclient.RadosObject{}
+			for _, name := range f.objects[args[1]] {
+				objects = append(objects, cephclient.RadosObject{Name: name})
+			}
+			output, _ := json.Marshal(objects)
+			return string(output), nil
+		}
+		if args[2] == "cp" {
+			if f.copyErr != nil {
+				return "", f.copyErr
+			}
+			f.objects[args[5]] = append(f.objects[args[5]], args[3])
+		}
+		return "", nil
+	case "rbd":
+		return `{"images":{"count":0,"snap_count":0}}`, nil
+	}
+
+	if args[0] == "auth" && args[1] == "get-or-create-key" {
+		return `{"key":"mysecurekey"}`, nil
+	}
+	if args[0] == "osd" && args[1] == "lspools" {
+		pools := []cephclient.CephStoragePoolSummary{}
+		for name := range f.pools {
+			pools = append(pools, cephclient.CephStoragePoolSummary{Name: name})
+		}
+		output, _ := json.Marshal(pools)
+		return string(output), nil
+	}
+	if args[0] == "osd" && args[1] == "pool" {
+		switch args[2] {
+		case "ls":
+			return "[]", nil
+		case "get":
+			profile, ok := f.pools[args[3]]
+			if !ok {
+				return "", enoent
+			}
+			return fmt.Sprintf(`{"pool":%q,"erasure_code_profile":%q}`, args[3], profile), nil
+		case "create":
+			f.pools[args[3]] = args[6]
+			f.rules[args[3]] = true
+		case "delete":
+			delete(f.pools, args[3])
+			delete(f.objects, args[3])
+		case "rename":
+			f.pools[args[4]] = f.pools[args[3]]
+			f.objects[args[4]] = f.objects[args[3]]
+			delete(f.pools, args[3])
+			delete(f.objects, args[3])
+		}
+		return "", nil
+	}
+	if args[0] == "osd" && args[1] == "erasure-code-profile" {
+		switch args[2] {
+		case "get":
+			return f.profiles[args[3]], nil
+		case "set":
+			f.profiles[args[3]] = fmt.Sprintf(`{"k":%q,"m":%q}`, args[5][2:], args[6][2:])
+		case "rm":
+			delete(f.profiles, args[3])
+		}
+		return "", nil
+	}
+	if args[0] == "osd" && args[1] == "crush" {
+		switch args[2] {
+		case "dump":
+			rules := []map[string]string{}
+			for name := range f.rules {
+				rules = append(rules, map[string]string{"rule_name": name})
+			}
+			output, _ := json.Marshal(map[string]interface{}{"rules": rules})
+			return string(output), nil
+		case "rule":
+			switch args[3] {
+			case "rm":
+				delete(f.rules, args[4])
+			case "rename":
+				delete(f.rules, args[4])
+				f.rules[args[5]] = true
+			}
+		}
+	}
+	return "", nil
+}
+
+func TestReconcileDataPoolMigration(t *testing.T) {
+	ctx := context.TODO()
+	dataPool := "my-store.rgw.buckets.data"
+	targetPool := "my-store.rgw.buckets.data-migration"
+	f := &fakeDataPools{
+		pools:    map[string]string{dataPool: "my-store_ecprofile"},
+		profiles: map[string]string{"my-store_ecprofile": `{"k":"2","m":"1"}`, "default": `{"plugin":"jerasure"}`},
+		rules:    map[string]bool{dataPool: true},
+		objects:  map[string][]string{dataPool: {"obj1", "obj2"}},
+	}
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutput: f.execute,
+		MockExecuteCommandWithTimeout: func(timeout time.Duration, command string, args ...string) (string, error) {
+			return f.execute(command, args...)
+		},
+	}
+	clientset := testop.New(t, 1)
+	c := &clusterd.Context{Clientset: clientset, Executor: executor}
+	clusterInfo := clienttest.CreateTestClusterInfo(1)
+
+	store := &cephv1.CephObjectStore{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-store", Namespace: clusterInfo.Namespace},
+		Spec: cephv1.ObjectStoreSpec{
+			DataPool: cephv1.PoolSpec{ErasureCoded: cephv1.ErasureCodedSpec{DataChunks: 4, CodingChunks: 2}},
+			Gateway:  cephv1.GatewaySpec{Port: 80, Instances: 1},
+		},
+	}
+	namespacedName := types.NamespacedName{Name: store.Name, Namespace: store.Namespace}
+	cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithRuntimeObjects(store).Build()
+	r := &ReconcileCephObjectStore{client: cl, scheme: scheme.Scheme, context: c, clusterInfo: clusterInfo, clusterSpec: &cephv1.ClusterSpec{}}
+	objContext := NewContext(c, clusterInfo, store.Name)
+	data := config.NewStatelessDaemonDataPathMap(config.RgwType, store.Name, store.Namespace, "/var/lib/rook/")
+	cfg := &clusterConfig{c, clusterInfo, store, "v1.1.0", r.clusterSpec, cephclient.NewMinimumOwnerInfoWithOwnerRef(), data, cl}
+	getStatus := func() *cephv1.PoolMigrationStatus {
+		s := &cephv1.CephObjectStore{}
+		assert.NoError(t, cl.Get(ctx, namespacedName, s))
+		store.Status = s.Status
+		return s.Status.DataPoolMigration
+	}
+
+	// the chunks cannot change without allowMigration
+	err := r.reconcileDataPoolMigration(cfg, objContext, store, namespacedName)
+	assert.Error(t, err)
+	assert.Equal(t, "my-store_ecprofile", f.pools[dataPool])
+
+	// a failed copy deletes the new pool and starts the rgw daemons again on the data pool
+	store.Spec.DataPool.ErasureCoded.AllowMigration = true
+	f.copyErr = errors.New("failed to copy")
+	err = r.reconcileDataPoolMigration(cfg, objContext, store, namespacedName)
+	assert.Error(t, err)
+	status := getStatus()
+	assert.Equal(t, cephv1.PoolMigrationMigrating, status.Phase)
+	assert.Contains(t, status.Message, "failed to copy")
+	assert.Equal(t, 2, status.Total)
+	assert.NotContains(t, f.pools, targetPool)
+	assert.Equal(t, "my-store_ecprofile", f.pools[dataPool])
+	deps, err := clientset.AppsV1().Deployments(store.Namespace).List(ctx, metav1.ListOptions{LabelSelector: cfg.storeLabelSelector()})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(deps.Items))
+
+	// the objects are copied to the new pool which replaces the data pool
+	f.copyErr = nil
+	err = r.reconcileDataPoolMigration(cfg, objContext, store, namespacedName)
+	assert.NoError(t, err)
+	status = getStatus()
+	assert.Equal(t, cephv1.PoolMigrationCompleted, status.Phase)
+	assert.Equal(t, 2, status.Migrated)
+	assert.Equal(t, map[string]string{dataPool: "my-store.rgw.buckets.data-migration_ecprofile"}, f.pools)
+	assert.Equal(t, []string{"obj1", "obj2"}, f.objects[dataPool])
+	assert.Equal(t, map[string]bool{dataPool: true}, f.rules)
+	assert.NotContains(t, f.profiles, "my-store_ecprofile")
+	// the rgw daemons are started again by the reconcile of the store
+	deps, err = clientset.AppsV1().Deployments(store.Namespace).List(ctx, metav1.ListOptions{LabelSelector: cfg.storeLabelSelector()})
+	assert.NoError(t, err)
+	assert.Empty(t, deps.Items)
+
+	// the migration is completed
+	err = r.reconcileDataPoolMigration(cfg, objContext, store, namespacedName)
+	assert.NoError(t, err)
+}
@@ -32,6 +32,7 @@ import (
 	"github.com/rook/rook/pkg/operator/ceph/cluster/mgr"
 	"github.com/rook/rook/pkg/operator/ceph/config"
 	opcontroller "github.com/rook/rook/pkg/operator/ceph/controller"
+	"github.com/rook/rook/pkg/operator/ceph/pool"
 	"github.com/rook/rook/pkg/operator/k8sutil"
 	"github.com/rook/rook/pkg/util/exec"
 	"golang.org/x/sync/errgroup"
@@ -653,13 +654,15 @@ func deletePools(ctx *Context, spec cephv1.ObjectStoreSpec, lastStore bool) erro
 		return errors.Wrapf(err, "failed to list erasure code profiles for cluster %s", ctx.clusterInfo.Namespace)
 	}
 	// cleans up the EC profile for the data pool only. Metadata pools don't support EC (only replication is supported).
-	ecProfileName := cephclient.GetErasureCodeProfileForPool(ctx.Name)
+	// The profile of a migrated data pool is named after the pool it was migrated to.
+	ecProfileNames := sets.NewString(
+		cephclient.GetErasureCodeProfileForPool(ctx.Name),
+		cephclient.GetErasureCodeProfileForPool(poolName(ctx.Name, pool.GetMigrationPoolName(dataPoolName))))
 	for i := range erasureCodes {
-		if erasureCodes[i] == ecProfileName {
-			if err := cephclient.DeleteErasureCodeProfile(ctx.Context, ctx.clusterInfo, ecProfileName); err != nil {
-				return errors.Wrapf(err, "failed to delete erasure code profile %s for object store %s", ecProfileName, ctx.Name)
+		if ecProfileNames.Has(erasureCodes[i]) {
+			if err := cephclient.DeleteErasureCodeProfile(ctx.Context, ctx.clusterInfo, erasureCodes[i]); err != nil {
+				return errors.Wrapf(err, "failed to delete erasure code profile %s for object store %s", erasureCodes[i], ctx.Name)
 			}
-			break
 		}
 	}
 
@@ -725,10 +728,22 @@ func CreatePools(context *Context, clusterSpec *cephv1.ClusterSpec, metadataPool
 
 	ecProfileName := ""
 	if dataPool.IsErasureCoded() {
-		ecProfileName = cephclient.GetErasureCodeProfileForPool(context.Name)
-		// create a new erasure code profile for the data pool
-		if err := cephclient.CreateErasureCodeProfile(context.Context, context.clusterInfo, ecProfileName, dataPool); err != nil {
-			return errors.Wrap(err, "failed to create erasure code profile")
+		// the profile of an existing data pool cannot change, the pool must be migrated to a pool with a new profile
+		name := poolName(context.Name, dataPoolName)
+		existingProfileName, profile, err := cephclient.GetPoolErasureCodeProfile(context.Context, context.clusterInfo, name)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get erasure code profile of pool %q", name)
+		}
+		if profile == nil {
+			ecProfileName = cephclient.GetErasureCodeProfileForPool(context.Name)
+			// create a new erasure code profile for the data pool
+			if err := cephclient.CreateErasureCodeProfile(context.Context, context.clusterInfo, ecProfileName, dataPool); err != nil {
+				return errors.Wrap(err, "failed to create erasure code profile")
+			}
+		} else if !profile.Matches(dataPool) {
+			return errors.Errorf("erasure code profile %q of pool %q is %s and cannot be changed to %s without migrating the pool", existingProfileName, name, profile.String(), cephclient.ErasureCodedSpecString(dataPool))
+		} else {
+			ecProfileName = existingProfileName
 		}
 	}
 
@@ -180,12 +180,8 @@ func (c *clusterConfig) makeRGWPodSpec(rgwConfig *rgwConfig) (v1.PodTemplateSpec
 	c.store.Spec.Gateway.Annotations.ApplyToObjectMeta(&podTemplateSpec.ObjectMeta)
 	c.store.Spec.Gateway.Labels.ApplyToObjectMeta(&podTemplateSpec.ObjectMeta)
 
-	if c.clusterSpec.Network.IsHost() {
-		podTemplateSpec.Spec.DNSPolicy = v1.DNSClusterFirstWithHostNet
-	} else if c.clusterSpec.Network.IsMultus() {
-		if err := k8sutil.ApplyMultus(c.clusterSpec.Network, &podTemplateSpec.ObjectMeta); err != nil {
-			return podTemplateSpec, err
-		}
+	if err := k8sutil.ApplyNetworkSettings(c.clusterSpec.Network, &podTemplateSpec.ObjectMeta, &podTemplateSpec.Spec); err != nil {
+		return podTemplateSpec, err
 	}
 
 	return podTemplateSpec, nil
@@ -0,0 +1,336 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pool
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/ceph/reporting"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	capacityHistoryName = "rook-ceph-capacity-history"
+	capacityHistoryKey  = "history"
+	// maxCapacitySamples bounds the samples kept for each pool and device class so the history fits
+	// in a configmap, the samples are spread evenly over the forecast window
+	maxCapacitySamples = 48
+	// targetSizeRatioHorizonDays is how far ahead the usage of a pool is projected to set its target_size_ratio
+	targetSizeRatioHorizonDays = 30
+	// minTargetSizeRatioChange avoids moving PGs around for small variations of the forecast
+	minTargetSizeRatioChange = 0.05
+	targetSizeRatioProperty  = "target_size_ratio"
+)
+
+var defaultCapacityForecastWindow = 7 * 24 * time.Hour
+
+// capacitySample is the used capacity at a point in time, the fields are short to keep the history small
+type capacitySample struct {
+	Time int64   `json:"t"`
+	Used float64 `json:"u"`
+}
+
+// capacityHistory is the usage history of the pools and device classes persisted between the status checks
+type capacityHistory struct {
+	Pools         map[string][]capacitySample `json:"pools,omitempty"`
+	DeviceClasses map[string][]capacitySample `json:"deviceClasses,omitempty"`
+}
+
+// capacityForecast is the growth rate and the days until full computed from the usage history
+type capacityForecast struct {
+	growthPerDay  float64
+	daysUntilFull *int64
+}
+
+// UpdateCapacityForecast samples the usage of the pools and device classes, computes their growth rate
+// and days until full over the forecast window and reports them in the CephBlockPool status and the
+// metrics. The target_size_ratio of the pools is adjusted if enabled in the spec. It returns the
+// forecast of the device classes for the CephCluster status, the previous one if it did not change.
+func UpdateCapacityForecast(clusterContext *clusterd.Context, clusterInfo *cephclient.ClusterInfo, spec *cephv1.CapacityForecastSpec, previous *cephv1.CapacityForecastStatus) (*cephv1.CapacityForecastStatus, error) {
+	stats, err := cephclient.GetPoolStats(clusterContext, clusterInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	window := defaultCapacityForecastWindow
+	if spec.Window != nil && spec.Window.Duration > 0 {
+		window = spec.Window.Duration
+	}
+	store := newCapacityHistoryStore(clusterContext, clusterInfo)
+	history, savedHistory := loadCapacityHistory(store)
+	now := time.Now().UTC()
+	lastUpdated := now.Format(time.RFC3339)
+
+	status := &cephv1.CapacityForecastStatus{}
+	deviceClasses := map[string][]capacitySample{}
+	var clusterTotalBytes float64
+	for name, classStats := range stats.StatsByClass {
+		sample := capacitySample{Time: now.Unix(), Used: classStats.TotalUsedRawBytes}
+		deviceClasses[name] = addCapacitySample(history.DeviceClasses[name], sample, window)
+		forecast := forecastRecordedCapacity(deviceClasses[name], classStats.TotalAvailBytes, window)
+		clusterTotalBytes += classStats.TotalBytes
+
+		status.DeviceClasses = append(status.DeviceClasses, cephv1.DeviceClassCapacityStatus{
+			Name:              name,
+			TotalBytes:        uint64(classStats.TotalBytes),
+			UsedBytes:         uint64(classStats.TotalUsedRawBytes),
+			AvailableBytes:    uint64(classStats.TotalAvailBytes),
+			GrowthBytesPerDay: int64(forecast.growthPerDay),
+			DaysUntilFull:     forecast.daysUntilFull,
+		})
+		setDeviceClassCapacityMetrics(clusterInfo.Namespace, name, forecast)
+	}
+	for name := range history.DeviceClasses {
+		if _, ok := deviceClasses[name]; !ok {
+			deleteDeviceClassCapacityMetrics(clusterInfo.Namespace, name)
+		}
+	}
+	sort.Slice(status.DeviceClasses, func(i, j int) bool { return status.DeviceClasses[i].Name < status.DeviceClasses[j].Name })
+	if previous != nil && reflect.DeepEqual(previous.DeviceClasses, status.DeviceClasses) {
+		status = previous
+	} else {
+		status.LastUpdated = lastUpdated
+	}
+
+	blockPools := &cephv1.CephBlockPoolList{}
+	if err := clusterContext.Client.List(context.TODO(), blockPools, client.InNamespace(clusterInfo.Namespace)); err != nil {
+		return nil, errors.Wrapf(err, "failed to list CephBlockPools in namespace %q", clusterInfo.Namespace)
+	}
+	blockPoolsByName := map[string]*cephv1.CephBlockPool{}
+	for i := range blockPools.Items {
+		blockPoolsByName[blockPools.Items[i].Name] = &blockPools.Items[i]
+	}
+
+	pools := map[string][]capacitySample{}
+	for _, p := range stats.Pools {
+		sample := capacitySample{Time: now.Unix(), Used: p.Stats.Stored}
+		pools[p.Name] = addCapacitySample(history.Pools[p.Name], sample, window)
+		forecast := forecastRecordedCapacity(pools[p.Name], p.Stats.MaxAvail, window)
+		setPoolCapacityMetrics(clusterInfo.Namespace, p.Name, forecast)
+
+		blockPool, ok := blockPoolsByName[p.Name]
+		if !ok || !blockPool.DeletionTimestamp.IsZero() {
+			continue
+		}
+		capacity := &cephv1.PoolCapacityStatus{
+			UsedBytes:         uint64(p.Stats.Stored),
+			AvailableBytes:    uint64(p.Stats.MaxAvail),
+			GrowthBytesPerDay: int64(forecast.growthPerDay),
+			DaysUntilFull:     forecast.daysUntilFull,
+		}
+		var previousCapacity *cephv1.PoolCapacityStatus
+		if blockPool.Status != nil && blockPool.Status.Capacity != nil {
+			previousCapacity = blockPool.Status.Capacity
+			capacity.TargetSizeRatio = previousCapacity.TargetSizeRatio
+		}
+		if spec.AutoTargetSizeRatio && !hasTargetSize(&blockPool.Spec) {
+			classTotalBytes := clusterTotalBytes
+			if classStats, ok := stats.StatsByClass[blockPool.Spec.DeviceClass]; ok {
+				classTotalBytes = classStats.TotalBytes
+			}
+			ratio := forecastTargetSizeRatio(p.Stats.Stored, p.Stats.BytesUsed, forecast.growthPerDay, classTotalBytes)
+			if ratio > 0 && math.Abs(ratio-capacity.TargetSizeRatio) >= minTargetSizeRatioChange {
+				value := strconv.FormatFloat(ratio, 'f', -1, 64)
+				if err := cephclient.SetPoolProperty(clusterContext, clusterInfo, p.Name, targetSizeRatioProperty, value); err != nil {
+					logger.Errorf("failed to set target_size_ratio of pool %q. %v", p.Name, err)
+				} else {
+					logger.Infof("set target_size_ratio of pool %q to %s from its forecast usage", p.Name, value)
+					capacity.TargetSizeRatio = ratio
+				}
+			}
+		}
+		if capacityChanged(previousCapacity, capacity) {
+			capacity.LastUpdated = lastUpdated
+			updatePoolCapacityStatus(clusterContext.Client, blockPool, capacity)
+		}
+	}
+	for name := range history.Pools {
+		if _, ok := pools[name]; !ok {
+			deletePoolCapacityMetrics(clusterInfo.Namespace, name)
+		}
+	}
+
+	history = capacityHistory{Pools: pools, DeviceClasses: deviceClasses}
+	if err := saveCapacityHistory(store, history, savedHistory); err != nil {
+		return status, err
+	}
+
+	return status, nil
+}
+
+// newCapacityHistoryStore returns the store of the usage history, a configmap owned by the cluster
+func newCapacityHistoryStore(clusterContext *clusterd.Context, clusterInfo *cephclient.ClusterInfo) *k8sutil.ConfigMapKVStore {
+	return k8sutil.NewConfigMapKVStore(clusterInfo.Namespace, clusterContext.Clientset, clusterInfo.OwnerInfo)
+}
+
+// loadCapacityHistory returns the usage history and its value as saved in the configmap
+func loadCapacityHistory(store *k8sutil.ConfigMapKVStore) (capacityHistory, string) {
+	history := capacityHistory{}
+	value, err := store.GetValue(capacityHistoryName, capacityHistoryKey)
+	if err != nil {
+		if !kerrors.IsNotFound(err) {
+			logger.Warningf("failed to load the capacity history, starting a new one. %v", err)
+		}
+		return history, ""
+	}
+	if err := json.Unmarshal([]byte(value), &history); err != nil {
+		logger.Warningf("failed to parse the capacity history, starting a new one. %v", err)
+		return capacityHistory{}, value
+	}
+	return history, value
+}
+
+// saveCapacityHistory saves the usage history in the configmap if it differs from the saved value
+func saveCapacityHistory(store *k8sutil.ConfigMapKVStore, history capacityHistory, savedValue string) error {
+	value, err := json.Marshal(history)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal the capacity history")
+	}
+	if string(value) == savedValue {
+		return nil
+	}
+	if err := store.SetValue(capacityHistoryName, capacityHistoryKey, string(value)); err != nil {
+		return errors.Wrap(err, "failed to save the capacity history")
+	}
+	return nil
+}
+
+// addCapacitySample drops the samples older than the window and appends the new sample if the last
+// one is older than the sample interval
+func addCapacitySample(samples []capacitySample, sample capacitySample, window time.Duration) []capacitySample {
+	oldest := sample.Time - int64(window.Seconds())
+	kept := []capacitySample{}
+	for _, s := range samples {
+		if s.Time >= oldest && s.Time <= sample.Time {
+			kept = append(kept, s)
+		}
+	}
+	if len(kept) == 0 || sample.Time-kept[len(kept)-1].Time >= sampleInterval(window) {
+		kept = append(kept, sample)
+	}
+	return kept
+}
+
+func sampleInterval(window time.Duration) int64 {
+	return int64(window.Seconds()) / maxCapacitySamples
+}
+
+// forecastRecordedCapacity computes the growth rate from the recorded samples only, so that the forecast
+// only changes when a sample is added to the history
+func forecastRecordedCapacity(samples []capacitySample, available float64, window time.Duration) capacityForecast {
+	if len(samples) == 0 {
+		return capacityForecast{}
+	}
+	return forecastCapacity(samples[:len(samples)-1], samples[len(samples)-1], available, window)
+}
+
+// forecastCapacity computes the growth rate of the usage with a least squares fit of the samples in the
+// window and the current sample. The growth rate is only reported once the samples span a sample
+// interval since a shorter span is too noisy to extrapolate.
+func forecastCapacity(samples []capacitySample, current capacitySample, available float64, window time.Duration) capacityForecast {
+	oldest := current.Time - int64(window.Seconds())
+	points := []capacitySample{}
+	for _, s := range samples {
+		if s.Time >= oldest && s.Time < current.Time {
+			points = append(points, s)
+		}
+	}
+	points = append(points, current)
+	if len(points) < 2 || current.Time-points[0].Time < sampleInterval(window) {
+		return capacityForecast{}
+	}
+
+	var sumX, sumY, sumXY, sumXX float64
+	n := float64(len(points))
+	for _, p := range points {
+		// the time is relative to the current sample to keep the sums small
+		x := float64(p.Time-current.Time) / (24 * 60 * 60)
+		sumX += x
+		sumY += p.Used
+		sumXY += x * p.Used
+		sumXX += x * x
+	}
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return capacityForecast{}
+	}
+
+	forecast := capacityForecast{growthPerDay: (n*sumXY - sumX*sumY) / denominator}
+	if forecast.growthPerDay > 0 {
+		days := int64(available / forecast.growthPerDay)
+		forecast.daysUntilFull = &days
+	}
+	return forecast
+}
+
+// hasTargetSize returns whether the expected size of the pool is set in its spec
+func hasTargetSize(spec *cephv1.PoolSpec) bool {
+	if spec.Replicated.TargetSizeRatio != 0 {
+		return true
+	}
+	_, hasRatio := spec.Parameters[targetSizeRatioProperty]
+	_, hasBytes := spec.Parameters["target_size_bytes"]
+	return hasRatio || hasBytes
+}
+
+// forecastTargetSizeRatio returns the ratio of the device class capacity the pool is expected to use
+// at the forecast horizon, rounded to two decimals
+func forecastTargetSizeRatio(stored, rawUsed, growthPerDay, totalBytes float64) float64 {
+	if stored <= 0 || totalBytes <= 0 {
+		return 0
+	}
+	projected := stored
+	if growthPerDay > 0 {
+		projected += growthPerDay * targetSizeRatioHorizonDays
+	}
+	// the raw usage accounts for the replication or erasure coding overhead of the pool
+	ratio := projected * (rawUsed / stored) / totalBytes
+	return math.Min(math.Round(ratio*100)/100, 1)
+}
+
+// capacityChanged returns whether the capacity of a pool differs from its status, regardless of when
+// the status was last updated
+func capacityChanged(previous, capacity *cephv1.PoolCapacityStatus) bool {
+	if previous == nil {
+		return true
+	}
+	unchanged := *previous
+	unchanged.LastUpdated = capacity.LastUpdated
+	return !reflect.DeepEqual(&unchanged, capacity)
+}
+
+func updatePoolCapacityStatus(c client.Client, blockPool *cephv1.CephBlockPool, capacity *cephv1.PoolCapacityStatus) {
+	if blockPool.Status == nil {
+		blockPool.Status = &cephv1.CephBlockPoolStatus{}
+	}
+	blockPool.Status.Capacity = capacity
+	if err := reporting.UpdateStatus(c, blockPool); err != nil {
+		logger.Warningf("failed to update the capacity status of pool %q. %v", blockPool.Name, err)
+	}
+}
@@ -0,0 +1,218 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pool
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	rookclient "github.com/rook/rook/pkg/client/clientset/versioned/fake"
+	"github.com/rook/rook/pkg/client/clientset/versioned/scheme"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	testop "github.com/rook/rook/pkg/operator/test"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const gib = float64(1 << 30)
+
+func TestAddCapacitySample(t *testing.T) {
+	window := 48 * time.Hour
+	now := time.Now().Unix()
+
+	samples := addCapacitySample(nil, capacitySample{Time: now, Used: 1}, window)
+	assert.Equal(t, []capacitySample{{Time: now, Used: 1}}, samples)
+
+	// a sample within the sample interval of the last one is not kept
+	samples = addCapacitySample(samples, capacitySample{Time: now + 60, Used: 2}, window)
+	assert.Equal(t, 1, len(samples))
+
+	// a sample after the sample interval is kept
+	samples = addCapacitySample(samples, capacitySample{Time: now + 3600, Used: 3}, window)
+	assert.Equal(t, 2, len(samples))
+
+	// the samples older than the window are dropped
+	samples = addCapacitySample(samples, capacitySample{Time: now + 50*3600, Used: 4}, window)
+	assert.Equal(t, []capacitySample{{Time: now + 50*3600, Used: 4}}, samples)
+}
+
+func TestForecastCapacity(t *testing.T) {
+	window := 7 * 24 * time.Hour
+	day := int64(24 * 60 * 60)
+	now := time.Now().Unix()
+	samples := []capacitySample{}
+	for i := int64(5); i > 0; i-- {
+		samples = append(samples, capacitySample{Time: now - i*day, Used: float64(100-i*10) * gib})
+	}
+	current := capacitySample{Time: now, Used: 100 * gib}
+
+	// the usage grows by 10GiB per day
+	forecast := forecastCapacity(samples, current, 50*gib, window)
+	assert.InDelta(t, 10*gib, forecast.growthPerDay, 1)
+	assert.Equal(t, int64(5), *forecast.daysUntilFull)
+
+	// a shrinking usage is never full
+	forecast = forecastCapacity(samples, capacitySample{Time: now, Used: 0}, 50*gib, window)
+	assert.True(t, forecast.growthPerDay < 0)
+	assert.Nil(t, forecast.daysUntilFull)
+
+	// no forecast without enough history
+	forecast = forecastCapacity(nil, current, 50*gib, window)
+	assert.Equal(t, capacityForecast{}, forecast)
+	forecast = forecastCapacity([]capacitySample{{Time: now - 60, Used: 0}}, current, 50*gib, window)
+	assert.Equal(t, capacityForecast{}, forecast)
+
+	// the samples out of the window are ignored
+	forecast = forecastCapacity(samples, current, 50*gib, 36*time.Hour)
+	assert.InDelta(t, 10*gib, forecast.growthPerDay, 1)
+}
+
+func TestForecastTargetSizeRatio(t *testing.T) {
+	// 100GiB replicated 3 times growing by 10GiB per day reaches 400GiB in 30 days
+	assert.Equal(t, 0.12, forecastTargetSizeRatio(100*gib, 300*gib, 10*gib, 10000*gib))
+	// a pool that is not growing keeps its current usage
+	assert.Equal(t, 0.03, forecastTargetSizeRatio(100*gib, 300*gib, -10*gib, 10000*gib))
+	// the ratio is at most the whole capacity
+	assert.Equal(t, float64(1), forecastTargetSizeRatio(100*gib, 300*gib, 10*gib, 100*gib))
+	assert.Equal(t, float64(0), forecastTargetSizeRatio(0, 0, 0, 100*gib))
+}
+
+func TestUpdateCapacityForecast(t *testing.T) {
+	ctx := context.TODO()
+	namespace := "rook-ceph"
+	stored := 100 * gib
+	propertiesSet := map[string]string{}
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutput: func(command string, args ...string) (string, error) {
+			if args[0] == "df" {
+				return fmt.Sprintf(`{"stats_by_class":{"hdd":{"total_bytes":%f,"total_avail_bytes":%f,"total_used_raw_bytes":%f}},
+					"pools":[{"name":"replicapool","id":1,"stats":{"stored":%f,"bytes_used":%f,"max_avail":%f}},
+					{"name":"myfs-data0","id":2,"stats":{"stored":0,"bytes_used":0,"max_avail":%f}}]}`,
+					10000*gib, 10000*gib-3*stored, 3*stored, stored, 3*stored, 1000*gib, 1000*gib), nil
+			}
+			if args[0] == "osd" && args[1] == "pool" && args[2] == "set" {
+				propertiesSet[args[3]+"/"+args[4]] = args[5]
+			}
+			return "", nil
+		},
+	}
+
+	blockPool := &cephv1.CephBlockPool{ObjectMeta: metav1.ObjectMeta{Name: "replicapool", Namespace: namespace}}
+	s := scheme.Scheme
+	s.AddKnownTypes(cephv1.SchemeGroupVersion, &cephv1.CephBlockPool{}, &cephv1.CephBlockPoolList{})
+	cl := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects([]runtime.Object{blockPool}...).Build()
+	c := &clusterd.Context{
+		Executor:      executor,
+		Clientset:     testop.New(t, 1),
+		RookClientset: rookclient.NewSimpleClientset(),
+		Client:        cl,
+	}
+	clusterInfo := cephclient.AdminClusterInfo(namespace)
+	clusterInfo.OwnerInfo = cephclient.NewMinimumOwnerInfo(t)
+	spec := &cephv1.CapacityForecastSpec{AutoTargetSizeRatio: true}
+
+	// seed a history of a pool growing by 10GiB per day
+	day := int64(24 * 60 * 60)
+	now := time.Now().Unix()
+	history := capacityHistory{Pools: map[string][]capacitySample{}, DeviceClasses: map[string][]capacitySample{}}
+	for i := int64(5); i > 0; i-- {
+		history.Pools["replicapool"] = append(history.Pools["replicapool"], capacitySample{Time: now - i*day, Used: stored - float64(i)*10*gib})
+		history.DeviceClasses["hdd"] = append(history.DeviceClasses["hdd"], capacitySample{Time: now - i*day, Used: 3 * (stored - float64(i)*10*gib)})
+	}
+	history.Pools["deletedpool"] = []capacitySample{{Time: now - day, Used: gib}}
+	value, err := json.Marshal(history)
+	assert.NoError(t, err)
+	store := newCapacityHistoryStore(c, clusterInfo)
+	assert.NoError(t, store.SetValue(capacityHistoryName, capacityHistoryKey, string(value)))
+
+	status, err := UpdateCapacityForecast(c, clusterInfo, spec, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(status.DeviceClasses))
+	assert.Equal(t, "hdd", status.DeviceClasses[0].Name)
+	assert.Equal(t, uint64(10000*gib), status.DeviceClasses[0].TotalBytes)
+	assert.InDelta(t, 30*gib, float64(status.DeviceClasses[0].GrowthBytesPerDay), 1)
+	assert.Equal(t, int64(323), *status.DeviceClasses[0].DaysUntilFull)
+
+	// the pool status has the forecast and the target_size_ratio
+	err = cl.Get(ctx, types.NamespacedName{Name: "replicapool", Namespace: namespace}, blockPool)
+	assert.NoError(t, err)
+	capacity := blockPool.Status.Capacity
+	assert.Equal(t, uint64(stored), capacity.UsedBytes)
+	assert.Equal(t, uint64(1000*gib), capacity.AvailableBytes)
+	assert.InDelta(t, 10*gib, float64(capacity.GrowthBytesPerDay), 1)
+	assert.Equal(t, int64(100), *capacity.DaysUntilFull)
+	assert.Equal(t, 0.12, capacity.TargetSizeRatio)
+	assert.Equal(t, map[string]string{"replicapool/target_size_ratio": "0.12"}, propertiesSet)
+
+	// the history only has the current pools
+	history, _ = loadCapacityHistory(store)
+	assert.Equal(t, 2, len(history.Pools))
+	assert.Equal(t, 6, len(history.Pools["replicapool"]))
+	assert.Equal(t, 1, len(history.Pools["myfs-data0"]))
+
+	// the target_size_ratio, the status and the history are not written again when the forecast does not change
+	configMapUpdates := 0
+	c.Clientset.(*k8sfake.Clientset).PrependReactor("update", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		configMapUpdates++
+		return false, nil, nil
+	})
+	propertiesSet = map[string]string{}
+	status.LastUpdated = "previous"
+	err = cl.Get(ctx, types.NamespacedName{Name: "replicapool", Namespace: namespace}, blockPool)
+	assert.NoError(t, err)
+	blockPool.Status.Capacity.LastUpdated = "previous"
+	assert.NoError(t, cl.Update(ctx, blockPool))
+	newStatus, err := UpdateCapacityForecast(c, clusterInfo, spec, status)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(propertiesSet))
+	assert.Equal(t, 0, configMapUpdates)
+	assert.Equal(t, "previous", newStatus.LastUpdated)
+	err = cl.Get(ctx, types.NamespacedName{Name: "replicapool", Namespace: namespace}, blockPool)
+	assert.NoError(t, err)
+	assert.Equal(t, "previous", blockPool.Status.Capacity.LastUpdated)
+
+	// the target_size_ratio in the spec is not overridden
+	err = cl.Get(ctx, types.NamespacedName{Name: "replicapool", Namespace: namespace}, blockPool)
+	assert.NoError(t, err)
+	blockPool.Status.Capacity.TargetSizeRatio = 0
+	blockPool.Spec.Replicated.TargetSizeRatio = 0.5
+	assert.NoError(t, cl.Update(ctx, blockPool))
+	_, err = UpdateCapacityForecast(c, clusterInfo, spec, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(propertiesSet))
+
+	// neither the target size in the parameters
+	err = cl.Get(ctx, types.NamespacedName{Name: "replicapool", Namespace: namespace}, blockPool)
+	assert.NoError(t, err)
+	blockPool.Spec.Replicated.TargetSizeRatio = 0
+	blockPool.Spec.Parameters = map[string]string{"target_size_bytes": "1000000000"}
+	assert.NoError(t, cl.Update(ctx, blockPool))
+	_, err = UpdateCapacityForecast(c, clusterInfo, spec, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(propertiesSet))
+}
@@ -33,11 +33,13 @@ import (
 	"github.com/rook/rook/pkg/operator/ceph/cluster/mon"
 	"github.com/rook/rook/pkg/operator/ceph/config"
 	opcontroller "github.com/rook/rook/pkg/operator/ceph/controller"
+	"github.com/rook/rook/pkg/operator/ceph/reporting"
 	"github.com/rook/rook/pkg/operator/k8sutil"
 	corev1 "k8s.io/api/core/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
@@ -70,6 +72,9 @@ type ReconcileCephBlockPool struct {
 	context           *clusterd.Context
 	clusterInfo       *cephclient.ClusterInfo
 	blockPoolChannels map[string]*blockPoolHealth
+	recorder          *k8sutil.EventReporter
+	// purgedTrashes are the deleted pools whose trash was purged
+	purgedTrashes map[types.UID]bool
 }
 
 type blockPoolHealth struct {
@@ -90,6 +95,8 @@ func newReconciler(mgr manager.Manager, context *clusterd.Context) reconcile.Rec
 		scheme:            mgr.GetScheme(),
 		context:           context,
 		blockPoolChannels: make(map[string]*blockPoolHealth),
+		recorder:          k8sutil.NewEventReporter(mgr.GetEventRecorderFor("rook-" + controllerName)),
+		purgedTrashes:     make(map[types.UID]bool),
 	}
 }
 
@@ -209,8 +216,23 @@ func (r *ReconcileCephBlockPool) reconcile(request reconcile.Request) (reconcile
 			r.cancelMirrorMonitoring(blockPoolChannelKey)
 		}
 
+		if err := r.purgeTrashOnDeletion(clusterInfo, cephBlockPool); err != nil {
+			return opcontroller.ImmediateRetryResult, err
+		}
+
+		// the images of the pool would be lost
+		deps, err := CephBlockPoolDependents(r.context, clusterInfo, r.client, cephBlockPool)
+		if err != nil {
+			return opcontroller.ImmediateRetryResult, err
+		}
+		if !deps.Empty() {
+			err := reporting.ReportDeletionBlockedDueToDependents(logger, r.client, cephBlockPool, deps)
+			return opcontroller.WaitForRequeueIfFinalizerBlocked, err
+		}
+		reporting.ReportDeletionNotBlockedDueToDependents(logger, r.client, r.recorder, cephBlockPool)
+
 		logger.Infof("deleting pool %q", cephBlockPool.Name)
-		err := deletePool(r.context, clusterInfo, cephBlockPool)
+		err = deletePool(r.context, clusterInfo, cephBlockPool)
 		if err != nil {
 			return opcontroller.ImmediateRetryResult, errors.Wrapf(err, "failed to delete pool %q. ", cephBlockPool.Name)
 		}
@@ -225,6 +247,7 @@ func (r *ReconcileCephBlockPool) reconcile(request reconcile.Request) (reconcile
 		if err != nil {
 			return opcontroller.ImmediateRetryResult, errors.Wrap(err, "failed to remove finalizer")
 		}
+		delete(r.purgedTrashes, cephBlockPool.UID)
 
 		// Return and do not requeue. Successful deletion.
 		return reconcile.Result{}, nil
@@ -327,11 +350,35 @@ func (r *ReconcileCephBlockPool) reconcile(request reconcile.Request) (reconcile
 }
 
 func (r *ReconcileCephBlockPool) reconcileCreatePool(clusterInfo *cephclient.ClusterInfo, cephCluster *cephv1.ClusterSpec, cephBlockPool *cephv1.CephBlockPool) (reconcile.Result, error) {
-	err := createPool(r.context, clusterInfo, cephCluster, cephBlockPool)
+	// the erasure code profile of a pool cannot change, the pool is migrated to a pool with the new profile
+	migrationNeeded, sourceProfile, err := ErasureCodeMigrationNeeded(r.context, clusterInfo, cephBlockPool.Name, cephBlockPool.Spec)
+	if err != nil {
+		return opcontroller.ImmediateRetryResult, err
+	}
+	if migrationNeeded || (cephBlockPool.Status != nil && IsMigrationInProgress(cephBlockPool.Status.Migration)) {
+		result, err := r.reconcileMigration(clusterInfo, cephCluster, cephBlockPool, sourceProfile)
+		if err != nil || !result.IsZero() {
+			return result, err
+		}
+	}
+
+	err = createPool(r.context, clusterInfo, cephCluster, cephBlockPool)
 	if err != nil {
 		return opcontroller.ImmediateRetryResult, errors.Wrapf(err, "failed to create pool %q.", cephBlockPool.GetName())
 	}
 
+	// record the qos limits that were set so only these limits are removed when they are removed from the spec
+	if !reflect.DeepEqual(cephBlockPool.Spec.QoS, appliedQoS(cephBlockPool)) {
+		var qos *cephv1.QoSSpec
+		if !cephBlockPool.Spec.QoS.IsEmpty() {
+			qos = cephBlockPool.Spec.QoS.DeepCopy()
+		}
+		poolName := types.NamespacedName{Name: cephBlockPool.Name, Namespace: cephBlockPool.Namespace}
+		if err := updateQoSStatus(r.client, poolName, qos); err != nil {
+			return opcontroller.ImmediateRetryResult, err
+		}
+	}
+
 	// Let's return here so that on the initial creation we don't check for update right away
 	return reconcile.Result{}, nil
 }
@@ -344,9 +391,25 @@ func createPool(context *clusterd.Context, clusterInfo *cephclient.ClusterInfo,
 		return errors.Wrapf(err, "failed to create pool %q", p.Name)
 	}
 
+	// the images of the pool inherit the qos limits of the pool, the images are never in an erasure coded pool
+	applied := appliedQoS(p)
+	if !(p.Spec.QoS.IsEmpty() && applied.IsEmpty()) && !p.Spec.IsErasureCoded() {
+		if err := cephclient.SetPoolQoS(context, clusterInfo, p.Name, p.Spec.QoS, applied); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
+// appliedQoS returns the qos limits that rook set on the pool
+func appliedQoS(p *cephv1.CephBlockPool) cephv1.QoSSpec {
+	if p.Status == nil || p.Status.QoS == nil {
+		return cephv1.QoSSpec{}
+	}
+	return *p.Status.QoS
+}
+
 // Delete the pool
 func deletePool(context *clusterd.Context, clusterInfo *cephclient.ClusterInfo, p *cephv1.CephBlockPool) error {
 	pools, err := cephclient.ListPoolSummaries(context, clusterInfo)
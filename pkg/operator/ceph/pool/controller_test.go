@@ -44,12 +44,17 @@ import (
 
 func TestCreatePool(t *testing.T) {
 	clusterInfo := &cephclient.ClusterInfo{Namespace: "myns"}
+	qosConfigured := false
 	executor := &exectest.MockExecutor{
 		MockExecuteCommandWithOutput: func(command string, args ...string) (string, error) {
 			logger.Infof("Command: %s %v", command, args)
 			if command == "ceph" && args[1] == "erasure-code-profile" {
 				return `{"k":"2","m":"1","plugin":"jerasure","technique":"reed_sol_van"}`, nil
 			}
+			if command == "rbd" && args[0] == "config" {
+				qosConfigured = true
+				return "[]", nil
+			}
 			return "", nil
 		},
 	}
@@ -62,6 +67,23 @@ func TestCreatePool(t *testing.T) {
 	clusterSpec := &cephv1.ClusterSpec{Storage: cephv1.StorageScopeSpec{Config: map[string]string{cephclient.CrushRootConfigKey: "cluster-crush-root"}}}
 	err := createPool(context, clusterInfo, clusterSpec, p)
 	assert.Nil(t, err)
+	// the qos limits are only applied when set
+	assert.False(t, qosConfigured)
+	iops := int64(100)
+	p.Spec.QoS.IOPSLimit = &iops
+	err = createPool(context, clusterInfo, clusterSpec, p)
+	assert.Nil(t, err)
+	assert.True(t, qosConfigured)
+	p.Spec.QoS.IOPSLimit = nil
+	qosConfigured = false
+
+	// the limits that were applied are removed
+	p.Status = &cephv1.CephBlockPoolStatus{QoS: &cephv1.QoSSpec{IOPSLimit: &iops}}
+	err = createPool(context, clusterInfo, clusterSpec, p)
+	assert.Nil(t, err)
+	assert.True(t, qosConfigured)
+	p.Status = nil
+	qosConfigured = false
 
 	// succeed with EC
 	p.Spec.Replicated.Size = 0
@@ -69,6 +91,7 @@ func TestCreatePool(t *testing.T) {
 	p.Spec.ErasureCoded.DataChunks = 2
 	err = createPool(context, clusterInfo, clusterSpec, p)
 	assert.Nil(t, err)
+	assert.False(t, qosConfigured)
 }
 
 func TestDeletePool(t *testing.T) {
@@ -278,6 +301,9 @@ func TestCephBlockPoolController(t *testing.T) {
 			if args[0] == "config" && args[2] == "mgr." && args[3] == "mgr/prometheus/rbd_stats_pools" {
 				return "", nil
 			}
+			if command == "rbd" && args[0] == "config" {
+				return "[]", nil
+			}
 
 			return "", nil
 		},
@@ -336,6 +362,9 @@ func TestCephBlockPoolController(t *testing.T) {
 			if args[0] == "mirror" && args[1] == "pool" && args[2] == "peer" && args[3] == "bootstrap" && args[4] == "create" {
 				return `eyJmc2lkIjoiYzZiMDg3ZjItNzgyOS00ZGJiLWJjZmMtNTNkYzM0ZTBiMzVkIiwiY2xpZW50X2lkIjoicmJkLW1pcnJvci1wZWVyIiwia2V5IjoiQVFBV1lsWmZVQ1Q2RGhBQVBtVnAwbGtubDA5YVZWS3lyRVV1NEE9PSIsIm1vbl9ob3N0IjoiW3YyOjE5Mi4xNjguMTExLjEwOjMzMDAsdjE6MTkyLjE2OC4xMTEuMTA6Njc4OV0sW3YyOjE5Mi4xNjguMTExLjEyOjMzMDAsdjE6MTkyLjE2OC4xMTEuMTI6Njc4OV0sW3YyOjE5Mi4xNjguMTExLjExOjMzMDAsdjE6MTkyLjE2OC4xMTEuMTE6Njc4OV0ifQ==`, nil
 			}
+			if command == "rbd" && args[0] == "config" {
+				return "[]", nil
+			}
 			return "", nil
 		},
 	}
@@ -0,0 +1,266 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package crushrule to manage the CRUSH rules and buckets that pools place their data with
+package crushrule
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/coreos/pkg/capnslog"
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/ceph/cluster/mon"
+	opcontroller "github.com/rook/rook/pkg/operator/ceph/controller"
+	"github.com/rook/rook/pkg/operator/ceph/reporting"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	controllerName = "ceph-crush-rule-controller"
+)
+
+var logger = capnslog.NewPackageLogger("github.com/rook/rook", controllerName)
+
+var cephCrushRuleKind = reflect.TypeOf(cephv1.CephCrushRule{}).Name()
+
+// Sets the type meta for the controller main object
+var controllerTypeMeta = metav1.TypeMeta{
+	Kind:       cephCrushRuleKind,
+	APIVersion: fmt.Sprintf("%s/%s", cephv1.CustomResourceGroup, cephv1.Version),
+}
+
+// ReconcileCephCrushRule reconciles a CephCrushRule object
+type ReconcileCephCrushRule struct {
+	client      client.Client
+	scheme      *runtime.Scheme
+	context     *clusterd.Context
+	clusterInfo *cephclient.ClusterInfo
+}
+
+// Add creates a new CephCrushRule Controller and adds it to the Manager. The Manager will set fields on the Controller
+// and Start it when the Manager is Started.
+func Add(mgr manager.Manager, context *clusterd.Context) error {
+	return add(mgr, newReconciler(mgr, context))
+}
+
+// newReconciler returns a new reconcile.Reconciler
+func newReconciler(mgr manager.Manager, context *clusterd.Context) reconcile.Reconciler {
+	return &ReconcileCephCrushRule{
+		client:  mgr.GetClient(),
+		scheme:  mgr.GetScheme(),
+		context: context,
+	}
+}
+
+func add(mgr manager.Manager, r reconcile.Reconciler) error {
+	// Create a new controller
+	c, err := controller.New(controllerName, mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+	logger.Info("successfully started")
+
+	// Watch for changes on the CephCrushRule CRD object
+	err = c.Watch(&source.Kind{Type: &cephv1.CephCrushRule{TypeMeta: controllerTypeMeta}}, &handler.EnqueueRequestForObject{}, opcontroller.WatchControllerPredicate())
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Reconcile reads that state of the cluster for a CephCrushRule object and makes changes based on the state read
+// and what is in the CephCrushRule.Spec
+// The Controller will requeue the Request to be processed again if the returned error is non-nil or
+// Result.Requeue is true, otherwise upon completion it will remove the work from the queue.
+func (r *ReconcileCephCrushRule) Reconcile(context context.Context, request reconcile.Request) (reconcile.Result, error) {
+	// workaround because the rook logging mechanism is not compatible with the controller-runtime logging interface
+	reconcileResponse, err := r.reconcile(request)
+	if err != nil {
+		logger.Errorf("failed to reconcile %v", err)
+	}
+
+	return reconcileResponse, err
+}
+
+func (r *ReconcileCephCrushRule) reconcile(request reconcile.Request) (reconcile.Result, error) {
+	// Fetch the CephCrushRule instance
+	cephCrushRule := &cephv1.CephCrushRule{}
+	err := r.client.Get(context.TODO(), request.NamespacedName, cephCrushRule)
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			logger.Debug("cephCrushRule resource not found. Ignoring since object must be deleted.")
+			return reconcile.Result{}, nil
+		}
+		// Error reading the object - requeue the request.
+		return reconcile.Result{}, errors.Wrap(err, "failed to get cephCrushRule")
+	}
+
+	// Set a finalizer so we can do cleanup before the object goes away
+	err = opcontroller.AddFinalizerIfNotPresent(r.client, cephCrushRule)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrap(err, "failed to add finalizer")
+	}
+
+	// The CR was just created, initializing status fields
+	if cephCrushRule.Status == nil {
+		updateStatus(r.client, request.NamespacedName, cephv1.ConditionProgressing, nil, "")
+	}
+
+	// Make sure a CephCluster is present otherwise do nothing
+	_, isReadyToReconcile, cephClusterExists, reconcileResponse := opcontroller.IsReadyToReconcile(r.client, r.context, request.NamespacedName, controllerName)
+	if !isReadyToReconcile {
+		// This handles the case where the Ceph Cluster is gone and we want to delete that CR
+		// We skip the deleteCrushRule() function since everything is gone already
+		//
+		// Also, only remove the finalizer if the CephCluster is gone
+		// If not, we should wait for it to be ready
+		// This handles the case where the operator is not ready to accept Ceph command but the cluster exists
+		if !cephCrushRule.GetDeletionTimestamp().IsZero() && !cephClusterExists {
+			// Remove finalizer
+			err = opcontroller.RemoveFinalizer(r.client, cephCrushRule)
+			if err != nil {
+				return opcontroller.ImmediateRetryResult, errors.Wrap(err, "failed to remove finalizer")
+			}
+
+			// Return and do not requeue. Successful deletion.
+			return reconcile.Result{}, nil
+		}
+		return reconcileResponse, nil
+	}
+
+	// Populate clusterInfo during each reconcile
+	r.clusterInfo, _, _, err = mon.LoadClusterInfo(r.context, request.NamespacedName.Namespace)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrap(err, "failed to populate cluster info")
+	}
+
+	// DELETE: the CR was deleted
+	if !cephCrushRule.GetDeletionTimestamp().IsZero() {
+		logger.Debugf("deleting crush rule %q", cephCrushRule.Name)
+		// the rule cannot be deleted while pools use it
+		err := cephclient.DeleteCrushRule(r.context, r.clusterInfo, cephCrushRule.Name)
+		if err != nil {
+			var ruleID *int
+			if cephCrushRule.Status != nil {
+				ruleID = cephCrushRule.Status.RuleID
+			}
+			updateStatus(r.client, request.NamespacedName, cephv1.ConditionDeleting, ruleID, err.Error())
+			return reconcile.Result{}, errors.Wrapf(err, "failed to delete ceph crush rule %q", cephCrushRule.Name)
+		}
+
+		// Remove finalizer
+		err = opcontroller.RemoveFinalizer(r.client, cephCrushRule)
+		if err != nil {
+			return reconcile.Result{}, errors.Wrap(err, "failed to remove finalizer")
+		}
+
+		// Return and do not requeue. Successful deletion.
+		return reconcile.Result{}, nil
+	}
+
+	// validate the crush rule settings
+	err = validateCrushRule(cephCrushRule)
+	if err != nil {
+		updateStatus(r.client, request.NamespacedName, cephv1.ConditionFailure, nil, err.Error())
+		return reconcile.Result{}, errors.Wrapf(err, "invalid crush rule %q arguments", cephCrushRule.Name)
+	}
+
+	// Create or Update the crush rule
+	ruleID, err := r.createOrUpdateCrushRule(cephCrushRule)
+	if err != nil {
+		if strings.Contains(err.Error(), opcontroller.UninitializedCephConfigError) {
+			logger.Info(opcontroller.OperatorNotInitializedMessage)
+			return opcontroller.WaitForRequeueIfOperatorNotInitialized, nil
+		}
+		updateStatus(r.client, request.NamespacedName, cephv1.ConditionFailure, nil, err.Error())
+		return reconcile.Result{}, errors.Wrapf(err, "failed to create or update crush rule %q", cephCrushRule.Name)
+	}
+
+	// Success! Let's update the status
+	updateStatus(r.client, request.NamespacedName, cephv1.ConditionReady, &ruleID, "")
+
+	// Return and do not requeue
+	logger.Debug("done reconciling")
+	return reconcile.Result{}, nil
+}
+
+// Create the bucket types, the buckets and the rule of the CR
+func (r *ReconcileCephCrushRule) createOrUpdateCrushRule(cephCrushRule *cephv1.CephCrushRule) (int, error) {
+	crushMap, err := cephclient.GetCrushMap(r.context, r.clusterInfo)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to get crush map")
+	}
+	if err := validateCrushRuleWithCrushMap(crushMap, cephCrushRule.Spec); err != nil {
+		return 0, errors.Wrap(err, "invalid crush rule for the crush map")
+	}
+
+	if err := cephclient.EnsureCrushBucketTypes(r.context, r.clusterInfo, cephCrushRule.Spec.BucketTypes); err != nil {
+		return 0, err
+	}
+	if err := cephclient.EnsureCrushBuckets(r.context, r.clusterInfo, cephCrushRule.Spec.Buckets); err != nil {
+		return 0, err
+	}
+
+	ruleID, err := cephclient.CreateOrUpdateCrushRule(r.context, r.clusterInfo, cephCrushRule.Name, cephCrushRule.Spec)
+	if err != nil {
+		return 0, err
+	}
+
+	return ruleID, nil
+}
+
+// updateStatus updates an object with a given status
+func updateStatus(client client.Client, name types.NamespacedName, status cephv1.ConditionType, ruleID *int, message string) {
+	cephCrushRule := &cephv1.CephCrushRule{}
+	if err := client.Get(context.TODO(), name, cephCrushRule); err != nil {
+		if kerrors.IsNotFound(err) {
+			logger.Debug("CephCrushRule resource not found. Ignoring since object must be deleted.")
+			return
+		}
+		logger.Warningf("failed to retrieve ceph crush rule %q to update status to %q. %v", name, status, err)
+		return
+	}
+	if cephCrushRule.Status == nil {
+		cephCrushRule.Status = &cephv1.CephCrushRuleStatus{}
+	}
+
+	cephCrushRule.Status.Phase = status
+	cephCrushRule.Status.Message = message
+	if ruleID != nil {
+		cephCrushRule.Status.RuleID = ruleID
+	}
+	if err := reporting.UpdateStatus(client, cephCrushRule); err != nil {
+		logger.Errorf("failed to set ceph crush rule %q status to %q. %v", name, status, err)
+		return
+	}
+	logger.Debugf("ceph crush rule %q status updated to %q", name, status)
+}
@@ -0,0 +1,285 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crushrule
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	rookclient "github.com/rook/rook/pkg/client/clientset/versioned/fake"
+	"github.com/rook/rook/pkg/client/clientset/versioned/scheme"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	testop "github.com/rook/rook/pkg/operator/test"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/tevino/abool"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const testCrushMap = `{
+	"devices":[{"id":0,"name":"osd.0","class":"hdd"}],
+	"types":[{"type_id":0,"name":"osd"},{"type_id":1,"name":"host"},{"type_id":4,"name":"row"},{"type_id":5,"name":"pdu"},{"type_id":10,"name":"root"}],
+	"buckets":[
+		{"id":-1,"name":"default","type_id":10,"type_name":"root","items":[{"id":-3}]},
+		{"id":-3,"name":"node1","type_id":1,"type_name":"host","items":[{"id":0}]}
+	],
+	"rules":[{"rule_id":0,"rule_name":"replicated_rule","type":1}]
+}`
+
+func pduCrushRuleSpec() cephv1.CrushRuleSpec {
+	return cephv1.CrushRuleSpec{
+		BucketTypes: []string{"pdu"},
+		Buckets: []cephv1.CrushBucketSpec{
+			{Name: "pdu1", Type: "pdu", Parent: "default"},
+			{Name: "node1", Type: "host", Parent: "pdu1"},
+		},
+		Steps: []cephv1.CrushRuleStepSpec{
+			{Op: "take", Item: "default", DeviceClass: "hdd"},
+			{Op: "chooseleaf", Type: "pdu"},
+			{Op: "emit"},
+		},
+	}
+}
+
+func TestValidateCrushRule(t *testing.T) {
+	r := &cephv1.CephCrushRule{ObjectMeta: metav1.ObjectMeta{Name: "pdu-rule", Namespace: "rook-ceph"}, Spec: pduCrushRuleSpec()}
+	assert.NoError(t, validateCrushRule(r))
+
+	// the rule must start with take
+	r.Spec.Steps = r.Spec.Steps[1:]
+	assert.Error(t, validateCrushRule(r))
+
+	// the rule must end with emit
+	r.Spec = pduCrushRuleSpec()
+	r.Spec.Steps = r.Spec.Steps[:2]
+	assert.Error(t, validateCrushRule(r))
+
+	// chooseleaf needs a type
+	r.Spec = pduCrushRuleSpec()
+	r.Spec.Steps[1].Type = ""
+	assert.Error(t, validateCrushRule(r))
+
+	// take needs an item
+	r.Spec = pduCrushRuleSpec()
+	r.Spec.Steps[0].Item = ""
+	assert.Error(t, validateCrushRule(r))
+
+	// the buckets need a type
+	r.Spec = pduCrushRuleSpec()
+	r.Spec.Buckets[0].Type = ""
+	assert.Error(t, validateCrushRule(r))
+
+	r.Spec = pduCrushRuleSpec()
+	r.Namespace = ""
+	assert.Error(t, validateCrushRule(r))
+}
+
+func TestValidateCrushRuleWithCrushMap(t *testing.T) {
+	var crushMap cephclient.CrushMap
+	assert.NoError(t, json.Unmarshal([]byte(testCrushMap), &crushMap))
+
+	spec := pduCrushRuleSpec()
+	assert.NoError(t, validateCrushRuleWithCrushMap(crushMap, spec))
+
+	// the bucket type is created by the rule
+	spec.BucketTypes = []string{"busbar"}
+	spec.Buckets[0].Type = "busbar"
+	spec.Steps[1].Type = "busbar"
+	assert.NoError(t, validateCrushRuleWithCrushMap(crushMap, spec))
+
+	// unknown bucket type
+	spec = pduCrushRuleSpec()
+	spec.Buckets[0].Type = "busbar"
+	assert.Error(t, validateCrushRuleWithCrushMap(crushMap, spec))
+
+	// unknown failure domain
+	spec = pduCrushRuleSpec()
+	spec.Steps[1].Type = "busbar"
+	assert.Error(t, validateCrushRuleWithCrushMap(crushMap, spec))
+
+	// unknown parent bucket
+	spec = pduCrushRuleSpec()
+	spec.Buckets[0].Parent = "other"
+	assert.Error(t, validateCrushRuleWithCrushMap(crushMap, spec))
+
+	// unknown root
+	spec = pduCrushRuleSpec()
+	spec.Steps[0].Item = "other"
+	assert.Error(t, validateCrushRuleWithCrushMap(crushMap, spec))
+
+	// the root can be created by the rule
+	spec.Buckets = append(spec.Buckets, cephv1.CrushBucketSpec{Name: "other", Type: "root"})
+	assert.NoError(t, validateCrushRuleWithCrushMap(crushMap, spec))
+
+	// no osd with the device class
+	spec = pduCrushRuleSpec()
+	spec.Steps[0].DeviceClass = "ssd"
+	assert.Error(t, validateCrushRuleWithCrushMap(crushMap, spec))
+}
+
+func TestCephCrushRuleController(t *testing.T) {
+	ctx := context.TODO()
+	var (
+		name      = "pdu-rule"
+		namespace = "rook-ceph"
+	)
+
+	crushRule := &cephv1.CephCrushRule{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			UID:       types.UID("c47cac40-9bee-4d52-823b-ccd803ba5bfe"),
+		},
+		Spec:   pduCrushRuleSpec(),
+		Status: &cephv1.CephCrushRuleStatus{},
+	}
+	cephCluster := &cephv1.CephCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      namespace,
+			Namespace: namespace,
+		},
+		Status: cephv1.ClusterStatus{
+			Phase: cephv1.ConditionReady,
+			CephVersion: &cephv1.ClusterVersion{
+				Version: "14.2.9-0",
+			},
+			CephStatus: &cephv1.CephStatus{
+				Health: "HEALTH_OK",
+			},
+		},
+	}
+
+	cephCommands := []string{}
+	setCrushMap := false
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutput: func(command string, args ...string) (string, error) {
+			if args[0] == "status" {
+				return `{"fsid":"c47cac40-9bee-4d52-823b-ccd803ba5bfe","health":{"checks":{},"status":"HEALTH_OK"},"pgmap":{"num_pgs":100,"pgs_by_state":[{"state_name":"active+clean","count":100}]}}`, nil
+			}
+			if command == "ceph" && args[0] == "osd" && args[1] == "crush" {
+				switch args[2] {
+				case "dump":
+					return testCrushMap, nil
+				case "add-bucket", "move":
+					cephCommands = append(cephCommands, strings.Join(args[2:5], " "))
+				case "rule":
+					if args[3] == "rm" && args[4] == "in-use-rule" {
+						return "", errors.New("crush rule in use")
+					}
+				}
+			}
+			if command == "ceph" && args[0] == "osd" && args[1] == "setcrushmap" {
+				setCrushMap = true
+			}
+			return "", nil
+		},
+	}
+	c := &clusterd.Context{
+		Executor:                   executor,
+		Clientset:                  testop.New(t, 1),
+		RookClientset:              rookclient.NewSimpleClientset(),
+		RequestCancelOrchestration: abool.New(),
+	}
+
+	// Mock clusterInfo
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "rook-ceph-mon",
+			Namespace: namespace,
+		},
+		Data: map[string][]byte{
+			"fsid":         []byte(name),
+			"mon-secret":   []byte("monsecret"),
+			"admin-secret": []byte("adminsecret"),
+		},
+		Type: k8sutil.RookType,
+	}
+	_, err := c.Clientset.CoreV1().Secrets(namespace).Create(ctx, secret, metav1.CreateOptions{})
+	assert.NoError(t, err)
+
+	// Register operator types with the runtime scheme.
+	s := scheme.Scheme
+	s.AddKnownTypes(cephv1.SchemeGroupVersion, &cephv1.CephCrushRule{}, &cephv1.CephCluster{}, &cephv1.CephClusterList{})
+
+	objects := []runtime.Object{crushRule, cephCluster}
+	cl := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(objects...).Build()
+	c.Client = cl
+	r := &ReconcileCephCrushRule{
+		client:  cl,
+		scheme:  s,
+		context: c,
+	}
+	req := reconcile.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      name,
+			Namespace: namespace,
+		},
+	}
+
+	// the bucket is created and moved under the root with the host, then the rule is added to the crush map
+	res, err := r.Reconcile(ctx, req)
+	assert.NoError(t, err)
+	assert.False(t, res.Requeue)
+	assert.Equal(t, []string{"add-bucket pdu1 pdu", "move pdu1 root=default", "move node1 pdu=pdu1"}, cephCommands)
+	assert.True(t, setCrushMap)
+
+	err = cl.Get(ctx, req.NamespacedName, crushRule)
+	assert.NoError(t, err)
+	assert.Equal(t, cephv1.ConditionReady, crushRule.Status.Phase)
+	assert.Equal(t, 1, *crushRule.Status.RuleID)
+
+	// an invalid rule is reported in the status
+	crushRule.Spec.Steps[0].DeviceClass = "ssd"
+	assert.NoError(t, cl.Update(ctx, crushRule))
+	_, err = r.Reconcile(ctx, req)
+	assert.Error(t, err)
+	err = cl.Get(ctx, req.NamespacedName, crushRule)
+	assert.NoError(t, err)
+	assert.Equal(t, cephv1.ConditionFailure, crushRule.Status.Phase)
+	assert.Contains(t, crushRule.Status.Message, `device class "ssd" to take not found`)
+
+	// a rule deleted before its first status write is reported as deleting when it cannot be removed
+	now := metav1.Now()
+	deleted := &cephv1.CephCrushRule{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "in-use-rule",
+			Namespace:         namespace,
+			Finalizers:        []string{"cephcrushrule.ceph.rook.io"},
+			DeletionTimestamp: &now,
+		},
+		Spec: pduCrushRuleSpec(),
+	}
+	assert.NoError(t, cl.Create(ctx, deleted))
+	deletedReq := reconcile.Request{NamespacedName: types.NamespacedName{Name: deleted.Name, Namespace: namespace}}
+	_, err = r.Reconcile(ctx, deletedReq)
+	assert.Error(t, err)
+	deleted = &cephv1.CephCrushRule{}
+	assert.NoError(t, cl.Get(ctx, deletedReq.NamespacedName, deleted))
+	assert.Equal(t, cephv1.ConditionDeleting, deleted.Status.Phase)
+	assert.Nil(t, deleted.Status.RuleID)
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crushrule
+
+import (
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// validateCrushRule validates the settings of the crush rule that do not depend on the crush map
+func validateCrushRule(r *cephv1.CephCrushRule) error {
+	if r.Name == "" {
+		return errors.New("missing name")
+	}
+	if r.Namespace == "" {
+		return errors.New("missing namespace")
+	}
+
+	steps := r.Spec.Steps
+	if len(steps) == 0 {
+		return errors.New("missing steps")
+	}
+	if steps[0].Op != "take" {
+		return errors.Errorf("the first step must be take, not %q", steps[0].Op)
+	}
+	if steps[len(steps)-1].Op != "emit" {
+		return errors.Errorf("the last step must be emit, not %q", steps[len(steps)-1].Op)
+	}
+	for i, step := range steps {
+		switch step.Op {
+		case "take":
+			if step.Item == "" {
+				return errors.Errorf("missing item of take step %d", i)
+			}
+		case "choose", "chooseleaf":
+			if step.Type == "" {
+				return errors.Errorf("missing type of %s step %d", step.Op, i)
+			}
+			if step.Num < 0 {
+				return errors.Errorf("invalid num %d of %s step %d", step.Num, step.Op, i)
+			}
+		case "emit":
+		default:
+			return errors.Errorf("unrecognized op %q of step %d", step.Op, i)
+		}
+	}
+
+	for _, bucket := range r.Spec.Buckets {
+		if bucket.Name == "" || bucket.Type == "" {
+			return errors.New("the buckets must have a name and a type")
+		}
+		if bucket.Name == bucket.Parent {
+			return errors.Errorf("bucket %q cannot be its own parent", bucket.Name)
+		}
+	}
+
+	return nil
+}
+
+// validateCrushRuleWithCrushMap validates that the bucket types, buckets and device classes of the crush rule
+// exist in the crush map or are created by the crush rule
+func validateCrushRuleWithCrushMap(crushMap cephclient.CrushMap, spec cephv1.CrushRuleSpec) error {
+	bucketTypes := sets.NewString(spec.BucketTypes...)
+	for _, t := range crushMap.Types {
+		bucketTypes.Insert(t.Name)
+	}
+	buckets := sets.NewString()
+	for _, bucket := range crushMap.Buckets {
+		buckets.Insert(bucket.Name)
+	}
+	for _, bucket := range spec.Buckets {
+		buckets.Insert(bucket.Name)
+	}
+	deviceClasses := sets.NewString(cephclient.CrushDeviceClasses(crushMap)...)
+
+	for _, bucket := range spec.Buckets {
+		if !bucketTypes.Has(bucket.Type) {
+			return errors.Errorf("unrecognized type %q of bucket %q", bucket.Type, bucket.Name)
+		}
+		if bucket.Parent != "" && !buckets.Has(bucket.Parent) {
+			return errors.Errorf("parent %q of bucket %q not found", bucket.Parent, bucket.Name)
+		}
+	}
+
+	for _, step := range spec.Steps {
+		switch step.Op {
+		case "take":
+			if !buckets.Has(step.Item) {
+				return errors.Errorf("bucket %q to take not found", step.Item)
+			}
+			if step.DeviceClass != "" && !deviceClasses.Has(step.DeviceClass) {
+				return errors.Errorf("device class %q to take not found", step.DeviceClass)
+			}
+		case "choose", "chooseleaf":
+			if !bucketTypes.Has(step.Type) {
+				return errors.Errorf("unrecognized type %q to %s", step.Type, step.Op)
+			}
+		}
+	}
+
+	return nil
+}
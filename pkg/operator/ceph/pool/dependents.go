@@ -0,0 +1,117 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pool
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/util/dependents"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	imageDependentType      = "rbd images"
+	trashImageDependentType = "rbd images in the trash"
+	snapshotDependentType   = "rbd snapshots"
+)
+
+// CephBlockPoolDependents returns the rbd images, images in the trash and snapshots of the pool and the
+// CephBlockPoolRadosNamespaces of the pool that should block its deletion
+func CephBlockPoolDependents(
+	clusterdCtx *clusterd.Context,
+	clusterInfo *cephclient.ClusterInfo,
+	k8sClient client.Client,
+	p *cephv1.CephBlockPool,
+) (*dependents.DependentList, error) {
+	nsName := fmt.Sprintf("%s/%s", p.Namespace, p.Name)
+	baseErrMsg := fmt.Sprintf("failed to get dependents of CephBlockPool %q", nsName)
+
+	deps := dependents.NewDependentList()
+
+	// CephBlockPoolRadosNamespaces
+	radosNamespaces := &cephv1.CephBlockPoolRadosNamespaceList{}
+	if err := k8sClient.List(context.TODO(), radosNamespaces, client.InNamespace(p.Namespace)); err != nil {
+		return deps, errors.Wrapf(err, "%s. failed to list CephBlockPoolRadosNamespaces", baseErrMsg)
+	}
+	for _, radosNamespace := range radosNamespaces.Items {
+		if radosNamespace.Spec.BlockPoolName == p.Name {
+			deps.Add("CephBlockPoolRadosNamespaces", radosNamespace.Name)
+		}
+	}
+
+	// there are no images if the pool does not exist anymore
+	exists, err := poolExists(clusterdCtx, clusterInfo, p.Name)
+	if err != nil {
+		return deps, errors.Wrap(err, baseErrMsg)
+	}
+	if !exists {
+		return deps, nil
+	}
+
+	inventory, err := cephclient.GetPoolImageInventory(clusterdCtx, clusterInfo, p.Name)
+	if err != nil {
+		return deps, errors.Wrap(err, baseErrMsg)
+	}
+	for _, image := range inventory.Images {
+		deps.Add(imageDependentType, image)
+	}
+	for _, image := range inventory.TrashImages {
+		deps.Add(trashImageDependentType, image)
+	}
+	for _, snapshot := range inventory.Snapshots {
+		deps.Add(snapshotDependentType, snapshot)
+	}
+
+	return deps, nil
+}
+
+// purgeTrashOnDeletion purges the trash of a deleted pool that opted in with purgeTrashOnDeletion. The trash
+// is purged once, the images whose deferment time has not expired are then reported as dependents.
+func (r *ReconcileCephBlockPool) purgeTrashOnDeletion(clusterInfo *cephclient.ClusterInfo, p *cephv1.CephBlockPool) error {
+	if !p.Spec.PurgeTrashOnDeletion || r.purgedTrashes[p.UID] {
+		return nil
+	}
+	exists, err := poolExists(r.context, clusterInfo, p.Name)
+	if err != nil {
+		return errors.Wrapf(err, "failed to purge the trash of pool %q", p.Name)
+	}
+	if exists {
+		if err := cephclient.PurgeTrash(r.context, clusterInfo, p.Name); err != nil {
+			return err
+		}
+	}
+	r.purgedTrashes[p.UID] = true
+	return nil
+}
+
+func poolExists(context *clusterd.Context, clusterInfo *cephclient.ClusterInfo, poolName string) (bool, error) {
+	pools, err := cephclient.ListPoolSummaries(context, clusterInfo)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to list pools")
+	}
+	for _, pool := range pools {
+		if pool.Name == poolName {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pool
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/client/clientset/versioned/scheme"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestCephBlockPoolDependents(t *testing.T) {
+	namespace := "rook-ceph"
+	pools := `[{"poolnum":1,"poolname":"replicapool"}]`
+	trash := `[{"id":"10f5a3b6e2a4","name":"csi-vol-2"}]`
+	images := `[{"image":"csi-vol-1"},{"image":"csi-vol-1","snapshot":"snap1","snapshot_id":4}]`
+	purged := false
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutput: func(command string, args ...string) (string, error) {
+			logger.Infof("Command: %s %v", command, args)
+			if command == "ceph" && args[0] == "osd" && args[1] == "lspools" {
+				return pools, nil
+			}
+			if command == "ceph" && args[0] == "osd" && args[1] == "pool" && args[2] == "ls" {
+				// no other pool stores its data in the pool
+				return `[]`, nil
+			}
+			if command != "rbd" {
+				return "", errors.Errorf("unexpected command %s %v", command, args)
+			}
+			switch args[0] {
+			case "namespace":
+				return `[]`, nil
+			case "ls":
+				return images, nil
+			case "trash":
+				if args[1] == "purge" {
+					purged = true
+					trash = `[]`
+					return "", nil
+				}
+				return trash, nil
+			}
+			return "", errors.Errorf("unexpected command %s %v", command, args)
+		},
+	}
+	c := &clusterd.Context{Executor: executor}
+	clusterInfo := cephclient.AdminClusterInfo(namespace)
+
+	p := &cephv1.CephBlockPool{ObjectMeta: metav1.ObjectMeta{Name: "replicapool", Namespace: namespace}}
+	radosNamespace := &cephv1.CephBlockPoolRadosNamespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "team-a", Namespace: namespace},
+		Spec:       cephv1.CephBlockPoolRadosNamespaceSpec{BlockPoolName: "replicapool"},
+	}
+	otherRadosNamespace := &cephv1.CephBlockPoolRadosNamespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "team-b", Namespace: namespace},
+		Spec:       cephv1.CephBlockPoolRadosNamespaceSpec{BlockPoolName: "otherpool"},
+	}
+	s := scheme.Scheme
+	s.AddKnownTypes(cephv1.SchemeGroupVersion, &cephv1.CephBlockPoolRadosNamespace{}, &cephv1.CephBlockPoolRadosNamespaceList{})
+	cl := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects([]runtime.Object{radosNamespace, otherRadosNamespace}...).Build()
+
+	// the images, the images in the trash, the snapshots and the rados namespaces block the deletion
+	deps, err := CephBlockPoolDependents(c, clusterInfo, cl, p)
+	assert.NoError(t, err)
+	assert.False(t, purged)
+	assert.ElementsMatch(t, []string{"CephBlockPoolRadosNamespaces", imageDependentType, trashImageDependentType, snapshotDependentType}, deps.PluralKinds())
+	assert.Equal(t, []string{"team-a"}, deps.OfPluralKind("CephBlockPoolRadosNamespaces"))
+	assert.Equal(t, []string{"replicapool/csi-vol-1"}, deps.OfPluralKind(imageDependentType))
+	assert.Equal(t, []string{"replicapool/csi-vol-2 (10f5a3b6e2a4)"}, deps.OfPluralKind(trashImageDependentType))
+	assert.Equal(t, []string{"replicapool/csi-vol-1@snap1"}, deps.OfPluralKind(snapshotDependentType))
+
+	// the trash is not purged when listing the dependents
+	assert.NoError(t, cl.Delete(context.TODO(), radosNamespace))
+	images = `[]`
+	p.Spec.PurgeTrashOnDeletion = true
+	deps, err = CephBlockPoolDependents(c, clusterInfo, cl, p)
+	assert.NoError(t, err)
+	assert.False(t, purged)
+	assert.Equal(t, []string{trashImageDependentType}, deps.PluralKinds())
+
+	// the trash is purged once when the pool opted in
+	r := &ReconcileCephBlockPool{context: c, purgedTrashes: map[types.UID]bool{}}
+	p.UID = "1234"
+	assert.NoError(t, r.purgeTrashOnDeletion(clusterInfo, p))
+	assert.True(t, purged)
+	deps, err = CephBlockPoolDependents(c, clusterInfo, cl, p)
+	assert.NoError(t, err)
+	assert.True(t, deps.Empty())
+	purged = false
+	assert.NoError(t, r.purgeTrashOnDeletion(clusterInfo, p))
+	assert.False(t, purged)
+
+	// the pool does not exist anymore
+	pools = `[]`
+	trash = `[{"id":"10f5a3b6e2a4","name":"csi-vol-2"}]`
+	deps, err = CephBlockPoolDependents(c, clusterInfo, cl, p)
+	assert.NoError(t, err)
+	assert.True(t, deps.Empty())
+}
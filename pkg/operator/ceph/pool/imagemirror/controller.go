@@ -0,0 +1,315 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package imagemirror to manage the mirroring of selected images of a CephBlockPool
+package imagemirror
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/coreos/pkg/capnslog"
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/ceph/cluster/mon"
+	opcontroller "github.com/rook/rook/pkg/operator/ceph/controller"
+	"github.com/rook/rook/pkg/operator/ceph/reporting"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	controllerName = "ceph-block-pool-image-mirror-controller"
+)
+
+var logger = capnslog.NewPackageLogger("github.com/rook/rook", controllerName)
+
+var cephBlockPoolImageMirrorKind = reflect.TypeOf(cephv1.CephBlockPoolImageMirror{}).Name()
+
+// Sets the type meta for the controller main object
+var controllerTypeMeta = metav1.TypeMeta{
+	Kind:       cephBlockPoolImageMirrorKind,
+	APIVersion: fmt.Sprintf("%s/%s", cephv1.CustomResourceGroup, cephv1.Version),
+}
+
+var (
+	// defaultStatusCheckInterval is the interval to refresh the mirroring status of the images
+	defaultStatusCheckInterval = 1 * time.Minute
+)
+
+// ReconcileCephBlockPoolImageMirror reconciles a CephBlockPoolImageMirror object
+type ReconcileCephBlockPoolImageMirror struct {
+	client      client.Client
+	scheme      *runtime.Scheme
+	context     *clusterd.Context
+	clusterInfo *cephclient.ClusterInfo
+}
+
+// Add creates a new CephBlockPoolImageMirror Controller and adds it to the Manager. The Manager will set fields on the Controller
+// and Start it when the Manager is Started.
+func Add(mgr manager.Manager, context *clusterd.Context) error {
+	return add(mgr, newReconciler(mgr, context))
+}
+
+// newReconciler returns a new reconcile.Reconciler
+func newReconciler(mgr manager.Manager, context *clusterd.Context) reconcile.Reconciler {
+	return &ReconcileCephBlockPoolImageMirror{
+		client:  mgr.GetClient(),
+		scheme:  mgr.GetScheme(),
+		context: context,
+	}
+}
+
+func add(mgr manager.Manager, r reconcile.Reconciler) error {
+	// Create a new controller
+	c, err := controller.New(controllerName, mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+	logger.Info("successfully started")
+
+	// Watch for changes on the CephBlockPoolImageMirror CRD object
+	err = c.Watch(&source.Kind{Type: &cephv1.CephBlockPoolImageMirror{TypeMeta: controllerTypeMeta}}, &handler.EnqueueRequestForObject{}, opcontroller.WatchControllerPredicate())
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Reconcile reads that state of the cluster for a CephBlockPoolImageMirror object and makes changes based on the state read
+// and what is in the CephBlockPoolImageMirror.Spec
+// The Controller will requeue the Request to be processed again if the returned error is non-nil or
+// Result.Requeue is true, otherwise upon completion it will remove the work from the queue.
+func (r *ReconcileCephBlockPoolImageMirror) Reconcile(context context.Context, request reconcile.Request) (reconcile.Result, error) {
+	// workaround because the rook logging mechanism is not compatible with the controller-runtime logging interface
+	reconcileResponse, err := r.reconcile(request)
+	if err != nil {
+		logger.Errorf("failed to reconcile %v", err)
+	}
+
+	return reconcileResponse, err
+}
+
+func (r *ReconcileCephBlockPoolImageMirror) reconcile(request reconcile.Request) (reconcile.Result, error) {
+	// Fetch the CephBlockPoolImageMirror instance
+	imageMirror := &cephv1.CephBlockPoolImageMirror{}
+	err := r.client.Get(context.TODO(), request.NamespacedName, imageMirror)
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			logger.Debug("cephBlockPoolImageMirror resource not found. Ignoring since object must be deleted.")
+			return reconcile.Result{}, nil
+		}
+		// Error reading the object - requeue the request.
+		return reconcile.Result{}, errors.Wrap(err, "failed to get cephBlockPoolImageMirror")
+	}
+
+	// Set a finalizer so we can do cleanup before the object goes away
+	err = opcontroller.AddFinalizerIfNotPresent(r.client, imageMirror)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrap(err, "failed to add finalizer")
+	}
+
+	// The CR was just created, initializing status fields
+	if imageMirror.Status == nil {
+		imageMirror.Status = &cephv1.CephBlockPoolImageMirrorStatus{}
+		updateStatus(r.client, request.NamespacedName, cephv1.ConditionProgressing, "", nil, "")
+	}
+
+	// Make sure a CephCluster is present otherwise do nothing
+	_, isReadyToReconcile, cephClusterExists, reconcileResponse := opcontroller.IsReadyToReconcile(r.client, r.context, request.NamespacedName, controllerName)
+	if !isReadyToReconcile {
+		// This handles the case where the Ceph Cluster is gone and we want to delete that CR
+		// We skip the disableMirroring() function since everything is gone already
+		//
+		// Also, only remove the finalizer if the CephCluster is gone
+		// If not, we should wait for it to be ready
+		// This handles the case where the operator is not ready to accept Ceph command but the cluster exists
+		if !imageMirror.GetDeletionTimestamp().IsZero() && !cephClusterExists {
+			// Remove finalizer
+			err = opcontroller.RemoveFinalizer(r.client, imageMirror)
+			if err != nil {
+				return opcontroller.ImmediateRetryResult, errors.Wrap(err, "failed to remove finalizer")
+			}
+
+			// Return and do not requeue. Successful deletion.
+			return reconcile.Result{}, nil
+		}
+		return reconcileResponse, nil
+	}
+
+	// Populate clusterInfo during each reconcile
+	r.clusterInfo, _, _, err = mon.LoadClusterInfo(r.context, request.NamespacedName.Namespace)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrap(err, "failed to populate cluster info")
+	}
+
+	// DELETE: the CR was deleted
+	if !imageMirror.GetDeletionTimestamp().IsZero() {
+		logger.Debugf("deleting image mirror %q", imageMirror.Name)
+		err := r.disableMirroring(imageMirror)
+		if err != nil {
+			return reconcile.Result{}, errors.Wrapf(err, "failed to disable mirroring of the images of %q", imageMirror.Name)
+		}
+
+		// Remove finalizer
+		err = opcontroller.RemoveFinalizer(r.client, imageMirror)
+		if err != nil {
+			return reconcile.Result{}, errors.Wrap(err, "failed to remove finalizer")
+		}
+
+		// Return and do not requeue. Successful deletion.
+		return reconcile.Result{}, nil
+	}
+
+	// validate the image mirror settings
+	err = validateImageMirror(imageMirror)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrapf(err, "invalid image mirror %q arguments", imageMirror.Name)
+	}
+
+	// The pool must exist and be ready before mirroring its images
+	cephBlockPool := &cephv1.CephBlockPool{}
+	poolName := types.NamespacedName{Name: imageMirror.Spec.BlockPoolName, Namespace: request.Namespace}
+	err = r.client.Get(context.TODO(), poolName, cephBlockPool)
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			logger.Infof("waiting for ceph block pool %q to be created before mirroring the images of %q", poolName.Name, imageMirror.Name)
+			return opcontroller.WaitForRequeueIfCephClusterNotReady, nil
+		}
+		return reconcile.Result{}, errors.Wrapf(err, "failed to get ceph block pool %q", poolName.Name)
+	}
+	if cephBlockPool.Status == nil || cephBlockPool.Status.Phase != cephv1.ConditionReady {
+		logger.Infof("waiting for ceph block pool %q to be ready before mirroring the images of %q", poolName.Name, imageMirror.Name)
+		return opcontroller.WaitForRequeueIfCephClusterNotReady, nil
+	}
+	if !cephBlockPool.Spec.Mirroring.Enabled {
+		updateStatus(r.client, request.NamespacedName, cephv1.ConditionFailure, "mirroring is not enabled on the pool", nil, imageMirror.Status.LastResync)
+		return reconcile.Result{}, errors.Errorf("mirroring is not enabled on ceph block pool %q", poolName.Name)
+	}
+	if len(imageMirror.Spec.Images) > 0 && cephBlockPool.Spec.Mirroring.Mode != "image" {
+		updateStatus(r.client, request.NamespacedName, cephv1.ConditionFailure, "the pool mirroring mode must be image to mirror selected images", nil, imageMirror.Status.LastResync)
+		return reconcile.Result{}, errors.Errorf("mirroring mode of ceph block pool %q must be %q to mirror selected images", poolName.Name, "image")
+	}
+
+	// Apply the desired state to the images
+	imageStatus, lastResync, err := r.reconcileImages(imageMirror)
+	if err != nil {
+		if strings.Contains(err.Error(), opcontroller.UninitializedCephConfigError) {
+			logger.Info(opcontroller.OperatorNotInitializedMessage)
+			return opcontroller.WaitForRequeueIfOperatorNotInitialized, nil
+		}
+		updateStatus(r.client, request.NamespacedName, cephv1.ConditionFailure, err.Error(), imageStatus, lastResync)
+		return reconcile.Result{}, errors.Wrapf(err, "failed to mirror the images of %q", imageMirror.Name)
+	}
+
+	// Success! Let's update the status
+	updateStatus(r.client, request.NamespacedName, cephv1.ConditionReady, "", imageStatus, lastResync)
+
+	// Requeue to refresh the mirroring status of the images
+	logger.Debug("done reconciling")
+	if imageMirror.Spec.StatusCheck.Mirror.Disabled {
+		return reconcile.Result{}, nil
+	}
+	return reconcile.Result{RequeueAfter: statusCheckInterval(imageMirror)}, nil
+}
+
+func validateImageMirror(imageMirror *cephv1.CephBlockPoolImageMirror) error {
+	if imageMirror.Spec.BlockPoolName == "" {
+		return errors.New("missing blockPoolName")
+	}
+	switch imageMirror.Spec.State {
+	case "", cephv1.ImageMirrorStatePrimary, cephv1.ImageMirrorStateSecondary:
+	default:
+		return errors.Errorf("invalid state %q, must be %q or %q", imageMirror.Spec.State, cephv1.ImageMirrorStatePrimary, cephv1.ImageMirrorStateSecondary)
+	}
+	switch imageMirror.Spec.Mode {
+	case "", "snapshot", "journal":
+	default:
+		return errors.Errorf("invalid mode %q, must be %q or %q", imageMirror.Spec.Mode, "snapshot", "journal")
+	}
+	for _, image := range imageMirror.Spec.Images {
+		if image == "" {
+			return errors.New("empty image name")
+		}
+	}
+
+	return nil
+}
+
+func statusCheckInterval(imageMirror *cephv1.CephBlockPoolImageMirror) time.Duration {
+	if imageMirror.Spec.StatusCheck.Mirror.Interval != nil {
+		return imageMirror.Spec.StatusCheck.Mirror.Interval.Duration
+	}
+	return defaultStatusCheckInterval
+}
+
+// updateStatus updates an object with a given status
+func updateStatus(client client.Client, name types.NamespacedName, status cephv1.ConditionType, message string, images []cephv1.ImageMirrorStatus, lastResync string) {
+	imageMirror := &cephv1.CephBlockPoolImageMirror{}
+	if err := client.Get(context.TODO(), name, imageMirror); err != nil {
+		if kerrors.IsNotFound(err) {
+			logger.Debug("CephBlockPoolImageMirror resource not found. Ignoring since object must be deleted.")
+			return
+		}
+		logger.Warningf("failed to retrieve ceph block pool image mirror %q to update status to %q. %v", name, status, err)
+		return
+	}
+	previous := imageMirror.Status.DeepCopy()
+	if imageMirror.Status == nil {
+		imageMirror.Status = &cephv1.CephBlockPoolImageMirrorStatus{}
+	}
+
+	imageMirror.Status.Phase = status
+	imageMirror.Status.Message = message
+	imageMirror.Status.LastResync = lastResync
+	if images != nil {
+		imageMirror.Status.Images = images
+		imageMirror.Status.LastChecked = time.Now().UTC().Format(time.RFC3339)
+	}
+	if !imageMirrorStatusChanged(previous, imageMirror.Status) {
+		logger.Debugf("ceph block pool image mirror %q status is unchanged", name)
+		return
+	}
+	if err := reporting.UpdateStatus(client, imageMirror); err != nil {
+		logger.Errorf("failed to set ceph block pool image mirror %q status to %q. %v", name, status, err)
+		return
+	}
+	logger.Debugf("ceph block pool image mirror %q status updated to %q", name, status)
+}
+
+// imageMirrorStatusChanged returns whether the status changed other than the time of the check
+func imageMirrorStatusChanged(previous, status *cephv1.CephBlockPoolImageMirrorStatus) bool {
+	if previous == nil {
+		return true
+	}
+	unchanged := *previous
+	unchanged.LastChecked = status.LastChecked
+	return !reflect.DeepEqual(&unchanged, status)
+}
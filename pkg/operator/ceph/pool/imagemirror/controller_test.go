@@ -0,0 +1,337 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package imagemirror
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	rookclient "github.com/rook/rook/pkg/client/clientset/versioned/fake"
+	"github.com/rook/rook/pkg/client/clientset/versioned/scheme"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	testop "github.com/rook/rook/pkg/operator/test"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/tevino/abool"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// fakeImage is the mirroring state of an image in the mocked rbd tool
+type fakeImage struct {
+	enabled  bool
+	primary  bool
+	resynced bool
+}
+
+func newRBDExecutor(images map[string]*fakeImage) *exectest.MockExecutor {
+	return &exectest.MockExecutor{
+		MockExecuteCommandWithOutput: func(command string, args ...string) (string, error) {
+			if args[0] == "status" {
+				return `{"fsid":"c47cac40-9bee-4d52-823b-ccd803ba5bfe","health":{"checks":{},"status":"HEALTH_OK"},"pgmap":{"num_pgs":100,"pgs_by_state":[{"state_name":"active+clean","count":100}]}}`, nil
+			}
+			if command != "rbd" {
+				return "", nil
+			}
+			if args[0] == "mirror" && args[1] == "pool" && args[2] == "status" {
+				list := ""
+				for name, image := range images {
+					if image.enabled {
+						if list != "" {
+							list += ","
+						}
+						list += fmt.Sprintf(`{"name":%q,"state":"up+replaying"}`, strings.TrimPrefix(name, args[3]+"/"))
+					}
+				}
+				return `{"images":[` + list + `]}`, nil
+			}
+
+			var imageSpec string
+			switch args[0] {
+			case "info":
+				imageSpec = args[1]
+			case "snap":
+				imageSpec = args[3]
+			case "mirror":
+				imageSpec = args[3]
+			}
+			image, ok := images[imageSpec]
+			if !ok {
+				return "", errors.Errorf("image %q not found", imageSpec)
+			}
+			switch {
+			case args[0] == "info":
+				if !image.enabled {
+					return `{"name":"image"}`, nil
+				}
+				return fmt.Sprintf(`{"name":"image","mirroring":{"mode":"snapshot","state":"enabled","primary":%t}}`, image.primary), nil
+			case args[0] == "snap":
+				return `[{"id":5,"name":".mirror.primary.1","timestamp":"Thu Jun  3 09:15:10 2021","namespace":{"type":"mirror"}}]`, nil
+			case args[2] == "status":
+				return `{"name":"image","state":"up+stopped","description":"local image is primary","last_update":"2021-06-03 09:15:30"}`, nil
+			case args[2] == "enable":
+				image.enabled = true
+				image.primary = true
+			case args[2] == "disable":
+				image.enabled = false
+			case args[2] == "promote":
+				image.primary = true
+			case args[2] == "demote":
+				image.primary = false
+			case args[2] == "resync":
+				image.resynced = true
+			}
+			return "", nil
+		},
+	}
+}
+
+func TestValidateImageMirror(t *testing.T) {
+	m := &cephv1.CephBlockPoolImageMirror{}
+	assert.Error(t, validateImageMirror(m))
+
+	m.Spec.BlockPoolName = "replicapool"
+	assert.NoError(t, validateImageMirror(m))
+
+	m.Spec.State = "tertiary"
+	assert.Error(t, validateImageMirror(m))
+	m.Spec.State = cephv1.ImageMirrorStateSecondary
+	assert.NoError(t, validateImageMirror(m))
+
+	m.Spec.Mode = "sync"
+	assert.Error(t, validateImageMirror(m))
+	m.Spec.Mode = "journal"
+	assert.NoError(t, validateImageMirror(m))
+
+	m.Spec.Images = []string{"image1", ""}
+	assert.Error(t, validateImageMirror(m))
+}
+
+func TestStatusCheckInterval(t *testing.T) {
+	m := &cephv1.CephBlockPoolImageMirror{}
+	assert.Equal(t, defaultStatusCheckInterval, statusCheckInterval(m))
+	m.Spec.StatusCheck.Mirror.Interval = &metav1.Duration{Duration: 10 * time.Second}
+	assert.Equal(t, 10*time.Second, statusCheckInterval(m))
+}
+
+func TestReconcileImages(t *testing.T) {
+	images := map[string]*fakeImage{
+		"replicapool/app-1": {},
+		"replicapool/app-2": {enabled: true, primary: false},
+		"replicapool/other": {enabled: true, primary: true},
+	}
+	r := &ReconcileCephBlockPoolImageMirror{
+		context:     &clusterd.Context{Executor: newRBDExecutor(images)},
+		clusterInfo: cephclient.AdminClusterInfo("rook-ceph"),
+	}
+	m := &cephv1.CephBlockPoolImageMirror{
+		Spec: cephv1.CephBlockPoolImageMirrorSpec{
+			BlockPoolName: "replicapool",
+			Images:        []string{"app-1", "app-2"},
+		},
+	}
+
+	// mirroring is enabled and the images are promoted
+	status, lastResync, err := r.reconcileImages(m)
+	assert.NoError(t, err)
+	assert.Equal(t, "", lastResync)
+	assert.Equal(t, 2, len(status))
+	assert.True(t, images["replicapool/app-1"].enabled)
+	assert.True(t, images["replicapool/app-1"].primary)
+	assert.True(t, images["replicapool/app-2"].primary)
+	assert.Equal(t, "app-1", status[0].Name)
+	assert.True(t, status[0].Primary)
+	assert.Equal(t, "up+stopped", status[0].State)
+	assert.Equal(t, "Thu Jun  3 09:15:10 2021", status[0].LastSnapshotTime)
+	// the images that are not selected are not changed
+	assert.True(t, images["replicapool/other"].primary)
+
+	// the images are demoted and resynced once
+	m.Spec.State = cephv1.ImageMirrorStateSecondary
+	m.Spec.Resync = "1"
+	status, lastResync, err = r.reconcileImages(m)
+	assert.NoError(t, err)
+	assert.Equal(t, "1", lastResync)
+	assert.False(t, status[0].Primary)
+	assert.False(t, images["replicapool/app-1"].primary)
+	assert.False(t, images["replicapool/app-2"].primary)
+	assert.True(t, images["replicapool/app-1"].resynced)
+
+	images["replicapool/app-1"].resynced = false
+	m.Status = &cephv1.CephBlockPoolImageMirrorStatus{LastResync: lastResync}
+	_, _, err = r.reconcileImages(m)
+	assert.NoError(t, err)
+	assert.False(t, images["replicapool/app-1"].resynced)
+
+	// without selected images all the mirrored images of the pool are promoted
+	m.Spec.Images = nil
+	m.Spec.State = cephv1.ImageMirrorStatePrimary
+	status, _, err = r.reconcileImages(m)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(status))
+	for _, image := range images {
+		assert.True(t, image.primary)
+	}
+
+	// a missing image fails the reconcile but the other images are reconciled
+	m.Spec.Images = []string{"missing", "app-1"}
+	m.Spec.State = cephv1.ImageMirrorStateSecondary
+	status, _, err = r.reconcileImages(m)
+	assert.Error(t, err)
+	assert.Equal(t, 2, len(status))
+	assert.False(t, images["replicapool/app-1"].primary)
+
+	// the mirroring of the primary images is disabled on deletion
+	m.Spec.Images = []string{"app-1", "other"}
+	assert.NoError(t, r.disableMirroring(m))
+	assert.True(t, images["replicapool/app-1"].enabled)
+	assert.False(t, images["replicapool/other"].enabled)
+}
+
+func TestCephBlockPoolImageMirrorController(t *testing.T) {
+	ctx := context.TODO()
+	namespace := "rook-ceph"
+	imageMirror := &cephv1.CephBlockPoolImageMirror{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "app",
+			Namespace: namespace,
+			UID:       types.UID("c47cac40-9bee-4d52-823b-ccd803ba5bfe"),
+		},
+		Spec: cephv1.CephBlockPoolImageMirrorSpec{
+			BlockPoolName: "replicapool",
+			Images:        []string{"app-1"},
+		},
+		Status: &cephv1.CephBlockPoolImageMirrorStatus{},
+	}
+	cephCluster := &cephv1.CephCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      namespace,
+			Namespace: namespace,
+		},
+		Status: cephv1.ClusterStatus{
+			Phase:       cephv1.ConditionReady,
+			CephVersion: &cephv1.ClusterVersion{Version: "14.2.9-0"},
+			CephStatus:  &cephv1.CephStatus{Health: "HEALTH_OK"},
+		},
+	}
+	cephBlockPool := &cephv1.CephBlockPool{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "replicapool",
+			Namespace: namespace,
+		},
+		Spec: cephv1.PoolSpec{
+			Mirroring: cephv1.MirroringSpec{Enabled: true, Mode: "pool"},
+		},
+		Status: &cephv1.CephBlockPoolStatus{Phase: cephv1.ConditionReady},
+	}
+
+	images := map[string]*fakeImage{"replicapool/app-1": {}}
+	c := &clusterd.Context{
+		Executor:                   newRBDExecutor(images),
+		Clientset:                  testop.New(t, 1),
+		RookClientset:              rookclient.NewSimpleClientset(),
+		RequestCancelOrchestration: abool.New(),
+	}
+
+	// Mock clusterInfo
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "rook-ceph-mon",
+			Namespace: namespace,
+		},
+		Data: map[string][]byte{
+			"fsid":         []byte("fsid"),
+			"mon-secret":   []byte("monsecret"),
+			"admin-secret": []byte("adminsecret"),
+		},
+		Type: k8sutil.RookType,
+	}
+	_, err := c.Clientset.CoreV1().Secrets(namespace).Create(ctx, secret, metav1.CreateOptions{})
+	assert.NoError(t, err)
+
+	s := scheme.Scheme
+	s.AddKnownTypes(cephv1.SchemeGroupVersion, &cephv1.CephBlockPoolImageMirror{}, &cephv1.CephCluster{}, &cephv1.CephClusterList{}, &cephv1.CephBlockPool{})
+	objects := []runtime.Object{imageMirror, cephCluster, cephBlockPool}
+	cl := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(objects...).Build()
+	c.Client = cl
+	r := &ReconcileCephBlockPoolImageMirror{
+		client:  cl,
+		scheme:  s,
+		context: c,
+	}
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: "app", Namespace: namespace}}
+
+	// the pool does not mirror selected images
+	_, err = r.Reconcile(ctx, req)
+	assert.Error(t, err)
+	assert.NoError(t, cl.Get(ctx, req.NamespacedName, imageMirror))
+	assert.Equal(t, cephv1.ConditionFailure, imageMirror.Status.Phase)
+	assert.False(t, images["replicapool/app-1"].enabled)
+
+	cephBlockPool.Spec.Mirroring.Mode = "image"
+	assert.NoError(t, cl.Update(ctx, cephBlockPool))
+	res, err := r.Reconcile(ctx, req)
+	assert.NoError(t, err)
+	assert.Equal(t, defaultStatusCheckInterval, res.RequeueAfter)
+	assert.True(t, images["replicapool/app-1"].enabled)
+
+	assert.NoError(t, cl.Get(ctx, req.NamespacedName, imageMirror))
+	assert.Equal(t, cephv1.ConditionReady, imageMirror.Status.Phase)
+	assert.Equal(t, 1, len(imageMirror.Status.Images))
+	assert.True(t, imageMirror.Status.Images[0].Primary)
+	assert.NotEmpty(t, imageMirror.Status.LastChecked)
+
+	// the periodic check does not write the unchanged status
+	resourceVersion := imageMirror.ResourceVersion
+	_, err = r.Reconcile(ctx, req)
+	assert.NoError(t, err)
+	imageMirror = &cephv1.CephBlockPoolImageMirror{}
+	assert.NoError(t, cl.Get(ctx, req.NamespacedName, imageMirror))
+	assert.Equal(t, resourceVersion, imageMirror.ResourceVersion)
+}
+
+func TestImageMirrorStatusChanged(t *testing.T) {
+	status := &cephv1.CephBlockPoolImageMirrorStatus{
+		Phase:       cephv1.ConditionReady,
+		Images:      []cephv1.ImageMirrorStatus{{Name: "app-1", Primary: true}},
+		LastChecked: "2021-10-01T10:00:00Z",
+	}
+	assert.True(t, imageMirrorStatusChanged(nil, status))
+
+	// only the time of the check changed
+	checked := status.DeepCopy()
+	checked.LastChecked = "2021-10-01T10:05:00Z"
+	assert.False(t, imageMirrorStatusChanged(status, checked))
+
+	checked.Images[0].Primary = false
+	assert.True(t, imageMirrorStatusChanged(status, checked))
+
+	checked = status.DeepCopy()
+	checked.Message = "failed"
+	assert.True(t, imageMirrorStatusChanged(status, checked))
+}
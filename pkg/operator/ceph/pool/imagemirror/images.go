@@ -0,0 +1,174 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package imagemirror
+
+import (
+	"strings"
+	"syscall"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/util/exec"
+)
+
+const (
+	imageMirroringEnabled = "enabled"
+	defaultMirroringMode  = "snapshot"
+)
+
+// reconcileImages enables the mirroring of the images and promotes or demotes them to the desired
+// state. All the images are reconciled even if some of them fail so that a failover makes as much
+// progress as possible. It returns the mirroring status of the images and the resync value applied.
+func (r *ReconcileCephBlockPoolImageMirror) reconcileImages(imageMirror *cephv1.CephBlockPoolImageMirror) ([]cephv1.ImageMirrorStatus, string, error) {
+	spec := imageMirror.Spec
+	lastResync := ""
+	if imageMirror.Status != nil {
+		lastResync = imageMirror.Status.LastResync
+	}
+
+	state := spec.State
+	if state == "" {
+		state = cephv1.ImageMirrorStatePrimary
+	}
+	mode := spec.Mode
+	if mode == "" {
+		mode = defaultMirroringMode
+	}
+	resync := spec.Resync != "" && spec.Resync != lastResync
+
+	// without selected images the state applies to all the mirrored images of the pool
+	images := spec.Images
+	if len(images) == 0 {
+		mirrored, err := cephclient.ListMirroredImages(r.context, r.clusterInfo, spec.BlockPoolName, spec.RadosNamespace)
+		if err != nil {
+			return nil, lastResync, err
+		}
+		for _, image := range mirrored {
+			images = append(images, image.Name)
+		}
+	}
+
+	imageStatus := []cephv1.ImageMirrorStatus{}
+	failures := []string{}
+	for _, image := range images {
+		status, err := r.reconcileImage(imageMirror, image, state, mode, resync)
+		if err != nil {
+			logger.Errorf("failed to reconcile mirroring of image %q. %v", image, err)
+			failures = append(failures, err.Error())
+		}
+		imageStatus = append(imageStatus, status)
+	}
+	if len(failures) > 0 {
+		return imageStatus, lastResync, errors.Errorf("failed to reconcile the mirroring of %d image(s): %s", len(failures), strings.Join(failures, "; "))
+	}
+
+	if resync {
+		logger.Infof("flagged the secondary images of %q for resync", imageMirror.Name)
+		lastResync = spec.Resync
+	}
+	return imageStatus, lastResync, nil
+}
+
+func (r *ReconcileCephBlockPoolImageMirror) reconcileImage(imageMirror *cephv1.CephBlockPoolImageMirror, image, state, mode string, resync bool) (cephv1.ImageMirrorStatus, error) {
+	status := cephv1.ImageMirrorStatus{Name: image}
+	imageSpec := cephclient.GetImageSpec(imageMirror.Spec.BlockPoolName, imageMirror.Spec.RadosNamespace, image)
+
+	info, err := cephclient.GetImageMirroringInfo(r.context, r.clusterInfo, imageSpec)
+	if err != nil {
+		status.Description = "failed to get image info"
+		return status, err
+	}
+
+	if info.State != imageMirroringEnabled {
+		if state == cephv1.ImageMirrorStateSecondary {
+			// a secondary image is created by rbd-mirror with mirroring enabled
+			status.Description = "mirroring is not enabled"
+			return status, errors.Errorf("mirroring of secondary image %q is not enabled", imageSpec)
+		}
+		if err := cephclient.EnableImageMirroring(r.context, r.clusterInfo, imageSpec, mode); err != nil {
+			status.Description = "failed to enable mirroring"
+			return status, err
+		}
+		info.Primary = true
+	}
+
+	if state == cephv1.ImageMirrorStatePrimary && !info.Primary {
+		if err := cephclient.PromoteImage(r.context, r.clusterInfo, imageSpec, imageMirror.Spec.Force); err != nil {
+			status.Description = "failed to promote image"
+			return status, err
+		}
+		info.Primary = true
+	} else if state == cephv1.ImageMirrorStateSecondary && info.Primary {
+		if err := cephclient.DemoteImage(r.context, r.clusterInfo, imageSpec); err != nil {
+			status.Primary = true
+			status.Description = "failed to demote image"
+			return status, err
+		}
+		info.Primary = false
+	}
+	status.Primary = info.Primary
+
+	if resync && !info.Primary {
+		if err := cephclient.ResyncImage(r.context, r.clusterInfo, imageSpec); err != nil {
+			status.Description = "failed to resync image"
+			return status, err
+		}
+	}
+
+	// the status is informational, failing to read it does not fail the reconcile
+	mirrorStatus, err := cephclient.GetImageMirroringStatus(r.context, r.clusterInfo, imageSpec)
+	if err != nil {
+		logger.Warningf("failed to get mirroring status of image %q. %v", imageSpec, err)
+	} else {
+		status.State = mirrorStatus.State
+		status.Description = mirrorStatus.Description
+		status.LastUpdate = mirrorStatus.LastUpdate
+	}
+	lastSnapshot, err := cephclient.GetLastMirrorSnapshotTime(r.context, r.clusterInfo, imageSpec)
+	if err != nil {
+		logger.Warningf("failed to get the last mirror snapshot of image %q. %v", imageSpec, err)
+	} else {
+		status.LastSnapshotTime = lastSnapshot
+	}
+
+	return status, nil
+}
+
+// disableMirroring disables the mirroring of the selected images that are primary on this cluster. The
+// secondary images are left to the peer cluster where they are primary.
+func (r *ReconcileCephBlockPoolImageMirror) disableMirroring(imageMirror *cephv1.CephBlockPoolImageMirror) error {
+	for _, image := range imageMirror.Spec.Images {
+		imageSpec := cephclient.GetImageSpec(imageMirror.Spec.BlockPoolName, imageMirror.Spec.RadosNamespace, image)
+		info, err := cephclient.GetImageMirroringInfo(r.context, r.clusterInfo, imageSpec)
+		if err != nil {
+			if code, ok := exec.ExitStatus(errors.Cause(err)); ok && code == int(syscall.ENOENT) {
+				logger.Debugf("image %q does not exist", imageSpec)
+				continue
+			}
+			return err
+		}
+		if info.State != imageMirroringEnabled || !info.Primary {
+			continue
+		}
+		if err := cephclient.DisableImageMirroring(r.context, r.clusterInfo, imageSpec); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pool
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	poolGrowthRate = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "rook_ceph_pool_growth_bytes_per_day",
+		Help: "Growth rate of the data stored in the pool over the capacity forecast window",
+	}, []string{"namespace", "pool"})
+	poolDaysUntilFull = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "rook_ceph_pool_days_until_full",
+		Help: "Days until the pool is full at its current growth rate, absent when the pool is not growing",
+	}, []string{"namespace", "pool"})
+	deviceClassGrowthRate = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "rook_ceph_device_class_growth_bytes_per_day",
+		Help: "Growth rate of the raw capacity used in the device class over the capacity forecast window",
+	}, []string{"namespace", "device_class"})
+	deviceClassDaysUntilFull = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "rook_ceph_device_class_days_until_full",
+		Help: "Days until the device class is full at its current growth rate, absent when the usage is not growing",
+	}, []string{"namespace", "device_class"})
+)
+
+func init() {
+	// the metrics are served by the metrics endpoint of the operator manager
+	metrics.Registry.MustRegister(poolGrowthRate, poolDaysUntilFull, deviceClassGrowthRate, deviceClassDaysUntilFull)
+}
+
+func setPoolCapacityMetrics(namespace, pool string, forecast capacityForecast) {
+	poolGrowthRate.WithLabelValues(namespace, pool).Set(forecast.growthPerDay)
+	if forecast.daysUntilFull != nil {
+		poolDaysUntilFull.WithLabelValues(namespace, pool).Set(float64(*forecast.daysUntilFull))
+	} else {
+		poolDaysUntilFull.DeleteLabelValues(namespace, pool)
+	}
+}
+
+func deletePoolCapacityMetrics(namespace, pool string) {
+	poolGrowthRate.DeleteLabelValues(namespace, pool)
+	poolDaysUntilFull.DeleteLabelValues(namespace, pool)
+}
+
+func setDeviceClassCapacityMetrics(namespace, deviceClass string, forecast capacityForecast) {
+	deviceClassGrowthRate.WithLabelValues(namespace, deviceClass).Set(forecast.growthPerDay)
+	if forecast.daysUntilFull != nil {
+		deviceClassDaysUntilFull.WithLabelValues(namespace, deviceClass).Set(float64(*forecast.daysUntilFull))
+	} else {
+		deviceClassDaysUntilFull.DeleteLabelValues(namespace, deviceClass)
+	}
+}
+
+func deleteDeviceClassCapacityMetrics(namespace, deviceClass string) {
+	deviceClassGrowthRate.DeleteLabelValues(namespace, deviceClass)
+	deviceClassDaysUntilFull.DeleteLabelValues(namespace, deviceClass)
+}
@@ -0,0 +1,290 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pool
+
+import (
+	"fmt"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	opcontroller "github.com/rook/rook/pkg/operator/ceph/controller"
+	"github.com/rook/rook/pkg/util/exec"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const migrationPoolSuffix = "-migration"
+
+// migrationRetryInterval is how often the images in use are retried
+var migrationRetryInterval = time.Minute
+
+// GetMigrationPoolName returns the name of the pool the data of a pool is migrated to
+func GetMigrationPoolName(poolName string) string {
+	return poolName + migrationPoolSuffix
+}
+
+// ErasureCodeMigrationNeeded returns whether the chunks of an existing erasure coded pool differ from the
+// spec, and the current chunks of the pool
+func ErasureCodeMigrationNeeded(context *clusterd.Context, clusterInfo *cephclient.ClusterInfo, poolName string, spec cephv1.PoolSpec) (bool, string, error) {
+	if !spec.IsErasureCoded() {
+		return false, "", nil
+	}
+	_, profile, err := cephclient.GetPoolErasureCodeProfile(context, clusterInfo, poolName)
+	if err != nil {
+		return false, "", errors.Wrapf(err, "failed to get erasure code profile of pool %q", poolName)
+	}
+	if profile == nil {
+		return false, "", nil
+	}
+	return !profile.Matches(spec), profile.String(), nil
+}
+
+// IsMigrationInProgress returns whether a migration was started and is not completed
+func IsMigrationInProgress(status *cephv1.PoolMigrationStatus) bool {
+	return status != nil && status.Phase != "" && status.Phase != cephv1.PoolMigrationCompleted
+}
+
+// NewMigrationStatus returns the status of a migration of the pool from the source profile to the profile of the spec
+func NewMigrationStatus(poolName, sourceProfile string, spec cephv1.PoolSpec) *cephv1.PoolMigrationStatus {
+	return &cephv1.PoolMigrationStatus{
+		Phase:         cephv1.PoolMigrationMigrating,
+		SourceProfile: sourceProfile,
+		TargetProfile: cephclient.ErasureCodedSpecString(spec),
+		TargetPool:    GetMigrationPoolName(poolName),
+		StartTime:     time.Now().UTC().Format(time.RFC3339),
+	}
+}
+
+// CompleteMigration sets the status of a completed migration
+func CompleteMigration(status *cephv1.PoolMigrationStatus) {
+	status.Phase = cephv1.PoolMigrationCompleted
+	status.Pending = nil
+	status.Message = fmt.Sprintf("the pool was migrated from %s to %s", status.SourceProfile, status.TargetProfile)
+	status.CompletionTime = time.Now().UTC().Format(time.RFC3339)
+}
+
+// imagesInPoolError reports the images that still store their data in a pool to be deleted after its migration
+type imagesInPoolError struct {
+	poolName string
+	images   int
+}
+
+func (e *imagesInPoolError) Error() string {
+	return fmt.Sprintf("%d image(s) still store their data in pool %q and must be migrated before the pool is deleted", e.images, e.poolName)
+}
+
+// SwapMigratedPool replaces a pool with the pool its data was migrated to. The pool with the old erasure code
+// profile is deleted and the new pool and its crush rule are renamed after it. The rbd clients refer to the
+// pools by ID and the rgw daemons by name so the pool keeps working for the clients that use the new pool.
+// Each step is idempotent so an interrupted swap resumes where it stopped. The pool is not deleted if images, including the images
+// in the trash, still store their data in it.
+func SwapMigratedPool(context *clusterd.Context, clusterInfo *cephclient.ClusterInfo, poolName, targetPool string, spec cephv1.PoolSpec) error {
+	pools, err := listPoolNames(context, clusterInfo)
+	if err != nil {
+		return err
+	}
+
+	if pools[poolName] {
+		profileName, profile, err := cephclient.GetPoolErasureCodeProfile(context, clusterInfo, poolName)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get erasure code profile of pool %q", poolName)
+		}
+		if profile != nil && !profile.Matches(spec) {
+			if !pools[targetPool] {
+				return errors.Errorf("pool %q to replace pool %q does not exist", targetPool, poolName)
+			}
+			// images may have been created or restored from the trash since their migration
+			images, err := cephclient.ListImagesInDataPools(context, clusterInfo, poolName)
+			if err != nil {
+				return errors.Wrapf(err, "failed to list the images with their data in pool %q", poolName)
+			}
+			if len(images) > 0 {
+				return &imagesInPoolError{poolName: poolName, images: len(images)}
+			}
+			// the crush rule of the pool is deleted with the pool
+			if err := cephclient.DeletePool(context, clusterInfo, poolName); err != nil {
+				return errors.Wrapf(err, "failed to delete pool %q after its migration", poolName)
+			}
+			if err := cephclient.DeleteErasureCodeProfile(context, clusterInfo, profileName); err != nil {
+				logger.Warningf("failed to delete erasure code profile %q. %v", profileName, err)
+			}
+			pools[poolName] = false
+		}
+	}
+
+	if pools[targetPool] {
+		if pools[poolName] {
+			return errors.Errorf("cannot rename pool %q to %q since the pool already exists", targetPool, poolName)
+		}
+		if err := cephclient.RenamePool(context, clusterInfo, targetPool, poolName); err != nil {
+			return err
+		}
+	}
+
+	// the crush rule is renamed once the pool is renamed so a failed rename is retried
+	ruleExists, err := cephclient.CrushRuleExists(context, clusterInfo, targetPool)
+	if err != nil {
+		return errors.Wrapf(err, "failed to check the crush rule of pool %q", poolName)
+	}
+	if ruleExists {
+		if err := cephclient.RenameCrushRule(context, clusterInfo, targetPool, poolName); err != nil {
+			return errors.Wrapf(err, "failed to rename the crush rule of pool %q", poolName)
+		}
+	}
+
+	logger.Infof("pool %q replaced by the migrated pool", poolName)
+	return nil
+}
+
+func listPoolNames(context *clusterd.Context, clusterInfo *cephclient.ClusterInfo) (map[string]bool, error) {
+	summaries, err := cephclient.ListPoolSummaries(context, clusterInfo)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list pools")
+	}
+	pools := map[string]bool{}
+	for _, summary := range summaries {
+		pools[summary.Name] = true
+	}
+	return pools, nil
+}
+
+// reconcileMigration migrates the images of an erasure coded pool whose chunks changed to a new pool with
+// the new erasure code profile, then replaces the pool with the new pool
+func (r *ReconcileCephBlockPool) reconcileMigration(clusterInfo *cephclient.ClusterInfo, clusterSpec *cephv1.ClusterSpec, p *cephv1.CephBlockPool, sourceProfile string) (reconcile.Result, error) {
+	poolName := types.NamespacedName{Name: p.Name, Namespace: p.Namespace}
+	var status *cephv1.PoolMigrationStatus
+	if p.Status != nil {
+		status = p.Status.Migration
+	}
+
+	if !IsMigrationInProgress(status) {
+		if !p.Spec.ErasureCoded.AllowMigration {
+			return opcontroller.ImmediateRetryResult, errors.Errorf("the erasure code chunks of pool %q changed from %s to %s, set allowMigration to migrate the pool", p.Name, sourceProfile, cephclient.ErasureCodedSpecString(p.Spec))
+		}
+		status = NewMigrationStatus(p.Name, sourceProfile, p.Spec)
+		logger.Infof("migrating pool %q from erasure code profile %s to %s", p.Name, status.SourceProfile, status.TargetProfile)
+		updateMigrationStatus(r.client, poolName, status)
+	}
+
+	if status.Phase == cephv1.PoolMigrationMigrating {
+		if err := cephclient.CreatePoolWithProfile(r.context, clusterInfo, clusterSpec, status.TargetPool, p.Spec, poolApplicationNameRBD); err != nil {
+			return opcontroller.ImmediateRetryResult, errors.Wrapf(err, "failed to create pool %q to migrate pool %q", status.TargetPool, p.Name)
+		}
+
+		if err := r.migrateImages(clusterInfo, poolName, status); err != nil {
+			status.Message = err.Error()
+			updateMigrationStatus(r.client, poolName, status)
+			return opcontroller.ImmediateRetryResult, err
+		}
+		if len(status.Pending) > 0 {
+			status.Message = fmt.Sprintf("%d image(s) are in use or in the trash and will be migrated once their clients stop using them or they are restored or purged from the trash", len(status.Pending))
+			updateMigrationStatus(r.client, poolName, status)
+			logger.Info(status.Message)
+			return reconcile.Result{RequeueAfter: migrationRetryInterval}, nil
+		}
+
+		status.Phase = cephv1.PoolMigrationSwapping
+		status.Message = ""
+		updateMigrationStatus(r.client, poolName, status)
+	}
+
+	if err := SwapMigratedPool(r.context, clusterInfo, p.Name, status.TargetPool, p.Spec); err != nil {
+		status.Message = err.Error()
+		if _, ok := err.(*imagesInPoolError); ok {
+			logger.Info(status.Message)
+			status.Phase = cephv1.PoolMigrationMigrating
+			updateMigrationStatus(r.client, poolName, status)
+			return opcontroller.ImmediateRetryResult, nil
+		}
+		updateMigrationStatus(r.client, poolName, status)
+		return opcontroller.ImmediateRetryResult, err
+	}
+	CompleteMigration(status)
+	updateMigrationStatus(r.client, poolName, status)
+	logger.Infof("completed the migration of pool %q to erasure code profile %s", p.Name, status.TargetProfile)
+
+	return reconcile.Result{}, nil
+}
+
+// migrateImages migrates the images that store their data in the pool to the target pool. The images in
+// use or in the trash cannot be migrated and are reported as pending.
+func (r *ReconcileCephBlockPool) migrateImages(clusterInfo *cephclient.ClusterInfo, poolName types.NamespacedName, status *cephv1.PoolMigrationStatus) error {
+	images, err := cephclient.ListImagesInDataPools(r.context, clusterInfo, poolName.Name, status.TargetPool)
+	if err != nil {
+		return err
+	}
+
+	// the images are migrated in place so an image with its data in the target pool may still be migrating
+	toMigrate := map[string]string{}
+	status.Total = len(images)
+	status.Migrated = 0
+	status.Pending = nil
+	for _, image := range images {
+		if image.TrashID != "" {
+			if image.DataPool == status.TargetPool {
+				status.Migrated++
+			} else {
+				status.Pending = append(status.Pending, fmt.Sprintf("%s (%s)", image.ImageSpec, image.TrashID))
+			}
+			continue
+		}
+		state := ""
+		if image.DataPool == status.TargetPool {
+			state, err = cephclient.GetImageMigrationState(r.context, clusterInfo, image.ImageSpec)
+			if err != nil {
+				return err
+			}
+			if state == "" {
+				status.Migrated++
+				continue
+			}
+		}
+		toMigrate[image.ImageSpec] = state
+	}
+
+	for imageSpec, state := range toMigrate {
+		if err := migrateImage(r.context, clusterInfo, imageSpec, state, status.TargetPool); err != nil {
+			if code, ok := exec.ExitStatus(errors.Cause(err)); ok && code == int(syscall.EBUSY) {
+				status.Pending = append(status.Pending, imageSpec)
+				continue
+			}
+			return err
+		}
+		status.Migrated++
+		updateMigrationStatus(r.client, poolName, status)
+	}
+
+	return nil
+}
+
+func migrateImage(context *clusterd.Context, clusterInfo *cephclient.ClusterInfo, imageSpec, state, dataPool string) error {
+	if state == "" {
+		if err := cephclient.PrepareImageMigration(context, clusterInfo, imageSpec, dataPool); err != nil {
+			return err
+		}
+	}
+	if state != cephclient.ImageMigrationExecuted {
+		if err := cephclient.ExecuteImageMigration(context, clusterInfo, imageSpec); err != nil {
+			return err
+		}
+	}
+	return cephclient.CommitImageMigration(context, clusterInfo, imageSpec)
+}
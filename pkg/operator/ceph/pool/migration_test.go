@@ -0,0 +1,315 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pool
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"testing"
+	"time"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/client/clientset/versioned/scheme"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// fakeCephPools simulates the pools, erasure code profiles and images of a cluster
+type fakeCephPools struct {
+	pools      map[string]string
+	profiles   map[string]string
+	images     map[string]string
+	migrations map[string]string
+	busy       map[string]bool
+	// trash are the data pools of the images in the trash by image id
+	trash map[string]string
+	rules map[string]bool
+	// renameRuleErr fails the renames of the crush rules
+	renameRuleErr error
+	commands      []string
+}
+
+func newFakeCephPools() *fakeCephPools {
+	return &fakeCephPools{
+		pools:      map[string]string{"replicapool": "", "ecpool": "ecpool_ecprofile"},
+		profiles:   map[string]string{"ecpool_ecprofile": `{"k":"2","m":"1"}`, "default": `{"plugin":"jerasure"}`},
+		images:     map[string]string{"replicapool/image1": "ecpool", "replicapool/image2": "ecpool", "replicapool/image3": ""},
+		migrations: map[string]string{},
+		busy:       map[string]bool{},
+		trash:      map[string]string{},
+		rules:      map[string]bool{"replicapool": true, "ecpool": true},
+	}
+}
+
+func (f *fakeCephPools) execute(command string, args ...string) (string, error) {
+	logger.Infof("Command: %s %v", command, args)
+	if command == "rbd" {
+		return f.executeRBD(args...)
+	}
+	if args[0] == "osd" && args[1] == "lspools" {
+		pools := []cephclient.CephStoragePoolSummary{}
+		for name := range f.pools {
+			pools = append(pools, cephclient.CephStoragePoolSummary{Name: name})
+		}
+		output, _ := json.Marshal(pools)
+		return string(output), nil
+	}
+	if args[0] == "osd" && args[1] == "pool" {
+		switch args[2] {
+		case "ls":
+			return `[{"pool_name":"replicapool","type":1,"application_metadata":{"rbd":{}}}]`, nil
+		case "get":
+			profile, ok := f.pools[args[3]]
+			if !ok {
+				return "", exec.Command("sh", "-c", "exit 2").Run()
+			}
+			return fmt.Sprintf(`{"pool":%q,"erasure_code_profile":%q}`, args[3], profile), nil
+		case "create":
+			f.pools[args[3]] = args[6]
+			f.rules[args[3]] = true
+		case "delete":
+			delete(f.pools, args[3])
+			delete(f.rules, args[3])
+		case "rename":
+			f.pools[args[4]] = f.pools[args[3]]
+			delete(f.pools, args[3])
+		}
+		f.commands = append(f.commands, fmt.Sprintf("pool %s %s", args[2], args[3]))
+		return "", nil
+	}
+	if args[0] == "osd" && args[1] == "erasure-code-profile" {
+		switch args[2] {
+		case "get":
+			return f.profiles[args[3]], nil
+		case "set":
+			f.profiles[args[3]] = fmt.Sprintf(`{"k":%q,"m":%q}`, args[5][2:], args[6][2:])
+		case "rm":
+			delete(f.profiles, args[3])
+		}
+		f.commands = append(f.commands, fmt.Sprintf("profile %s %s", args[2], args[3]))
+		return "", nil
+	}
+	if args[0] == "osd" && args[1] == "crush" && args[2] == "dump" {
+		rules := []map[string]string{}
+		for name := range f.rules {
+			rules = append(rules, map[string]string{"rule_name": name})
+		}
+		output, _ := json.Marshal(map[string]interface{}{"rules": rules})
+		return string(output), nil
+	}
+	if args[0] == "osd" && args[1] == "crush" && args[2] == "rule" {
+		f.commands = append(f.commands, fmt.Sprintf("rule %s %s", args[3], args[4]))
+		if args[3] == "rename" {
+			if f.renameRuleErr != nil {
+				return "", f.renameRuleErr
+			}
+			delete(f.rules, args[4])
+			f.rules[args[5]] = true
+		}
+	}
+	return "", nil
+}
+
+func (f *fakeCephPools) executeRBD(args ...string) (string, error) {
+	switch args[0] {
+	case "namespace":
+		return "[]", nil
+	case "pool":
+		// the images store their data in the erasure coded pool but are in the replicated pool
+		return `{"images":{"count":0,"snap_count":0}}`, nil
+	case "ls":
+		names := []string{}
+		for spec := range f.images {
+			names = append(names, spec[len("replicapool/"):])
+		}
+		output, _ := json.Marshal(names)
+		return string(output), nil
+	case "trash":
+		images := []map[string]string{}
+		for id := range f.trash {
+			images = append(images, map[string]string{"id": id, "name": "trashed-" + id})
+		}
+		output, _ := json.Marshal(images)
+		return string(output), nil
+	case "info":
+		if args[1] == "--pool" {
+			return fmt.Sprintf(`{"data_pool":%q}`, f.trash[args[4]]), nil
+		}
+		return fmt.Sprintf(`{"data_pool":%q}`, f.images[args[1]]), nil
+	case "status":
+		if state := f.migrations[args[1]]; state != "" {
+			return fmt.Sprintf(`{"migration":{"state":%q}}`, state), nil
+		}
+		return "{}", nil
+	case "migration":
+		switch args[1] {
+		case "prepare":
+			if f.busy[args[4]] {
+				// rbd fails with EBUSY when the image is in use
+				return "", exec.Command("sh", "-c", "exit 16").Run()
+			}
+			f.images[args[4]] = args[3]
+			f.migrations[args[4]] = "prepared"
+		case "execute":
+			f.migrations[args[2]] = cephclient.ImageMigrationExecuted
+		case "commit":
+			delete(f.migrations, args[2])
+		}
+	}
+	return "", nil
+}
+
+func TestSwapMigratedPool(t *testing.T) {
+	f := newFakeCephPools()
+	f.pools["ecpool-migration"] = "ecpool-migration_ecprofile"
+	f.rules["ecpool-migration"] = true
+	f.profiles["ecpool-migration_ecprofile"] = `{"k":"4","m":"2"}`
+	f.images = map[string]string{"replicapool/image1": "ecpool-migration"}
+	f.trash["1234"] = "ecpool"
+	c := &clusterd.Context{Executor: &exectest.MockExecutor{MockExecuteCommandWithOutput: f.execute}}
+	clusterInfo := cephclient.AdminClusterInfo("rook-ceph")
+	spec := cephv1.PoolSpec{ErasureCoded: cephv1.ErasureCodedSpec{DataChunks: 4, CodingChunks: 2}}
+
+	// the pool is not deleted while an image in the trash stores its data in it
+	err := SwapMigratedPool(c, clusterInfo, "ecpool", "ecpool-migration", spec)
+	assert.Error(t, err)
+	_, ok := err.(*imagesInPoolError)
+	assert.True(t, ok)
+	assert.Contains(t, f.pools, "ecpool")
+	assert.Empty(t, f.commands)
+
+	// a failed rename of the crush rule is returned and retried
+	f.trash = map[string]string{}
+	f.renameRuleErr = exec.Command("sh", "-c", "exit 1").Run()
+	err = SwapMigratedPool(c, clusterInfo, "ecpool", "ecpool-migration", spec)
+	assert.Error(t, err)
+	assert.Equal(t, map[string]string{"replicapool": "", "ecpool": "ecpool-migration_ecprofile"}, f.pools)
+	assert.NotContains(t, f.profiles, "ecpool_ecprofile")
+	assert.Contains(t, f.rules, "ecpool-migration")
+
+	f.renameRuleErr = nil
+	f.commands = nil
+	err = SwapMigratedPool(c, clusterInfo, "ecpool", "ecpool-migration", spec)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"rule rename ecpool-migration"}, f.commands)
+	assert.Equal(t, map[string]bool{"replicapool": true, "ecpool": true}, f.rules)
+
+	// the swap is idempotent
+	f.commands = nil
+	err = SwapMigratedPool(c, clusterInfo, "ecpool", "ecpool-migration", spec)
+	assert.NoError(t, err)
+	assert.Empty(t, f.commands)
+}
+
+func TestReconcileMigration(t *testing.T) {
+	ctx := context.TODO()
+	namespace := "rook-ceph"
+	f := newFakeCephPools()
+	f.busy["replicapool/image2"] = true
+	c := &clusterd.Context{Executor: &exectest.MockExecutor{MockExecuteCommandWithOutput: f.execute}}
+	clusterInfo := cephclient.AdminClusterInfo(namespace)
+
+	p := &cephv1.CephBlockPool{
+		ObjectMeta: metav1.ObjectMeta{Name: "ecpool", Namespace: namespace},
+		Spec:       cephv1.PoolSpec{ErasureCoded: cephv1.ErasureCodedSpec{DataChunks: 4, CodingChunks: 2}},
+	}
+	s := scheme.Scheme
+	s.AddKnownTypes(cephv1.SchemeGroupVersion, &cephv1.CephBlockPool{}, &cephv1.CephBlockPoolList{})
+	cl := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects([]runtime.Object{p}...).Build()
+	r := &ReconcileCephBlockPool{client: cl, scheme: s, context: c}
+	name := types.NamespacedName{Name: p.Name, Namespace: namespace}
+	reconcileMigration := func() (time.Duration, error) {
+		assert.NoError(t, cl.Get(ctx, name, p))
+		needed, sourceProfile, err := ErasureCodeMigrationNeeded(c, clusterInfo, p.Name, p.Spec)
+		assert.NoError(t, err)
+		assert.True(t, needed || IsMigrationInProgress(p.Status.Migration))
+		result, err := r.reconcileMigration(clusterInfo, &cephv1.ClusterSpec{}, p, sourceProfile)
+		assert.NoError(t, cl.Get(ctx, name, p))
+		return result.RequeueAfter, err
+	}
+
+	// the migration must be allowed
+	_, err := reconcileMigration()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "set allowMigration to migrate the pool")
+	assert.Nil(t, p.Status)
+
+	// the image in use is pending
+	p.Spec.ErasureCoded.AllowMigration = true
+	assert.NoError(t, cl.Update(ctx, p))
+	requeue, err := reconcileMigration()
+	assert.NoError(t, err)
+	assert.Equal(t, migrationRetryInterval, requeue)
+	status := p.Status.Migration
+	assert.Equal(t, cephv1.PoolMigrationMigrating, status.Phase)
+	assert.Equal(t, "k=2,m=1", status.SourceProfile)
+	assert.Equal(t, "k=4,m=2", status.TargetProfile)
+	assert.Equal(t, "ecpool-migration", status.TargetPool)
+	assert.Equal(t, 2, status.Total)
+	assert.Equal(t, 1, status.Migrated)
+	assert.Equal(t, []string{"replicapool/image2"}, status.Pending)
+	assert.Equal(t, "ecpool-migration", f.images["replicapool/image1"])
+	assert.Contains(t, f.pools, "ecpool")
+
+	// the images in the trash cannot be migrated
+	f.busy = map[string]bool{}
+	f.trash["1234"] = "ecpool"
+	requeue, err = reconcileMigration()
+	assert.NoError(t, err)
+	assert.Equal(t, migrationRetryInterval, requeue)
+	status = p.Status.Migration
+	assert.Equal(t, cephv1.PoolMigrationMigrating, status.Phase)
+	assert.Equal(t, []string{"replicapool/trashed-1234 (1234)"}, status.Pending)
+
+	// the migration goes back to migrating if an image stores its data in the pool when it is swapped
+	status.Phase = cephv1.PoolMigrationSwapping
+	updateMigrationStatus(cl, name, status)
+	requeue, err = reconcileMigration()
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(0), requeue)
+	status = p.Status.Migration
+	assert.Equal(t, cephv1.PoolMigrationMigrating, status.Phase)
+	assert.Contains(t, status.Message, "1 image(s) still store their data in pool \"ecpool\"")
+	assert.Contains(t, f.pools, "ecpool")
+
+	// the pool is swapped once all the images are migrated
+	f.trash = map[string]string{}
+	requeue, err = reconcileMigration()
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(0), requeue)
+	status = p.Status.Migration
+	assert.Equal(t, cephv1.PoolMigrationCompleted, status.Phase)
+	assert.Equal(t, 2, status.Migrated)
+	assert.Empty(t, status.Pending)
+	assert.NotEmpty(t, status.CompletionTime)
+	assert.Equal(t, "ecpool-migration", f.images["replicapool/image2"])
+	assert.Empty(t, f.migrations)
+	assert.Equal(t, map[string]string{"replicapool": "", "ecpool": "ecpool-migration_ecprofile"}, f.pools)
+
+	// no migration is needed anymore
+	needed, _, err := ErasureCodeMigrationNeeded(c, clusterInfo, p.Name, p.Spec)
+	assert.NoError(t, err)
+	assert.False(t, needed)
+}
@@ -0,0 +1,342 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package radosnamespace to manage rbd namespaces of a CephBlockPool
+package radosnamespace
+
+import (
+	"context"
+	"crypto/md5"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/coreos/pkg/capnslog"
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/ceph/cluster/mon"
+	opcontroller "github.com/rook/rook/pkg/operator/ceph/controller"
+	"github.com/rook/rook/pkg/operator/ceph/csi"
+	"github.com/rook/rook/pkg/operator/ceph/reporting"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	controllerName = "ceph-block-pool-rados-namespace-controller"
+)
+
+var logger = capnslog.NewPackageLogger("github.com/rook/rook", controllerName)
+
+var cephBlockPoolRadosNamespaceKind = reflect.TypeOf(cephv1.CephBlockPoolRadosNamespace{}).Name()
+
+// Sets the type meta for the controller main object
+var controllerTypeMeta = metav1.TypeMeta{
+	Kind:       cephBlockPoolRadosNamespaceKind,
+	APIVersion: fmt.Sprintf("%s/%s", cephv1.CustomResourceGroup, cephv1.Version),
+}
+
+// ReconcileCephBlockPoolRadosNamespace reconciles a CephBlockPoolRadosNamespace object
+type ReconcileCephBlockPoolRadosNamespace struct {
+	client      client.Client
+	scheme      *runtime.Scheme
+	context     *clusterd.Context
+	clusterInfo *cephclient.ClusterInfo
+}
+
+// Add creates a new CephBlockPoolRadosNamespace Controller and adds it to the Manager. The Manager will set fields on the Controller
+// and Start it when the Manager is Started.
+func Add(mgr manager.Manager, context *clusterd.Context) error {
+	return add(mgr, newReconciler(mgr, context))
+}
+
+// newReconciler returns a new reconcile.Reconciler
+func newReconciler(mgr manager.Manager, context *clusterd.Context) reconcile.Reconciler {
+	return &ReconcileCephBlockPoolRadosNamespace{
+		client:  mgr.GetClient(),
+		scheme:  mgr.GetScheme(),
+		context: context,
+	}
+}
+
+func add(mgr manager.Manager, r reconcile.Reconciler) error {
+	// Create a new controller
+	c, err := controller.New(controllerName, mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+	logger.Info("successfully started")
+
+	// Watch for changes on the CephBlockPoolRadosNamespace CRD object
+	err = c.Watch(&source.Kind{Type: &cephv1.CephBlockPoolRadosNamespace{TypeMeta: controllerTypeMeta}}, &handler.EnqueueRequestForObject{}, opcontroller.WatchControllerPredicate())
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Reconcile reads that state of the cluster for a CephBlockPoolRadosNamespace object and makes changes based on the state read
+// and what is in the CephBlockPoolRadosNamespace.Spec
+// The Controller will requeue the Request to be processed again if the returned error is non-nil or
+// Result.Requeue is true, otherwise upon completion it will remove the work from the queue.
+func (r *ReconcileCephBlockPoolRadosNamespace) Reconcile(context context.Context, request reconcile.Request) (reconcile.Result, error) {
+	// workaround because the rook logging mechanism is not compatible with the controller-runtime logging interface
+	reconcileResponse, err := r.reconcile(request)
+	if err != nil {
+		logger.Errorf("failed to reconcile %v", err)
+	}
+
+	return reconcileResponse, err
+}
+
+func (r *ReconcileCephBlockPoolRadosNamespace) reconcile(request reconcile.Request) (reconcile.Result, error) {
+	// Fetch the CephBlockPoolRadosNamespace instance
+	cephBlockPoolRadosNamespace := &cephv1.CephBlockPoolRadosNamespace{}
+	err := r.client.Get(context.TODO(), request.NamespacedName, cephBlockPoolRadosNamespace)
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			logger.Debug("cephBlockPoolRadosNamespace resource not found. Ignoring since object must be deleted.")
+			return reconcile.Result{}, nil
+		}
+		// Error reading the object - requeue the request.
+		return reconcile.Result{}, errors.Wrap(err, "failed to get cephBlockPoolRadosNamespace")
+	}
+
+	// Set a finalizer so we can do cleanup before the object goes away
+	err = opcontroller.AddFinalizerIfNotPresent(r.client, cephBlockPoolRadosNamespace)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrap(err, "failed to add finalizer")
+	}
+
+	// The CR was just created, initializing status fields
+	if cephBlockPoolRadosNamespace.Status == nil {
+		updateStatus(r.client, request.NamespacedName, cephv1.ConditionProgressing, nil)
+	}
+
+	// Make sure a CephCluster is present otherwise do nothing
+	_, isReadyToReconcile, cephClusterExists, reconcileResponse := opcontroller.IsReadyToReconcile(r.client, r.context, request.NamespacedName, controllerName)
+	if !isReadyToReconcile {
+		// This handles the case where the Ceph Cluster is gone and we want to delete that CR
+		// We skip the deleteRadosNamespace() function since everything is gone already
+		//
+		// Also, only remove the finalizer if the CephCluster is gone
+		// If not, we should wait for it to be ready
+		// This handles the case where the operator is not ready to accept Ceph command but the cluster exists
+		if !cephBlockPoolRadosNamespace.GetDeletionTimestamp().IsZero() && !cephClusterExists {
+			// The csi config entry is not owned by the cluster, remove it
+			if err := csi.RemoveClusterConfig(r.context.Clientset, buildClusterID(cephBlockPoolRadosNamespace), csi.ConfigMutex); err != nil {
+				return reconcile.Result{}, errors.Wrap(err, "failed to remove the rados namespace from the csi config")
+			}
+
+			// Remove finalizer
+			err = opcontroller.RemoveFinalizer(r.client, cephBlockPoolRadosNamespace)
+			if err != nil {
+				return opcontroller.ImmediateRetryResult, errors.Wrap(err, "failed to remove finalizer")
+			}
+
+			// Return and do not requeue. Successful deletion.
+			return reconcile.Result{}, nil
+		}
+		return reconcileResponse, nil
+	}
+
+	// Populate clusterInfo during each reconcile
+	r.clusterInfo, _, _, err = mon.LoadClusterInfo(r.context, request.NamespacedName.Namespace)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrap(err, "failed to populate cluster info")
+	}
+
+	// DELETE: the CR was deleted
+	if !cephBlockPoolRadosNamespace.GetDeletionTimestamp().IsZero() {
+		logger.Debugf("deleting rados namespace %q", cephBlockPoolRadosNamespace.Name)
+		err := r.deleteRadosNamespace(cephBlockPoolRadosNamespace)
+		if err != nil {
+			return reconcile.Result{}, errors.Wrapf(err, "failed to delete ceph blockpool rados namespace %q", cephBlockPoolRadosNamespace.Name)
+		}
+
+		// Remove finalizer
+		err = opcontroller.RemoveFinalizer(r.client, cephBlockPoolRadosNamespace)
+		if err != nil {
+			return reconcile.Result{}, errors.Wrap(err, "failed to remove finalizer")
+		}
+
+		// Return and do not requeue. Successful deletion.
+		return reconcile.Result{}, nil
+	}
+
+	// validate the rados namespace settings
+	err = validateRadosNamespace(cephBlockPoolRadosNamespace)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrapf(err, "invalid rados namespace %q arguments", cephBlockPoolRadosNamespace.Name)
+	}
+
+	// The pool must exist and be ready before creating a namespace in it
+	cephBlockPool := &cephv1.CephBlockPool{}
+	poolName := types.NamespacedName{Name: cephBlockPoolRadosNamespace.Spec.BlockPoolName, Namespace: request.Namespace}
+	err = r.client.Get(context.TODO(), poolName, cephBlockPool)
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			logger.Infof("waiting for ceph block pool %q to be created before creating rados namespace %q", poolName.Name, cephBlockPoolRadosNamespace.Name)
+			return opcontroller.WaitForRequeueIfCephClusterNotReady, nil
+		}
+		return reconcile.Result{}, errors.Wrapf(err, "failed to get ceph block pool %q", poolName.Name)
+	}
+	if cephBlockPool.Status == nil || cephBlockPool.Status.Phase != cephv1.ConditionReady {
+		logger.Infof("waiting for ceph block pool %q to be ready before creating rados namespace %q", poolName.Name, cephBlockPoolRadosNamespace.Name)
+		return opcontroller.WaitForRequeueIfCephClusterNotReady, nil
+	}
+
+	// Create or Update the rados namespace
+	err = r.createOrUpdateRadosNamespace(cephBlockPoolRadosNamespace)
+	if err != nil {
+		if strings.Contains(err.Error(), opcontroller.UninitializedCephConfigError) {
+			logger.Info(opcontroller.OperatorNotInitializedMessage)
+			return opcontroller.WaitForRequeueIfOperatorNotInitialized, nil
+		}
+		updateStatus(r.client, request.NamespacedName, cephv1.ConditionFailure, nil)
+		return reconcile.Result{}, errors.Wrapf(err, "failed to create or update rados namespace %q", cephBlockPoolRadosNamespace.Name)
+	}
+
+	// Register the rados namespace in the csi config so storage classes can use it
+	err = csi.SaveRadosNamespaceConfig(r.context.Clientset, buildClusterID(cephBlockPoolRadosNamespace), r.clusterInfo, cephBlockPoolRadosNamespace.Name, csi.ConfigMutex)
+	if err != nil {
+		updateStatus(r.client, request.NamespacedName, cephv1.ConditionFailure, nil)
+		return reconcile.Result{}, errors.Wrapf(err, "failed to save the csi config of rados namespace %q", cephBlockPoolRadosNamespace.Name)
+	}
+
+	// Apply the qos limits to the images of the rados namespace
+	err = r.applyQoS(cephBlockPoolRadosNamespace)
+	if err != nil {
+		updateStatus(r.client, request.NamespacedName, cephv1.ConditionFailure, nil)
+		return reconcile.Result{}, errors.Wrapf(err, "failed to apply the qos limits of rados namespace %q", cephBlockPoolRadosNamespace.Name)
+	}
+
+	// Success! Let's update the status
+	updateStatus(r.client, request.NamespacedName, cephv1.ConditionReady, buildStatusInfo(cephBlockPoolRadosNamespace))
+
+	// Return and do not requeue
+	logger.Debug("done reconciling")
+	return reconcile.Result{}, nil
+}
+
+// Create the rados namespace
+func (r *ReconcileCephBlockPoolRadosNamespace) createOrUpdateRadosNamespace(cephBlockPoolRadosNamespace *cephv1.CephBlockPoolRadosNamespace) error {
+	err := cephclient.CreateRadosNamespace(r.context, r.clusterInfo, cephBlockPoolRadosNamespace.Spec.BlockPoolName, cephBlockPoolRadosNamespace.Name)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create ceph blockpool rados namespace %q", cephBlockPoolRadosNamespace.Name)
+	}
+
+	return nil
+}
+
+// Delete the rados namespace
+func (r *ReconcileCephBlockPoolRadosNamespace) deleteRadosNamespace(cephBlockPoolRadosNamespace *cephv1.CephBlockPoolRadosNamespace) error {
+	logger.Infof("deleting ceph blockpool rados namespace object %q", cephBlockPoolRadosNamespace.Name)
+	if err := cephclient.DeleteRadosNamespace(r.context, r.clusterInfo, cephBlockPoolRadosNamespace.Spec.BlockPoolName, cephBlockPoolRadosNamespace.Name); err != nil {
+		return err
+	}
+
+	if err := csi.RemoveClusterConfig(r.context.Clientset, buildClusterID(cephBlockPoolRadosNamespace), csi.ConfigMutex); err != nil {
+		return errors.Wrap(err, "failed to remove the rados namespace from the csi config")
+	}
+
+	logger.Infof("deleted ceph blockpool rados namespace %q", cephBlockPoolRadosNamespace.Name)
+	return nil
+}
+
+func validateRadosNamespace(cephBlockPoolRadosNamespace *cephv1.CephBlockPoolRadosNamespace) error {
+	if cephBlockPoolRadosNamespace.Name == "" {
+		return errors.New("missing name")
+	}
+	if cephBlockPoolRadosNamespace.Namespace == "" {
+		return errors.New("missing namespace")
+	}
+	if cephBlockPoolRadosNamespace.Spec.BlockPoolName == "" {
+		return errors.New("missing blockPoolName")
+	}
+
+	return nil
+}
+
+// buildClusterID returns the clusterID of the rados namespace that storage classes refer to. The
+// namespace name is not unique across pools and clusters, so a hash of all of them is used instead.
+func buildClusterID(cephBlockPoolRadosNamespace *cephv1.CephBlockPoolRadosNamespace) string {
+	clusterID := fmt.Sprintf("%s-%s-block-%s", cephBlockPoolRadosNamespace.Namespace, cephBlockPoolRadosNamespace.Spec.BlockPoolName, cephBlockPoolRadosNamespace.Name)
+	return fmt.Sprintf("%x", md5.Sum([]byte(clusterID)))
+}
+
+func buildStatusInfo(cephBlockPoolRadosNamespace *cephv1.CephBlockPoolRadosNamespace) map[string]string {
+	return map[string]string{"clusterID": buildClusterID(cephBlockPoolRadosNamespace)}
+}
+
+// updateStatus updates an object with a given status
+func updateStatus(client client.Client, name types.NamespacedName, status cephv1.ConditionType, info map[string]string) {
+	cephBlockPoolRadosNamespace := &cephv1.CephBlockPoolRadosNamespace{}
+	if err := client.Get(context.TODO(), name, cephBlockPoolRadosNamespace); err != nil {
+		if kerrors.IsNotFound(err) {
+			logger.Debug("CephBlockPoolRadosNamespace resource not found. Ignoring since object must be deleted.")
+			return
+		}
+		logger.Warningf("failed to retrieve ceph blockpool rados namespace %q to update status to %q. %v", name, status, err)
+		return
+	}
+	if cephBlockPoolRadosNamespace.Status == nil {
+		cephBlockPoolRadosNamespace.Status = &cephv1.CephBlockPoolRadosNamespaceStatus{}
+	}
+
+	cephBlockPoolRadosNamespace.Status.Phase = status
+	if info != nil {
+		cephBlockPoolRadosNamespace.Status.Info = info
+	}
+	if err := reporting.UpdateStatus(client, cephBlockPoolRadosNamespace); err != nil {
+		logger.Errorf("failed to set ceph blockpool rados namespace %q status to %q. %v", name, status, err)
+		return
+	}
+	logger.Debugf("ceph blockpool rados namespace %q status updated to %q", name, status)
+}
+
+// updateQoSStatus records the qos limits that were applied to the images of the rados namespace
+func updateQoSStatus(client client.Client, name types.NamespacedName, qos *cephv1.QoSSpec) error {
+	cephBlockPoolRadosNamespace := &cephv1.CephBlockPoolRadosNamespace{}
+	if err := client.Get(context.TODO(), name, cephBlockPoolRadosNamespace); err != nil {
+		if kerrors.IsNotFound(err) {
+			logger.Debug("CephBlockPoolRadosNamespace resource not found. Ignoring since object must be deleted.")
+			return nil
+		}
+		return errors.Wrapf(err, "failed to retrieve ceph blockpool rados namespace %q to update the qos status", name)
+	}
+	if cephBlockPoolRadosNamespace.Status == nil {
+		cephBlockPoolRadosNamespace.Status = &cephv1.CephBlockPoolRadosNamespaceStatus{}
+	}
+
+	cephBlockPoolRadosNamespace.Status.QoS = qos
+	if err := reporting.UpdateStatus(client, cephBlockPoolRadosNamespace); err != nil {
+		return errors.Wrapf(err, "failed to set the qos status of ceph blockpool rados namespace %q", name)
+	}
+	logger.Debugf("ceph blockpool rados namespace %q qos status updated", name)
+	return nil
+}
@@ -0,0 +1,270 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package radosnamespace
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	rookclient "github.com/rook/rook/pkg/client/clientset/versioned/fake"
+	"github.com/rook/rook/pkg/client/clientset/versioned/scheme"
+	"github.com/rook/rook/pkg/clusterd"
+	"github.com/rook/rook/pkg/operator/ceph/csi"
+	"github.com/rook/rook/pkg/operator/k8sutil"
+	testop "github.com/rook/rook/pkg/operator/test"
+	exectest "github.com/rook/rook/pkg/util/exec/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/tevino/abool"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestValidateRadosNamespace(t *testing.T) {
+	r := &cephv1.CephBlockPoolRadosNamespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a", Namespace: "rook-ceph"}}
+	assert.Error(t, validateRadosNamespace(r))
+
+	r.Spec.BlockPoolName = "replicapool"
+	assert.NoError(t, validateRadosNamespace(r))
+
+	r.Namespace = ""
+	assert.Error(t, validateRadosNamespace(r))
+}
+
+func TestBuildClusterID(t *testing.T) {
+	r := &cephv1.CephBlockPoolRadosNamespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "team-a", Namespace: "rook-ceph"},
+		Spec:       cephv1.CephBlockPoolRadosNamespaceSpec{BlockPoolName: "replicapool"},
+	}
+	clusterID := buildClusterID(r)
+	assert.Equal(t, 32, len(clusterID))
+	assert.Equal(t, clusterID, buildClusterID(r))
+	assert.Equal(t, map[string]string{"clusterID": clusterID}, buildStatusInfo(r))
+
+	// the same namespace in another pool has another clusterID
+	r.Spec.BlockPoolName = "otherpool"
+	assert.NotEqual(t, clusterID, buildClusterID(r))
+}
+
+func TestCephBlockPoolRadosNamespaceController(t *testing.T) {
+	ctx := context.TODO()
+	var (
+		name      = "team-a"
+		namespace = "rook-ceph"
+		iopsLimit = int64(100)
+	)
+
+	radosNamespace := &cephv1.CephBlockPoolRadosNamespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			UID:       types.UID("c47cac40-9bee-4d52-823b-ccd803ba5bfe"),
+		},
+		Spec: cephv1.CephBlockPoolRadosNamespaceSpec{
+			BlockPoolName: "replicapool",
+			QoS:           cephv1.QoSSpec{IOPSLimit: &iopsLimit},
+		},
+		Status: &cephv1.CephBlockPoolRadosNamespaceStatus{},
+	}
+	cephCluster := &cephv1.CephCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      namespace,
+			Namespace: namespace,
+		},
+		Status: cephv1.ClusterStatus{
+			Phase: cephv1.ConditionReady,
+			CephVersion: &cephv1.ClusterVersion{
+				Version: "14.2.9-0",
+			},
+			CephStatus: &cephv1.CephStatus{
+				Health: "HEALTH_OK",
+			},
+		},
+	}
+	cephBlockPool := &cephv1.CephBlockPool{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "replicapool",
+			Namespace: namespace,
+		},
+		Status: &cephv1.CephBlockPoolStatus{
+			Phase: cephv1.ConditionProgressing,
+		},
+	}
+
+	createdNamespaces := []string{}
+	qosImages := []string{}
+	listedImages := 0
+	imageOptions := "[]"
+	executor := &exectest.MockExecutor{
+		MockExecuteCommandWithOutput: func(command string, args ...string) (string, error) {
+			if args[0] == "status" {
+				return `{"fsid":"c47cac40-9bee-4d52-823b-ccd803ba5bfe","health":{"checks":{},"status":"HEALTH_OK"},"pgmap":{"num_pgs":100,"pgs_by_state":[{"state_name":"active+clean","count":100}]}}`, nil
+			}
+			if command == "rbd" && args[0] == "namespace" && args[1] == "create" {
+				createdNamespaces = append(createdNamespaces, args[3]+"/"+args[5])
+			}
+			if command == "rbd" && args[0] == "ls" {
+				listedImages++
+				return `["csi-vol-1","csi-vol-2"]`, nil
+			}
+			if command == "rbd" && args[0] == "config" && args[2] == "list" {
+				return imageOptions, nil
+			}
+			if command == "rbd" && args[0] == "config" && args[2] == "set" {
+				qosImages = append(qosImages, args[3]+" "+args[4]+"="+args[5])
+			}
+			if command == "rbd" && args[0] == "config" && args[2] == "remove" {
+				qosImages = append(qosImages, args[3]+" -"+args[4])
+			}
+			return "", nil
+		},
+	}
+	c := &clusterd.Context{
+		Executor:                   executor,
+		Clientset:                  testop.New(t, 1),
+		RookClientset:              rookclient.NewSimpleClientset(),
+		RequestCancelOrchestration: abool.New(),
+	}
+
+	// Mock clusterInfo
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "rook-ceph-mon",
+			Namespace: namespace,
+		},
+		Data: map[string][]byte{
+			"fsid":         []byte(name),
+			"mon-secret":   []byte("monsecret"),
+			"admin-secret": []byte("adminsecret"),
+		},
+		Type: k8sutil.RookType,
+	}
+	_, err := c.Clientset.CoreV1().Secrets(namespace).Create(ctx, secret, metav1.CreateOptions{})
+	assert.NoError(t, err)
+
+	// Mock the csi config map in the operator namespace
+	os.Setenv(k8sutil.PodNamespaceEnvVar, "rook-ceph-operator")
+	defer os.Unsetenv(k8sutil.PodNamespaceEnvVar)
+	csi.EnableRBD = true
+	defer func() { csi.EnableRBD = false }()
+	csiConfig := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: csi.ConfigName, Namespace: "rook-ceph-operator"},
+		Data:       map[string]string{csi.ConfigKey: "[]"},
+	}
+	_, err = c.Clientset.CoreV1().ConfigMaps("rook-ceph-operator").Create(ctx, csiConfig, metav1.CreateOptions{})
+	assert.NoError(t, err)
+
+	// Register operator types with the runtime scheme.
+	s := scheme.Scheme
+	s.AddKnownTypes(cephv1.SchemeGroupVersion, &cephv1.CephBlockPoolRadosNamespace{}, &cephv1.CephCluster{}, &cephv1.CephClusterList{}, &cephv1.CephBlockPool{})
+	s.AddKnownTypes(v1.SchemeGroupVersion, &v1.PersistentVolume{}, &v1.PersistentVolumeList{})
+
+	// the volume of csi-vol-2 has the qos limits of its storage class
+	csi.RBDDriverName = "rook-ceph.rbd.csi.ceph.com"
+	defer func() { csi.RBDDriverName = "" }()
+	pv := &v1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "pvc-2"},
+		Spec: v1.PersistentVolumeSpec{
+			PersistentVolumeSource: v1.PersistentVolumeSource{
+				CSI: &v1.CSIPersistentVolumeSource{
+					Driver: csi.RBDDriverName,
+					VolumeAttributes: map[string]string{
+						"clusterID":           buildClusterID(radosNamespace),
+						"pool":                "replicapool",
+						"imageName":           "csi-vol-2",
+						csi.QoSIOPSLimitParam: "500",
+					},
+				},
+			},
+		},
+	}
+
+	objects := []runtime.Object{radosNamespace, cephCluster, cephBlockPool, pv}
+	cl := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(objects...).Build()
+	c.Client = cl
+	r := &ReconcileCephBlockPoolRadosNamespace{
+		client:  cl,
+		scheme:  s,
+		context: c,
+	}
+	req := reconcile.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      name,
+			Namespace: namespace,
+		},
+	}
+
+	// the pool is not ready yet
+	res, err := r.Reconcile(ctx, req)
+	assert.NoError(t, err)
+	assert.True(t, res.Requeue)
+	assert.Equal(t, 0, len(createdNamespaces))
+
+	// the pool is ready
+	cephBlockPool.Status.Phase = cephv1.ConditionReady
+	assert.NoError(t, cl.Update(ctx, cephBlockPool))
+	res, err = r.Reconcile(ctx, req)
+	assert.NoError(t, err)
+	assert.False(t, res.Requeue)
+	assert.Equal(t, []string{"replicapool/team-a"}, createdNamespaces)
+	assert.Equal(t, []string{"replicapool/team-a/csi-vol-1 rbd_qos_iops_limit=100"}, qosImages)
+
+	err = cl.Get(ctx, req.NamespacedName, radosNamespace)
+	assert.NoError(t, err)
+	assert.Equal(t, cephv1.ConditionReady, radosNamespace.Status.Phase)
+	clusterID := radosNamespace.Status.Info["clusterID"]
+	assert.Equal(t, buildClusterID(radosNamespace), clusterID)
+	assert.Equal(t, &cephv1.QoSSpec{IOPSLimit: &iopsLimit}, radosNamespace.Status.QoS)
+
+	// the qos limits are removed from the spec, only the limits rook set are removed from the images
+	qosImages = []string{}
+	imageOptions = `[{"name":"rbd_qos_iops_limit","value":"100","source":"image"},
+		{"name":"rbd_qos_bps_limit","value":"4096","source":"image"}]`
+	radosNamespace.Spec.QoS = cephv1.QoSSpec{}
+	assert.NoError(t, cl.Update(ctx, radosNamespace))
+	_, err = r.Reconcile(ctx, req)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"replicapool/team-a/csi-vol-1 -rbd_qos_iops_limit"}, qosImages)
+	radosNamespace = &cephv1.CephBlockPoolRadosNamespace{}
+	err = cl.Get(ctx, req.NamespacedName, radosNamespace)
+	assert.NoError(t, err)
+	assert.Nil(t, radosNamespace.Status.QoS)
+
+	// the images are not listed while there are no limits to apply or to remove
+	listedImages = 0
+	_, err = r.Reconcile(ctx, req)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, listedImages)
+
+	// the rados namespace is in the csi config
+	cm, err := c.Clientset.CoreV1().ConfigMaps("rook-ceph-operator").Get(ctx, csi.ConfigName, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Contains(t, cm.Data[csi.ConfigKey], `"clusterID":"`+clusterID+`"`)
+	assert.Contains(t, cm.Data[csi.ConfigKey], `"radosNamespace":"team-a"`)
+	assert.Contains(t, cm.Data[csi.ConfigKey], `"namespace":"rook-ceph"`)
+
+	// the rados namespace is deleted
+	assert.NoError(t, r.deleteRadosNamespace(radosNamespace))
+	cm, err = c.Clientset.CoreV1().ConfigMaps("rook-ceph-operator").Get(ctx, csi.ConfigName, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "[]", cm.Data[csi.ConfigKey])
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package radosnamespace
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/ceph/csi"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// applyQoS applies the qos limits of the rados namespace to its images. Ceph has no rbd config at the level of
+// a rados namespace, so the limits are set on each image. The images of the volumes with the limits of their
+// StorageClass are skipped, new images get the limits when their volume is provisioned. The applied limits are
+// recorded in the status so only these limits are removed from the images when they are removed from the spec.
+func (r *ReconcileCephBlockPoolRadosNamespace) applyQoS(cephBlockPoolRadosNamespace *cephv1.CephBlockPoolRadosNamespace) error {
+	qos := cephBlockPoolRadosNamespace.Spec.QoS
+	applied := cephv1.QoSSpec{}
+	if cephBlockPoolRadosNamespace.Status != nil && cephBlockPoolRadosNamespace.Status.QoS != nil {
+		applied = *cephBlockPoolRadosNamespace.Status.QoS
+	}
+	if qos.IsEmpty() && applied.IsEmpty() {
+		return nil
+	}
+
+	if err := r.applyImagesQoS(cephBlockPoolRadosNamespace, qos, applied); err != nil {
+		return err
+	}
+
+	if reflect.DeepEqual(qos, applied) {
+		return nil
+	}
+	var status *cephv1.QoSSpec
+	if !qos.IsEmpty() {
+		status = &qos
+	}
+	return updateQoSStatus(r.client, types.NamespacedName{Name: cephBlockPoolRadosNamespace.Name, Namespace: cephBlockPoolRadosNamespace.Namespace}, status)
+}
+
+// applyImagesQoS sets the qos limits on the images of the rados namespace and removes the applied limits that
+// are not set anymore
+func (r *ReconcileCephBlockPoolRadosNamespace) applyImagesQoS(cephBlockPoolRadosNamespace *cephv1.CephBlockPoolRadosNamespace, qos, applied cephv1.QoSSpec) error {
+	poolName := cephBlockPoolRadosNamespace.Spec.BlockPoolName
+	images, err := cephclient.ListImagesInRadosNamespace(r.context, r.clusterInfo, poolName, cephBlockPoolRadosNamespace.Name)
+	if err != nil {
+		return err
+	}
+	if len(images) == 0 {
+		return nil
+	}
+
+	skippedImages, err := r.imagesWithStorageClassQoS(buildClusterID(cephBlockPoolRadosNamespace), poolName)
+	if err != nil {
+		return err
+	}
+
+	for _, image := range images {
+		if skippedImages.Has(image) {
+			logger.Debugf("skipping image %q with the qos limits of its storage class", image)
+			continue
+		}
+		imageSpec := cephclient.GetImageSpec(poolName, cephBlockPoolRadosNamespace.Name, image)
+		if err := cephclient.SetImageQoS(r.context, r.clusterInfo, imageSpec, qos, applied); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// imagesWithStorageClassQoS returns the images of the volumes of the clusterID and pool whose StorageClass
+// has qos limits
+func (r *ReconcileCephBlockPoolRadosNamespace) imagesWithStorageClassQoS(clusterID, poolName string) (sets.String, error) {
+	images := sets.NewString()
+	pvs := &corev1.PersistentVolumeList{}
+	if err := r.client.List(context.TODO(), pvs); err != nil {
+		return images, errors.Wrap(err, "failed to list persistent volumes")
+	}
+	for i := range pvs.Items {
+		volume, err := csi.GetRBDVolume(&pvs.Items[i])
+		if err != nil {
+			// the volume controller does not apply invalid limits either
+			logger.Warningf("%v", err)
+			continue
+		}
+		if volume != nil && volume.ClusterID == clusterID && volume.Pool == poolName && volume.QoS != nil {
+			images.Insert(volume.ImageName)
+		}
+	}
+	return images, nil
+}
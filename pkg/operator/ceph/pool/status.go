@@ -21,6 +21,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/pkg/errors"
 	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
 	"github.com/rook/rook/pkg/operator/ceph/reporting"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
@@ -54,6 +55,55 @@ func updateStatus(client client.Client, poolName types.NamespacedName, status ce
 	logger.Debugf("pool %q status updated to %q", poolName, status)
 }
 
+// updateMigrationStatus updates the erasure code migration status of a pool CR
+func updateMigrationStatus(client client.Client, poolName types.NamespacedName, migration *cephv1.PoolMigrationStatus) {
+	pool := &cephv1.CephBlockPool{}
+	err := client.Get(context.TODO(), poolName, pool)
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			logger.Debug("CephBlockPool resource not found. Ignoring since object must be deleted.")
+			return
+		}
+		logger.Warningf("failed to retrieve pool %q to update migration status. %v", poolName, err)
+		return
+	}
+
+	if pool.Status == nil {
+		pool.Status = &cephv1.CephBlockPoolStatus{}
+	}
+
+	pool.Status.Migration = migration
+	if err := reporting.UpdateStatus(client, pool); err != nil {
+		logger.Warningf("failed to set pool %q migration status. %v", pool.Name, err)
+		return
+	}
+	logger.Debugf("pool %q migration status updated to %q", poolName, migration.Phase)
+}
+
+// updateQoSStatus records the qos limits that were set on a pool
+func updateQoSStatus(client client.Client, poolName types.NamespacedName, qos *cephv1.QoSSpec) error {
+	pool := &cephv1.CephBlockPool{}
+	err := client.Get(context.TODO(), poolName, pool)
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			logger.Debug("CephBlockPool resource not found. Ignoring since object must be deleted.")
+			return nil
+		}
+		return errors.Wrapf(err, "failed to retrieve pool %q to update the qos status", poolName)
+	}
+
+	if pool.Status == nil {
+		pool.Status = &cephv1.CephBlockPoolStatus{}
+	}
+
+	pool.Status.QoS = qos
+	if err := reporting.UpdateStatus(client, pool); err != nil {
+		return errors.Wrapf(err, "failed to set the qos status of pool %q", pool.Name)
+	}
+	logger.Debugf("pool %q qos status updated", poolName)
+	return nil
+}
+
 // updateStatusBucket updates an object with a given status
 func (c *mirrorChecker) updateStatusMirroring(mirrorStatus *cephv1.PoolMirroringStatusSummarySpec, mirrorInfo *cephv1.PoolMirroringInfo, snapSchedStatus []cephv1.SnapshotSchedulesSpec, details string) {
 	blockPool := &cephv1.CephBlockPool{}
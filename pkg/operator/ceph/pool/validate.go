@@ -23,6 +23,7 @@ import (
 	"github.com/rook/rook/pkg/clusterd"
 	"github.com/rook/rook/pkg/daemon/ceph/client"
 	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // ValidatePool Validate the pool arguments
@@ -36,6 +37,10 @@ func ValidatePool(context *clusterd.Context, clusterInfo *client.ClusterInfo, cl
 	if err := ValidatePoolSpec(context, clusterInfo, clusterSpec, &p.Spec); err != nil {
 		return err
 	}
+	// the images are in a replicated pool, an erasure coded pool only stores their data
+	if p.Spec.IsErasureCoded() && !p.Spec.QoS.IsEmpty() {
+		return errors.New("qos limits cannot be set on an erasure coded pool, set them on the replicated pool of the images")
+	}
 	return nil
 }
 
@@ -69,11 +74,14 @@ func ValidatePoolSpec(context *clusterd.Context, clusterInfo *cephclient.Cluster
 		if p.IsErasureCoded() {
 			return errors.New("erasure coded pools are not supported in stretch clusters")
 		}
+		if p.CrushRule != "" {
+			return errors.New("pools in a stretch cluster must use the stretch crush rule")
+		}
 	}
 
 	var crush client.CrushMap
 	var err error
-	if p.FailureDomain != "" || p.CrushRoot != "" {
+	if p.FailureDomain != "" || p.CrushRoot != "" || p.CrushRule != "" {
 		crush, err = client.GetCrushMap(context, clusterInfo)
 		if err != nil {
 			return errors.Wrap(err, "failed to get crush map")
@@ -108,6 +116,20 @@ func ValidatePoolSpec(context *clusterd.Context, clusterInfo *cephclient.Cluster
 		}
 	}
 
+	// validate the crush rule if specified, it is created by a CephCrushRule
+	if p.CrushRule != "" {
+		found := false
+		for _, rule := range crush.Rules {
+			if rule.Name == p.CrushRule {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return errors.Errorf("crush rule %q not found", p.CrushRule)
+		}
+	}
+
 	// validate the crush subdomain if specified
 	if p.Replicated.SubFailureDomain != "" {
 		found := false
@@ -147,6 +169,27 @@ func ValidatePoolSpec(context *clusterd.Context, clusterInfo *cephclient.Cluster
 		}
 	}
 
+	// validate pool compression algorithm and ratio if specified
+	if p.CompressionAlgorithm != "" {
+		switch p.CompressionAlgorithm {
+		case "snappy", "zlib", "zstd", "lz4":
+			break
+		default:
+			return errors.Errorf("unrecognized compression algorithm %q", p.CompressionAlgorithm)
+		}
+	}
+	if p.CompressionRequiredRatio < 0 || p.CompressionRequiredRatio > 1 {
+		return errors.Errorf("compression required ratio %v must be between 0 and 1", p.CompressionRequiredRatio)
+	}
+
+	if err := validateScrubIntervals(p.Scrub); err != nil {
+		return err
+	}
+
+	if p.RecoveryPriority != nil && (*p.RecoveryPriority < -10 || *p.RecoveryPriority > 10) {
+		return errors.Errorf("recovery priority %d must be between -10 and 10", *p.RecoveryPriority)
+	}
+
 	// Validate mirroring settings
 	if p.Mirroring.Enabled {
 		switch p.Mirroring.Mode {
@@ -172,6 +215,24 @@ func ValidatePoolSpec(context *clusterd.Context, clusterInfo *cephclient.Cluster
 	return nil
 }
 
+// validateScrubIntervals validates that the scrub intervals are positive and that the pool is not scrubbed
+// more often when the cluster is busy than when it is idle
+func validateScrubIntervals(scrub cephv1.PoolScrubSpec) error {
+	intervals := []struct {
+		name     string
+		interval *metav1.Duration
+	}{{"min", scrub.MinInterval}, {"max", scrub.MaxInterval}, {"deep", scrub.DeepInterval}}
+	for _, i := range intervals {
+		if i.interval != nil && i.interval.Duration <= 0 {
+			return errors.Errorf("scrub %s interval %q must be positive", i.name, i.interval.Duration)
+		}
+	}
+	if scrub.MinInterval != nil && scrub.MaxInterval != nil && scrub.MinInterval.Duration > scrub.MaxInterval.Duration {
+		return errors.Errorf("scrub min interval %q must not be greater than the max interval %q", scrub.MinInterval.Duration, scrub.MaxInterval.Duration)
+	}
+	return nil
+}
+
 // validateDeviceClasses validates the primary and secondary device classes in the HybridStorageSpec
 func validateDeviceClasses(context *clusterd.Context, clusterInfo *cephclient.ClusterInfo,
 	p *cephv1.PoolSpec) error {
@@ -19,6 +19,7 @@ package pool
 
 import (
 	"testing"
+	"time"
 
 	"github.com/pkg/errors"
 	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
@@ -58,6 +59,19 @@ func TestValidatePool(t *testing.T) {
 	err = ValidatePool(context, clusterInfo, clusterSpec, &p)
 	assert.NotNil(t, err)
 
+	// must not specify qos limits on an erasure coded pool
+	p = cephv1.CephBlockPool{ObjectMeta: metav1.ObjectMeta{Name: "mypool", Namespace: clusterInfo.Namespace}}
+	p.Spec.ErasureCoded.CodingChunks = 1
+	p.Spec.ErasureCoded.DataChunks = 2
+	iops := int64(100)
+	p.Spec.QoS.IOPSLimit = &iops
+	err = ValidatePool(context, clusterInfo, clusterSpec, &p)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "qos limits cannot be set on an erasure coded pool")
+	p.Spec.QoS.IOPSLimit = nil
+	err = ValidatePool(context, clusterInfo, clusterSpec, &p)
+	assert.NoError(t, err)
+
 	// succeed with replication settings
 	p = cephv1.CephBlockPool{ObjectMeta: metav1.ObjectMeta{Name: "mypool", Namespace: clusterInfo.Namespace}}
 	p.Spec.Replicated.Size = 1
@@ -140,6 +154,48 @@ func TestValidatePool(t *testing.T) {
 	err = ValidatePool(context, clusterInfo, clusterSpec, &p)
 	assert.Nil(t, err)
 
+	// compression algorithm and ratio
+	p.Spec.CompressionAlgorithm = "zstd"
+	p.Spec.CompressionRequiredRatio = 0.875
+	err = ValidatePool(context, clusterInfo, clusterSpec, &p)
+	assert.NoError(t, err)
+	p.Spec.CompressionAlgorithm = "gzip"
+	err = ValidatePool(context, clusterInfo, clusterSpec, &p)
+	assert.Error(t, err)
+	p.Spec.CompressionAlgorithm = "zstd"
+	p.Spec.CompressionRequiredRatio = 1.5
+	err = ValidatePool(context, clusterInfo, clusterSpec, &p)
+	assert.Error(t, err)
+	p.Spec.CompressionRequiredRatio = 0
+
+	// scrub intervals and recovery priority
+	{
+		p := cephv1.CephBlockPool{ObjectMeta: metav1.ObjectMeta{Name: "mypool", Namespace: clusterInfo.Namespace}}
+		p.Spec.Replicated.Size = 3
+		p.Spec.Scrub.MinInterval = &metav1.Duration{Duration: 24 * time.Hour}
+		p.Spec.Scrub.MaxInterval = &metav1.Duration{Duration: 7 * 24 * time.Hour}
+		p.Spec.Scrub.DeepInterval = &metav1.Duration{Duration: 14 * 24 * time.Hour}
+		priority := 5
+		p.Spec.RecoveryPriority = &priority
+		err = ValidatePool(context, clusterInfo, clusterSpec, &p)
+		assert.NoError(t, err)
+
+		// the min interval is greater than the max interval
+		p.Spec.Scrub.MinInterval.Duration = 8 * 24 * time.Hour
+		err = ValidatePool(context, clusterInfo, clusterSpec, &p)
+		assert.Error(t, err)
+		p.Spec.Scrub.MinInterval.Duration = 24 * time.Hour
+
+		p.Spec.Scrub.DeepInterval.Duration = 0
+		err = ValidatePool(context, clusterInfo, clusterSpec, &p)
+		assert.EqualError(t, err, `scrub deep interval "0s" must be positive`)
+		p.Spec.Scrub.DeepInterval = nil
+
+		priority = 11
+		err = ValidatePool(context, clusterInfo, clusterSpec, &p)
+		assert.EqualError(t, err, "recovery priority 11 must be between -10 and 10")
+	}
+
 	// Add mirror test mode
 	{
 		p := cephv1.CephBlockPool{ObjectMeta: metav1.ObjectMeta{Name: "mypool", Namespace: clusterInfo.Namespace}}
@@ -185,7 +241,7 @@ func TestValidateCrushProperties(t *testing.T) {
 	executor.MockExecuteCommandWithOutput = func(command string, args ...string) (string, error) {
 		logger.Infof("Command: %s %v", command, args)
 		if args[1] == "crush" && args[2] == "dump" {
-			return `{"types":[{"type_id": 0,"name": "osd"}],"buckets":[{"id": -1,"name":"default"},{"id": -2,"name":"good"}, {"id": -3,"name":"host"}]}`, nil
+			return `{"types":[{"type_id": 0,"name": "osd"}],"buckets":[{"id": -1,"name":"default"},{"id": -2,"name":"good"}, {"id": -3,"name":"host"}],"rules":[{"rule_id":0,"rule_name":"replicated_rule"},{"rule_id":1,"rule_name":"pdu_rule"}]}`, nil
 		}
 		return "", errors.Errorf("unexpected ceph command %q", args)
 	}
@@ -218,6 +274,17 @@ func TestValidateCrushProperties(t *testing.T) {
 	err = ValidatePool(context, clusterInfo, clusterSpec, p)
 	assert.Nil(t, err)
 
+	// fail with a crush rule that doesn't exist
+	p.Spec.CrushRule = "missing_rule"
+	err = ValidatePool(context, clusterInfo, clusterSpec, p)
+	assert.Error(t, err)
+
+	// succeed with a crush rule that exists
+	p.Spec.CrushRule = "pdu_rule"
+	err = ValidatePool(context, clusterInfo, clusterSpec, p)
+	assert.NoError(t, err)
+	p.Spec.CrushRule = ""
+
 	// Success replica size is 4 and replicasPerFailureDomain is 2
 	p.Spec.Replicated.Size = 4
 	p.Spec.Replicated.ReplicasPerFailureDomain = 2
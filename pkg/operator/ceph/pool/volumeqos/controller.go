@@ -0,0 +1,215 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package volumeqos to apply the rbd QoS limits of the StorageClasses and rados namespaces to the provisioned images
+package volumeqos
+
+import (
+	"context"
+
+	"github.com/coreos/pkg/capnslog"
+	"github.com/pkg/errors"
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/clusterd"
+	cephclient "github.com/rook/rook/pkg/daemon/ceph/client"
+	"github.com/rook/rook/pkg/operator/ceph/cluster/mon"
+	opcontroller "github.com/rook/rook/pkg/operator/ceph/controller"
+	"github.com/rook/rook/pkg/operator/ceph/csi"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	controllerName = "ceph-volume-qos-controller"
+)
+
+var logger = capnslog.NewPackageLogger("github.com/rook/rook", controllerName)
+
+// ReconcileVolumeQoS reconciles the PersistentVolumes provisioned by the rbd driver
+type ReconcileVolumeQoS struct {
+	client  client.Client
+	scheme  *runtime.Scheme
+	context *clusterd.Context
+}
+
+// Add creates a new volume QoS Controller and adds it to the Manager. The Manager will set fields on the Controller
+// and Start it when the Manager is Started.
+func Add(mgr manager.Manager, context *clusterd.Context) error {
+	return add(mgr, newReconciler(mgr, context))
+}
+
+// newReconciler returns a new reconcile.Reconciler
+func newReconciler(mgr manager.Manager, context *clusterd.Context) reconcile.Reconciler {
+	return &ReconcileVolumeQoS{
+		client:  mgr.GetClient(),
+		scheme:  mgr.GetScheme(),
+		context: context,
+	}
+}
+
+func add(mgr manager.Manager, r reconcile.Reconciler) error {
+	// Create a new controller
+	c, err := controller.New(controllerName, mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+	logger.Info("successfully started")
+
+	// Watch for the PersistentVolumes of the rbd driver, the limits are applied once the volume is provisioned
+	err = c.Watch(&source.Kind{Type: &corev1.PersistentVolume{}}, &handler.EnqueueRequestForObject{},
+		predicate.Funcs{
+			CreateFunc: func(e event.CreateEvent) bool {
+				return isRBDVolume(e.Object)
+			},
+			UpdateFunc: func(e event.UpdateEvent) bool {
+				return false
+			},
+			DeleteFunc: func(e event.DeleteEvent) bool {
+				return false
+			},
+			GenericFunc: func(e event.GenericEvent) bool {
+				return false
+			},
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func isRBDVolume(obj client.Object) bool {
+	pv, ok := obj.(*corev1.PersistentVolume)
+	if !ok {
+		return false
+	}
+	return pv.Spec.CSI != nil && pv.Spec.CSI.Driver == csi.RBDDriverName
+}
+
+// Reconcile applies the QoS limits of the StorageClass of a PersistentVolume to its image, or the limits of
+// its rados namespace if the StorageClass has none.
+// The Controller will requeue the Request to be processed again if the returned error is non-nil or
+// Result.Requeue is true, otherwise upon completion it will remove the work from the queue.
+func (r *ReconcileVolumeQoS) Reconcile(context context.Context, request reconcile.Request) (reconcile.Result, error) {
+	// workaround because the rook logging mechanism is not compatible with the controller-runtime logging interface
+	reconcileResponse, err := r.reconcile(request)
+	if err != nil {
+		logger.Errorf("failed to reconcile %v", err)
+	}
+
+	return reconcileResponse, err
+}
+
+func (r *ReconcileVolumeQoS) reconcile(request reconcile.Request) (reconcile.Result, error) {
+	// Fetch the PersistentVolume instance
+	pv := &corev1.PersistentVolume{}
+	err := r.client.Get(context.TODO(), request.NamespacedName, pv)
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			logger.Debug("persistent volume resource not found. Ignoring since object must be deleted.")
+			return reconcile.Result{}, nil
+		}
+		// Error reading the object - requeue the request.
+		return reconcile.Result{}, errors.Wrap(err, "failed to get persistent volume")
+	}
+	if !pv.GetDeletionTimestamp().IsZero() {
+		return reconcile.Result{}, nil
+	}
+
+	volume, err := csi.GetRBDVolume(pv)
+	if err != nil {
+		// the parameters will not change, there is no point in requeuing
+		logger.Errorf("failed to apply the qos limits of volume %q. %v", pv.Name, err)
+		return reconcile.Result{}, nil
+	}
+	if volume == nil {
+		return reconcile.Result{}, nil
+	}
+
+	clusterNamespace, radosNamespace, found, err := csi.GetClusterIDLocation(r.context.Clientset, volume.ClusterID)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrapf(err, "failed to find the cluster of volume %q", pv.Name)
+	}
+	if !found {
+		logger.Debugf("clusterID %q of volume %q not found in the csi config", volume.ClusterID, pv.Name)
+		return reconcile.Result{}, nil
+	}
+
+	qos, err := r.volumeQoS(volume, clusterNamespace, radosNamespace)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if qos == nil {
+		logger.Debugf("no qos limits to apply to volume %q", pv.Name)
+		return reconcile.Result{}, nil
+	}
+
+	// Make sure the CephCluster is ready to run ceph commands
+	_, isReadyToReconcile, _, reconcileResponse := opcontroller.IsReadyToReconcile(r.client, r.context, types.NamespacedName{Namespace: clusterNamespace}, controllerName)
+	if !isReadyToReconcile {
+		return reconcileResponse, nil
+	}
+
+	clusterInfo, _, _, err := mon.LoadClusterInfo(r.context, clusterNamespace)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrap(err, "failed to populate cluster info")
+	}
+
+	imageSpec := cephclient.GetImageSpec(volume.Pool, radosNamespace, volume.ImageName)
+	// the limits of the volume are only set, the rados namespace removes the limits it applied
+	if err := cephclient.SetImageQoS(r.context, clusterInfo, imageSpec, *qos, cephv1.QoSSpec{}); err != nil {
+		return reconcile.Result{}, errors.Wrapf(err, "failed to apply the qos limits of volume %q", pv.Name)
+	}
+
+	logger.Debugf("applied the qos limits of volume %q to image %q", pv.Name, imageSpec)
+	return reconcile.Result{}, nil
+}
+
+// volumeQoS returns the limits of the StorageClass of the volume, otherwise the limits of its rados namespace.
+// It returns nil if there are none, the image inherits the limits of its pool in that case.
+func (r *ReconcileVolumeQoS) volumeQoS(volume *csi.RBDVolume, clusterNamespace, radosNamespace string) (*cephv1.QoSSpec, error) {
+	if volume.QoS != nil {
+		return volume.QoS, nil
+	}
+	if radosNamespace == "" {
+		return nil, nil
+	}
+
+	// the name of the CephBlockPoolRadosNamespace is the name of the rados namespace
+	cephBlockPoolRadosNamespace := &cephv1.CephBlockPoolRadosNamespace{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: radosNamespace, Namespace: clusterNamespace}, cephBlockPoolRadosNamespace)
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "failed to get ceph blockpool rados namespace %q", radosNamespace)
+	}
+	if cephBlockPoolRadosNamespace.Spec.BlockPoolName != volume.Pool || cephBlockPoolRadosNamespace.Spec.QoS.IsEmpty() {
+		return nil, nil
+	}
+	return &cephBlockPoolRadosNamespace.Spec.QoS, nil
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volumeqos
+
+import (
+	"testing"
+
+	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
+	"github.com/rook/rook/pkg/client/clientset/versioned/scheme"
+	"github.com/rook/rook/pkg/operator/ceph/csi"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestVolumeQoS(t *testing.T) {
+	namespaceIOPS := int64(100)
+	storageClassIOPS := int64(500)
+	radosNamespace := &cephv1.CephBlockPoolRadosNamespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "team-a", Namespace: "rook-ceph"},
+		Spec: cephv1.CephBlockPoolRadosNamespaceSpec{
+			BlockPoolName: "replicapool",
+			QoS:           cephv1.QoSSpec{IOPSLimit: &namespaceIOPS},
+		},
+	}
+	s := scheme.Scheme
+	s.AddKnownTypes(cephv1.SchemeGroupVersion, &cephv1.CephBlockPoolRadosNamespace{})
+	cl := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects([]runtime.Object{radosNamespace}...).Build()
+	r := &ReconcileVolumeQoS{client: cl, scheme: s}
+
+	// the limits of the storage class take precedence
+	volume := &csi.RBDVolume{Pool: "replicapool", ImageName: "csi-vol-1", QoS: &cephv1.QoSSpec{IOPSLimit: &storageClassIOPS}}
+	qos, err := r.volumeQoS(volume, "rook-ceph", "team-a")
+	assert.NoError(t, err)
+	assert.Equal(t, storageClassIOPS, *qos.IOPSLimit)
+
+	// the limits of the rados namespace
+	volume.QoS = nil
+	qos, err = r.volumeQoS(volume, "rook-ceph", "team-a")
+	assert.NoError(t, err)
+	assert.Equal(t, namespaceIOPS, *qos.IOPSLimit)
+
+	// the image inherits the limits of the pool
+	qos, err = r.volumeQoS(volume, "rook-ceph", "")
+	assert.NoError(t, err)
+	assert.Nil(t, qos)
+
+	// the rados namespace of another pool
+	volume.Pool = "otherpool"
+	qos, err = r.volumeQoS(volume, "rook-ceph", "team-a")
+	assert.NoError(t, err)
+	assert.Nil(t, qos)
+}
+
+func TestIsRBDVolume(t *testing.T) {
+	csi.RBDDriverName = "rook-ceph.rbd.csi.ceph.com"
+	defer func() { csi.RBDDriverName = "" }()
+
+	pv := &corev1.PersistentVolume{}
+	assert.False(t, isRBDVolume(pv))
+	pv.Spec.CSI = &corev1.CSIPersistentVolumeSource{Driver: "rook-ceph.cephfs.csi.ceph.com"}
+	assert.False(t, isRBDVolume(pv))
+	pv.Spec.CSI.Driver = csi.RBDDriverName
+	assert.True(t, isRBDVolume(pv))
+	assert.False(t, isRBDVolume(&corev1.Pod{}))
+}
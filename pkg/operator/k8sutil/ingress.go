@@ -0,0 +1,55 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8sutil
+
+import (
+	"context"
+	"fmt"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// CreateOrUpdateIngress creates an ingress or updates the ingress declaratively if it already exists.
+func CreateOrUpdateIngress(clientset kubernetes.Interface, ingressDefinition *networkingv1.Ingress) (*networkingv1.Ingress, error) {
+	ctx := context.TODO()
+	name := ingressDefinition.Name
+	ingresses := clientset.NetworkingV1().Ingresses(ingressDefinition.Namespace)
+	logger.Debugf("creating ingress %s", name)
+
+	i, err := ingresses.Create(ctx, ingressDefinition, metav1.CreateOptions{})
+	if err != nil {
+		if !errors.IsAlreadyExists(err) {
+			return nil, fmt.Errorf("failed to create ingress %s. %+v", name, err)
+		}
+		existing, err := ingresses.Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get existing ingress %s in order to update. %+v", name, err)
+		}
+		// ResourceVersion required to update ingresses to prevent race conditions
+		ingressDefinition.ResourceVersion = existing.ResourceVersion
+		i, err = ingresses.Update(ctx, ingressDefinition, metav1.UpdateOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to update ingress %s. %+v", name, err)
+		}
+	} else {
+		logger.Debugf("created ingress %s", i.Name)
+	}
+	return i, nil
+}
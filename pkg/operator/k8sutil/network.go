@@ -23,8 +23,10 @@ import (
 	"strings"
 
 	netapi "github.com/k8snetworkplumbingwg/network-attachment-definition-client/pkg/apis/k8s.cni.cncf.io/v1"
+	"github.com/pkg/errors"
 	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
 	"github.com/rook/rook/pkg/apis/rook.io"
+	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -33,6 +35,13 @@ const (
 	publicNetworkSelectorKeyName = "public"
 	// clusterNetworkSelectorKeyName is the network selector key for the ceph cluster network
 	clusterNetworkSelectorKeyName = "cluster"
+
+	// networksAnnotation is the annotation requesting the network attachments of a pod to Multus
+	networksAnnotation = "k8s.v1.cni.cncf.io/networks"
+	// networkStatusAnnotation is the annotation where Multus reports the networks attached to a pod
+	networkStatusAnnotation = "k8s.v1.cni.cncf.io/network-status"
+	// legacyNetworkStatusAnnotation is the network status annotation of Multus before v3.7
+	legacyNetworkStatusAnnotation = "k8s.v1.cni.cncf.io/networks-status"
 )
 
 // NetworkAttachmentConfig represents the configuration of the NetworkAttachmentDefinitions object
@@ -83,7 +92,10 @@ func ApplyMultus(net cephv1.NetworkSpec, objectMeta *metav1.ObjectMeta) error {
 
 		var isExcluded bool
 		for _, clusterNetworkApps := range getClusterNetworkApps() {
-			isExcluded = strings.Contains(objectMeta.Labels["app"], clusterNetworkApps)
+			if strings.Contains(objectMeta.Labels["app"], clusterNetworkApps) {
+				isExcluded = true
+				break
+			}
 		}
 		if isExcluded {
 			v = append(v, string(ns))
@@ -107,13 +119,138 @@ func ApplyMultus(net cephv1.NetworkSpec, objectMeta *metav1.ObjectMeta) error {
 	}
 
 	t := rook.Annotations{
-		"k8s.v1.cni.cncf.io/networks": networks,
+		networksAnnotation: networks,
 	}
 	t.ApplyToObjectMeta(objectMeta)
 
 	return nil
 }
 
+// ApplyNetworkSettings applies the network of the cluster to the pod of a Ceph daemon or of a client of the
+// Ceph daemons. With Multus the pod is attached to the public network, and to the cluster network for the OSDs.
+func ApplyNetworkSettings(net cephv1.NetworkSpec, objectMeta *metav1.ObjectMeta, podSpec *v1.PodSpec) error {
+	if net.IsHost() {
+		podSpec.DNSPolicy = v1.DNSClusterFirstWithHostNet
+	} else if net.IsMultus() {
+		return ApplyMultus(net, objectMeta)
+	}
+	return nil
+}
+
+// ApplyMultusPublicNetworks attaches a pod shared by several clusters, like the CSI plugins, to the public
+// network of each cluster. The selectors without a namespace are qualified with the namespace of their cluster
+// since the pod may run in another namespace. Returns whether a network was attached.
+func ApplyMultusPublicNetworks(clusters []cephv1.CephCluster, objectMeta *metav1.ObjectMeta) (bool, error) {
+	v := []string{}
+	for _, cluster := range clusters {
+		if !cluster.Spec.Network.IsMultus() {
+			continue
+		}
+		selector, ok := cluster.Spec.Network.Selectors[publicNetworkSelectorKeyName]
+		if !ok {
+			continue
+		}
+		selection, err := parseNetworkSelection(selector, cluster.Namespace)
+		if err != nil {
+			return false, errors.Wrapf(err, "failed to parse public network of CephCluster %q", cluster.Name)
+		}
+		network, err := json.Marshal(selection)
+		if err != nil {
+			return false, errors.Wrapf(err, "failed to marshal public network of CephCluster %q", cluster.Name)
+		}
+		if !contains(v, string(network)) {
+			v = append(v, string(network))
+		}
+	}
+	if len(v) == 0 {
+		return false, nil
+	}
+
+	// Sort network strings so that pods/deployments won't need updated in a loop if nothing changes
+	sort.Strings(v)
+	t := rook.Annotations{
+		networksAnnotation: "[" + strings.Join(v, ", ") + "]",
+	}
+	t.ApplyToObjectMeta(objectMeta)
+
+	return true, nil
+}
+
+// IsAttachedToPublicNetwork returns whether Multus attached the pod to the public network of the cluster
+func IsAttachedToPublicNetwork(net cephv1.NetworkSpec, clusterNamespace string, pod *v1.Pod) (bool, error) {
+	selector, ok := net.Selectors[publicNetworkSelectorKeyName]
+	if !ok {
+		return true, nil
+	}
+	selection, err := parseNetworkSelection(selector, clusterNamespace)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to parse public network")
+	}
+	public := fmt.Sprintf("%s/%s", selection["namespace"], selection["name"])
+
+	status, ok := pod.Annotations[networkStatusAnnotation]
+	if !ok {
+		status, ok = pod.Annotations[legacyNetworkStatusAnnotation]
+	}
+	if !ok {
+		return false, nil
+	}
+	var networks []struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal([]byte(status), &networks); err != nil {
+		return false, errors.Wrapf(err, "failed to parse network status of pod %q", pod.Name)
+	}
+	for _, network := range networks {
+		name := network.Name
+		if !strings.Contains(name, "/") {
+			name = fmt.Sprintf("%s/%s", pod.Namespace, name)
+		}
+		if name == public {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// parseNetworkSelection converts a network selector in the short or JSON syntax of Multus to the JSON syntax,
+// with the given namespace when the selector does not set one
+func parseNetworkSelection(selector, namespace string) (map[string]interface{}, error) {
+	selection := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(selector), &selection); err != nil {
+		// short syntax: <namespace>/<name>@<interface>
+		selection = map[string]interface{}{}
+		name := selector
+		if i := strings.LastIndex(name, "@"); i >= 0 {
+			selection["interface"] = name[i+1:]
+			name = name[:i]
+		}
+		if i := strings.Index(name, "/"); i >= 0 {
+			selection["namespace"] = name[:i]
+			name = name[i+1:]
+		}
+		selection["name"] = name
+	}
+
+	if name, ok := selection["name"].(string); !ok || name == "" {
+		return nil, errors.Errorf("no network name in selector %q", selector)
+	}
+	if ns, ok := selection["namespace"].(string); !ok || ns == "" {
+		selection["namespace"] = namespace
+	}
+	return selection, nil
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
 // getClusterNetworkApps returns the list of ceph apps that utilize cluster network
 func getClusterNetworkApps() []string {
 	return []string{"osd"}
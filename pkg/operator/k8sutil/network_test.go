@@ -22,6 +22,7 @@ import (
 	netapi "github.com/k8snetworkplumbingwg/network-attachment-definition-client/pkg/apis/k8s.cni.cncf.io/v1"
 	cephv1 "github.com/rook/rook/pkg/apis/ceph.rook.io/v1"
 	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -170,6 +171,75 @@ func TestApplyMultus(t *testing.T) {
 	})
 }
 
+func TestApplyNetworkSettings(t *testing.T) {
+	t.Run("host network", func(t *testing.T) {
+		objMeta := metav1.ObjectMeta{}
+		podSpec := v1.PodSpec{}
+		assert.NoError(t, ApplyNetworkSettings(cephv1.NetworkSpec{HostNetwork: true}, &objMeta, &podSpec))
+		assert.Equal(t, v1.DNSClusterFirstWithHostNet, podSpec.DNSPolicy)
+		assert.Empty(t, objMeta.Annotations)
+	})
+
+	t.Run("multus", func(t *testing.T) {
+		net := cephv1.NetworkSpec{Provider: "multus", Selectors: map[string]string{"public": "public-net", "cluster": "cluster-net"}}
+		objMeta := metav1.ObjectMeta{Labels: map[string]string{"app": "rook-ceph-osd-prepare"}}
+		podSpec := v1.PodSpec{}
+		assert.NoError(t, ApplyNetworkSettings(net, &objMeta, &podSpec))
+		assert.Equal(t, "cluster-net, public-net", objMeta.Annotations[networksAnnotation])
+		assert.Equal(t, v1.DNSPolicy(""), podSpec.DNSPolicy)
+	})
+}
+
+func TestApplyMultusPublicNetworks(t *testing.T) {
+	newCluster := func(namespace string, net cephv1.NetworkSpec) cephv1.CephCluster {
+		return cephv1.CephCluster{ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: namespace}, Spec: cephv1.ClusterSpec{Network: net}}
+	}
+
+	// no cluster uses multus
+	objMeta := metav1.ObjectMeta{}
+	applied, err := ApplyMultusPublicNetworks([]cephv1.CephCluster{newCluster("a", cephv1.NetworkSpec{})}, &objMeta)
+	assert.NoError(t, err)
+	assert.False(t, applied)
+	assert.Empty(t, objMeta.Annotations)
+
+	// the public networks of all the clusters are attached, qualified with the namespace of their cluster
+	clusters := []cephv1.CephCluster{
+		newCluster("b", cephv1.NetworkSpec{Provider: "multus", Selectors: map[string]string{"public": "public-net@net1", "cluster": "cluster-net"}}),
+		newCluster("a", cephv1.NetworkSpec{Provider: "multus", Selectors: map[string]string{"public": `{"name": "public-net", "namespace": "net-ns"}`}}),
+		newCluster("c", cephv1.NetworkSpec{}),
+	}
+	applied, err = ApplyMultusPublicNetworks(clusters, &objMeta)
+	assert.NoError(t, err)
+	assert.True(t, applied)
+	assert.Equal(t, `[{"interface":"net1","name":"public-net","namespace":"b"}, {"name":"public-net","namespace":"net-ns"}]`, objMeta.Annotations[networksAnnotation])
+}
+
+func TestIsAttachedToPublicNetwork(t *testing.T) {
+	net := cephv1.NetworkSpec{Provider: "multus", Selectors: map[string]string{"public": "public-net"}}
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "rook-ceph-system"}}
+
+	// multus did not attach any network
+	attached, err := IsAttachedToPublicNetwork(net, "rook-ceph", pod)
+	assert.NoError(t, err)
+	assert.False(t, attached)
+
+	// the pod is attached to a network of the same name in another namespace
+	pod.Annotations = map[string]string{networkStatusAnnotation: `[{"name": "cbr0", "default": true}, {"name": "public-net"}]`}
+	attached, err = IsAttachedToPublicNetwork(net, "rook-ceph", pod)
+	assert.NoError(t, err)
+	assert.False(t, attached)
+
+	pod.Annotations = map[string]string{legacyNetworkStatusAnnotation: `[{"name": "cbr0", "default": true}, {"name": "rook-ceph/public-net"}]`}
+	attached, err = IsAttachedToPublicNetwork(net, "rook-ceph", pod)
+	assert.NoError(t, err)
+	assert.True(t, attached)
+
+	// clusters without a public network selector use the default network
+	attached, err = IsAttachedToPublicNetwork(cephv1.NetworkSpec{Provider: "multus"}, "rook-ceph", &v1.Pod{})
+	assert.NoError(t, err)
+	assert.True(t, attached)
+}
+
 func TestGetNetworkAttachmentConfig(t *testing.T) {
 	dummyNetAttachDef := netapi.NetworkAttachmentDefinition{
 		Spec: netapi.NetworkAttachmentDefinitionSpec{
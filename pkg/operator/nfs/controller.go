@@ -33,6 +33,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/pointer"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -45,6 +46,9 @@ const (
 	nfsConfigMapPath = "/nfs-ganesha/config"
 	nfsPort          = 2049
 	rpcPort          = 111
+	// recoveryDirName is the directory of the first export where ganesha keeps the state of the clients,
+	// for the clients to reclaim their state from the replica that takes over the exports
+	recoveryDirName = ".ganesha-recovery"
 )
 
 type NFSServerReconciler struct {
@@ -110,6 +114,15 @@ func (r *NFSServerReconciler) Reconcile(context context.Context, req ctrl.Reques
 		return reconcile.Result{}, err
 	}
 
+	if err := r.validateSharedClaims(context, instance); err != nil {
+		r.Recorder.Eventf(instance, corev1.EventTypeWarning, nfsv1alpha1.EventFailed, "Invalid NFSServer exports: %+v", err)
+		r.Log.Errorf("Invalid NFSServer exports: %+v", err)
+		// the claims can still be created or fixed, the exports are validated again on the next reconcile
+		instance.Status.State = nfsv1alpha1.StatePending
+		instance.Status.Message = err.Error()
+		return reconcile.Result{}, err
+	}
+
 	if err := r.reconcileNFSServerConfig(context, instance); err != nil {
 		r.Recorder.Eventf(instance, corev1.EventTypeWarning, nfsv1alpha1.EventFailed, "Failed reconciling nfsserver config: %+v", err)
 		r.Log.Errorf("Error reconciling nfsserver config: %+v", err)
@@ -128,14 +141,110 @@ func (r *NFSServerReconciler) Reconcile(context context.Context, req ctrl.Reques
 		return reconcile.Result{}, client.IgnoreNotFound(err)
 	}
 
-	switch int(sts.Status.ReadyReplicas) {
-	case instance.Spec.Replicas:
+	if err := r.updateReplicasStatus(context, instance); err != nil {
+		r.Log.Errorf("Error updating the replicas status of nfsserver: %+v", err)
+	}
+
+	// only the active replica is ready, the standbys wait for it to fail
+	if sts.Status.ReadyReplicas > 0 {
 		instance.Status.State = nfsv1alpha1.StateRunning
+		instance.Status.Message = ""
+		if instance.Status.StandbyReplicas < instance.Spec.Replicas-1 {
+			instance.Status.Message = fmt.Sprintf("%d of %d standby replicas are running", instance.Status.StandbyReplicas, instance.Spec.Replicas-1)
+		}
 		return reconcile.Result{}, nil
-	default:
-		instance.Status.State = nfsv1alpha1.StatePending
-		return reconcile.Result{RequeueAfter: 10 * time.Second}, nil
 	}
+
+	instance.Status.State = nfsv1alpha1.StatePending
+	return reconcile.Result{RequeueAfter: 10 * time.Second}, nil
+}
+
+// recreateStatefulSetIfImmutable deletes the statefulset of the server when an immutable setting changed, like the
+// pod management policy of the servers created before the standby replicas. The pods are orphaned and adopted by
+// the new statefulset, which is created once the old one is gone.
+func (r *NFSServerReconciler) recreateStatefulSetIfImmutable(ctx context.Context, desired *appsv1.StatefulSet) error {
+	current := &appsv1.StatefulSet{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: desired.Name, Namespace: desired.Namespace}, current); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if !current.DeletionTimestamp.IsZero() {
+		return fmt.Errorf("waiting for statefulset %q to be deleted before creating it again", current.Name)
+	}
+	if current.Spec.PodManagementPolicy == desired.Spec.PodManagementPolicy {
+		return nil
+	}
+
+	r.Log.Infof("recreating statefulset %q to change its pod management policy from %q to %q", current.Name, current.Spec.PodManagementPolicy, desired.Spec.PodManagementPolicy)
+	orphan := metav1.DeletePropagationOrphan
+	if err := r.Client.Delete(ctx, current, &client.DeleteOptions{PropagationPolicy: &orphan}); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: desired.Name, Namespace: desired.Namespace}, current); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	return fmt.Errorf("waiting for statefulset %q to be deleted before creating it again", current.Name)
+}
+
+// validateSharedClaims checks that the claims of the exports can be mounted by all the replicas
+func (r *NFSServerReconciler) validateSharedClaims(ctx context.Context, cr *nfsv1alpha1.NFSServer) error {
+	if cr.Spec.Replicas <= 1 {
+		return nil
+	}
+
+	for _, export := range cr.Spec.Exports {
+		claimName := export.PersistentVolumeClaim.ClaimName
+		pvc, err := r.Context.Clientset.CoreV1().PersistentVolumeClaims(cr.Namespace).Get(ctx, claimName, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to get claim %q of export %q: %v", claimName, export.Name, err)
+		}
+
+		shared := false
+		for _, mode := range pvc.Spec.AccessModes {
+			if mode == corev1.ReadWriteMany {
+				shared = true
+			}
+		}
+		if !shared {
+			return fmt.Errorf("claim %q of export %q must be ReadWriteMany to be shared by the %d replicas", claimName, export.Name, cr.Spec.Replicas)
+		}
+	}
+
+	return nil
+}
+
+// updateReplicasStatus reports the replica serving the exports and the standbys
+func (r *NFSServerReconciler) updateReplicasStatus(ctx context.Context, cr *nfsv1alpha1.NFSServer) error {
+	active, err := activeReplica(ctx, r.Context.Clientset, cr.Namespace, activeLeaseName(cr))
+	if err != nil {
+		return fmt.Errorf("failed to get the active replica: %v", err)
+	}
+
+	selector := metav1.FormatLabelSelector(&metav1.LabelSelector{MatchLabels: newLabels(cr)})
+	pods, err := r.Context.Clientset.CoreV1().Pods(cr.Namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return fmt.Errorf("failed to list the replicas: %v", err)
+	}
+
+	standby := 0
+	for _, pod := range pods.Items {
+		if pod.Status.Phase != corev1.PodRunning || pod.DeletionTimestamp != nil {
+			continue
+		}
+		// a single replica does not hold the lease, it serves the exports while it runs
+		if cr.Spec.Replicas <= 1 && active == "" {
+			active = pod.Name
+		}
+		if pod.Name != active {
+			standby++
+		}
+	}
+
+	if active != cr.Status.ActiveReplica {
+		r.Log.Infof("replica %q of nfsserver %q is active", active, cr.Name)
+	}
+	cr.Status.ActiveReplica = active
+	cr.Status.StandbyReplicas = standby
+	return nil
 }
 
 func (r *NFSServerReconciler) reconcileNFSServerConfig(ctx context.Context, cr *nfsv1alpha1.NFSServer) error {
@@ -179,6 +288,13 @@ NFS_Core_Param {
 	fsid_device = true;
 }
 `
+	if len(cr.Spec.Exports) > 0 {
+		nfsGaneshaAdditionalConfig += `
+NFSv4 {
+	RecoveryRoot = ` + path.Join("/", cr.Spec.Exports[0].PersistentVolumeClaim.ClaimName, recoveryDirName) + `;
+}
+`
+	}
 
 	exportsList = append(exportsList, nfsGaneshaAdditionalConfig)
 	configdata := make(map[string]string)
@@ -236,17 +352,28 @@ func (r *NFSServerReconciler) reconcileNFSServer(ctx context.Context, cr *nfsv1a
 		r.Recorder.Eventf(cr, corev1.EventTypeNormal, nfsv1alpha1.EventUpdated, "%s nfs-server service: %s", strings.Title(string(svcop)), svc.Name)
 	}
 
-	sts, err := newStatefulSetForNFSServer(cr, r.Context.Clientset, ctx)
+	desired, err := newStatefulSetForNFSServer(cr, r.Context.Clientset, ctx)
 	if err != nil {
 		return fmt.Errorf("unable to generate the NFS StatefulSet spec: %v", err)
 	}
 
+	if err := r.recreateStatefulSetIfImmutable(ctx, desired); err != nil {
+		return err
+	}
+
+	// the existing statefulset is read into sts, the template is applied in the mutate func
+	sts := &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: desired.Name, Namespace: desired.Namespace}}
 	stsop, err := controllerutil.CreateOrUpdate(ctx, r.Client, sts, func() error {
 		if sts.ObjectMeta.CreationTimestamp.IsZero() {
 			sts.Spec.Selector = &metav1.LabelSelector{
 				MatchLabels: newLabels(cr),
 			}
+			sts.Spec.ServiceName = desired.Spec.ServiceName
+			sts.Spec.PodManagementPolicy = desired.Spec.PodManagementPolicy
 		}
+		sts.Labels = desired.Labels
+		sts.Spec.Replicas = desired.Spec.Replicas
+		sts.Spec.Template = desired.Spec.Template
 
 		if err := controllerutil.SetControllerReference(cr, sts, r.Scheme); err != nil {
 			return err
@@ -27,6 +27,7 @@ import (
 	"github.com/rook/rook/pkg/clusterd"
 	"github.com/rook/rook/pkg/operator/k8sutil"
 	"github.com/rook/rook/pkg/operator/test"
+	"github.com/stretchr/testify/assert"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -307,3 +308,67 @@ func TestNFSServerReconciler_Reconcile(t *testing.T) {
 		})
 	}
 }
+
+func TestReconcileNFSServerStatefulSet(t *testing.T) {
+	os.Setenv(k8sutil.PodNamespaceEnvVar, "rook-system")
+	defer os.Unsetenv(k8sutil.PodNamespaceEnvVar)
+	os.Setenv(k8sutil.PodNameEnvVar, "rook-operator")
+	defer os.Unsetenv(k8sutil.PodNameEnvVar)
+
+	ctx := context.TODO()
+	clientset := test.New(t, 3)
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "rook-operator", Namespace: "rook-system"},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "mypodContainer", Image: "rook/test"}}},
+	}
+	_, err := clientset.CoreV1().Pods(pod.Namespace).Create(ctx, pod, metav1.CreateOptions{})
+	assert.NoError(t, err)
+
+	name := types.NamespacedName{Name: "nfs-server", Namespace: "nfs-server"}
+	cr := newCustomResource(name).WithExports("share1", "ReadWrite", "none", "test-claim").WithState(nfsv1alpha1.StateInitializing).Generate()
+	scheme := clientgoscheme.Scheme
+	scheme.AddKnownTypes(nfsv1alpha1.SchemeGroupVersion, cr)
+
+	// a server created before the standby replicas, with the default pod management policy and no lease
+	existing := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: name.Name, Namespace: name.Namespace, Labels: newLabels(cr)},
+		Spec: appsv1.StatefulSetSpec{
+			Selector:            &metav1.LabelSelector{MatchLabels: newLabels(cr)},
+			PodManagementPolicy: appsv1.OrderedReadyPodManagement,
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{Containers: []corev1.Container{
+					{Name: "nfs-server", Image: "rook/test", Args: []string{"nfs", "server"}},
+				}},
+			},
+		},
+	}
+	fc := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(cr, existing).Build()
+	r := &NFSServerReconciler{
+		Context:  &clusterd.Context{Clientset: clientset},
+		Client:   fc,
+		Scheme:   scheme,
+		Log:      logger,
+		Recorder: record.NewFakeRecorder(10),
+	}
+
+	// the statefulset is recreated with the parallel pod management policy
+	assert.NoError(t, r.reconcileNFSServer(ctx, cr))
+	sts := &appsv1.StatefulSet{}
+	assert.NoError(t, fc.Get(ctx, name, sts))
+	assert.Equal(t, appsv1.ParallelPodManagement, sts.Spec.PodManagementPolicy)
+	// a single replica does not wait for the lease
+	assert.Equal(t, []string{"nfs", "server", "--ganeshaConfigPath=" + nfsConfigMapPath + "/" + cr.Name}, sts.Spec.Template.Spec.Containers[0].Args)
+
+	// the template of an existing statefulset is updated with the standby replicas
+	cr.Spec.Replicas = 2
+	assert.NoError(t, r.reconcileNFSServer(ctx, cr))
+	sts = &appsv1.StatefulSet{}
+	assert.NoError(t, fc.Get(ctx, name, sts))
+	assert.Equal(t, int32(2), *sts.Spec.Replicas)
+	leaseArg := "--leaseName=" + activeLeaseName(cr)
+	assert.Contains(t, sts.Spec.Template.Spec.Containers[0].Args, leaseArg)
+	assert.Contains(t, sts.Spec.Template.Spec.Containers[1].Args, leaseArg)
+	assert.NotNil(t, sts.Spec.Template.Spec.Containers[0].ReadinessProbe)
+	assert.NotNil(t, sts.Spec.Template.Spec.Affinity.PodAntiAffinity)
+	assert.Equal(t, path.Join("/", "test-claim"), sts.Spec.Template.Spec.Containers[0].VolumeMounts[1].MountPath)
+}
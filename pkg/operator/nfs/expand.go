@@ -0,0 +1,98 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nfs
+
+import (
+	"context"
+	"path/filepath"
+	"strconv"
+
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// expandVolumes grows the volumes of the provisioner whose claims request more storage than their capacity
+func (p *Provisioner) expandVolumes(ctx context.Context) error {
+	pvs, err := p.client.CoreV1().PersistentVolumes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return errors.Wrap(err, "failed to list volumes")
+	}
+
+	for i := range pvs.Items {
+		pv := &pvs.Items[i]
+		if pv.Annotations[provisionedByAnnotationKey] != p.name || pv.Spec.NFS == nil || pv.Spec.ClaimRef == nil || pv.Status.Phase != v1.VolumeBound {
+			continue
+		}
+		if err := p.expandVolume(ctx, pv); err != nil {
+			logger.Errorf("failed to expand volume %q. %v", pv.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// expandVolume raises the quota of the volume to the storage requested by its claim. The volume is
+// expanded online, the clients see the new size without remounting.
+func (p *Provisioner) expandVolume(ctx context.Context, pv *v1.PersistentVolume) error {
+	pvc, err := p.client.CoreV1().PersistentVolumeClaims(pv.Spec.ClaimRef.Namespace).Get(ctx, pv.Spec.ClaimRef.Name, metav1.GetOptions{})
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return nil
+		}
+		return errors.Wrapf(err, "failed to get claim %q", pv.Spec.ClaimRef.Name)
+	}
+	if pvc.UID != pv.Spec.ClaimRef.UID {
+		return nil
+	}
+
+	requested := pvc.Spec.Resources.Requests[v1.ResourceStorage]
+	capacity := pv.Spec.Capacity[v1.ResourceStorage]
+	if requested.Cmp(capacity) > 0 {
+		// the volumes of exports without quotas have no block
+		if block := pv.Annotations[projectBlockAnnotationKey]; block != "" {
+			projectsFile := filepath.Join(filepath.Dir(pv.Spec.NFS.Path), "projects")
+			resized, err := p.quotaer.ResizeProjectQuota(projectsFile, block, strconv.FormatInt(requested.Value(), 10))
+			if err != nil {
+				return errors.Wrapf(err, "failed to resize quota to %s", requested.String())
+			}
+			pv.Annotations[projectBlockAnnotationKey] = resized
+		}
+
+		pv.Spec.Capacity[v1.ResourceStorage] = requested
+		if pv, err = p.client.CoreV1().PersistentVolumes().Update(ctx, pv, metav1.UpdateOptions{}); err != nil {
+			return errors.Wrap(err, "failed to update capacity")
+		}
+		logger.Infof("expanded volume %q from %s to %s", pv.Name, capacity.String(), requested.String())
+	}
+
+	// the claim reports the new capacity once the volume is expanded
+	capacity = pv.Spec.Capacity[v1.ResourceStorage]
+	claimCapacity := pvc.Status.Capacity[v1.ResourceStorage]
+	if claimCapacity.Cmp(capacity) < 0 {
+		if pvc.Status.Capacity == nil {
+			pvc.Status.Capacity = v1.ResourceList{}
+		}
+		pvc.Status.Capacity[v1.ResourceStorage] = capacity
+		if _, err := p.client.CoreV1().PersistentVolumeClaims(pvc.Namespace).UpdateStatus(ctx, pvc, metav1.UpdateOptions{}); err != nil {
+			return errors.Wrapf(err, "failed to update capacity of claim %q", pvc.Name)
+		}
+	}
+
+	return nil
+}
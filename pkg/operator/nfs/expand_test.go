@@ -0,0 +1,71 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nfs
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	apiresource "k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sclientfake "k8s.io/client-go/kubernetes/fake"
+)
+
+type resizeQuota struct {
+	FakeQuota
+	resized []string
+}
+
+func (q *resizeQuota) ResizeProjectQuota(projectsFile, block, limit string) (string, error) {
+	q.resized = append(q.resized, projectsFile+" "+limit)
+	return "1:/export/dir:" + limit + "\n", nil
+}
+
+func TestExpandVolumes(t *testing.T) {
+	ctx := context.TODO()
+	exportPath := filepath.Join(mountPath, "test-claim")
+	defer os.RemoveAll(mountPath)
+
+	pvc, pv := newBoundVolume(t, "data", exportPath, apiresource.MustParse("1Mi"))
+	pv.Annotations[projectBlockAnnotationKey] = "1:/export/dir:1048576\n"
+	pvc.Spec.Resources.Requests[corev1.ResourceStorage] = apiresource.MustParse("2Mi")
+	_, otherPV := newBoundVolume(t, "other", exportPath, apiresource.MustParse("1Mi"))
+	otherPV.Annotations[provisionedByAnnotationKey] = "nfs.rook.io/other-provisioner"
+
+	quota := &resizeQuota{}
+	client := k8sclientfake.NewSimpleClientset(pvc, pv, otherPV)
+	p := &Provisioner{client: client, quotaer: quota, name: testProvisionerName}
+
+	// the quota and capacity of the volume are raised to the request of the claim
+	assert.NoError(t, p.expandVolumes(ctx))
+	assert.Equal(t, []string{filepath.Join(exportPath, "projects") + " 2097152"}, quota.resized)
+	pv, err := client.CoreV1().PersistentVolumes().Get(ctx, "data-pv", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "2Mi", pv.Spec.Capacity.Storage().String())
+	assert.Equal(t, "1:/export/dir:2097152\n", pv.Annotations[projectBlockAnnotationKey])
+	pvc, err = client.CoreV1().PersistentVolumeClaims("default").Get(ctx, "data", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "2Mi", pvc.Status.Capacity.Storage().String())
+
+	// the volumes are expanded once
+	assert.NoError(t, p.expandVolumes(ctx))
+	assert.Equal(t, 1, len(quota.resized))
+}
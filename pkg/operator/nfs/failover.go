@@ -0,0 +1,105 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nfs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	nfsv1alpha1 "github.com/rook/rook/pkg/apis/nfs.rook.io/v1alpha1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+const (
+	// the standbys take over the exports at most leaseDuration after the active replica stopped renewing the lease
+	leaseDuration = 15 * time.Second
+	// the active replica stops serving when it could not renew the lease for renewDeadline, before a standby takes over
+	renewDeadline = 10 * time.Second
+	retryPeriod   = 2 * time.Second
+)
+
+// activeLeaseName is the name of the lease held by the replica serving the exports
+func activeLeaseName(cr *nfsv1alpha1.NFSServer) string {
+	return fmt.Sprintf("%s-active", cr.Name)
+}
+
+// RunActive runs the server once the replica holds the lease. The other replicas wait as standbys to take
+// over the lease when the active replica fails. The process exits when the lease is lost so that a partitioned
+// replica does not keep serving the exports.
+func RunActive(ctx context.Context, clientset kubernetes.Interface, namespace, leaseName, identity string, run func(ctx context.Context)) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	lock := &resourcelock.LeaseLock{
+		LeaseMeta:  metav1.ObjectMeta{Name: leaseName, Namespace: namespace},
+		Client:     clientset.CoordinationV1(),
+		LockConfig: resourcelock.ResourceLockConfig{Identity: identity},
+	}
+
+	logger.Infof("waiting for the lease %q to serve the exports", leaseName)
+	leaderelection.RunOrDie(ctx, leaderelection.LeaderElectionConfig{
+		Lock:            lock,
+		LeaseDuration:   leaseDuration,
+		RenewDeadline:   renewDeadline,
+		RetryPeriod:     retryPeriod,
+		ReleaseOnCancel: true,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(ctx context.Context) {
+				logger.Infof("replica %q is active", identity)
+				run(ctx)
+				// release the lease for a standby to take over
+				cancel()
+			},
+			OnStoppedLeading: func() {
+				if ctx.Err() == nil {
+					logger.Fatalf("replica %q lost the lease %q", identity, leaseName)
+				}
+			},
+			OnNewLeader: func(active string) {
+				if active != identity {
+					logger.Infof("replica %q is active, %q is standby", active, identity)
+				}
+			},
+		},
+	})
+}
+
+// activeReplica returns the replica that holds the lease, or an empty string if no replica holds a valid lease
+func activeReplica(ctx context.Context, clientset kubernetes.Interface, namespace, leaseName string) (string, error) {
+	lease, err := clientset.CoordinationV1().Leases(namespace).Get(ctx, leaseName, metav1.GetOptions{})
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", err
+	}
+
+	if lease.Spec.HolderIdentity == nil || lease.Spec.RenewTime == nil || lease.Spec.LeaseDurationSeconds == nil {
+		return "", nil
+	}
+	expiry := lease.Spec.RenewTime.Add(time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second)
+	if time.Now().After(expiry) {
+		return "", nil
+	}
+
+	return *lease.Spec.HolderIdentity, nil
+}
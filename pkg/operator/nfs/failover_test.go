@@ -0,0 +1,138 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nfs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rook/rook/pkg/clusterd"
+	"github.com/stretchr/testify/assert"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	k8sclientfake "k8s.io/client-go/kubernetes/fake"
+	"sigs.k8s.io/sig-storage-lib-external-provisioner/v6/controller"
+)
+
+func newLease(holder string, renewed time.Time) *coordinationv1.Lease {
+	duration := int32(15)
+	renewTime := metav1.NewMicroTime(renewed)
+	return &coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{Name: "nfs-server-active", Namespace: "nfs-server"},
+		Spec: coordinationv1.LeaseSpec{
+			HolderIdentity:       &holder,
+			LeaseDurationSeconds: &duration,
+			RenewTime:            &renewTime,
+		},
+	}
+}
+
+func newReplica(name string, phase corev1.PodPhase) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "nfs-server", Labels: map[string]string{"app": "nfs-server"}},
+		Status:     corev1.PodStatus{Phase: phase},
+	}
+}
+
+func TestActiveReplica(t *testing.T) {
+	ctx := context.TODO()
+
+	// no replica started yet
+	active, err := activeReplica(ctx, k8sclientfake.NewSimpleClientset(), "nfs-server", "nfs-server-active")
+	assert.NoError(t, err)
+	assert.Equal(t, "", active)
+
+	clientset := k8sclientfake.NewSimpleClientset(newLease("nfs-server-1", time.Now()))
+	active, err = activeReplica(ctx, clientset, "nfs-server", "nfs-server-active")
+	assert.NoError(t, err)
+	assert.Equal(t, "nfs-server-1", active)
+
+	// the active replica stopped renewing the lease
+	clientset = k8sclientfake.NewSimpleClientset(newLease("nfs-server-1", time.Now().Add(-time.Minute)))
+	active, err = activeReplica(ctx, clientset, "nfs-server", "nfs-server-active")
+	assert.NoError(t, err)
+	assert.Equal(t, "", active)
+}
+
+func TestNFSServerReplicas(t *testing.T) {
+	ctx := context.TODO()
+	cr := newCustomResource(types.NamespacedName{Name: "nfs-server", Namespace: "nfs-server"}).WithExports("share1", "ReadWrite", "none", "test-claim").Generate()
+	claim := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-claim", Namespace: "nfs-server"},
+		Spec:       corev1.PersistentVolumeClaimSpec{AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}},
+	}
+	clientset := k8sclientfake.NewSimpleClientset(
+		claim,
+		newLease("nfs-server-1", time.Now()),
+		newReplica("nfs-server-0", corev1.PodRunning),
+		newReplica("nfs-server-1", corev1.PodRunning),
+		newReplica("nfs-server-2", corev1.PodPending),
+	)
+	r := &NFSServerReconciler{Context: &clusterd.Context{Clientset: clientset}, Log: logger}
+
+	// a single replica can use any claim
+	assert.NoError(t, r.validateSharedClaims(ctx, cr))
+
+	// the standbys mount the claims of the active replica
+	cr.Spec.Replicas = 3
+	assert.Error(t, r.validateSharedClaims(ctx, cr))
+	claim.Spec.AccessModes = []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany}
+	_, err := clientset.CoreV1().PersistentVolumeClaims("nfs-server").Update(ctx, claim, metav1.UpdateOptions{})
+	assert.NoError(t, err)
+	assert.NoError(t, r.validateSharedClaims(ctx, cr))
+
+	assert.NoError(t, r.updateReplicasStatus(ctx, cr))
+	assert.Equal(t, "nfs-server-1", cr.Status.ActiveReplica)
+	assert.Equal(t, 1, cr.Status.StandbyReplicas)
+
+	// the lease moves to a standby after a failover
+	_, err = clientset.CoordinationV1().Leases("nfs-server").Update(ctx, newLease("nfs-server-0", time.Now()), metav1.UpdateOptions{})
+	assert.NoError(t, err)
+	assert.NoError(t, clientset.CoreV1().Pods("nfs-server").Delete(ctx, "nfs-server-1", metav1.DeleteOptions{}))
+	assert.NoError(t, r.updateReplicasStatus(ctx, cr))
+	assert.Equal(t, "nfs-server-0", cr.Status.ActiveReplica)
+	assert.Equal(t, 0, cr.Status.StandbyReplicas)
+
+	// a single replica serves the exports without the lease
+	cr.Spec.Replicas = 1
+	assert.NoError(t, clientset.CoordinationV1().Leases("nfs-server").Delete(ctx, "nfs-server-active", metav1.DeleteOptions{}))
+	assert.NoError(t, r.updateReplicasStatus(ctx, cr))
+	assert.Equal(t, "nfs-server-0", cr.Status.ActiveReplica)
+	assert.Equal(t, 0, cr.Status.StandbyReplicas)
+}
+
+func TestProvisionerStandby(t *testing.T) {
+	ctx := context.TODO()
+	clientset := k8sclientfake.NewSimpleClientset(newLease("nfs-server-1", time.Now()))
+	p := &Provisioner{client: clientset}
+
+	// without a lease the provisioner is always active
+	assert.NoError(t, p.ignoreIfStandby(ctx))
+
+	p.SetActiveLease("nfs-server", "nfs-server-active", "nfs-server-1")
+	assert.NoError(t, p.ignoreIfStandby(ctx))
+
+	// the standby replicas ignore the volumes
+	p.SetActiveLease("nfs-server", "nfs-server-active", "nfs-server-0")
+	_, _, err := p.Provision(ctx, controller.ProvisionOptions{})
+	assert.IsType(t, &controller.IgnoredError{}, err)
+	err = p.Delete(ctx, &corev1.PersistentVolume{})
+	assert.IsType(t, &controller.IgnoredError{}, err)
+}
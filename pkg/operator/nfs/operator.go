@@ -22,6 +22,7 @@ import (
 
 	"github.com/coreos/pkg/capnslog"
 	"github.com/rook/rook/pkg/clusterd"
+	appsv1 "k8s.io/api/apps/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -69,6 +70,8 @@ func (o *Operator) Run() error {
 
 	if err := ctrl.NewControllerManagedBy(mgr).
 		For(&nfsv1alpha1.NFSServer{}).
+		// follow the failover of the active replica
+		Owns(&appsv1.StatefulSet{}).
 		Complete(reconciler); err != nil {
 		return err
 	}
@@ -25,6 +25,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	rookclient "github.com/rook/rook/pkg/client/clientset/versioned"
@@ -46,18 +47,26 @@ const (
 
 var (
 	mountPath = "/"
+	// syncInterval is the interval to take the snapshots and to expand the volumes
+	syncInterval = 30 * time.Second
 )
 
 type Provisioner struct {
 	client     kubernetes.Interface
 	rookClient rookclient.Interface
 	quotaer    Quotaer
+	// name of the provisioner in the storage classes of its volumes
+	name string
+	// the lease of the replica serving the exports, when the nfs server has standby replicas
+	leaseNamespace string
+	leaseName      string
+	identity       string
 }
 
 var _ controller.Provisioner = &Provisioner{}
 
 // NewNFSProvisioner returns an instance of nfsProvisioner
-func NewNFSProvisioner(clientset kubernetes.Interface, rookClientset rookclient.Interface) (*Provisioner, error) {
+func NewNFSProvisioner(clientset kubernetes.Interface, rookClientset rookclient.Interface, name string) (*Provisioner, error) {
 	quotaer, err := NewProjectQuota()
 	if err != nil {
 		return nil, err
@@ -67,11 +76,74 @@ func NewNFSProvisioner(clientset kubernetes.Interface, rookClientset rookclient.
 		client:     clientset,
 		rookClient: rookClientset,
 		quotaer:    quotaer,
+		name:       name,
 	}, nil
 }
 
+// SetActiveLease makes the provisioner provision, delete and sync the volumes only while its replica holds the lease
+// to serve the exports
+func (p *Provisioner) SetActiveLease(namespace, leaseName, identity string) {
+	p.leaseNamespace = namespace
+	p.leaseName = leaseName
+	p.identity = identity
+}
+
+// isActive returns whether the replica of the provisioner serves the exports
+func (p *Provisioner) isActive(ctx context.Context) (bool, error) {
+	if p.leaseName == "" {
+		return true, nil
+	}
+
+	active, err := activeReplica(ctx, p.client, p.leaseNamespace, p.leaseName)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to get the active replica from lease %q", p.leaseName)
+	}
+	return active == p.identity, nil
+}
+
+// ignoreIfStandby returns an IgnoredError on the standby replicas, the volumes are handled by the active replica
+func (p *Provisioner) ignoreIfStandby(ctx context.Context) error {
+	active, err := p.isActive(ctx)
+	if err != nil {
+		return err
+	}
+	if !active {
+		return &controller.IgnoredError{Reason: fmt.Sprintf("replica %q does not hold the lease %q to serve the exports", p.identity, p.leaseName)}
+	}
+	return nil
+}
+
+// SyncVolumes periodically takes the snapshots and expands the volumes of the provisioner until the context is done
+func (p *Provisioner) SyncVolumes(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-time.After(syncInterval):
+			// the standby replicas leave the volumes to the active replica
+			if active, err := p.isActive(ctx); err != nil || !active {
+				if err != nil {
+					logger.Errorf("failed to sync volumes. %v", err)
+				}
+				continue
+			}
+			if err := p.syncSnapshots(ctx); err != nil {
+				logger.Errorf("failed to sync snapshots. %v", err)
+			}
+			if err := p.expandVolumes(ctx); err != nil {
+				logger.Errorf("failed to expand volumes. %v", err)
+			}
+		}
+	}
+}
+
 // Provision(context.Context, ProvisionOptions) (*v1.PersistentVolume, ProvisioningState, error)
 func (p *Provisioner) Provision(ctx context.Context, options controller.ProvisionOptions) (*v1.PersistentVolume, controller.ProvisioningState, error) {
+	if err := p.ignoreIfStandby(ctx); err != nil {
+		return nil, controller.ProvisioningNoChange, err
+	}
+
 	logger.Infof("nfs provisioner: ProvisionOptions %v", options)
 	annotations := make(map[string]string)
 
@@ -125,18 +197,35 @@ func (p *Provisioner) Provision(ctx context.Context, options controller.Provisio
 		return nil, controller.ProvisioningFinished, fmt.Errorf("No export name from storageclass is match with NFSServer %s in namespace %s", nfsserver.Name, nfsserver.Namespace)
 	}
 
+	capacity := options.PVC.Spec.Resources.Requests[v1.ResourceName(v1.ResourceStorage)]
+	source, err := p.volumeSource(ctx, options.PVC, exportPath, capacity)
+	if err != nil {
+		return nil, controller.ProvisioningFinished, err
+	}
+
 	pvName := strings.Join([]string{options.PVC.Namespace, options.PVC.Name, options.PVName}, "-")
 	fullPath := path.Join(exportPath, pvName)
 	if err := os.MkdirAll(fullPath, 0700); err != nil {
 		return nil, controller.ProvisioningFinished, errors.New("unable to create directory to provision new pv: " + err.Error())
 	}
 
-	capacity := options.PVC.Spec.Resources.Requests[v1.ResourceName(v1.ResourceStorage)]
+	// the quota is set before populating the volume so that the copy is limited to the capacity
 	block, err := p.createQuota(exportPath, fullPath, strconv.FormatInt(capacity.Value(), 10))
 	if err != nil {
 		return nil, controller.ProvisioningFinished, err
 	}
 
+	if source != "" {
+		logger.Infof("populating volume %q from %q", options.PVName, source)
+		if err := copyDirectory(source, fullPath); err != nil {
+			if qerr := p.removeQuota(exportPath, block); qerr != nil {
+				logger.Errorf("failed to remove the quota of volume %q. %v", options.PVName, qerr)
+			}
+			_ = os.RemoveAll(fullPath)
+			return nil, controller.ProvisioningFinished, errors.Wrapf(err, "failed to populate volume %q", options.PVName)
+		}
+	}
+
 	annotations[projectBlockAnnotationKey] = block
 
 	pv := &v1.PersistentVolume{
@@ -165,6 +254,10 @@ func (p *Provisioner) Provision(ctx context.Context, options controller.Provisio
 }
 
 func (p *Provisioner) Delete(ctx context.Context, volume *v1.PersistentVolume) error {
+	if err := p.ignoreIfStandby(ctx); err != nil {
+		return err
+	}
+
 	nfsPath := volume.Spec.PersistentVolumeSource.NFS.Path
 	pvName := path.Base(nfsPath)
 
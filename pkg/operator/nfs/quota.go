@@ -18,6 +18,7 @@ import (
 type Quotaer interface {
 	CreateProjectQuota(projectsFile, directory, limit string) (string, error)
 	RemoveProjectQuota(projectID uint16, projectsFile, block string) error
+	ResizeProjectQuota(projectsFile, block, limit string) (string, error)
 	RestoreProjectQuota() error
 }
 
@@ -171,6 +172,48 @@ func (q *Quota) RemoveProjectQuota(projectID uint16, projectsFile, block string)
 	return q.removeProject(projectID, projectsFile, block)
 }
 
+// ResizeProjectQuota sets a new limit to the project of the block and returns the updated block
+func (q *Quota) ResizeProjectQuota(projectsFile, block, limit string) (string, error) {
+	re := regexp.MustCompile("(?m:^([0-9]+):(.+):(.+)$)")
+	match := re.FindStringSubmatch(block)
+	if match == nil {
+		return "", fmt.Errorf("invalid project block %q", block)
+	}
+	projectID, err := strconv.ParseUint(match[1], 10, 16)
+	if err != nil {
+		return "", fmt.Errorf("invalid project id in block %q: %v", block, err)
+	}
+	directory := match[2]
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	read, err := ioutil.ReadFile(projectsFile) // #nosec
+	if err != nil {
+		return "", err
+	}
+
+	// the line of the project is found by its id and directory, the limit may have been resized already
+	lineRe := regexp.MustCompile("(?m:^" + regexp.QuoteMeta(match[1]+":"+directory+":") + ".+$\n)")
+	current := lineRe.Find(read)
+	if current == nil {
+		return "", fmt.Errorf("project %d for directory %s not found in projects file %s", projectID, directory, projectsFile)
+	}
+
+	logger.Infof("resizing quota for project id %d to limit %s", projectID, limit)
+	if err := q.setQuota(uint16(projectID), projectsFile, directory, limit); err != nil {
+		return "", err
+	}
+
+	resized := match[1] + ":" + directory + ":" + limit + "\n"
+	updated := strings.Replace(string(read), string(current), resized, 1)
+	if err := ioutil.WriteFile(projectsFile, []byte(updated), 0); err != nil {
+		return "", err
+	}
+
+	return resized, nil
+}
+
 func (q *Quota) RestoreProjectQuota() error {
 	mountEntries, err := findProjectQuotaMount()
 	if err != nil {
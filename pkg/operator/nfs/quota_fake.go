@@ -14,6 +14,10 @@ func (q *FakeQuota) RemoveProjectQuota(projectID uint16, projectsFile, block str
 	return nil
 }
 
+func (q *FakeQuota) ResizeProjectQuota(projectsFile, block, limit string) (string, error) {
+	return block, nil
+}
+
 func (q *FakeQuota) RestoreProjectQuota() error {
 	return nil
 }
@@ -0,0 +1,270 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nfs
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+
+	"github.com/pkg/errors"
+	nfsv1alpha1 "github.com/rook/rook/pkg/apis/nfs.rook.io/v1alpha1"
+	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	apiresource "k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	// snapshotAnnotationKey on a claim restores the snapshot with that name to the new volume
+	snapshotAnnotationKey = "nfs.rook.io/snapshot-name"
+	// provisionedByAnnotationKey is set on the volumes by the provisioner controller
+	provisionedByAnnotationKey = "pv.kubernetes.io/provisioned-by"
+	// snapshotsDirName is the directory of the export where the snapshots are copied to
+	snapshotsDirName = "snapshots"
+)
+
+// copyDirectory copies the content of a directory, sharing the blocks of the files when the filesystem supports reflinks
+func copyDirectory(source, destination string) error {
+	cmd := exec.Command("cp", "-a", "--reflink=auto", source+"/.", destination) // #nosec
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("cp failed with error: %v, output: %s", err, out)
+	}
+
+	return nil
+}
+
+// volumeSource returns the directory to populate the volume of the claim from, the volume of another
+// claim in the dataSource or a snapshot in the annotations. The source must be on the same export.
+func (p *Provisioner) volumeSource(ctx context.Context, pvc *v1.PersistentVolumeClaim, exportPath string, capacity apiresource.Quantity) (string, error) {
+	snapshotName, restore := pvc.Annotations[snapshotAnnotationKey]
+	if pvc.Spec.DataSource != nil && restore {
+		return "", errors.Errorf("claim %q cannot have both a dataSource and the annotation %q", pvc.Name, snapshotAnnotationKey)
+	}
+
+	if restore {
+		snapshot, err := p.rookClient.NfsV1alpha1().NFSVolumeSnapshots(pvc.Namespace).Get(ctx, snapshotName, metav1.GetOptions{})
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to get snapshot %q to restore", snapshotName)
+		}
+		if !snapshot.Status.ReadyToUse {
+			return "", errors.Errorf("snapshot %q is not ready to use", snapshotName)
+		}
+		if filepath.Dir(snapshot.Status.Path) != filepath.Join(exportPath, snapshotsDirName) {
+			return "", errors.Errorf("snapshot %q is not on the export %q of the storage class", snapshotName, exportPath)
+		}
+		if snapshot.Status.RestoreSize != nil && capacity.Cmp(*snapshot.Status.RestoreSize) < 0 {
+			return "", errors.Errorf("requested capacity %s is smaller than the size %s of snapshot %q", capacity.String(), snapshot.Status.RestoreSize.String(), snapshotName)
+		}
+		return snapshot.Status.Path, nil
+	}
+
+	if pvc.Spec.DataSource == nil {
+		return "", nil
+	}
+	if pvc.Spec.DataSource.Kind != "PersistentVolumeClaim" || (pvc.Spec.DataSource.APIGroup != nil && *pvc.Spec.DataSource.APIGroup != "") {
+		return "", errors.Errorf("data source %s %q is not supported, only claims can be cloned", pvc.Spec.DataSource.Kind, pvc.Spec.DataSource.Name)
+	}
+
+	source, err := p.client.CoreV1().PersistentVolumeClaims(pvc.Namespace).Get(ctx, pvc.Spec.DataSource.Name, metav1.GetOptions{})
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get claim %q to clone", pvc.Spec.DataSource.Name)
+	}
+	if source.Spec.VolumeName == "" {
+		return "", errors.Errorf("claim %q to clone is not bound", source.Name)
+	}
+	pv, err := p.client.CoreV1().PersistentVolumes().Get(ctx, source.Spec.VolumeName, metav1.GetOptions{})
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get volume %q to clone", source.Spec.VolumeName)
+	}
+	if pv.Spec.NFS == nil || filepath.Dir(pv.Spec.NFS.Path) != exportPath {
+		return "", errors.Errorf("volume %q to clone is not on the export %q of the storage class", pv.Name, exportPath)
+	}
+	sourceCapacity := pv.Spec.Capacity[v1.ResourceStorage]
+	if capacity.Cmp(sourceCapacity) < 0 {
+		return "", errors.Errorf("requested capacity %s is smaller than the size %s of claim %q", capacity.String(), sourceCapacity.String(), source.Name)
+	}
+
+	return pv.Spec.NFS.Path, nil
+}
+
+// syncSnapshots takes and removes the snapshots of the volumes of this provisioner
+func (p *Provisioner) syncSnapshots(ctx context.Context) error {
+	snapshots, err := p.rookClient.NfsV1alpha1().NFSVolumeSnapshots(v1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return errors.Wrap(err, "failed to list nfs volume snapshots")
+	}
+
+	for i := range snapshots.Items {
+		snapshot := &snapshots.Items[i]
+		if err := p.syncSnapshot(ctx, snapshot); err != nil {
+			logger.Errorf("failed to sync nfs volume snapshot %s/%s. %v", snapshot.Namespace, snapshot.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func (p *Provisioner) syncSnapshot(ctx context.Context, snapshot *nfsv1alpha1.NFSVolumeSnapshot) error {
+	if snapshot.DeletionTimestamp != nil {
+		if snapshot.Status.Provisioner != p.name {
+			return nil
+		}
+		return p.deleteSnapshot(ctx, snapshot)
+	}
+
+	if snapshot.Status.ReadyToUse || (snapshot.Status.Provisioner != "" && snapshot.Status.Provisioner != p.name) {
+		return nil
+	}
+
+	// the snapshot is taken by the provisioner of the volume, which has the export mounted
+	pvc, err := p.client.CoreV1().PersistentVolumeClaims(snapshot.Namespace).Get(ctx, snapshot.Spec.PersistentVolumeClaimName, metav1.GetOptions{})
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			logger.Debugf("claim %q of nfs volume snapshot %q not found", snapshot.Spec.PersistentVolumeClaimName, snapshot.Name)
+			return nil
+		}
+		return errors.Wrapf(err, "failed to get claim %q", snapshot.Spec.PersistentVolumeClaimName)
+	}
+	if pvc.Spec.VolumeName == "" {
+		return nil
+	}
+	pv, err := p.client.CoreV1().PersistentVolumes().Get(ctx, pvc.Spec.VolumeName, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to get volume %q", pvc.Spec.VolumeName)
+	}
+	if pv.Annotations[provisionedByAnnotationKey] != p.name || pv.Spec.NFS == nil {
+		return nil
+	}
+
+	// record the snapshot before copying so that a partial copy is removed with the snapshot
+	if snapshot.Status.Provisioner == "" {
+		if snapshot.UID == "" {
+			return errors.Errorf("nfs volume snapshot %s/%s has no uid", snapshot.Namespace, snapshot.Name)
+		}
+		snapshot.Status.Provisioner = p.name
+		snapshot.Status.Path = snapshotPath(filepath.Dir(pv.Spec.NFS.Path), snapshot)
+		if snapshot, err = p.rookClient.NfsV1alpha1().NFSVolumeSnapshots(snapshot.Namespace).UpdateStatus(ctx, snapshot, metav1.UpdateOptions{}); err != nil {
+			return errors.Wrap(err, "failed to update status")
+		}
+	}
+	if !controllerutil.ContainsFinalizer(snapshot, nfsv1alpha1.SnapshotFinalizer) {
+		controllerutil.AddFinalizer(snapshot, nfsv1alpha1.SnapshotFinalizer)
+		if snapshot, err = p.rookClient.NfsV1alpha1().NFSVolumeSnapshots(snapshot.Namespace).Update(ctx, snapshot, metav1.UpdateOptions{}); err != nil {
+			return errors.Wrap(err, "failed to add finalizer")
+		}
+	}
+
+	logger.Infof("taking snapshot %s/%s of volume %q", snapshot.Namespace, snapshot.Name, pv.Name)
+	err = os.MkdirAll(snapshot.Status.Path, 0700)
+	if err == nil {
+		snapshot, err = p.createSnapshotQuota(ctx, snapshot, pv)
+	}
+	if err == nil {
+		err = copyDirectory(pv.Spec.NFS.Path, snapshot.Status.Path)
+	}
+	if err != nil {
+		snapshot.Status.Message = err.Error()
+		if _, uerr := p.rookClient.NfsV1alpha1().NFSVolumeSnapshots(snapshot.Namespace).UpdateStatus(ctx, snapshot, metav1.UpdateOptions{}); uerr != nil {
+			logger.Errorf("failed to update status of nfs volume snapshot %q. %v", snapshot.Name, uerr)
+		}
+		return errors.Wrapf(err, "failed to copy volume %q", pv.Name)
+	}
+
+	now := metav1.Now()
+	size := pv.Spec.Capacity[v1.ResourceStorage]
+	snapshot.Status.ReadyToUse = true
+	snapshot.Status.CreationTime = &now
+	snapshot.Status.RestoreSize = &size
+	snapshot.Status.Message = ""
+	if _, err := p.rookClient.NfsV1alpha1().NFSVolumeSnapshots(snapshot.Namespace).UpdateStatus(ctx, snapshot, metav1.UpdateOptions{}); err != nil {
+		return errors.Wrap(err, "failed to update status")
+	}
+
+	logger.Infof("snapshot %s/%s of volume %q is ready to use", snapshot.Namespace, snapshot.Name, pv.Name)
+	return nil
+}
+
+// createSnapshotQuota limits the copy of the volume to the capacity of the volume, like the volume itself. The
+// project block of the quota is recorded in the annotations of the snapshot to remove it with the snapshot.
+func (p *Provisioner) createSnapshotQuota(ctx context.Context, snapshot *nfsv1alpha1.NFSVolumeSnapshot, pv *v1.PersistentVolume) (*nfsv1alpha1.NFSVolumeSnapshot, error) {
+	if _, ok := snapshot.Annotations[projectBlockAnnotationKey]; ok {
+		return snapshot, nil
+	}
+
+	capacity := pv.Spec.Capacity[v1.ResourceStorage]
+	block, err := p.createQuota(snapshotExportPath(snapshot), snapshot.Status.Path, strconv.FormatInt(capacity.Value(), 10))
+	if err != nil {
+		return snapshot, errors.Wrap(err, "failed to create the quota of the snapshot")
+	}
+
+	if snapshot.Annotations == nil {
+		snapshot.Annotations = map[string]string{}
+	}
+	snapshot.Annotations[projectBlockAnnotationKey] = block
+	updated, err := p.rookClient.NfsV1alpha1().NFSVolumeSnapshots(snapshot.Namespace).Update(ctx, snapshot, metav1.UpdateOptions{})
+	if err != nil {
+		if qerr := p.removeQuota(snapshotExportPath(snapshot), block); qerr != nil {
+			logger.Errorf("failed to remove the quota of nfs volume snapshot %q. %v", snapshot.Name, qerr)
+		}
+		delete(snapshot.Annotations, projectBlockAnnotationKey)
+		return snapshot, errors.Wrap(err, "failed to record the quota of the snapshot")
+	}
+	return updated, nil
+}
+
+// snapshotPath returns the directory of the snapshot in the export. The directory is keyed by the uid of the
+// snapshot, since the namespaces and names of the snapshots may collide once joined.
+func snapshotPath(exportPath string, snapshot *nfsv1alpha1.NFSVolumeSnapshot) string {
+	return filepath.Join(exportPath, snapshotsDirName, string(snapshot.UID))
+}
+
+// snapshotExportPath returns the path of the export the snapshot is stored in
+func snapshotExportPath(snapshot *nfsv1alpha1.NFSVolumeSnapshot) string {
+	return filepath.Dir(filepath.Dir(snapshot.Status.Path))
+}
+
+// deleteSnapshot removes the copy of the volume and releases the snapshot
+func (p *Provisioner) deleteSnapshot(ctx context.Context, snapshot *nfsv1alpha1.NFSVolumeSnapshot) error {
+	if !controllerutil.ContainsFinalizer(snapshot, nfsv1alpha1.SnapshotFinalizer) {
+		return nil
+	}
+
+	logger.Infof("removing snapshot %s/%s", snapshot.Namespace, snapshot.Name)
+	if block, ok := snapshot.Annotations[projectBlockAnnotationKey]; ok {
+		if err := p.removeQuota(snapshotExportPath(snapshot), block); err != nil {
+			return errors.Wrapf(err, "failed to remove the quota of snapshot directory %q", snapshot.Status.Path)
+		}
+	}
+	if snapshot.Status.Path != "" {
+		if err := os.RemoveAll(snapshot.Status.Path); err != nil {
+			return errors.Wrapf(err, "failed to remove snapshot directory %q", snapshot.Status.Path)
+		}
+	}
+
+	controllerutil.RemoveFinalizer(snapshot, nfsv1alpha1.SnapshotFinalizer)
+	if _, err := p.rookClient.NfsV1alpha1().NFSVolumeSnapshots(snapshot.Namespace).Update(ctx, snapshot, metav1.UpdateOptions{}); err != nil {
+		return errors.Wrap(err, "failed to remove finalizer")
+	}
+
+	return nil
+}
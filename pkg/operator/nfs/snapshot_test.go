@@ -0,0 +1,204 @@
+/*
+Copyright 2021 The Rook Authors. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nfs
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	nfsv1alpha1 "github.com/rook/rook/pkg/apis/nfs.rook.io/v1alpha1"
+	rookclientfake "github.com/rook/rook/pkg/client/clientset/versioned/fake"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	apiresource "k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	k8sclientfake "k8s.io/client-go/kubernetes/fake"
+	"sigs.k8s.io/sig-storage-lib-external-provisioner/v6/controller"
+)
+
+const testProvisionerName = "nfs.rook.io/test-nfsserver-provisioner"
+
+// newBoundVolume returns a claim bound to a volume of the provisioner with a file in its directory
+func newBoundVolume(t *testing.T, name, exportPath string, capacity apiresource.Quantity) (*corev1.PersistentVolumeClaim, *corev1.PersistentVolume) {
+	pvc := newDummyPVC(name, "default", capacity, "share-1")
+	pvc.UID = types.UID(name + "-uid")
+	pvc.Spec.VolumeName = name + "-pv"
+	pvc.Status.Capacity = corev1.ResourceList{corev1.ResourceStorage: capacity}
+
+	pv := newDummyPV(name+"-pv", "share-1", filepath.Join(exportPath, "default-"+name+"-"+name+"-pv"), capacity, corev1.PersistentVolumeReclaimDelete)
+	pv.Annotations[provisionedByAnnotationKey] = testProvisionerName
+	pv.Spec.ClaimRef = &corev1.ObjectReference{Namespace: "default", Name: name, UID: pvc.UID}
+	pv.Status.Phase = corev1.VolumeBound
+
+	assert.NoError(t, os.MkdirAll(pv.Spec.NFS.Path, 0700))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(pv.Spec.NFS.Path, "data"), []byte(name), 0600))
+	return pvc, pv
+}
+
+// recordingQuota records the quotas and whether their directory was empty when they were created
+type recordingQuota struct {
+	FakeQuota
+	created []string
+	removed []string
+}
+
+func (q *recordingQuota) CreateProjectQuota(projectsFile, directory, limit string) (string, error) {
+	files, err := ioutil.ReadDir(directory)
+	if err != nil {
+		return "", err
+	}
+	q.created = append(q.created, fmt.Sprintf("%s:%s:%d", filepath.Base(directory), limit, len(files)))
+	return fmt.Sprintf("%d:%s:%s\n", len(q.created), directory, limit), nil
+}
+
+func (q *recordingQuota) RemoveProjectQuota(projectID uint16, projectsFile, block string) error {
+	q.removed = append(q.removed, block)
+	return nil
+}
+
+func TestSnapshotAndRestore(t *testing.T) {
+	ctx := context.TODO()
+	exportPath := filepath.Join(mountPath, "test-claim")
+	defer os.RemoveAll(mountPath)
+
+	fakeQuoater := &recordingQuota{}
+	nfsserver := newCustomResource(types.NamespacedName{Name: "test-nfsserver", Namespace: "test-nfsserver"}).WithExports("share-1", "ReadWrite", "none", "test-claim").Generate()
+	sc := newDummyStorageClass("share-1", types.NamespacedName{Name: nfsserver.Name, Namespace: nfsserver.Namespace}, corev1.PersistentVolumeReclaimDelete)
+	pvc, pv := newBoundVolume(t, "data", exportPath, apiresource.MustParse("1Mi"))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(exportPath, "projects"), []byte{}, 0600))
+	snapshot := &nfsv1alpha1.NFSVolumeSnapshot{
+		ObjectMeta: metav1.ObjectMeta{Name: "snap", Namespace: "default", UID: "4c6b1d3e-snap"},
+		Spec:       nfsv1alpha1.NFSVolumeSnapshotSpec{PersistentVolumeClaimName: "data"},
+	}
+
+	client := k8sclientfake.NewSimpleClientset(newServiceForNFSServer(nfsserver), sc, pvc, pv)
+	rookClient := rookclientfake.NewSimpleClientset(nfsserver, snapshot)
+	p := &Provisioner{client: client, rookClient: rookClient, quotaer: fakeQuoater, name: testProvisionerName}
+
+	// the snapshots of the volumes of other provisioners are ignored
+	other := &Provisioner{client: client, rookClient: rookClient, quotaer: fakeQuoater, name: "nfs.rook.io/other-provisioner"}
+	assert.NoError(t, other.syncSnapshots(ctx))
+	snapshot, err := rookClient.NfsV1alpha1().NFSVolumeSnapshots("default").Get(ctx, "snap", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, nfsv1alpha1.NFSVolumeSnapshotStatus{}, snapshot.Status)
+
+	// the volume is copied to the snapshots directory of the export
+	assert.NoError(t, p.syncSnapshots(ctx))
+	snapshot, err = rookClient.NfsV1alpha1().NFSVolumeSnapshots("default").Get(ctx, "snap", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.True(t, snapshot.Status.ReadyToUse)
+	assert.Equal(t, filepath.Join(exportPath, "snapshots", "4c6b1d3e-snap"), snapshot.Status.Path)
+	assert.Equal(t, testProvisionerName, snapshot.Status.Provisioner)
+	assert.Equal(t, "1Mi", snapshot.Status.RestoreSize.String())
+	assert.NotNil(t, snapshot.Status.CreationTime)
+	assert.Equal(t, []string{nfsv1alpha1.SnapshotFinalizer}, snapshot.Finalizers)
+	content, err := ioutil.ReadFile(filepath.Join(snapshot.Status.Path, "data"))
+	assert.NoError(t, err)
+	assert.Equal(t, "data", string(content))
+
+	// the copy is limited by a quota of the size of the volume, set before copying
+	assert.Equal(t, []string{"4c6b1d3e-snap:1048576:0"}, fakeQuoater.created)
+	snapshotBlock := snapshot.Annotations[projectBlockAnnotationKey]
+	assert.Equal(t, "1:"+snapshot.Status.Path+":1048576\n", snapshotBlock)
+
+	// the snapshot is restored to a new claim
+	restore := newDummyPVC("restore", "default", apiresource.MustParse("1Mi"), "share-1")
+	restore.Annotations = map[string]string{snapshotAnnotationKey: "snap"}
+	restored, _, err := p.Provision(ctx, controller.ProvisionOptions{StorageClass: sc, PVName: "restore-pv", PVC: restore})
+	assert.NoError(t, err)
+	content, err = ioutil.ReadFile(filepath.Join(restored.Spec.NFS.Path, "data"))
+	assert.NoError(t, err)
+	assert.Equal(t, "data", string(content))
+	assert.Equal(t, "default-restore-restore-pv:1048576:0", fakeQuoater.created[1])
+
+	// the claim must be as big as the snapshot
+	restore = newDummyPVC("small", "default", apiresource.MustParse("512Ki"), "share-1")
+	restore.Annotations = map[string]string{snapshotAnnotationKey: "snap"}
+	_, _, err = p.Provision(ctx, controller.ProvisionOptions{StorageClass: sc, PVName: "small-pv", PVC: restore})
+	assert.Error(t, err)
+	_, err = os.Stat(filepath.Join(exportPath, "default-small-small-pv"))
+	assert.True(t, os.IsNotExist(err))
+
+	// the copy is removed with the snapshot
+	now := metav1.Now()
+	snapshot.DeletionTimestamp = &now
+	_, err = rookClient.NfsV1alpha1().NFSVolumeSnapshots("default").Update(ctx, snapshot, metav1.UpdateOptions{})
+	assert.NoError(t, err)
+	assert.NoError(t, p.syncSnapshots(ctx))
+	snapshot, err = rookClient.NfsV1alpha1().NFSVolumeSnapshots("default").Get(ctx, "snap", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Empty(t, snapshot.Finalizers)
+	_, err = os.Stat(snapshot.Status.Path)
+	assert.True(t, os.IsNotExist(err))
+	assert.Equal(t, []string{snapshotBlock}, fakeQuoater.removed)
+}
+
+func TestSnapshotPath(t *testing.T) {
+	// the joined namespaces and names of the snapshots collide
+	first := &nfsv1alpha1.NFSVolumeSnapshot{ObjectMeta: metav1.ObjectMeta{Name: "c", Namespace: "a-b", UID: "uid-1"}}
+	second := &nfsv1alpha1.NFSVolumeSnapshot{ObjectMeta: metav1.ObjectMeta{Name: "b-c", Namespace: "a", UID: "uid-2"}}
+	assert.Equal(t, "/export/snapshots/uid-1", snapshotPath("/export", first))
+	assert.NotEqual(t, snapshotPath("/export", first), snapshotPath("/export", second))
+}
+
+func TestProvisionClone(t *testing.T) {
+	ctx := context.TODO()
+	exportPath := filepath.Join(mountPath, "test-claim")
+	defer os.RemoveAll(mountPath)
+
+	fakeQuoater := &recordingQuota{}
+	nfsserver := newCustomResource(types.NamespacedName{Name: "test-nfsserver", Namespace: "test-nfsserver"}).WithExports("share-1", "ReadWrite", "none", "test-claim").Generate()
+	sc := newDummyStorageClass("share-1", types.NamespacedName{Name: nfsserver.Name, Namespace: nfsserver.Namespace}, corev1.PersistentVolumeReclaimDelete)
+	pvc, pv := newBoundVolume(t, "data", exportPath, apiresource.MustParse("1Mi"))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(exportPath, "projects"), []byte{}, 0600))
+	p := &Provisioner{
+		client:     k8sclientfake.NewSimpleClientset(newServiceForNFSServer(nfsserver), sc, pvc, pv),
+		rookClient: rookclientfake.NewSimpleClientset(nfsserver),
+		quotaer:    fakeQuoater,
+		name:       testProvisionerName,
+	}
+
+	clone := newDummyPVC("clone", "default", apiresource.MustParse("2Mi"), "share-1")
+	clone.Spec.DataSource = &corev1.TypedLocalObjectReference{Kind: "PersistentVolumeClaim", Name: "data"}
+	cloned, _, err := p.Provision(ctx, controller.ProvisionOptions{StorageClass: sc, PVName: "clone-pv", PVC: clone})
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(exportPath, "default-clone-clone-pv"), cloned.Spec.NFS.Path)
+	content, err := ioutil.ReadFile(filepath.Join(cloned.Spec.NFS.Path, "data"))
+	assert.NoError(t, err)
+	assert.Equal(t, "data", string(content))
+
+	// the quota is set before the copy so that the copy cannot exceed the capacity of the clone
+	assert.Equal(t, []string{"default-clone-clone-pv:2097152:0"}, fakeQuoater.created)
+
+	// the clone cannot be smaller than the source
+	clone = newDummyPVC("small", "default", apiresource.MustParse("512Ki"), "share-1")
+	clone.Spec.DataSource = &corev1.TypedLocalObjectReference{Kind: "PersistentVolumeClaim", Name: "data"}
+	_, _, err = p.Provision(ctx, controller.ProvisionOptions{StorageClass: sc, PVName: "small-pv", PVC: clone})
+	assert.Error(t, err)
+
+	// only claims can be cloned
+	apiGroup := "snapshot.storage.k8s.io"
+	clone = newDummyPVC("snapshot", "default", apiresource.MustParse("1Mi"), "share-1")
+	clone.Spec.DataSource = &corev1.TypedLocalObjectReference{APIGroup: &apiGroup, Kind: "VolumeSnapshot", Name: "data"}
+	_, _, err = p.Provision(ctx, controller.ProvisionOptions{StorageClass: sc, PVName: "snapshot-pv", PVC: clone})
+	assert.Error(t, err)
+}
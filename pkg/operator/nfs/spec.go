@@ -81,6 +81,14 @@ func newStatefulSetForNFSServer(cr *nfsv1alpha1.NFSServer, clientset kubernetes.
 
 	privileged := true
 	replicas := int32(cr.Spec.Replicas)
+	serverArgs := []string{"nfs", "server", "--ganeshaConfigPath=" + nfsConfigMapPath + "/" + cr.Name}
+	provisionerArgs := []string{"nfs", "provisioner", "--provisioner=" + "nfs.rook.io/" + cr.Name + "-provisioner"}
+	// a single replica serves the exports without waiting for the lease, it has no standby to fail over to
+	if cr.Spec.Replicas > 1 {
+		leaseArg := "--leaseName=" + activeLeaseName(cr)
+		serverArgs = append(serverArgs, leaseArg)
+		provisionerArgs = append(provisionerArgs, leaseArg)
+	}
 	return &appsv1.StatefulSet{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      cr.Name,
@@ -90,6 +98,8 @@ func newStatefulSetForNFSServer(cr *nfsv1alpha1.NFSServer, clientset kubernetes.
 		Spec: appsv1.StatefulSetSpec{
 			Replicas:    &replicas,
 			ServiceName: cr.Name,
+			// the standbys are never ready, they must not block the start of the other replicas
+			PodManagementPolicy: appsv1.ParallelPodManagement,
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      cr.Name,
@@ -98,11 +108,26 @@ func newStatefulSetForNFSServer(cr *nfsv1alpha1.NFSServer, clientset kubernetes.
 				},
 				Spec: corev1.PodSpec{
 					ServiceAccountName: "rook-nfs-server",
+					// spread the replicas so that a node failure only takes down the active replica
+					Affinity: &corev1.Affinity{
+						PodAntiAffinity: &corev1.PodAntiAffinity{
+							PreferredDuringSchedulingIgnoredDuringExecution: []corev1.WeightedPodAffinityTerm{
+								{
+									Weight: 100,
+									PodAffinityTerm: corev1.PodAffinityTerm{
+										LabelSelector: &metav1.LabelSelector{MatchLabels: newLabels(cr)},
+										TopologyKey:   corev1.LabelHostname,
+									},
+								},
+							},
+						},
+					},
 					Containers: []corev1.Container{
 						{
 							Name:  "nfs-server",
 							Image: image,
-							Args:  []string{"nfs", "server", "--ganeshaConfigPath=" + nfsConfigMapPath + "/" + cr.Name},
+							Args:  serverArgs,
+							Env:   []corev1.EnvVar{k8sutil.NameEnvVar(), k8sutil.NamespaceEnvVar()},
 							Ports: []corev1.ContainerPort{
 								{
 									Name:          "nfs-port",
@@ -113,6 +138,13 @@ func newStatefulSetForNFSServer(cr *nfsv1alpha1.NFSServer, clientset kubernetes.
 									ContainerPort: int32(rpcPort),
 								},
 							},
+							// only the active replica serves the exports, the service sends the clients to the ready replica
+							ReadinessProbe: &corev1.Probe{
+								Handler: corev1.Handler{
+									TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromInt(int(nfsPort))},
+								},
+								PeriodSeconds: 5,
+							},
 							SecurityContext: &corev1.SecurityContext{
 								Capabilities: &corev1.Capabilities{
 									Add: []corev1.Capability{
@@ -125,7 +157,8 @@ func newStatefulSetForNFSServer(cr *nfsv1alpha1.NFSServer, clientset kubernetes.
 						{
 							Name:                     "nfs-provisioner",
 							Image:                    image,
-							Args:                     []string{"nfs", "provisioner", "--provisioner=" + "nfs.rook.io/" + cr.Name + "-provisioner"},
+							Args:                     provisionerArgs,
+							Env:                      []corev1.EnvVar{k8sutil.NameEnvVar(), k8sutil.NamespaceEnvVar()},
 							TerminationMessagePath:   "/dev/termination-log",
 							TerminationMessagePolicy: corev1.TerminationMessageReadFile,
 							SecurityContext: &corev1.SecurityContext{